@@ -3,10 +3,19 @@ package main
 import (
 	"log"
 	"os"
+	"strconv"
 
+	"github.com/adonese/cost-of-living/internal/auth"
+	"github.com/adonese/cost-of-living/internal/fx"
 	"github.com/adonese/cost-of-living/internal/handlers"
+	"github.com/adonese/cost-of-living/internal/importer"
 	customMiddleware "github.com/adonese/cost-of-living/internal/middleware"
-	"github.com/adonese/cost-of-living/internal/repository/postgres"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/openapi"
+	"github.com/adonese/cost-of-living/internal/ratelimit"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/backend"
+	"github.com/adonese/cost-of-living/internal/repository/cache"
 	"github.com/adonese/cost-of-living/internal/services/estimator"
 	uihandlers "github.com/adonese/cost-of-living/internal/ui/handlers"
 	"github.com/adonese/cost-of-living/pkg/database"
@@ -31,14 +40,68 @@ func main() {
 	defer db.Close()
 
 	// Initialize repositories
-	costDataPointRepo := postgres.NewCostDataPointRepository(db.GetConn())
+	var costDataPointRepo repository.CostDataPointRepository = backend.NewCostDataPointRepository(db)
 	logger.Info("Initialized CostDataPointRepository")
 
-	// Aggregation/estimator service
-	estimatorService := estimator.NewService(costDataPointRepo, nil)
+	// Cache list queries; the estimator issues several per estimate
+	cacheConfig, err := cache.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid cache configuration: %v", err)
+	}
+	if cacheConfig.Enabled() {
+		costDataPointRepo = cache.NewCostDataPointRepository(costDataPointRepo, cacheConfig)
+		logger.Info("Enabled repository cache", "size", cacheConfig.Size, "ttl", cacheConfig.TTL)
+	}
 
-	// Initialize Echo
+	// Exchange rates for the currency query parameter
+	rates, err := fx.NewStoreFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load FX rates: %v", err)
+	}
+
+	// API keys authenticate writes. Reads stay public unless
+	// AUTH_PUBLIC_READS=false, in which case they need a reader key.
+	authService := auth.NewService(backend.NewAPIKeyRepository(db))
+	publicReads := true
+	if s := os.Getenv("AUTH_PUBLIC_READS"); s != "" {
+		if publicReads, err = strconv.ParseBool(s); err != nil {
+			log.Fatalf("AUTH_PUBLIC_READS: invalid boolean %q", s)
+		}
+	}
+
+	// Per-client rate limits, shared between instances with the postgres store
+	rateLimitConfig, err := ratelimit.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid rate limit configuration: %v", err)
+	}
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore(rateLimitConfig.IdleTTL())
+	if rateLimitConfig.Store == ratelimit.StorePostgres {
+		if db.Driver() != database.DriverPostgres {
+			log.Fatalf("RATE_LIMIT_STORE=postgres requires PostgreSQL, got driver %q", db.Driver())
+		}
+		rateLimitStore = ratelimit.NewPostgresStore(db.GetConn(), rateLimitConfig.IdleTTL())
+	}
+	logger.Info("Configured rate limits",
+		"anonymous", rateLimitConfig.Anonymous.String(),
+		"api_key", rateLimitConfig.APIKey.String(),
+		"store", rateLimitConfig.Store,
+	)
+	rateLimit := customMiddleware.RateLimit(rateLimitStore, rateLimitConfig)
+
+	// Aggregation/estimator service. Trends read the price rollups where
+	// the database has them.
+	estimatorService := estimator.NewService(costDataPointRepo, &estimator.Config{
+		Rates:   rates,
+		Rollups: backend.NewPriceRollupRepository(db),
+	})
+
+	// Initialize Echo. Client IPs, used by the rate limiter, come from
+	// X-Forwarded-For only when a trusted proxy sets it.
 	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	if rateLimitConfig.TrustProxy {
+		e.IPExtractor = echo.ExtractIPFromXFFHeader()
+	}
 
 	// Basic middleware
 	e.Use(middleware.Logger())
@@ -52,7 +115,7 @@ func main() {
 	// Public UI routes
 	homeHandler := uihandlers.NewHomeHandler(estimatorService)
 	e.GET("/", homeHandler.Index)
-	e.POST("/ui/estimate", homeHandler.EstimatePartial)
+	e.POST("/ui/estimate", homeHandler.EstimatePartial, rateLimit)
 
 	// Health check route
 	e.GET("/health", handlers.NewHealthHandler(db).Health)
@@ -60,21 +123,25 @@ func main() {
 	// Metrics endpoint for Prometheus
 	e.GET("/metrics", echo.WrapHandler(promhttp.Handler()))
 
-	// API v1 routes
-	api := e.Group("/api/v1")
-
-	// Cost data points endpoints
-	costDataPointHandler := handlers.NewCostDataPointHandler(costDataPointRepo)
-	api.POST("/cost-data-points", costDataPointHandler.Create)
-	api.GET("/cost-data-points/:id", costDataPointHandler.GetByID)
-	api.GET("/cost-data-points", costDataPointHandler.List)
-	api.PUT("/cost-data-points/:id", costDataPointHandler.Update)
-	api.DELETE("/cost-data-points/:id", costDataPointHandler.Delete)
-
-	// Estimates + summary endpoints
-	estimateHandler := handlers.NewEstimatorHandler(estimatorService)
-	api.POST("/estimates", estimateHandler.Estimate)
-	api.GET("/estimates/summary", estimateHandler.Summary)
+	// API v1 routes. Every route accepts a key and is rate limited per key,
+	// or per IP without one, and requests are checked against the OpenAPI
+	// document before they reach a handler. Unless reads are public, reads
+	// need a reader key.
+	api := e.Group("/api/v1",
+		customMiddleware.Authenticate(authService),
+		rateLimit,
+		openapi.ValidateRequests(openapi.Spec()),
+	)
+	var read []echo.MiddlewareFunc
+	if !publicReads {
+		read = append(read, customMiddleware.RequireRole(models.RoleReader))
+	}
+	registerAPIRoutes(api, apiHandlers{
+		costDataPoints: handlers.NewCostDataPointHandlerWithRates(costDataPointRepo, rates),
+		imports:        handlers.NewImportHandler(importer.NewImporter(costDataPointRepo)),
+		estimates:      handlers.NewEstimatorHandler(estimatorService),
+		apiKeys:        handlers.NewAPIKeyHandler(authService),
+	}, read)
 
 	// Start server
 	port := os.Getenv("PORT")
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"github.com/adonese/cost-of-living/internal/handlers"
+	customMiddleware "github.com/adonese/cost-of-living/internal/middleware"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/openapi"
+	"github.com/labstack/echo/v4"
+)
+
+// apiHandlers are the handlers behind the /api/v1 routes
+type apiHandlers struct {
+	costDataPoints *handlers.CostDataPointHandler
+	imports        *handlers.ImportHandler
+	estimates      *handlers.EstimatorHandler
+	apiKeys        *handlers.APIKeyHandler
+}
+
+// registerAPIRoutes adds the /api/v1 routes to api. Writes need a
+// contributor key and key administration an admin key; read guards the
+// reads. Every route except the docs themselves must be described by
+// openapi.Spec, which TestRoutesMatchSpec checks.
+func registerAPIRoutes(api *echo.Group, h apiHandlers, read []echo.MiddlewareFunc) {
+	write := customMiddleware.RequireRole(models.RoleContributor)
+
+	// API description
+	spec := openapi.Spec()
+	api.GET("/openapi.json", openapi.SpecHandler(spec))
+	api.GET("/docs", openapi.DocsHandler(spec, openapi.BasePath+"/openapi.json"))
+
+	// Cost data points endpoints
+	api.POST("/cost-data-points", h.costDataPoints.Create, write)
+	api.GET("/cost-data-points/export", h.costDataPoints.Export, read...)
+	api.GET("/cost-data-points/:id", h.costDataPoints.GetByID, read...)
+	api.GET("/cost-data-points/:id/history", h.costDataPoints.History, read...)
+	api.GET("/cost-data-points", h.costDataPoints.List, read...)
+	api.PUT("/cost-data-points/:id", h.costDataPoints.Update, write)
+	api.DELETE("/cost-data-points/:id", h.costDataPoints.Delete, write)
+	api.POST("/cost-data-points/:id/restore", h.costDataPoints.Restore, write)
+
+	// Bulk import endpoint
+	api.POST("/cost-data-points/import", h.imports.Import, write)
+
+	// Estimates + summary endpoints; an estimate is a read despite the POST
+	api.POST("/estimates", h.estimates.Estimate, read...)
+	api.GET("/estimates/summary", h.estimates.Summary, read...)
+
+	// Price trends
+	api.GET("/trends", h.estimates.Trends, read...)
+
+	// Area comparison
+	api.GET("/areas/compare", h.estimates.CompareAreas, read...)
+
+	// API key administration
+	admin := api.Group("/admin", customMiddleware.RequireRole(models.RoleAdmin))
+	admin.POST("/api-keys", h.apiKeys.Create)
+	admin.GET("/api-keys", h.apiKeys.List)
+	admin.DELETE("/api-keys/:id", h.apiKeys.Revoke)
+}
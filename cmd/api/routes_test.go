@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/adonese/cost-of-living/internal/auth"
+	"github.com/adonese/cost-of-living/internal/handlers"
+	"github.com/adonese/cost-of-living/internal/importer"
+	"github.com/adonese/cost-of-living/internal/openapi"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/internal/services/estimator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// undocumented are the /api/v1 routes the spec does not describe
+var undocumented = map[string]bool{
+	"GET /api/v1/openapi.json": true,
+	"GET /api/v1/docs":         true,
+}
+
+func newTestServer() *echo.Echo {
+	repo := mock.NewCostDataPointRepository()
+
+	e := echo.New()
+	api := e.Group(openapi.BasePath, openapi.ValidateRequests(openapi.Spec()))
+	registerAPIRoutes(api, apiHandlers{
+		costDataPoints: handlers.NewCostDataPointHandler(repo),
+		imports:        handlers.NewImportHandler(importer.NewImporter(repo)),
+		estimates:      handlers.NewEstimatorHandler(estimator.NewService(repo, nil)),
+		apiKeys:        handlers.NewAPIKeyHandler(auth.NewService(mock.NewAPIKeyRepository())),
+	}, nil)
+	return e
+}
+
+// TestRoutesMatchSpec fails when a route is added without documenting it,
+// or the spec documents an operation that is not served
+func TestRoutesMatchSpec(t *testing.T) {
+	e := newTestServer()
+
+	var routes []string
+	for _, route := range e.Routes() {
+		if !strings.HasPrefix(route.Path, openapi.BasePath+"/") || route.Method == echo.RouteNotFound {
+			continue
+		}
+		if key := route.Method + " " + route.Path; !undocumented[key] {
+			routes = append(routes, route.Method+" "+openapi.FromEchoPath(route.Path))
+		}
+	}
+
+	var operations []string
+	for path, item := range openapi.Spec().Paths {
+		for method := range *item {
+			operations = append(operations, strings.ToUpper(method)+" "+path)
+		}
+	}
+
+	sort.Strings(routes)
+	sort.Strings(operations)
+	assert.Equal(t, operations, routes, "registerAPIRoutes and openapi.Spec disagree")
+}
+
+func TestSpecAndDocsAreServed(t *testing.T) {
+	e := newTestServer()
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	assert.NotNil(t, doc.Paths["/api/v1/cost-data-points/{id}"])
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/docs", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), echo.MIMETextHTML)
+	assert.Contains(t, rec.Body.String(), `id="createEstimate"`)
+}
+
+func TestRequestsAreValidatedAgainstSpec(t *testing.T) {
+	e := newTestServer()
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/cost-data-points?limit=ten", nil))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Invalid limit parameter")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/estimates", strings.NewReader(`{"adults": 2, "bedrooms": 1, "housing_type": "castle", "lifestyle": "budget", "emirate": "Dubai", "transport_mode": "public"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "housing_type must be one of apartment, villa, shared")
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/adonese/cost-of-living/internal/auth"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository/backend"
+	"github.com/adonese/cost-of-living/pkg/database"
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+// main manages API keys directly in the database. It is how the first admin
+// key is issued; after that keys can also be managed through the admin API.
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	command, args := os.Args[1], os.Args[2:]
+
+	logger.Init()
+
+	db, err := database.Connect(database.NewConfigFromEnv())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	service := auth.NewService(backend.NewAPIKeyRepository(db))
+	ctx := context.Background()
+
+	switch command {
+	case "issue":
+		flags := flag.NewFlagSet("issue", flag.ExitOnError)
+		name := flags.String("name", "", "Who or what the key is for, e.g. ingest-bot")
+		role := flags.String("role", string(models.RoleContributor), "Role of the key: reader, contributor or admin")
+		flags.Parse(args)
+
+		key, plaintext, err := service.Issue(ctx, *name, models.Role(*role), "cli")
+		if err != nil {
+			log.Fatalf("Failed to issue API key: %v", err)
+		}
+		fmt.Printf("Issued %s key %s (%s)\n", key.Role, key.Prefix, key.Name)
+		fmt.Println("Store this key now; it cannot be shown again:")
+		fmt.Println(plaintext)
+
+	case "list":
+		keys, err := service.List(ctx)
+		if err != nil {
+			log.Fatalf("Failed to list API keys: %v", err)
+		}
+		for _, key := range keys {
+			status := "active"
+			if key.Revoked() {
+				status = "revoked"
+			}
+			fmt.Printf("%-36s  %-8s  %-11s  %-7s  %s\n", key.ID, key.Prefix, key.Role, status, key.Name)
+		}
+
+	case "revoke":
+		if len(args) < 1 {
+			log.Fatal("Revoke command requires a key ID")
+		}
+		key, err := service.Revoke(ctx, args[0])
+		if err != nil {
+			log.Fatalf("Failed to revoke API key: %v", err)
+		}
+		fmt.Printf("Revoked key %s (%s)\n", key.Prefix, key.Name)
+
+	default:
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: apikey <command>")
+	fmt.Println("\nCommands:")
+	fmt.Println("  issue -name NAME [-role ROLE] - Issue a key and print it once")
+	fmt.Println("  list                          - List keys without their secrets")
+	fmt.Println("  revoke ID                     - Revoke a key")
+}
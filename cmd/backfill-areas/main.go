@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/adonese/cost-of-living/internal/gazetteer"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/backend"
+	"github.com/adonese/cost-of-living/internal/services"
+	"github.com/adonese/cost-of-living/pkg/database"
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+// main rewrites the area of stored listings to canonical gazetteer
+// communities, matching what the scrapers now store
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Report the renames without updating any rows")
+	category := flag.String("category", "Housing", "Only backfill this category (empty for all)")
+	source := flag.String("source", "", "Only backfill rows from this source, e.g. bayut")
+	emirate := flag.String("emirate", "", "Only backfill rows in this emirate")
+	flag.Parse()
+
+	logger.Init()
+
+	db, err := database.Connect(database.NewConfigFromEnv())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	filter := repository.ListFilter{
+		Category: *category,
+		Source:   *source,
+		Emirate:  *emirate,
+	}
+
+	backfill := services.NewAreaBackfill(backend.NewCostDataPointRepository(db), gazetteer.Default())
+	result, err := backfill.Run(context.Background(), filter, *dryRun)
+	if result != nil {
+		printResult(result)
+	}
+	if err != nil {
+		log.Fatalf("Area backfill failed: %v", err)
+	}
+}
+
+func printResult(result *services.AreaBackfillResult) {
+	if result.DryRun {
+		fmt.Println("Dry run: no rows were updated")
+	}
+
+	renames := make([]string, 0, len(result.Renames))
+	for rename := range result.Renames {
+		renames = append(renames, rename)
+	}
+	sort.Strings(renames)
+	for _, rename := range renames {
+		fmt.Printf("  %-60s %d\n", rename, result.Renames[rename])
+	}
+
+	fmt.Printf("Scanned %d listings, updated %d, failed %d\n", result.Scanned, result.Updated, result.Failed)
+
+	if unresolved := result.UnresolvedAreas(); len(unresolved) > 0 {
+		fmt.Printf("%d areas are not in the gazetteer:\n", len(unresolved))
+		for _, area := range unresolved {
+			fmt.Printf("  %-60s %d\n", area, result.Unresolved[area])
+		}
+	}
+}
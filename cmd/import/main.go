@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adonese/cost-of-living/internal/export"
+	"github.com/adonese/cost-of-living/internal/importer"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/backend"
+	"github.com/adonese/cost-of-living/pkg/database"
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+func main() {
+	file := flag.String("file", "", "CSV or NDJSON file to import (- for stdin)")
+	formatName := flag.String("format", "", "Input format: csv or ndjson (default: from file extension)")
+	mappingPath := flag.String("mapping", "", "JSON column-mapping spec")
+	dryRun := flag.Bool("dry-run", false, "Validate rows without writing them")
+	enforceMaxAge := flag.Bool("enforce-max-age", false, "Reject rows recorded more than a year ago")
+	batchSize := flag.Int("batch-size", importer.DefaultBatchSize, "Rows per validation and insert batch")
+	reportPath := flag.String("report", "", "Write the full per-row JSON report to this file")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	logger.Init()
+
+	format, err := resolveFormat(*formatName, *file)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var mapping importer.Mapping
+	if *mappingPath != "" {
+		data, err := os.ReadFile(*mappingPath)
+		if err != nil {
+			log.Fatalf("Failed to read mapping: %v", err)
+		}
+		if mapping, err = importer.ParseMapping(data); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	var input io.Reader = os.Stdin
+	if *file != "-" {
+		f, err := os.Open(*file)
+		if err != nil {
+			log.Fatalf("Failed to open input: %v", err)
+		}
+		defer f.Close()
+		input = f
+	}
+
+	// Dry runs only validate, so they work without a database
+	var repo repository.CostDataPointRepository
+	if !*dryRun {
+		db, err := database.Connect(database.NewConfigFromEnv())
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer db.Close()
+		repo = backend.NewCostDataPointRepository(db)
+	}
+
+	imp := importer.NewImporter(repo)
+	imp.SetBatchSize(*batchSize)
+
+	report, err := imp.Import(context.Background(), input, importer.Options{
+		Format:        format,
+		Mapping:       mapping,
+		DryRun:        *dryRun,
+		EnforceMaxAge: *enforceMaxAge,
+	})
+	if err != nil {
+		log.Fatalf("Import failed: %v", err)
+	}
+
+	if *reportPath != "" {
+		if err := writeReport(*reportPath, report); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	printSummary(report)
+	if report.Rejected > 0 {
+		os.Exit(2)
+	}
+}
+
+// resolveFormat uses the explicit format or falls back to the file extension
+func resolveFormat(name, file string) (export.Format, error) {
+	if name == "" {
+		switch strings.ToLower(filepath.Ext(file)) {
+		case ".ndjson", ".jsonl":
+			name = string(export.FormatNDJSON)
+		}
+	}
+	format, err := export.ParseFormat(name)
+	if err != nil || format == export.FormatParquetLite {
+		return "", fmt.Errorf("unsupported import format %q, use csv or ndjson", name)
+	}
+	return format, nil
+}
+
+func writeReport(path string, report *importer.Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+func printSummary(report *importer.Report) {
+	mode := "Imported"
+	if report.DryRun {
+		mode = "Validated (dry run)"
+	}
+	fmt.Printf("%s %d rows: %d accepted, %d rejected in %s\n",
+		mode, report.Total, report.Accepted, report.Rejected, report.Duration)
+
+	for _, row := range report.Rows {
+		if row.Status != importer.RowRejected {
+			continue
+		}
+		fmt.Printf("  line %d: %s\n", row.Line, strings.Join(row.Errors, "; "))
+	}
+}
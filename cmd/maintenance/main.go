@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/repository/postgres"
+	"github.com/adonese/cost-of-living/internal/services"
+	"github.com/adonese/cost-of-living/pkg/database"
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+// main runs the retention job once. The policy is read from the same environment
+// variables as the worker; flags override them.
+func main() {
+	policy, err := services.RetentionPolicyFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dryRun := flag.Bool("dry-run", false, "Count expired rows without compressing, archiving or deleting")
+	compressAfter := flag.Int("compress-after-days", -1, "Compress chunks older than this many days (0 disables, default from COMPRESS_AFTER_DAYS)")
+	retentionDays := flag.Int("retention-days", -1, "Default retention in days (0 keeps forever, default from RETENTION_DAYS)")
+	categoryDays := flag.String("category-retention", "", "Per-category retention, e.g. Housing=365,Food=730 (default from RETENTION_CATEGORY_DAYS)")
+	archiveDir := flag.String("archive-dir", policy.ArchiveDir, "Directory receiving NDJSON archives of expired rows")
+	batchSize := flag.Int("batch-size", policy.BatchSize, "Rows archived and deleted per transaction")
+	flag.Parse()
+
+	logger.Init()
+
+	policy.DryRun = *dryRun
+	policy.ArchiveDir = *archiveDir
+	policy.BatchSize = *batchSize
+	if *compressAfter >= 0 {
+		policy.CompressAfter = time.Duration(*compressAfter) * 24 * time.Hour
+	}
+	if *retentionDays >= 0 {
+		policy.DefaultRetention = time.Duration(*retentionDays) * 24 * time.Hour
+	}
+	if *categoryDays != "" {
+		if policy.CategoryRetention, err = services.ParseCategoryRetention(*categoryDays); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	db, err := database.Connect(database.NewConfigFromEnv())
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Compression and chunk retention are TimescaleDB features
+	if db.Driver() != database.DriverPostgres {
+		log.Fatalf("Maintenance requires PostgreSQL with TimescaleDB, got driver %q", db.Driver())
+	}
+
+	service := services.NewRetentionService(postgres.NewMaintenanceRepository(db.GetConn()), policy)
+	result, err := service.Run(context.Background())
+	if result != nil {
+		printResult(result)
+	}
+	if err != nil {
+		log.Fatalf("Retention failed: %v", err)
+	}
+}
+
+func printResult(result *services.RetentionResult) {
+	if result.DryRun {
+		fmt.Println("Dry run: nothing was compressed, archived or deleted")
+	} else if result.CompressAfter > 0 {
+		fmt.Printf("Compression policy: chunks older than %s\n", result.CompressAfter)
+	} else {
+		fmt.Println("Compression policy: disabled")
+	}
+
+	for _, scope := range result.Scopes {
+		fmt.Printf("  %-16s cutoff %s  expired %d", scope.Category, scope.Cutoff.Format(time.RFC3339), scope.Expired)
+		if scope.ArchiveFile != "" {
+			fmt.Printf("  -> %s", scope.ArchiveFile)
+		}
+		fmt.Println()
+	}
+	fmt.Printf("Total expired: %d\n", result.Expired)
+}
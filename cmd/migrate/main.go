@@ -8,7 +8,9 @@ import (
 
 	"github.com/adonese/cost-of-living/pkg/database"
 	"github.com/golang-migrate/migrate/v4"
+	migratedb "github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
@@ -28,17 +30,21 @@ func main() {
 	}
 	defer db.Close()
 
-	// Create migrate instance
-	driver, err := postgres.WithInstance(db.GetConn(), &postgres.Config{})
+	// Create migrate instance. SQLite has its own migration set under
+	// migrations/sqlite with the same version numbers.
+	var driver migratedb.Driver
+	source, driverName := "file://migrations", "postgres"
+	if db.Driver() == database.DriverSQLite {
+		source, driverName = "file://migrations/sqlite", "sqlite3"
+		driver, err = sqlite3.WithInstance(db.GetConn(), &sqlite3.Config{})
+	} else {
+		driver, err = postgres.WithInstance(db.GetConn(), &postgres.Config{})
+	}
 	if err != nil {
 		log.Fatalf("Failed to create migration driver: %v", err)
 	}
 
-	m, err := migrate.NewWithDatabaseInstance(
-		"file://migrations",
-		"postgres",
-		driver,
-	)
+	m, err := migrate.NewWithDatabaseInstance(source, driverName, driver)
 	if err != nil {
 		log.Fatalf("Failed to create migrate instance: %v", err)
 	}
@@ -7,7 +7,7 @@ import (
 	"os"
 	"time"
 
-	"github.com/adonese/cost-of-living/internal/repository/postgres"
+	"github.com/adonese/cost-of-living/internal/repository/backend"
 	"github.com/adonese/cost-of-living/internal/scrapers"
 	"github.com/adonese/cost-of-living/internal/scrapers/bayut"
 	"github.com/adonese/cost-of-living/internal/scrapers/dubizzle"
@@ -36,10 +36,11 @@ func main() {
 	logger.Info("Connected to database successfully")
 
 	// Create repository
-	repo := postgres.NewCostDataPointRepository(db.GetConn())
+	repo := backend.NewCostDataPointRepository(db)
 
 	// Create scraper service
 	service := services.NewScraperService(repo)
+	service.SetRunRepository(backend.NewScrapeRunRepository(db))
 
 	// Register scrapers
 	scraperConfig := scrapers.Config{
@@ -130,6 +131,7 @@ func logScrapeResult(res *services.ScrapeResult) {
 
 	logger.Info("Scraper summary",
 		"scraper", res.ScraperName,
+		"run_id", res.RunID,
 		"fetched", res.Fetched,
 		"validated", res.Validation.Valid,
 		"dropped_invalid", res.Validation.Invalid,
@@ -8,6 +8,7 @@ import (
 	"go.temporal.io/sdk/client"
 	"go.temporal.io/sdk/worker"
 
+	"github.com/adonese/cost-of-living/internal/notify"
 	"github.com/adonese/cost-of-living/internal/repository/postgres"
 	"github.com/adonese/cost-of-living/internal/scrapers"
 	"github.com/adonese/cost-of-living/internal/scrapers/aadc"
@@ -34,11 +35,18 @@ func main() {
 	}
 	defer db.Close()
 
-	// Create repository
+	// The retention workflow relies on TimescaleDB
+	if db.Driver() != database.DriverPostgres {
+		log.Fatalf("The worker requires PostgreSQL with TimescaleDB, got driver %q", db.Driver())
+	}
+
+	// Create repositories
 	repo := postgres.NewCostDataPointRepository(db.GetConn())
+	runRepo := postgres.NewScrapeRunRepository(db.GetConn())
 
 	// Create scraper service with validation enabled
 	scraperService := services.NewScraperService(repo)
+	scraperService.SetRunRepository(runRepo)
 
 	// Configure scrapers
 	scraperConfig := scrapers.Config{
@@ -61,10 +69,31 @@ func main() {
 
 	logger.Info("All scrapers registered", "total", allScrapers)
 
+	// Compensation rolls back data written by failed scrape runs
+	compensationAction, err := services.ParseCompensationAction(os.Getenv("SCRAPE_COMPENSATION_ACTION"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	var notifier notify.Notifier = notify.NewLogNotifier()
+	if webhookURL := os.Getenv("ALERT_WEBHOOK_URL"); webhookURL != "" {
+		notifier = notify.MultiNotifier{notifier, notify.NewWebhookNotifier(webhookURL, nil)}
+	}
+	compensation := services.NewCompensationService(repo, runRepo, notifier, compensationAction)
+
+	// Retention compresses old chunks and archives expired rows
+	retentionPolicy, err := services.RetentionPolicyFromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	retention := services.NewRetentionService(postgres.NewMaintenanceRepository(db.GetConn()), retentionPolicy)
+
 	// Set activity dependencies
 	workflow.SetActivityDependencies(&workflow.ScraperActivityDependencies{
 		ScraperService: scraperService,
 		Repository:     repo,
+		RunRepository:  runRepo,
+		Compensation:   compensation,
+		Retention:      retention,
 	})
 
 	// Get Temporal address from env
@@ -113,6 +142,9 @@ func main() {
 	w.RegisterWorkflow(workflow.CareemScraperWorkflow)
 	w.RegisterWorkflow(workflow.ScheduledCareemWorkflow)
 
+	// Register maintenance workflow
+	w.RegisterWorkflow(workflow.MaintenanceWorkflow)
+
 	// Register core activities
 	w.RegisterActivity(workflow.HelloActivity)
 	w.RegisterActivity(workflow.RunScraperActivity)
@@ -125,6 +157,9 @@ func main() {
 	w.RegisterActivity(workflow.DetectOutliersActivity)
 	w.RegisterActivity(workflow.CheckDuplicatesActivity)
 
+	// Register maintenance activities
+	w.RegisterActivity(workflow.RetentionActivity)
+
 	logger.Info("Worker starting...", "queue", "cost-of-living-task-queue")
 
 	// Start worker
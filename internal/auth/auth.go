@@ -0,0 +1,163 @@
+// Package auth issues and verifies API keys. A key has the form
+// col_<prefix>_<secret>: the prefix is stored in clear to look the key up and
+// to name it in logs and the revision history, while only the SHA-256 hash of
+// the whole key is stored, so a leaked database does not leak credentials.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+)
+
+// KeyPrefix starts every key so they are easy to spot in configs and scanners
+const KeyPrefix = "col"
+
+// touchInterval limits how often last_used_at is written for a busy key
+const touchInterval = time.Minute
+
+// ErrInvalidKey is returned for keys that are malformed, unknown or revoked.
+// The cases are not distinguished so callers cannot probe for valid prefixes.
+var ErrInvalidKey = errors.New("invalid api key")
+
+// Service issues, verifies and revokes API keys
+type Service struct {
+	repo repository.APIKeyRepository
+	now  func() time.Time
+}
+
+// NewService creates a service storing keys in repo
+func NewService(repo repository.APIKeyRepository) *Service {
+	return &Service{repo: repo, now: time.Now}
+}
+
+// Issue creates a key with the given role and returns it together with its
+// plaintext, which is not stored and cannot be recovered later
+func (s *Service) Issue(ctx context.Context, name string, role models.Role, createdBy string) (*models.APIKey, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", repository.ValidationFailed(errors.New("name is required"))
+	}
+	if !role.Valid() {
+		return nil, "", repository.ValidationFailed(fmt.Errorf("unknown role %q", role))
+	}
+
+	prefix, plaintext, err := generate()
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := &models.APIKey{
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      Hash(plaintext),
+		Role:      role,
+		CreatedBy: createdBy,
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	return key, plaintext, nil
+}
+
+// Authenticate returns the active key matching plaintext, or ErrInvalidKey
+func (s *Service) Authenticate(ctx context.Context, plaintext string) (*models.APIKey, error) {
+	prefix, ok := parse(plaintext)
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+
+	key, err := s.repo.GetByPrefix(ctx, prefix)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(Hash(plaintext))) != 1 || key.Revoked() {
+		return nil, ErrInvalidKey
+	}
+
+	now := s.now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= touchInterval {
+		// Usage tracking is best effort and must not fail the request
+		if err := s.repo.Touch(ctx, key.ID, now); err == nil {
+			key.LastUsedAt = &now
+		}
+	}
+
+	return key, nil
+}
+
+// List returns every key, newest first
+func (s *Service) List(ctx context.Context) ([]*models.APIKey, error) {
+	return s.repo.List(ctx)
+}
+
+// Revoke revokes a key so it can no longer authenticate
+func (s *Service) Revoke(ctx context.Context, id string) (*models.APIKey, error) {
+	if err := s.repo.Revoke(ctx, id, s.now().UTC()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Hash returns the hex SHA-256 digest stored for a key
+func Hash(plaintext string) string {
+	sum := sha256.Sum256([]byte(plaintext))
+	return hex.EncodeToString(sum[:])
+}
+
+// generate returns a new random prefix and the full key containing it
+func generate() (prefix, plaintext string, err error) {
+	idBytes := make([]byte, 4)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", fmt.Errorf("generate api key: %w", err)
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", fmt.Errorf("generate api key: %w", err)
+	}
+
+	prefix = hex.EncodeToString(idBytes)
+	plaintext = KeyPrefix + "_" + prefix + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return prefix, plaintext, nil
+}
+
+// parse extracts the prefix from a key, reporting whether it is well formed
+func parse(plaintext string) (string, bool) {
+	parts := strings.SplitN(plaintext, "_", 3)
+	if len(parts) != 3 || parts[0] != KeyPrefix || len(parts[1]) != 8 || parts[2] == "" {
+		return "", false
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil {
+		return "", false
+	}
+	return parts[1], true
+}
+
+type contextKey struct{}
+
+// WithKey returns a context carrying the key a request authenticated with
+func WithKey(ctx context.Context, key *models.APIKey) context.Context {
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+// KeyFromContext returns the key stored by WithKey, or nil for anonymous
+// requests
+func KeyFromContext(ctx context.Context) *models.APIKey {
+	key, _ := ctx.Value(contextKey{}).(*models.APIKey)
+	return key
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+)
+
+func TestIssueAndAuthenticate(t *testing.T) {
+	repo := mock.NewAPIKeyRepository()
+	svc := NewService(repo)
+	ctx := context.Background()
+
+	key, plaintext, err := svc.Issue(ctx, " ingest bot ", models.RoleContributor, "apikey:admin001")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(plaintext, "col_"+key.Prefix+"_"))
+	assert.Equal(t, "ingest bot", key.Name)
+	assert.Equal(t, Hash(plaintext), key.Hash)
+	assert.NotContains(t, key.Hash, plaintext, "the plaintext is never stored")
+
+	got, err := svc.Authenticate(ctx, plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, key.ID, got.ID)
+	assert.Equal(t, models.RoleContributor, got.Role)
+	assert.NotNil(t, got.LastUsedAt)
+}
+
+func TestIssueValidates(t *testing.T) {
+	svc := NewService(mock.NewAPIKeyRepository())
+
+	_, _, err := svc.Issue(context.Background(), "", models.RoleReader, "")
+	assert.True(t, errors.Is(err, repository.ErrValidationFailed))
+
+	_, _, err = svc.Issue(context.Background(), "bot", models.Role("owner"), "")
+	assert.True(t, errors.Is(err, repository.ErrValidationFailed))
+}
+
+func TestAuthenticateRejectsInvalidKeys(t *testing.T) {
+	svc := NewService(mock.NewAPIKeyRepository())
+	ctx := context.Background()
+
+	key, plaintext, err := svc.Issue(ctx, "bot", models.RoleReader, "")
+	require.NoError(t, err)
+
+	for name, candidate := range map[string]string{
+		"empty":          "",
+		"malformed":      "not-a-key",
+		"wrong scheme":   "xyz_" + key.Prefix + "_secret",
+		"unknown prefix": "col_00000000_secret",
+		"wrong secret":   "col_" + key.Prefix + "_secret",
+	} {
+		_, err := svc.Authenticate(ctx, candidate)
+		assert.ErrorIs(t, err, ErrInvalidKey, name)
+	}
+
+	_, err = svc.Revoke(ctx, key.ID)
+	require.NoError(t, err)
+	_, err = svc.Authenticate(ctx, plaintext)
+	assert.ErrorIs(t, err, ErrInvalidKey, "revoked keys no longer authenticate")
+}
+
+func TestAuthenticateThrottlesTouch(t *testing.T) {
+	repo := mock.NewAPIKeyRepository()
+	svc := NewService(repo)
+	now := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, plaintext, err := svc.Issue(ctx, "bot", models.RoleReader, "")
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err := svc.Authenticate(ctx, plaintext)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, repo.GetCallCount("Touch"))
+
+	now = now.Add(2 * time.Minute)
+	_, err = svc.Authenticate(ctx, plaintext)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.GetCallCount("Touch"))
+}
+
+func TestRevokeUnknownKey(t *testing.T) {
+	svc := NewService(mock.NewAPIKeyRepository())
+
+	_, err := svc.Revoke(context.Background(), "missing")
+	assert.True(t, errors.Is(err, repository.ErrNotFound))
+}
+
+func TestKeyContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, KeyFromContext(ctx))
+
+	key := &models.APIKey{Prefix: "3f9a1c2b", Role: models.RoleAdmin}
+	assert.Same(t, key, KeyFromContext(WithKey(ctx, key)))
+}
@@ -0,0 +1,115 @@
+package export
+
+import (
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+)
+
+// ColumnType describes the value type of an export column
+type ColumnType string
+
+const (
+	TypeString ColumnType = "string"
+	TypeFloat  ColumnType = "float"
+	TypeInt    ColumnType = "int"
+	TypeTime   ColumnType = "timestamp"
+	TypeList   ColumnType = "list<string>"
+	TypeAny    ColumnType = "any"
+)
+
+// Column is a single flattened field of a cost data point. Value returns nil
+// when the field is unset.
+type Column struct {
+	Name  string
+	Type  ColumnType
+	Value func(cdp *models.CostDataPoint) interface{}
+}
+
+// AttributePrefix is prepended to the column name of each exported attribute
+const AttributePrefix = "attr_"
+
+// Columns returns the export columns: the fixed data point fields, the
+// flattened location, then one column per requested attribute
+func Columns(attributes []string) []Column {
+	columns := []Column{
+		{"id", TypeString, func(c *models.CostDataPoint) interface{} { return c.ID }},
+		{"category", TypeString, func(c *models.CostDataPoint) interface{} { return c.Category }},
+		{"sub_category", TypeString, func(c *models.CostDataPoint) interface{} { return optionalString(c.SubCategory) }},
+		{"item_name", TypeString, func(c *models.CostDataPoint) interface{} { return c.ItemName }},
+		{"price", TypeFloat, func(c *models.CostDataPoint) interface{} { return c.Price }},
+		{"min_price", TypeFloat, func(c *models.CostDataPoint) interface{} { return optionalFloat(c.MinPrice) }},
+		{"max_price", TypeFloat, func(c *models.CostDataPoint) interface{} { return optionalFloat(c.MaxPrice) }},
+		{"median_price", TypeFloat, func(c *models.CostDataPoint) interface{} { return optionalFloat(c.MedianPrice) }},
+		{"sample_size", TypeInt, func(c *models.CostDataPoint) interface{} { return c.SampleSize }},
+		{"currency", TypeString, func(c *models.CostDataPoint) interface{} { return c.Currency }},
+		{"unit", TypeString, func(c *models.CostDataPoint) interface{} { return c.Unit }},
+		{"period", TypeString, func(c *models.CostDataPoint) interface{} { return c.Period }},
+		{"confidence", TypeFloat, func(c *models.CostDataPoint) interface{} { return float64(c.Confidence) }},
+		{"source", TypeString, func(c *models.CostDataPoint) interface{} { return c.Source }},
+		{"source_url", TypeString, func(c *models.CostDataPoint) interface{} { return optionalString(c.SourceURL) }},
+		{"location_emirate", TypeString, func(c *models.CostDataPoint) interface{} { return c.Location.Emirate }},
+		{"location_city", TypeString, func(c *models.CostDataPoint) interface{} { return optionalString(c.Location.City) }},
+		{"location_area", TypeString, func(c *models.CostDataPoint) interface{} { return optionalString(c.Location.Area) }},
+		{"location_lat", TypeFloat, func(c *models.CostDataPoint) interface{} {
+			if c.Location.Coordinates == nil {
+				return nil
+			}
+			return c.Location.Coordinates.Lat
+		}},
+		{"location_lon", TypeFloat, func(c *models.CostDataPoint) interface{} {
+			if c.Location.Coordinates == nil {
+				return nil
+			}
+			return c.Location.Coordinates.Lon
+		}},
+		{"tags", TypeList, func(c *models.CostDataPoint) interface{} {
+			if len(c.Tags) == 0 {
+				return nil
+			}
+			return c.Tags
+		}},
+		{"recorded_at", TypeTime, func(c *models.CostDataPoint) interface{} { return c.RecordedAt }},
+		{"valid_from", TypeTime, func(c *models.CostDataPoint) interface{} { return c.ValidFrom }},
+		{"valid_to", TypeTime, func(c *models.CostDataPoint) interface{} { return optionalTime(c.ValidTo) }},
+		{"first_seen_at", TypeTime, func(c *models.CostDataPoint) interface{} { return optionalTime(&c.FirstSeenAt) }},
+		{"last_seen_at", TypeTime, func(c *models.CostDataPoint) interface{} { return optionalTime(&c.LastSeenAt) }},
+		{"run_id", TypeString, func(c *models.CostDataPoint) interface{} { return optionalString(c.RunID) }},
+	}
+
+	for _, name := range attributes {
+		name := name
+		columns = append(columns, Column{
+			Name: AttributePrefix + name,
+			Type: TypeAny,
+			Value: func(c *models.CostDataPoint) interface{} {
+				return c.Attributes[name]
+			},
+		})
+	}
+
+	return columns
+}
+
+func optionalString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// optionalFloat treats zero as unset, matching how the repository stores
+// absent min/max/median prices
+func optionalFloat(f float64) interface{} {
+	if f == 0 {
+		return nil
+	}
+	return f
+}
+
+func optionalTime(t *time.Time) interface{} {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return *t
+}
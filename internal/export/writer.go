@@ -0,0 +1,267 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+)
+
+// Format is an export file format
+type Format string
+
+const (
+	FormatCSV    Format = "csv"
+	FormatNDJSON Format = "ndjson"
+	// FormatParquetLite is a columnar, newline-delimited JSON layout: a schema
+	// line followed by one line per row group holding an array per column. It
+	// is NOT Apache Parquet; it exists so columnar consumers (e.g. pandas via
+	// pd.DataFrame(group["columns"])) can load large exports without a
+	// Parquet dependency on either side.
+	FormatParquetLite Format = "parquet-lite"
+)
+
+// ParseFormat validates a format name, defaulting to CSV
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(s)) {
+	case "", FormatCSV:
+		return FormatCSV, nil
+	case FormatNDJSON:
+		return FormatNDJSON, nil
+	case FormatParquetLite:
+		return FormatParquetLite, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q", s)
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatNDJSON, FormatParquetLite:
+		return "application/x-ndjson"
+	default:
+		return "text/csv; charset=utf-8"
+	}
+}
+
+// Extension returns the file extension of the format
+func (f Format) Extension() string {
+	switch f {
+	case FormatNDJSON:
+		return "ndjson"
+	case FormatParquetLite:
+		return "pql.ndjson"
+	default:
+		return "csv"
+	}
+}
+
+// Writer encodes cost data points one at a time. Close flushes buffered
+// output but does not close the underlying io.Writer.
+type Writer interface {
+	Write(cdp *models.CostDataPoint) error
+	Close() error
+}
+
+// DefaultRowGroupSize is the number of rows per parquet-lite row group
+const DefaultRowGroupSize = 1000
+
+// NewWriter creates a writer for format over w. attributes selects which
+// attribute keys become columns.
+func NewWriter(format Format, w io.Writer, attributes []string) (Writer, error) {
+	columns := Columns(attributes)
+
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, columns)
+	case FormatNDJSON:
+		return &ndjsonWriter{w: w, columns: columns}, nil
+	case FormatParquetLite:
+		return newParquetLiteWriter(w, columns, DefaultRowGroupSize)
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// csvWriter writes a header row followed by one row per data point
+type csvWriter struct {
+	w       *csv.Writer
+	columns []Column
+	record  []string
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), columns: columns, record: make([]string, len(columns))}
+
+	for i, col := range columns {
+		cw.record[i] = col.Name
+	}
+	if err := cw.w.Write(cw.record); err != nil {
+		return nil, fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	return cw, nil
+}
+
+func (cw *csvWriter) Write(cdp *models.CostDataPoint) error {
+	for i, col := range cw.columns {
+		cw.record[i] = formatCSV(col.Value(cdp))
+	}
+	if err := cw.w.Write(cw.record); err != nil {
+		return fmt.Errorf("failed to write csv row: %w", err)
+	}
+	return nil
+}
+
+func (cw *csvWriter) Close() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+// formatCSV renders a column value as a CSV field
+func formatCSV(v interface{}) string {
+	switch value := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(value)
+	case time.Time:
+		return value.UTC().Format(time.RFC3339Nano)
+	case []string:
+		return strings.Join(value, ";")
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(value)
+		return string(data)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// ndjsonWriter writes one flat JSON object per line, keys in column order
+type ndjsonWriter struct {
+	w       io.Writer
+	columns []Column
+	buf     bytes.Buffer
+}
+
+func (nw *ndjsonWriter) Write(cdp *models.CostDataPoint) error {
+	nw.buf.Reset()
+	nw.buf.WriteByte('{')
+	for i, col := range nw.columns {
+		if i > 0 {
+			nw.buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(col.Name)
+		nw.buf.Write(key)
+		nw.buf.WriteByte(':')
+
+		value, err := json.Marshal(col.Value(cdp))
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", col.Name, err)
+		}
+		nw.buf.Write(value)
+	}
+	nw.buf.WriteString("}\n")
+
+	if _, err := nw.w.Write(nw.buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write ndjson row: %w", err)
+	}
+	return nil
+}
+
+func (nw *ndjsonWriter) Close() error {
+	return nil
+}
+
+// parquetLiteSchema is the first line of a parquet-lite export
+type parquetLiteSchema struct {
+	Format       string              `json:"format"`
+	Version      int                 `json:"version"`
+	RowGroupSize int                 `json:"row_group_size"`
+	Columns      []parquetLiteColumn `json:"columns"`
+}
+
+type parquetLiteColumn struct {
+	Name string     `json:"name"`
+	Type ColumnType `json:"type"`
+}
+
+// parquetLiteRowGroup is one subsequent line of a parquet-lite export
+type parquetLiteRowGroup struct {
+	Rows    int                      `json:"rows"`
+	Columns map[string][]interface{} `json:"columns"`
+}
+
+// parquetLiteWriter buffers at most one row group in memory
+type parquetLiteWriter struct {
+	enc     *json.Encoder
+	columns []Column
+	size    int
+	group   parquetLiteRowGroup
+}
+
+func newParquetLiteWriter(w io.Writer, columns []Column, rowGroupSize int) (*parquetLiteWriter, error) {
+	pw := &parquetLiteWriter{enc: json.NewEncoder(w), columns: columns, size: rowGroupSize}
+
+	schema := parquetLiteSchema{
+		Format:       string(FormatParquetLite),
+		Version:      1,
+		RowGroupSize: rowGroupSize,
+		Columns:      make([]parquetLiteColumn, len(columns)),
+	}
+	for i, col := range columns {
+		schema.Columns[i] = parquetLiteColumn{Name: col.Name, Type: col.Type}
+	}
+	if err := pw.enc.Encode(schema); err != nil {
+		return nil, fmt.Errorf("failed to write parquet-lite schema: %w", err)
+	}
+
+	pw.reset()
+	return pw, nil
+}
+
+func (pw *parquetLiteWriter) reset() {
+	pw.group.Rows = 0
+	pw.group.Columns = make(map[string][]interface{}, len(pw.columns))
+	for _, col := range pw.columns {
+		pw.group.Columns[col.Name] = make([]interface{}, 0, pw.size)
+	}
+}
+
+func (pw *parquetLiteWriter) Write(cdp *models.CostDataPoint) error {
+	for _, col := range pw.columns {
+		pw.group.Columns[col.Name] = append(pw.group.Columns[col.Name], col.Value(cdp))
+	}
+	pw.group.Rows++
+
+	if pw.group.Rows >= pw.size {
+		return pw.flush()
+	}
+	return nil
+}
+
+func (pw *parquetLiteWriter) flush() error {
+	if pw.group.Rows == 0 {
+		return nil
+	}
+	if err := pw.enc.Encode(pw.group); err != nil {
+		return fmt.Errorf("failed to write parquet-lite row group: %w", err)
+	}
+	pw.reset()
+	return nil
+}
+
+func (pw *parquetLiteWriter) Close() error {
+	return pw.flush()
+}
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleDataPoint() *models.CostDataPoint {
+	recorded := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &models.CostDataPoint{
+		ID:          "cdp-1",
+		Category:    "Housing",
+		SubCategory: "Rent",
+		ItemName:    "1BR Apartment, Marina",
+		Price:       85000,
+		SampleSize:  1,
+		Location: models.Location{
+			Emirate:     "Dubai",
+			City:        "Dubai",
+			Area:        "Marina",
+			Coordinates: &models.GeoPoint{Lat: 25.08, Lon: 55.14},
+		},
+		RecordedAt: recorded,
+		ValidFrom:  recorded,
+		Source:     "bayut",
+		Confidence: 0.9,
+		Currency:   "AED",
+		Period:     "year",
+		Tags:       []string{"rent", "apartment"},
+		Attributes: map[string]interface{}{"bedrooms": float64(1), "furnished": true},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for input, want := range map[string]Format{
+		"":             FormatCSV,
+		"csv":          FormatCSV,
+		"NDJSON":       FormatNDJSON,
+		"parquet-lite": FormatParquetLite,
+	} {
+		got, err := ParseFormat(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+
+	_, err := ParseFormat("xlsx")
+	assert.Error(t, err)
+}
+
+func TestCSVWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatCSV, &buf, []string{"bedrooms", "missing"})
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write(sampleDataPoint()))
+	require.NoError(t, w.Close())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	row := make(map[string]string)
+	for i, name := range records[0] {
+		row[name] = records[1][i]
+	}
+
+	assert.Equal(t, "1BR Apartment, Marina", row["item_name"])
+	assert.Equal(t, "85000", row["price"])
+	assert.Equal(t, "", row["min_price"])
+	assert.Equal(t, "Marina", row["location_area"])
+	assert.Equal(t, "25.08", row["location_lat"])
+	assert.Equal(t, "rent;apartment", row["tags"])
+	assert.Equal(t, "2025-03-01T12:00:00Z", row["recorded_at"])
+	assert.Equal(t, "1", row["attr_bedrooms"])
+	assert.Equal(t, "", row["attr_missing"])
+	assert.NotContains(t, row, "attr_furnished")
+}
+
+func TestNDJSONWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(FormatNDJSON, &buf, []string{"furnished"})
+	require.NoError(t, err)
+
+	require.NoError(t, w.Write(sampleDataPoint()))
+	require.NoError(t, w.Write(sampleDataPoint()))
+	require.NoError(t, w.Close())
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], `{"id":"cdp-1","category":"Housing"`), "keys keep column order")
+
+	var row map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &row))
+	assert.Equal(t, "Dubai", row["location_emirate"])
+	assert.Equal(t, 55.14, row["location_lon"])
+	assert.Equal(t, true, row["attr_furnished"])
+	assert.Nil(t, row["valid_to"])
+}
+
+func TestParquetLiteWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := newParquetLiteWriter(&buf, Columns(nil), 2)
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, w.Write(sampleDataPoint()))
+	}
+	require.NoError(t, w.Close())
+
+	scanner := bufio.NewScanner(&buf)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.Len(t, lines, 3, "schema plus two row groups")
+
+	var schema parquetLiteSchema
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &schema))
+	assert.Equal(t, "parquet-lite", schema.Format)
+	assert.Equal(t, "id", schema.Columns[0].Name)
+
+	var first, last parquetLiteRowGroup
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &last))
+	assert.Equal(t, 2, first.Rows)
+	assert.Len(t, first.Columns["price"], 2)
+	assert.Equal(t, 1, last.Rows)
+	assert.Equal(t, []interface{}{"Marina"}, last.Columns["location_area"])
+}
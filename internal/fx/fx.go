@@ -0,0 +1,202 @@
+// Package fx converts amounts between currencies. Prices are collected in
+// AED; the API converts them for users budgeting in other currencies using
+// a rate table supplied by a pluggable Provider.
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+// BaseCurrency is the currency data points are recorded in
+const BaseCurrency = models.DefaultCurrency
+
+// DefaultRefresh is how often a Store reloads rates from its provider
+const DefaultRefresh = time.Hour
+
+// ErrUnsupportedCurrency is returned for currencies missing from the rate table
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+// RateTable holds exchange rates quoted against Base: one unit of Base buys
+// Rates[code] units of code
+type RateTable struct {
+	Base   string             `json:"base"`
+	Date   string             `json:"date"`
+	Source string             `json:"source,omitempty"`
+	Rates  map[string]float64 `json:"rates"`
+}
+
+// Validate checks the table is usable
+func (t *RateTable) Validate() error {
+	if NormalizeCode(t.Base) == "" {
+		return fmt.Errorf("rate table: invalid base currency %q", t.Base)
+	}
+	if _, err := time.Parse("2006-01-02", t.Date); err != nil {
+		return fmt.Errorf("rate table: date must be YYYY-MM-DD: %w", err)
+	}
+	for code, rate := range t.Rates {
+		if NormalizeCode(code) != code {
+			return fmt.Errorf("rate table: invalid currency code %q", code)
+		}
+		if rate <= 0 {
+			return fmt.Errorf("rate table: rate for %s must be positive", code)
+		}
+	}
+	if rate, ok := t.Rates[t.Base]; ok && rate != 1 {
+		return fmt.Errorf("rate table: base currency %s must have rate 1", t.Base)
+	}
+	return nil
+}
+
+// Rate is the conversion applied to an amount, as reported to clients
+type Rate struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Rate   float64 `json:"rate"`
+	Date   string  `json:"date"`
+	Source string  `json:"source,omitempty"`
+}
+
+// Convert applies the rate to an amount in From
+func (r Rate) Convert(amount float64) float64 {
+	return amount * r.Rate
+}
+
+// Provider supplies the current rate table
+type Provider interface {
+	Latest(ctx context.Context) (*RateTable, error)
+}
+
+// Store serves rates from a provider, reloading them every refresh
+// interval. If a reload fails the previous table keeps being served.
+type Store struct {
+	provider Provider
+	refresh  time.Duration
+	now      func() time.Time
+
+	mu      sync.Mutex
+	table   *RateTable
+	fetched time.Time
+}
+
+// NewStore creates a store backed by provider. refresh <= 0 uses
+// DefaultRefresh.
+func NewStore(provider Provider, refresh time.Duration) *Store {
+	if refresh <= 0 {
+		refresh = DefaultRefresh
+	}
+	return &Store{
+		provider: provider,
+		refresh:  refresh,
+		now:      time.Now,
+	}
+}
+
+// NewStoreFromEnv creates a store reading FX_RATES_FILE, or the embedded
+// reference rates when it is unset, and reloading every FX_REFRESH (a Go
+// duration such as "6h")
+func NewStoreFromEnv() (*Store, error) {
+	refresh := DefaultRefresh
+	if s := os.Getenv("FX_REFRESH"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("FX_REFRESH: invalid duration %q", s)
+		}
+		refresh = d
+	}
+
+	store := NewStore(NewFileProvider(os.Getenv("FX_RATES_FILE")), refresh)
+
+	// Fail at startup rather than on the first request
+	if _, err := store.Table(context.Background()); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Table returns the current rate table, reloading it if it is stale
+func (s *Store) Table(ctx context.Context) (*RateTable, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.table != nil && s.now().Sub(s.fetched) < s.refresh {
+		return s.table, nil
+	}
+
+	table, err := s.provider.Latest(ctx)
+	if err == nil {
+		err = table.Validate()
+	}
+	if err != nil {
+		if s.table != nil {
+			logger.Warn("Failed to refresh FX rates, serving previous table", "error", err, "date", s.table.Date)
+			s.fetched = s.now()
+			return s.table, nil
+		}
+		return nil, fmt.Errorf("load fx rates: %w", err)
+	}
+
+	s.table, s.fetched = table, s.now()
+	return table, nil
+}
+
+// Rate returns the rate converting from one currency to another
+func (s *Store) Rate(ctx context.Context, from, to string) (Rate, error) {
+	from, to = NormalizeCode(from), NormalizeCode(to)
+
+	table, err := s.Table(ctx)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	fromRate, err := table.rate(from)
+	if err != nil {
+		return Rate{}, err
+	}
+	toRate, err := table.rate(to)
+	if err != nil {
+		return Rate{}, err
+	}
+
+	return Rate{
+		From:   from,
+		To:     to,
+		Rate:   toRate / fromRate,
+		Date:   table.Date,
+		Source: table.Source,
+	}, nil
+}
+
+func (t *RateTable) rate(code string) (float64, error) {
+	if code == t.Base {
+		return 1, nil
+	}
+	rate, ok := t.Rates[code]
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrUnsupportedCurrency, code)
+	}
+	return rate, nil
+}
+
+// NormalizeCode upper-cases a three-letter currency code, returning "" for
+// anything else
+func NormalizeCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != 3 {
+		return ""
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return ""
+		}
+	}
+	return code
+}
@@ -0,0 +1,175 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+type stubProvider struct {
+	table *RateTable
+	err   error
+	calls int
+}
+
+func (p *stubProvider) Latest(ctx context.Context) (*RateTable, error) {
+	p.calls++
+	return p.table, p.err
+}
+
+func testTable() *RateTable {
+	return &RateTable{
+		Base:   "AED",
+		Date:   "2025-11-01",
+		Source: "test",
+		Rates:  map[string]float64{"AED": 1, "USD": 0.25, "INR": 24},
+	}
+}
+
+func TestStoreRate(t *testing.T) {
+	store := NewStore(&stubProvider{table: testTable()}, 0)
+	ctx := context.Background()
+
+	rate, err := store.Rate(ctx, "AED", "usd")
+	require.NoError(t, err)
+	assert.Equal(t, Rate{From: "AED", To: "USD", Rate: 0.25, Date: "2025-11-01", Source: "test"}, rate)
+	assert.InDelta(t, 250, rate.Convert(1000), 1e-9)
+
+	// Cross rates go through the base currency
+	rate, err = store.Rate(ctx, "USD", "INR")
+	require.NoError(t, err)
+	assert.InDelta(t, 96, rate.Rate, 1e-9)
+
+	rate, err = store.Rate(ctx, "AED", "AED")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, rate.Rate)
+}
+
+func TestStoreRateUnsupportedCurrency(t *testing.T) {
+	store := NewStore(&stubProvider{table: testTable()}, 0)
+
+	for _, code := range []string{"XYZ", "dollars", ""} {
+		_, err := store.Rate(context.Background(), "AED", code)
+		assert.True(t, errors.Is(err, ErrUnsupportedCurrency), "%q: %v", code, err)
+	}
+}
+
+func TestStoreRefresh(t *testing.T) {
+	logger.Init()
+
+	provider := &stubProvider{table: testTable()}
+	store := NewStore(provider, time.Hour)
+	now := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := store.Table(ctx)
+	require.NoError(t, err)
+	_, err = store.Table(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, provider.calls, "table is cached until it goes stale")
+
+	updated := testTable()
+	updated.Date = "2025-11-02"
+	updated.Rates["USD"] = 0.27
+	provider.table = updated
+	now = now.Add(time.Hour)
+
+	rate, err := store.Rate(ctx, "AED", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, 2, provider.calls)
+	assert.Equal(t, "2025-11-02", rate.Date)
+	assert.Equal(t, 0.27, rate.Rate)
+
+	// A failed refresh keeps serving the previous table
+	provider.table, provider.err = nil, errors.New("provider down")
+	now = now.Add(time.Hour)
+
+	rate, err = store.Rate(ctx, "AED", "USD")
+	require.NoError(t, err)
+	assert.Equal(t, 3, provider.calls)
+	assert.Equal(t, "2025-11-02", rate.Date)
+}
+
+func TestStoreFailsWithoutTable(t *testing.T) {
+	store := NewStore(&stubProvider{err: errors.New("provider down")}, 0)
+
+	_, err := store.Rate(context.Background(), "AED", "USD")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrUnsupportedCurrency))
+}
+
+func TestRateTableValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*RateTable)
+	}{
+		{"missing base", func(t *RateTable) { t.Base = "" }},
+		{"bad date", func(t *RateTable) { t.Date = "1 Nov 2025" }},
+		{"lowercase code", func(t *RateTable) { t.Rates["eur"] = 0.23 }},
+		{"zero rate", func(t *RateTable) { t.Rates["EUR"] = 0 }},
+		{"base rate not one", func(t *RateTable) { t.Rates["AED"] = 2 }},
+	}
+
+	assert.NoError(t, testTable().Validate())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := testTable()
+			tt.modify(table)
+			assert.Error(t, table.Validate())
+		})
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	ctx := context.Background()
+
+	table, err := NewFileProvider("").Latest(ctx)
+	require.NoError(t, err)
+	require.NoError(t, table.Validate())
+	assert.Equal(t, BaseCurrency, table.Base)
+	assert.Contains(t, table.Rates, "USD")
+
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"base":"AED","date":"2025-12-01","rates":{"USD":0.27}}`), 0o644))
+
+	table, err = NewFileProvider(path).Latest(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-12-01", table.Date)
+	assert.Equal(t, 0.27, table.Rates["USD"])
+
+	_, err = NewFileProvider(filepath.Join(t.TempDir(), "missing.json")).Latest(ctx)
+	assert.Error(t, err)
+}
+
+func TestNewStoreFromEnv(t *testing.T) {
+	t.Setenv("FX_RATES_FILE", "")
+	t.Setenv("FX_REFRESH", "6h")
+
+	store, err := NewStoreFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Hour, store.refresh)
+
+	t.Setenv("FX_REFRESH", "soon")
+	_, err = NewStoreFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("FX_REFRESH", "")
+	t.Setenv("FX_RATES_FILE", filepath.Join(t.TempDir(), "missing.json"))
+	_, err = NewStoreFromEnv()
+	assert.Error(t, err)
+}
+
+func TestNormalizeCode(t *testing.T) {
+	assert.Equal(t, "USD", NormalizeCode(" usd "))
+	assert.Equal(t, "", NormalizeCode("US"))
+	assert.Equal(t, "", NormalizeCode("U5D"))
+}
@@ -0,0 +1,41 @@
+package fx
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+//go:embed rates.json
+var referenceRates []byte
+
+// FileProvider reads a rate table from a JSON file on every load, so edits
+// are picked up on the store's next refresh. An empty path serves the
+// reference rates compiled into the binary.
+type FileProvider struct {
+	path string
+}
+
+// NewFileProvider creates a provider reading path
+func NewFileProvider(path string) *FileProvider {
+	return &FileProvider{path: path}
+}
+
+// Latest implements Provider
+func (p *FileProvider) Latest(ctx context.Context) (*RateTable, error) {
+	data := referenceRates
+	if p.path != "" {
+		var err error
+		if data, err = os.ReadFile(p.path); err != nil {
+			return nil, fmt.Errorf("read rates file: %w", err)
+		}
+	}
+
+	var table RateTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("decode rates file: %w", err)
+	}
+	return &table, nil
+}
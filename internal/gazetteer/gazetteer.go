@@ -0,0 +1,231 @@
+// Package gazetteer maps the free-text area names produced by scrapers onto
+// canonical UAE communities, so that "JLT", "Jumeirah Lake Towers" and
+// "Jumeirah Lakes Towers" are counted as one area.
+//
+// The dataset is embedded from communities.json. Each community belongs to
+// an emirate and carries its aliases, the municipal district it sits in
+// where known, and an approximate centroid.
+//
+// Coverage is uneven: Dubai and Abu Dhabi have most of the communities that
+// show up in rental listings, while Sharjah, Ajman and the northern emirates
+// only list their main neighbourhoods. Areas missing from the dataset are
+// stored as scraped; cmd/backfill-areas reports them by listing count.
+package gazetteer
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/adonese/cost-of-living/internal/models"
+)
+
+// Community is a canonical residential area
+type Community struct {
+	Emirate  string          `json:"emirate"`
+	Name     string          `json:"name"`
+	District string          `json:"district,omitempty"`
+	Aliases  []string        `json:"aliases,omitempty"`
+	Centroid models.GeoPoint `json:"centroid"`
+}
+
+// Gazetteer resolves area names to communities. It is safe for concurrent
+// use once loaded.
+type Gazetteer struct {
+	communities []Community
+
+	// index maps normalised emirate and name (or alias) to a community
+	index map[key]int
+}
+
+type key struct {
+	emirate string
+	name    string
+}
+
+//go:embed communities.json
+var embedded []byte
+
+var (
+	defaultOnce      sync.Once
+	defaultGazetteer *Gazetteer
+)
+
+// Default returns the gazetteer built from the embedded dataset
+func Default() *Gazetteer {
+	defaultOnce.Do(func() {
+		g, err := Load(bytes.NewReader(embedded))
+		if err != nil {
+			// The dataset is compiled in and covered by tests
+			panic(fmt.Sprintf("gazetteer: invalid embedded dataset: %v", err))
+		}
+		defaultGazetteer = g
+	})
+	return defaultGazetteer
+}
+
+// Load reads a JSON array of communities. Names and aliases must be unique
+// within an emirate once normalised.
+func Load(r io.Reader) (*Gazetteer, error) {
+	var communities []Community
+	if err := json.NewDecoder(r).Decode(&communities); err != nil {
+		return nil, fmt.Errorf("decode communities: %w", err)
+	}
+
+	g := &Gazetteer{
+		communities: communities,
+		index:       make(map[key]int),
+	}
+	for i, c := range communities {
+		if c.Emirate == "" || c.Name == "" {
+			return nil, fmt.Errorf("community %d: emirate and name are required", i)
+		}
+		for _, name := range append([]string{c.Name}, c.Aliases...) {
+			k := key{emirate: normalize(c.Emirate), name: normalize(name)}
+			if k.name == "" {
+				return nil, fmt.Errorf("community %q: empty alias", c.Name)
+			}
+			if j, ok := g.index[k]; ok && j != i {
+				return nil, fmt.Errorf("%s: %q names both %q and %q", c.Emirate, name, communities[j].Name, c.Name)
+			}
+			g.index[k] = i
+		}
+	}
+
+	return g, nil
+}
+
+// Communities returns the communities of an emirate ordered by name, or of
+// every emirate when emirate is empty
+func (g *Gazetteer) Communities(emirate string) []Community {
+	want := normalize(emirate)
+	var result []Community
+	for _, c := range g.communities {
+		if want == "" || normalize(c.Emirate) == want {
+			result = append(result, c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Emirate != result[j].Emirate {
+			return result[i].Emirate < result[j].Emirate
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// Lookup finds the community an area name or alias refers to. When emirate
+// is empty the name must be unambiguous across emirates.
+func (g *Gazetteer) Lookup(emirate, name string) (Community, bool) {
+	for _, candidate := range candidates(name) {
+		if c, ok := g.lookup(emirate, candidate); ok {
+			return c, true
+		}
+	}
+	return Community{}, false
+}
+
+func (g *Gazetteer) lookup(emirate, name string) (Community, bool) {
+	if emirate != "" {
+		i, ok := g.index[key{emirate: normalize(emirate), name: name}]
+		if !ok {
+			return Community{}, false
+		}
+		return g.communities[i], true
+	}
+
+	found := -1
+	for k, i := range g.index {
+		if k.name != name {
+			continue
+		}
+		if found >= 0 && found != i {
+			return Community{}, false
+		}
+		found = i
+	}
+	if found < 0 {
+		return Community{}, false
+	}
+	return g.communities[found], true
+}
+
+// Resolve replaces loc.Area with the canonical community name. Area is tried
+// first, then each hint, such as the raw location text a scraper parsed
+// Area from ("Cluster D, Jumeirah Lake Towers (JLT), Dubai"). The emirate is
+// filled in when loc has none. Locations that match no community are
+// returned unchanged with ok false.
+func (g *Gazetteer) Resolve(loc models.Location, hints ...string) (models.Location, bool) {
+	texts := append([]string{loc.Area}, hints...)
+	for _, text := range texts {
+		for _, segment := range segments(text) {
+			c, ok := g.Lookup(loc.Emirate, segment)
+			if !ok {
+				continue
+			}
+			loc.Area = c.Name
+			if loc.Emirate == "" {
+				loc.Emirate = c.Emirate
+			}
+			return loc, true
+		}
+	}
+	return loc, false
+}
+
+var (
+	nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+	parenthesized   = regexp.MustCompile(`\(([^)]*)\)`)
+	segmentSplit    = regexp.MustCompile(`\s*[,|]\s*|\s+-\s+`)
+)
+
+// normalize lowercases a name and reduces punctuation and a leading "the"
+// so that "Al-Barsha" matches "Al Barsha" and "The Greens" matches "Greens"
+func normalize(s string) string {
+	s = strings.ToLower(strings.ReplaceAll(s, "&", " and "))
+	s = strings.TrimSpace(nonAlphanumeric.ReplaceAllString(s, " "))
+	return strings.TrimPrefix(s, "the ")
+}
+
+// candidates returns the normalised forms a name may be indexed under:
+// "Jumeirah Village Circle (JVC)" is tried whole, without the parentheses
+// and as the abbreviation inside them
+func candidates(name string) []string {
+	forms := []string{normalize(name)}
+	if match := parenthesized.FindStringSubmatch(name); match != nil {
+		forms = append(forms,
+			normalize(parenthesized.ReplaceAllString(name, "")),
+			normalize(match[1]))
+	}
+
+	result := forms[:0]
+	for _, form := range forms {
+		if form != "" {
+			result = append(result, form)
+		}
+	}
+	return result
+}
+
+// segments splits location text on the separators listing sites use between
+// sub-community, community and city, keeping the whole text as the first
+// segment
+func segments(text string) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	result := []string{text}
+	for _, part := range segmentSplit.Split(text, -1) {
+		if part = strings.TrimSpace(part); part != "" && part != text {
+			result = append(result, part)
+		}
+	}
+	return result
+}
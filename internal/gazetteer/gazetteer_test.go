@@ -0,0 +1,117 @@
+package gazetteer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultDatasetIsValid(t *testing.T) {
+	g := Default()
+
+	communities := g.Communities("")
+	require.NotEmpty(t, communities)
+	for _, c := range communities {
+		assert.True(t, c.Centroid.Valid(), "%s has no centroid", c.Name)
+
+		// Centroids must lie in the UAE
+		assert.True(t, c.Centroid.Lat > 22 && c.Centroid.Lat < 26.5, "%s latitude %v", c.Name, c.Centroid.Lat)
+		assert.True(t, c.Centroid.Lon > 51 && c.Centroid.Lon < 56.5, "%s longitude %v", c.Name, c.Centroid.Lon)
+	}
+
+	for _, emirate := range []string{"Dubai", "Abu Dhabi", "Sharjah", "Ajman", "Ras Al Khaimah", "Fujairah", "Umm Al Quwain"} {
+		assert.NotEmpty(t, g.Communities(emirate), emirate)
+	}
+}
+
+func TestLookupAliases(t *testing.T) {
+	g := Default()
+
+	for _, name := range []string{
+		"JLT",
+		"Jumeirah Lake Towers",
+		"Jumeirah Lakes Towers",
+		"jumeirah lake towers",
+		"Jumeirah Lake Towers (JLT)",
+		"  Jumeirah-Lake-Towers ",
+	} {
+		c, ok := g.Lookup("Dubai", name)
+		require.True(t, ok, name)
+		assert.Equal(t, "Jumeirah Lake Towers", c.Name, name)
+		assert.Equal(t, "Al Thanyah Fifth", c.District)
+	}
+
+	c, ok := g.Lookup("Dubai", "Greens")
+	require.True(t, ok)
+	assert.Equal(t, "The Greens", c.Name)
+
+	_, ok = g.Lookup("Dubai", "Atlantis Underwater Village")
+	assert.False(t, ok)
+}
+
+func TestLookupScopesByEmirate(t *testing.T) {
+	g := Default()
+
+	// Al Nahda exists in both Dubai and Sharjah
+	dubai, ok := g.Lookup("Dubai", "Al Nahda")
+	require.True(t, ok)
+	sharjah, ok := g.Lookup("sharjah", "Al Nahda")
+	require.True(t, ok)
+	assert.NotEqual(t, dubai.Centroid, sharjah.Centroid)
+	assert.Equal(t, "Sharjah", sharjah.Emirate)
+
+	_, ok = g.Lookup("", "Al Nahda")
+	assert.False(t, ok, "ambiguous without an emirate")
+
+	c, ok := g.Lookup("", "JVC")
+	require.True(t, ok)
+	assert.Equal(t, "Dubai", c.Emirate)
+
+	_, ok = g.Lookup("Abu Dhabi", "JLT")
+	assert.False(t, ok)
+}
+
+func TestResolve(t *testing.T) {
+	g := Default()
+
+	loc, ok := g.Resolve(models.Location{Emirate: "Dubai", City: "Dubai", Area: "JLT"})
+	require.True(t, ok)
+	assert.Equal(t, models.Location{Emirate: "Dubai", City: "Dubai", Area: "Jumeirah Lake Towers"}, loc)
+
+	// The parsed area is a sub-community; the raw text names the community
+	loc, ok = g.Resolve(models.Location{Emirate: "Dubai", Area: "Cluster D"}, "Cluster D, Jumeirah Lake Towers (JLT), Dubai")
+	require.True(t, ok)
+	assert.Equal(t, "Jumeirah Lake Towers", loc.Area)
+
+	loc, ok = g.Resolve(models.Location{Area: "Dubai Marina | Dubai"})
+	require.True(t, ok)
+	assert.Equal(t, "Dubai Marina", loc.Area)
+	assert.Equal(t, "Dubai", loc.Emirate)
+
+	coords := &models.GeoPoint{Lat: 25.1, Lon: 55.2}
+	unknown := models.Location{Emirate: "Dubai", Area: "Somewhere New", Coordinates: coords}
+	loc, ok = g.Resolve(unknown)
+	assert.False(t, ok)
+	assert.Equal(t, unknown, loc)
+}
+
+func TestLoadRejectsConflictingAliases(t *testing.T) {
+	_, err := Load(strings.NewReader(`[
+		{"emirate": "Dubai", "name": "Al Barsha", "aliases": ["Barsha"]},
+		{"emirate": "Dubai", "name": "Barsha Heights", "aliases": ["Barsha"]}
+	]`))
+	assert.Error(t, err)
+
+	_, err = Load(strings.NewReader(`[{"emirate": "Dubai", "name": ""}]`))
+	assert.Error(t, err)
+
+	// The same alias may name communities in different emirates
+	_, err = Load(strings.NewReader(`[
+		{"emirate": "Dubai", "name": "Al Nahda"},
+		{"emirate": "Sharjah", "name": "Al Nahda"}
+	]`))
+	assert.NoError(t, err)
+}
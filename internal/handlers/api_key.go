@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/adonese/cost-of-living/internal/auth"
+	"github.com/adonese/cost-of-living/internal/handlers/dto"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+)
+
+// APIKeyHandler handles the admin endpoints for issuing and revoking API keys
+type APIKeyHandler struct {
+	service  *auth.Service
+	validate *validator.Validate
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(service *auth.Service) *APIKeyHandler {
+	return &APIKeyHandler{
+		service:  service,
+		validate: validator.New(),
+	}
+}
+
+// Create handles POST /api/v1/admin/api-keys. The response carries the
+// plaintext key; it is not stored and is never returned again.
+func (h *APIKeyHandler) Create(c echo.Context) error {
+	var req dto.CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+	if err := h.validate.Struct(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Validation error: "+err.Error())
+	}
+
+	ctx := c.Request().Context()
+	var createdBy string
+	if admin := auth.KeyFromContext(ctx); admin != nil {
+		createdBy = admin.Actor()
+	}
+
+	key, plaintext, err := h.service.Issue(ctx, req.Name, models.Role(req.Role), createdBy)
+	if err != nil {
+		return repositoryError(err, "API key not found", "Failed to create API key")
+	}
+
+	return c.JSON(http.StatusCreated, dto.IssuedAPIKeyResponse{
+		APIKeyResponse: dto.FromAPIKey(key),
+		Key:            plaintext,
+	})
+}
+
+// List handles GET /api/v1/admin/api-keys
+func (h *APIKeyHandler) List(c echo.Context) error {
+	keys, err := h.service.List(c.Request().Context())
+	if err != nil {
+		return repositoryError(err, "API key not found", "Failed to list API keys")
+	}
+	return c.JSON(http.StatusOK, dto.FromAPIKeys(keys))
+}
+
+// Revoke handles DELETE /api/v1/admin/api-keys/:id. Revoking an unknown or
+// already revoked key is a 404.
+func (h *APIKeyHandler) Revoke(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "ID is required")
+	}
+
+	if _, err := h.service.Revoke(c.Request().Context(), id); err != nil {
+		return repositoryError(err, "API key not found", "Failed to revoke API key")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
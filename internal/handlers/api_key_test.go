@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/adonese/cost-of-living/internal/auth"
+	"github.com/adonese/cost-of-living/internal/handlers/dto"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyHandler(t *testing.T) {
+	e := echo.New()
+	service := auth.NewService(mock.NewAPIKeyRepository())
+	handler := NewAPIKeyHandler(service)
+	admin := &models.APIKey{Prefix: "a1b2c3d4", Role: models.RoleAdmin}
+
+	create := func(t *testing.T, body string) (*httptest.ResponseRecorder, error) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/api-keys", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req = req.WithContext(auth.WithKey(req.Context(), admin))
+		rec := httptest.NewRecorder()
+		return rec, handler.Create(e.NewContext(req, rec))
+	}
+
+	revoke := func(t *testing.T, id string) error {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/api-keys/"+id, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		err := handler.Revoke(c)
+		if err == nil {
+			assert.Equal(t, http.StatusNoContent, rec.Code)
+		}
+		return err
+	}
+
+	rec, err := create(t, `{"name": "ingest bot", "role": "contributor"}`)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.NotContains(t, rec.Body.String(), `"hash"`)
+
+	var issued dto.IssuedAPIKeyResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &issued))
+	assert.Equal(t, "ingest bot", issued.Name)
+	assert.Equal(t, models.RoleContributor, issued.Role)
+	assert.Equal(t, "apikey:a1b2c3d4", issued.CreatedBy)
+	assert.False(t, issued.Revoked)
+
+	key, err := service.Authenticate(context.Background(), issued.Key)
+	require.NoError(t, err, "the returned key authenticates")
+	assert.Equal(t, issued.ID, key.ID)
+
+	t.Run("invalid role", func(t *testing.T) {
+		_, err := create(t, `{"name": "bot", "role": "owner"}`)
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+
+	t.Run("list hides secrets", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/api-keys", nil)
+		rec := httptest.NewRecorder()
+		require.NoError(t, handler.List(e.NewContext(req, rec)))
+
+		var list dto.APIKeyListResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+		require.Equal(t, 1, list.Count)
+		assert.Equal(t, issued.Prefix, list.Keys[0].Prefix)
+		assert.NotContains(t, rec.Body.String(), issued.Key)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		require.NoError(t, revoke(t, issued.ID))
+
+		_, err := service.Authenticate(context.Background(), issued.Key)
+		assert.ErrorIs(t, err, auth.ErrInvalidKey)
+
+		err = revoke(t, issued.ID)
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code, "revoking twice is a 404")
+	})
+}
@@ -7,7 +7,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/adonese/cost-of-living/internal/auth"
+	"github.com/adonese/cost-of-living/internal/fx"
 	"github.com/adonese/cost-of-living/internal/handlers/dto"
+	"github.com/adonese/cost-of-living/internal/models"
 	"github.com/adonese/cost-of-living/internal/repository"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -16,13 +19,21 @@ import (
 // CostDataPointHandler handles HTTP requests for cost data points
 type CostDataPointHandler struct {
 	repo     repository.CostDataPointRepository
+	rates    *fx.Store
 	validate *validator.Validate
 }
 
 // NewCostDataPointHandler creates a new cost data point handler
 func NewCostDataPointHandler(repo repository.CostDataPointRepository) *CostDataPointHandler {
+	return NewCostDataPointHandlerWithRates(repo, nil)
+}
+
+// NewCostDataPointHandlerWithRates creates a cost data point handler that
+// converts listed prices with rates when a currency is requested
+func NewCostDataPointHandlerWithRates(repo repository.CostDataPointRepository, rates *fx.Store) *CostDataPointHandler {
 	return &CostDataPointHandler{
 		repo:     repo,
+		rates:    rates,
 		validate: validator.New(),
 	}
 }
@@ -52,7 +63,7 @@ func (h *CostDataPointHandler) Create(c echo.Context) error {
 
 	// Create in database
 	if err := h.repo.Create(c.Request().Context(), cdp); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create cost data point")
+		return repositoryError(err, "Cost data point not found", "Failed to create cost data point")
 	}
 
 	// Return created resource
@@ -85,7 +96,7 @@ func (h *CostDataPointHandler) GetByID(c echo.Context) error {
 		}
 		results, err := h.repo.List(c.Request().Context(), filter)
 		if err != nil {
-			return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get cost data point")
+			return repositoryError(err, "Cost data point not found", "Failed to get cost data point")
 		}
 
 		if len(results) == 0 {
@@ -98,10 +109,7 @@ func (h *CostDataPointHandler) GetByID(c echo.Context) error {
 	// Get the specific record
 	cdp, err := h.repo.GetByID(c.Request().Context(), id, recordedAt)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			return echo.NewHTTPError(http.StatusNotFound, "Cost data point not found")
-		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get cost data point")
+		return repositoryError(err, "Cost data point not found", "Failed to get cost data point")
 	}
 
 	return c.JSON(http.StatusOK, dto.FromModel(cdp))
@@ -109,31 +117,9 @@ func (h *CostDataPointHandler) GetByID(c echo.Context) error {
 
 // List handles GET /api/v1/cost-data-points
 func (h *CostDataPointHandler) List(c echo.Context) error {
-	filter := repository.ListFilter{}
-
-	// Parse query parameters
-	if category := c.QueryParam("category"); category != "" {
-		filter.Category = category
-	}
-
-	if emirate := c.QueryParam("emirate"); emirate != "" {
-		filter.Emirate = emirate
-	}
-
-	if startDateStr := c.QueryParam("start_date"); startDateStr != "" {
-		startDate, err := time.Parse(time.RFC3339, startDateStr)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, "Invalid start_date format, use RFC3339")
-		}
-		filter.StartDate = &startDate
-	}
-
-	if endDateStr := c.QueryParam("end_date"); endDateStr != "" {
-		endDate, err := time.Parse(time.RFC3339, endDateStr)
-		if err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, "Invalid end_date format, use RFC3339")
-		}
-		filter.EndDate = &endDate
+	filter, err := parseListFilter(c)
+	if err != nil {
+		return err
 	}
 
 	// Parse pagination parameters
@@ -160,29 +146,224 @@ func (h *CostDataPointHandler) List(c echo.Context) error {
 	}
 	filter.Offset = offset
 
-	// Get data from repository
-	results, err := h.repo.List(c.Request().Context(), filter)
+	// Cursor pagination resumes after the last row of the previous page
+	if cursorStr := c.QueryParam("cursor"); cursorStr != "" {
+		cursor, err := repository.DecodeCursor(cursorStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid cursor parameter")
+		}
+		filter.After = cursor
+	}
+	if err := filter.Validate(); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid filter: %v", err))
+	}
+
+	rate, err := h.rate(c)
+	if err != nil {
+		return err
+	}
+
+	// Fetch one extra row to learn whether another page exists
+	pageFilter := filter
+	pageFilter.Limit = limit + 1
+
+	results, err := h.repo.List(c.Request().Context(), pageFilter)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to list cost data points")
+		return repositoryError(err, "Cost data point not found", "Failed to list cost data points")
+	}
+
+	hasMore := len(results) > limit
+	if hasMore {
+		results = results[:limit]
+	}
+
+	// The total ignores the cursor so it stays the same across pages
+	countFilter := filter
+	countFilter.After = nil
+	total, err := h.repo.Count(c.Request().Context(), countFilter)
+	if err != nil {
+		return repositoryError(err, "Cost data point not found", "Failed to count cost data points")
 	}
 
 	// Convert to response DTOs
 	responseData := make([]dto.CostDataPointResponse, len(results))
 	for i, cdp := range results {
 		responseData[i] = dto.FromModel(cdp)
+		if rate != nil {
+			convertPrices(&responseData[i], *rate)
+		}
 	}
 
 	// Create paginated response
 	response := dto.ListResponse{
 		Data:       responseData,
-		TotalCount: len(responseData), // Note: This is not the true total count, but count of returned items
+		TotalCount: total,
 		Limit:      limit,
 		Offset:     offset,
+		FXRate:     rate,
+	}
+
+	// Cursors are keyed on (recorded_at, id), so they are only offered for
+	// listings ordered by recorded_at
+	if hasMore && (filter.OrderBy == "" || filter.OrderBy == "recorded_at") {
+		last := results[len(results)-1]
+		response.NextCursor = repository.Cursor{RecordedAt: last.RecordedAt, ID: last.ID}.Encode()
 	}
 
 	return c.JSON(http.StatusOK, response)
 }
 
+// attributeParamPrefix marks query parameters that filter on attributes,
+// e.g. attr.bedrooms=2
+const attributeParamPrefix = "attr."
+
+// parseListFilter builds a ListFilter from the query parameters shared by the
+// list endpoints. Pagination is parsed by the caller.
+func parseListFilter(c echo.Context) (repository.ListFilter, error) {
+	filter := repository.ListFilter{
+		Category:    c.QueryParam("category"),
+		SubCategory: c.QueryParam("sub_category"),
+		Source:      c.QueryParam("source"),
+		Emirate:     c.QueryParam("emirate"),
+		City:        c.QueryParam("city"),
+		Area:        c.QueryParam("area"),
+		TagMatch:    repository.TagMatch(c.QueryParam("tag_match")),
+		OrderBy:     c.QueryParam("order_by"),
+	}
+
+	// Accept either order_dir=asc or order=asc
+	direction := firstNonEmpty(c.QueryParam("order_dir"), c.QueryParam("order"))
+	filter.OrderDirection = repository.SortDirection(strings.ToLower(direction))
+
+	// Tags may be repeated (?tags=a&tags=b) or comma separated (?tags=a,b)
+	for _, value := range c.QueryParams()["tags"] {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				filter.Tags = append(filter.Tags, tag)
+			}
+		}
+	}
+
+	if minPriceStr := c.QueryParam("min_price"); minPriceStr != "" {
+		minPrice, err := strconv.ParseFloat(minPriceStr, 64)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "Invalid min_price parameter")
+		}
+		filter.MinPrice = &minPrice
+	}
+
+	if maxPriceStr := c.QueryParam("max_price"); maxPriceStr != "" {
+		maxPrice, err := strconv.ParseFloat(maxPriceStr, 64)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "Invalid max_price parameter")
+		}
+		filter.MaxPrice = &maxPrice
+	}
+
+	for key, values := range c.QueryParams() {
+		if !strings.HasPrefix(key, attributeParamPrefix) || len(values) == 0 {
+			continue
+		}
+		name := strings.TrimPrefix(key, attributeParamPrefix)
+		if name == "" {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "Attribute filter name is required")
+		}
+		if filter.Attributes == nil {
+			filter.Attributes = make(map[string]string)
+		}
+		filter.Attributes[name] = values[0]
+	}
+
+	// near=lat,lon&radius_km=3 selects points within 3 km
+	nearStr, radiusStr := c.QueryParam("near"), c.QueryParam("radius_km")
+	if nearStr != "" || radiusStr != "" {
+		if nearStr == "" || radiusStr == "" {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "near and radius_km must be given together")
+		}
+		center, err := parseCoordinates(nearStr, 2)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "Invalid near parameter, use lat,lon")
+		}
+		radius, err := strconv.ParseFloat(radiusStr, 64)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "Invalid radius_km parameter")
+		}
+		filter.Near = &repository.GeoRadius{
+			Center:   models.GeoPoint{Lat: center[0], Lon: center[1]},
+			RadiusKm: radius,
+		}
+	}
+
+	// bbox=min_lat,min_lon,max_lat,max_lon selects points within the box
+	if bboxStr := c.QueryParam("bbox"); bboxStr != "" {
+		corners, err := parseCoordinates(bboxStr, 4)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "Invalid bbox parameter, use min_lat,min_lon,max_lat,max_lon")
+		}
+		filter.Within = &repository.BoundingBox{
+			MinLat: corners[0],
+			MinLon: corners[1],
+			MaxLat: corners[2],
+			MaxLon: corners[3],
+		}
+	}
+
+	if startDateStr := c.QueryParam("start_date"); startDateStr != "" {
+		startDate, err := time.Parse(time.RFC3339, startDateStr)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "Invalid start_date format, use RFC3339")
+		}
+		filter.StartDate = &startDate
+	}
+
+	if endDateStr := c.QueryParam("end_date"); endDateStr != "" {
+		endDate, err := time.Parse(time.RFC3339, endDateStr)
+		if err != nil {
+			return filter, echo.NewHTTPError(http.StatusBadRequest, "Invalid end_date format, use RFC3339")
+		}
+		filter.EndDate = &endDate
+	}
+
+	includeDeleted, err := parseBoolParam(c, "include_deleted")
+	if err != nil {
+		return filter, err
+	}
+	filter.IncludeDeleted = includeDeleted
+
+	if err := filter.Validate(); err != nil {
+		return filter, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid filter: %v", err))
+	}
+
+	return filter, nil
+}
+
+// parseCoordinates parses exactly n comma-separated numbers
+func parseCoordinates(value string, n int) ([]float64, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != n {
+		return nil, fmt.Errorf("expected %d values, got %d", n, len(parts))
+	}
+	values := make([]float64, n)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
 // Update handles PUT /api/v1/cost-data-points/:id
 func (h *CostDataPointHandler) Update(c echo.Context) error {
 	id := c.Param("id")
@@ -204,10 +385,7 @@ func (h *CostDataPointHandler) Update(c echo.Context) error {
 	// Get existing record
 	cdp, err := h.repo.GetByID(c.Request().Context(), id, recordedAt)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			return echo.NewHTTPError(http.StatusNotFound, "Cost data point not found")
-		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to get cost data point")
+		return repositoryError(err, "Cost data point not found", "Failed to get cost data point")
 	}
 
 	// Parse update request
@@ -227,18 +405,17 @@ func (h *CostDataPointHandler) Update(c echo.Context) error {
 	// Apply updates
 	req.ApplyUpdate(cdp)
 
-	// Update in database
-	if err := h.repo.Update(c.Request().Context(), cdp); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			return echo.NewHTTPError(http.StatusNotFound, "Cost data point not found")
-		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update cost data point")
+	// Update in database, recording who changed it and why
+	if err := h.repo.Update(auditContext(c, req.Reason), cdp); err != nil {
+		return repositoryError(err, "Cost data point not found", "Failed to update cost data point")
 	}
 
 	return c.JSON(http.StatusOK, dto.FromModel(cdp))
 }
 
-// Delete handles DELETE /api/v1/cost-data-points/:id
+// Delete handles DELETE /api/v1/cost-data-points/:id. The data point is
+// soft deleted and can be restored unless hard=true is given, which needs an
+// admin key on top of the contributor key the route requires.
 func (h *CostDataPointHandler) Delete(c echo.Context) error {
 	id := c.Param("id")
 	if id == "" {
@@ -256,13 +433,52 @@ func (h *CostDataPointHandler) Delete(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "Invalid recorded_at format, use RFC3339")
 	}
 
-	// Delete from database
-	if err := h.repo.Delete(c.Request().Context(), id, recordedAt); err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			return echo.NewHTTPError(http.StatusNotFound, "Cost data point not found")
+	hard, err := parseBoolParam(c, "hard")
+	if err != nil {
+		return err
+	}
+	if hard {
+		if key := auth.KeyFromContext(c.Request().Context()); key == nil || !key.Role.Allows(models.RoleAdmin) {
+			return echo.NewHTTPError(http.StatusForbidden, "Permanent deletes require an admin API key")
 		}
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete cost data point")
+	}
+
+	// Delete from database, recording who removed it and why
+	ctx := auditContext(c, "")
+	if hard {
+		err = h.repo.Delete(ctx, id, recordedAt)
+	} else {
+		err = h.repo.SoftDelete(ctx, id, recordedAt)
+	}
+	if err != nil {
+		return repositoryError(err, "Cost data point not found", "Failed to delete cost data point")
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// Restore handles POST /api/v1/cost-data-points/:id/restore
+func (h *CostDataPointHandler) Restore(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "ID is required")
+	}
+
+	// Check for recorded_at query parameter (required for restore due to composite key)
+	recordedAtStr := c.QueryParam("recorded_at")
+	if recordedAtStr == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "recorded_at query parameter is required for restore")
+	}
+
+	recordedAt, err := time.Parse(time.RFC3339, recordedAtStr)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid recorded_at format, use RFC3339")
+	}
+
+	cdp, err := h.repo.Restore(auditContext(c, ""), id, recordedAt)
+	if err != nil {
+		return repositoryError(err, "Deleted cost data point not found", "Failed to restore cost data point")
+	}
+
+	return c.JSON(http.StatusOK, dto.FromModel(cdp))
+}
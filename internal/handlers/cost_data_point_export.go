@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/export"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/pkg/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// Export handles GET /api/v1/cost-data-points/export. It accepts the list
+// filters plus format (csv, ndjson or parquet-lite), attributes (comma
+// separated attribute keys to add as columns), limit and gzip, and streams
+// the matching rows straight from the repository to the response.
+func (h *CostDataPointHandler) Export(c echo.Context) error {
+	filter, err := parseListFilter(c)
+	if err != nil {
+		return err
+	}
+
+	format, err := export.ParseFormat(c.QueryParam("format"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid format parameter, use csv, ndjson or parquet-lite")
+	}
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid limit parameter")
+		}
+		filter.Limit = l
+	}
+
+	var attributes []string
+	for _, value := range c.QueryParams()["attributes"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				attributes = append(attributes, name)
+			}
+		}
+	}
+
+	useGzip, err := wantsGzip(c)
+	if err != nil {
+		return err
+	}
+
+	res := c.Response()
+	filename := fmt.Sprintf("cost-data-points-%s.%s", time.Now().UTC().Format("20060102T150405Z"), format.Extension())
+	res.Header().Set(echo.HeaderContentType, format.ContentType())
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	res.Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+
+	var out io.Writer = res
+	var gz *gzip.Writer
+	if useGzip {
+		res.Header().Set(echo.HeaderContentEncoding, "gzip")
+		gz = gzip.NewWriter(res)
+		out = gz
+	}
+
+	writer, err := export.NewWriter(format, out, attributes)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to start export")
+	}
+
+	// Headers are committed with the first byte, so failures from here on
+	// can only be logged and surface to the client as a truncated body
+	res.WriteHeader(http.StatusOK)
+
+	rows := 0
+	err = h.repo.Stream(c.Request().Context(), filter, func(cdp *models.CostDataPoint) error {
+		rows++
+		return writer.Write(cdp)
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if gz != nil {
+		if closeErr := gz.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		logger.Error("Export aborted", "format", format, "rows", rows, "error", err)
+		return nil
+	}
+
+	logger.Info("Export completed", "format", format, "rows", rows, "gzip", useGzip)
+	return nil
+}
+
+// wantsGzip reports whether the export should be compressed. An explicit
+// gzip query parameter wins over the Accept-Encoding header.
+func wantsGzip(c echo.Context) (bool, error) {
+	if param := c.QueryParam("gzip"); param != "" {
+		enabled, err := strconv.ParseBool(param)
+		if err != nil {
+			return false, echo.NewHTTPError(http.StatusBadRequest, "Invalid gzip parameter")
+		}
+		return enabled, nil
+	}
+
+	for _, encoding := range strings.Split(c.Request().Header.Get(echo.HeaderAcceptEncoding), ",") {
+		if name, _, _ := strings.Cut(strings.TrimSpace(encoding), ";"); strings.EqualFold(name, "gzip") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
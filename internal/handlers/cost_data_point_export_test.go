@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/pkg/logger"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostDataPointHandler_Export(t *testing.T) {
+	logger.Init()
+	e := echo.New()
+	mockRepo := mock.NewCostDataPointRepository()
+	handler := NewCostDataPointHandler(mockRepo)
+
+	now := time.Now()
+	for _, cdp := range []*models.CostDataPoint{
+		{Category: "Housing", ItemName: "Studio", Price: 45000, Source: "bayut", RecordedAt: now,
+			Location: models.Location{Emirate: "Dubai", Area: "JVC"}, Attributes: map[string]interface{}{"bedrooms": 0}},
+		{Category: "Housing", ItemName: "2BR", Price: 120000, Source: "bayut", RecordedAt: now.Add(-time.Hour),
+			Location: models.Location{Emirate: "Dubai", Area: "Marina"}, Attributes: map[string]interface{}{"bedrooms": 2}},
+		{Category: "Transportation", ItemName: "Metro", Price: 4, Source: "rta", RecordedAt: now,
+			Location: models.Location{Emirate: "Dubai"}},
+	} {
+		require.NoError(t, mockRepo.Create(context.Background(), cdp))
+	}
+
+	t.Run("csv with filters and attributes", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/cost-data-points/export?category=Housing&attributes=bedrooms", nil)
+		rec := httptest.NewRecorder()
+
+		require.NoError(t, handler.Export(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "text/csv")
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), ".csv")
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+
+		records, err := csv.NewReader(rec.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, "attr_bedrooms", records[0][len(records[0])-1])
+		assert.Equal(t, "Studio", records[1][3])
+		assert.Equal(t, "2", records[2][len(records[2])-1])
+		assert.Equal(t, 1, mockRepo.GetCallCount("Stream"))
+	})
+
+	t.Run("gzip ndjson", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/cost-data-points/export?format=ndjson", nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, "br, gzip;q=0.8")
+		rec := httptest.NewRecorder()
+
+		require.NoError(t, handler.Export(e.NewContext(req, rec)))
+		assert.Equal(t, "gzip", rec.Header().Get(echo.HeaderContentEncoding))
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get(echo.HeaderContentType))
+
+		gz, err := gzip.NewReader(rec.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(gz)
+		require.NoError(t, err)
+		assert.Len(t, strings.Split(strings.TrimSpace(string(body)), "\n"), 3)
+	})
+
+	t.Run("gzip disabled explicitly", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/cost-data-points/export?gzip=false", nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+		rec := httptest.NewRecorder()
+
+		require.NoError(t, handler.Export(e.NewContext(req, rec)))
+		assert.Empty(t, rec.Header().Get(echo.HeaderContentEncoding))
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/cost-data-points/export?format=xlsx", nil)
+		rec := httptest.NewRecorder()
+
+		err := handler.Export(e.NewContext(req, rec))
+		require.Error(t, err)
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+	})
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/adonese/cost-of-living/internal/auth"
+	"github.com/adonese/cost-of-living/internal/handlers/dto"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+// History handles GET /api/v1/cost-data-points/:id/history
+func (h *CostDataPointHandler) History(c echo.Context) error {
+	id := c.Param("id")
+	if id == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "ID is required")
+	}
+
+	ctx := c.Request().Context()
+	revisions, err := h.repo.History(ctx, id)
+	if err != nil {
+		return repositoryError(err, "Cost data point not found", "Failed to get cost data point history")
+	}
+
+	// An empty history is only meaningful for a point that exists
+	if len(revisions) == 0 {
+		count, err := h.repo.Count(ctx, repository.ListFilter{ID: id, IncludeDeleted: true})
+		if err != nil {
+			return repositoryError(err, "Cost data point not found", "Failed to get cost data point history")
+		}
+		if count == 0 {
+			return echo.NewHTTPError(http.StatusNotFound, "Cost data point not found")
+		}
+	}
+
+	return c.JSON(http.StatusOK, dto.FromRevisions(id, revisions))
+}
+
+// auditContext returns the request context carrying the API key that made
+// the change as its actor, and the reason for the change. A reason in the
+// request body takes precedence over the reason query parameter.
+func auditContext(c echo.Context, bodyReason string) context.Context {
+	reason := bodyReason
+	if reason == "" {
+		reason = c.QueryParam("reason")
+	}
+
+	ctx := c.Request().Context()
+	var actor string
+	if key := auth.KeyFromContext(ctx); key != nil {
+		actor = key.Actor()
+	}
+	return repository.WithAudit(ctx, actor, reason)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/auth"
+	"github.com/adonese/cost-of-living/internal/handlers/dto"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostDataPointHandler_History(t *testing.T) {
+	e := echo.New()
+	mockRepo := mock.NewCostDataPointRepository()
+	handler := NewCostDataPointHandler(mockRepo)
+
+	recordedAt := time.Now().UTC().Truncate(time.Second)
+	seed := func(t *testing.T) {
+		t.Helper()
+		mockRepo.Reset()
+		err := mockRepo.Create(nil, &models.CostDataPoint{
+			ID:         "tariff-1",
+			Category:   "Utilities",
+			ItemName:   "DEWA Electricity Slab 1",
+			Price:      0.23,
+			Location:   models.Location{Emirate: "Dubai"},
+			RecordedAt: recordedAt,
+			Source:     "dewa",
+		})
+		require.NoError(t, err)
+	}
+
+	history := func(t *testing.T, id string) (*httptest.ResponseRecorder, error) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/cost-data-points/"+id+"/history", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		return rec, handler.History(c)
+	}
+
+	t.Run("update and delete are recorded with actor and reason", func(t *testing.T) {
+		seed(t)
+
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/cost-data-points/tariff-1", strings.NewReader(`{"price": 0.25, "reason": "Tariff revised in gazette"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req = req.WithContext(auth.WithKey(req.Context(), &models.APIKey{Prefix: "3f9a1c2b", Role: models.RoleContributor}))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("tariff-1")
+		c.QueryParams().Add("recorded_at", recordedAt.Format(time.RFC3339))
+		require.NoError(t, handler.Update(c))
+
+		req = httptest.NewRequest(http.MethodDelete, "/api/v1/cost-data-points/tariff-1", nil)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("tariff-1")
+		c.QueryParams().Add("recorded_at", recordedAt.Format(time.RFC3339))
+		c.QueryParams().Add("reason", "superseded")
+		require.NoError(t, handler.Delete(c))
+
+		rec, err := history(t, "tariff-1")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response dto.HistoryResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		require.Equal(t, 2, response.Count)
+
+		deleted, updated := response.Revisions[0], response.Revisions[1]
+		assert.Equal(t, models.RevisionDelete, deleted.Action)
+		assert.Equal(t, repository.DefaultActor, deleted.Actor)
+		assert.Equal(t, "superseded", deleted.Reason)
+		assert.Equal(t, []string{"deleted_at"}, deleted.ChangedFields)
+		require.NotNil(t, deleted.OldValues)
+		assert.Equal(t, 0.25, deleted.OldValues.Price)
+		require.NotNil(t, deleted.NewValues)
+		assert.NotNil(t, deleted.NewValues.DeletedAt)
+
+		assert.Equal(t, models.RevisionUpdate, updated.Action)
+		assert.Equal(t, "apikey:3f9a1c2b", updated.Actor)
+		assert.Equal(t, "Tariff revised in gazette", updated.Reason)
+		assert.Equal(t, []string{"price"}, updated.ChangedFields)
+		assert.Equal(t, 0.23, updated.OldValues.Price)
+		assert.Equal(t, 0.25, updated.NewValues.Price)
+	})
+
+	t.Run("existing point without changes has empty history", func(t *testing.T) {
+		seed(t)
+
+		rec, err := history(t, "tariff-1")
+		require.NoError(t, err)
+
+		var response dto.HistoryResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, 0, response.Count)
+		assert.NotNil(t, response.Revisions)
+	})
+
+	t.Run("unknown point", func(t *testing.T) {
+		seed(t)
+
+		_, err := history(t, "nonexistent")
+		require.Error(t, err)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+}
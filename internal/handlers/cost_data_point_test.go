@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -8,7 +9,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/adonese/cost-of-living/internal/auth"
+	"github.com/adonese/cost-of-living/internal/fx"
 	"github.com/adonese/cost-of-living/internal/handlers/dto"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
 	"github.com/adonese/cost-of-living/internal/repository/mock"
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
@@ -393,6 +398,268 @@ func TestCostDataPointHandler_List(t *testing.T) {
 		assert.Equal(t, 5, response.Limit)
 		assert.Equal(t, 0, response.Offset)
 	})
+
+	t.Run("list with extended filters and ordering", func(t *testing.T) {
+		mockRepo.Reset()
+
+		listings := []dto.CreateCostDataPointRequest{
+			{
+				Category: "Housing", ItemName: "Marina 2BR", Price: 9000, Source: "bayut",
+				Location:   dto.LocationDTO{Emirate: "Dubai", City: "Dubai", Area: "Dubai Marina"},
+				Tags:       []string{"rent", "apartment"},
+				Attributes: map[string]interface{}{"bedrooms": "2"},
+			},
+			{
+				Category: "Housing", ItemName: "Marina 1BR", Price: 7000, Source: "bayut",
+				Location:   dto.LocationDTO{Emirate: "Dubai", City: "Dubai", Area: "Dubai Marina"},
+				Tags:       []string{"rent", "apartment"},
+				Attributes: map[string]interface{}{"bedrooms": "1"},
+			},
+			{
+				Category: "Housing", ItemName: "Marina 2BR (dubizzle)", Price: 8500, Source: "dubizzle",
+				Location:   dto.LocationDTO{Emirate: "Dubai", City: "Dubai", Area: "Dubai Marina"},
+				Tags:       []string{"rent"},
+				Attributes: map[string]interface{}{"bedrooms": "2"},
+			},
+			{
+				Category: "Housing", ItemName: "Deira 2BR", Price: 6000, Source: "bayut",
+				Location:   dto.LocationDTO{Emirate: "Dubai", City: "Dubai", Area: "Deira"},
+				Tags:       []string{"rent", "apartment"},
+				Attributes: map[string]interface{}{"bedrooms": "2"},
+			},
+		}
+		for _, l := range listings {
+			require.NoError(t, mockRepo.Create(nil, l.ToModel()))
+		}
+
+		list := func(query string) (*httptest.ResponseRecorder, dto.ListResponse, error) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/cost-data-points?"+query, nil)
+			rec := httptest.NewRecorder()
+			err := handler.List(e.NewContext(req, rec))
+			var response dto.ListResponse
+			if err == nil {
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			}
+			return rec, response, err
+		}
+
+		_, response, err := list("source=bayut&area=Dubai+Marina&attr.bedrooms=2")
+		require.NoError(t, err)
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, "Marina 2BR", response.Data[0].ItemName)
+
+		_, response, err = list("tags=rent,apartment&tag_match=all&min_price=6500&order_by=price&order_dir=asc")
+		require.NoError(t, err)
+		require.Len(t, response.Data, 2)
+		assert.Equal(t, 7000.0, response.Data[0].Price)
+		assert.Equal(t, 9000.0, response.Data[1].Price)
+
+		_, response, err = list("city=Dubai&max_price=8500&order_by=price&order_dir=desc")
+		require.NoError(t, err)
+		require.Len(t, response.Data, 3)
+		assert.Equal(t, 8500.0, response.Data[0].Price)
+	})
+
+	t.Run("list near a point", func(t *testing.T) {
+		mockRepo.Reset()
+
+		for _, loc := range []dto.LocationDTO{
+			{Emirate: "Dubai", Area: "Dubai Marina", Coordinates: &dto.GeoPointDTO{Lat: 25.0805, Lon: 55.1403}},
+			{Emirate: "Dubai", Area: "Downtown Dubai", Coordinates: &dto.GeoPointDTO{Lat: 25.1972, Lon: 55.2744}},
+			{Emirate: "Dubai", Area: "Unknown"},
+		} {
+			createReq := dto.CreateCostDataPointRequest{
+				Category: "Housing",
+				ItemName: "Apartment in " + loc.Area,
+				Price:    80000,
+				Location: loc,
+				Source:   "bayut",
+			}
+			require.NoError(t, mockRepo.Create(nil, createReq.ToModel()))
+		}
+
+		list := func(query string) dto.ListResponse {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/cost-data-points?"+query, nil)
+			rec := httptest.NewRecorder()
+			require.NoError(t, handler.List(e.NewContext(req, rec)))
+
+			var response dto.ListResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			return response
+		}
+
+		response := list("near=25.08,55.14&radius_km=3")
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, "Dubai Marina", response.Data[0].Location.Area)
+
+		response = list("near=25.08,55.14&radius_km=20")
+		assert.Len(t, response.Data, 2)
+
+		response = list("bbox=25.1,55.2,25.3,55.3")
+		require.Len(t, response.Data, 1)
+		assert.Equal(t, "Downtown Dubai", response.Data[0].Location.Area)
+	})
+
+	t.Run("list with cursor pagination", func(t *testing.T) {
+		mockRepo.Reset()
+
+		base := time.Now().Add(-time.Hour)
+		for i := 0; i < 12; i++ {
+			recordedAt := base.Add(time.Duration(i) * time.Minute)
+			createReq := dto.CreateCostDataPointRequest{
+				Category:   "Housing",
+				ItemName:   "Test Apartment",
+				Price:      50000.0,
+				Location:   dto.LocationDTO{Emirate: "Dubai"},
+				Source:     "manual",
+				RecordedAt: &recordedAt,
+			}
+			require.NoError(t, mockRepo.Create(nil, createReq.ToModel()))
+		}
+
+		seen := make(map[string]bool)
+		cursor := ""
+		pages := 0
+		for {
+			url := "/api/v1/cost-data-points?limit=5"
+			if cursor != "" {
+				url += "&cursor=" + cursor
+			}
+			req := httptest.NewRequest(http.MethodGet, url, nil)
+			rec := httptest.NewRecorder()
+			require.NoError(t, handler.List(e.NewContext(req, rec)))
+
+			var response dto.ListResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.EqualValues(t, 12, response.TotalCount)
+
+			for _, item := range response.Data {
+				assert.False(t, seen[item.ID], "duplicate row across pages")
+				seen[item.ID] = true
+			}
+			pages++
+
+			if response.NextCursor == "" {
+				break
+			}
+			cursor = response.NextCursor
+		}
+
+		assert.Equal(t, 3, pages)
+		assert.Len(t, seen, 12)
+	})
+
+	t.Run("list with invalid cursor", func(t *testing.T) {
+		for _, query := range []string{"cursor=not-a-cursor", "cursor=eyJ0IjoiMjAyNS0wMS0wMVQwMDowMDowMFoiLCJpZCI6ImEifQ&offset=5"} {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/cost-data-points?"+query, nil)
+			rec := httptest.NewRecorder()
+			err := handler.List(e.NewContext(req, rec))
+			require.Error(t, err, query)
+
+			he, ok := err.(*echo.HTTPError)
+			require.True(t, ok, query)
+			assert.Equal(t, http.StatusBadRequest, he.Code, query)
+		}
+	})
+
+	t.Run("list with invalid filters", func(t *testing.T) {
+		for _, query := range []string{
+			"order_by=password",
+			"order_dir=sideways",
+			"tag_match=some",
+			"min_price=cheap",
+			"min_price=10&max_price=5",
+			"near=25.08,55.14",
+			"radius_km=3",
+			"near=25.08&radius_km=3",
+			"near=95,55&radius_km=3",
+			"near=25.08,55.14&radius_km=-1",
+			"bbox=25,55,24,56",
+			"bbox=25,55,26",
+			"currency=XYZ",
+			"currency=USD",
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/cost-data-points?"+query, nil)
+			rec := httptest.NewRecorder()
+			err := handler.List(e.NewContext(req, rec))
+			require.Error(t, err, query)
+
+			he, ok := err.(*echo.HTTPError)
+			require.True(t, ok, query)
+			assert.Equal(t, http.StatusBadRequest, he.Code, query)
+		}
+	})
+}
+
+func TestCostDataPointHandler_ListCurrency(t *testing.T) {
+	e := echo.New()
+	mockRepo := mock.NewCostDataPointRepository()
+	rates := fx.NewStore(fx.NewFileProvider(""), 0)
+	handler := NewCostDataPointHandlerWithRates(mockRepo, rates)
+	usd, err := rates.Rate(context.Background(), "AED", "USD")
+	require.NoError(t, err)
+
+	now := time.Now()
+	for _, cdp := range []*models.CostDataPoint{
+		{ID: "rent", Category: "Housing", ItemName: "Apartment", Price: 100000, MinPrice: 90000, Currency: "AED", Period: "year"},
+		{ID: "taxi", Category: "Transportation", ItemName: "Taxi", Price: 2, Currency: "AED", Unit: "km"},
+		// Legacy units are normalised on write
+		{ID: "power", Category: "Utilities", ItemName: "Electricity", Price: 23, Unit: "fils_per_kwh"},
+		{ID: "sewage", Category: "Utilities", ItemName: "Sewage", Price: 0.5, Unit: "fraction"},
+	} {
+		cdp.Location = models.Location{Emirate: "Dubai"}
+		cdp.Source = "manual"
+		cdp.RecordedAt = now
+		require.NoError(t, mockRepo.Create(context.Background(), cdp))
+	}
+
+	list := func(query string) dto.ListResponse {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/cost-data-points?"+query, nil)
+		rec := httptest.NewRecorder()
+		require.NoError(t, handler.List(e.NewContext(req, rec)))
+
+		var response dto.ListResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		return response
+	}
+	byID := func(response dto.ListResponse) map[string]dto.CostDataPointResponse {
+		result := make(map[string]dto.CostDataPointResponse)
+		for _, item := range response.Data {
+			result[item.ID] = item
+		}
+		return result
+	}
+
+	t.Run("converts to requested currency", func(t *testing.T) {
+		response := list("currency=usd")
+		require.NotNil(t, response.FXRate)
+		assert.Equal(t, usd, *response.FXRate)
+
+		items := byID(response)
+		assert.InDelta(t, 100000*usd.Rate, items["rent"].Price, 0.001)
+		assert.InDelta(t, 90000*usd.Rate, items["rent"].MinPrice, 0.001)
+		assert.Equal(t, "USD", items["rent"].Currency)
+		assert.Equal(t, "year", items["rent"].Period)
+		assert.InDelta(t, 2*usd.Rate, items["taxi"].Price, 0.001)
+		assert.Equal(t, "USD", items["taxi"].Currency)
+		assert.Equal(t, "km", items["taxi"].Unit)
+		assert.InDelta(t, 0.23*usd.Rate, items["power"].Price, 0.001)
+		assert.Equal(t, "USD", items["power"].Currency)
+		assert.Equal(t, "kWh", items["power"].Unit)
+
+		// Amounts not in a currency are untouched
+		assert.Equal(t, 0.5, items["sewage"].Price)
+		assert.Equal(t, "", items["sewage"].Currency)
+		assert.Equal(t, "fraction", items["sewage"].Unit)
+	})
+
+	t.Run("AED is not converted", func(t *testing.T) {
+		for _, query := range []string{"", "currency=AED"} {
+			response := list(query)
+			assert.Nil(t, response.FXRate, query)
+			assert.Equal(t, 100000.0, byID(response)["rent"].Price, query)
+		}
+	})
 }
 
 func TestCostDataPointHandler_Update(t *testing.T) {
@@ -550,7 +817,68 @@ func TestCostDataPointHandler_Delete(t *testing.T) {
 		err = handler.Delete(c)
 		require.NoError(t, err)
 		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, 1, mockRepo.GetCallCount("SoftDelete"))
+
+		// Soft-deleted records are hidden but kept for restore
+		_, err = mockRepo.GetByID(context.Background(), "test-id-1", now)
+		assert.Error(t, err)
+		deleted, err := mockRepo.List(context.Background(), repository.ListFilter{ID: "test-id-1", IncludeDeleted: true})
+		require.NoError(t, err)
+		require.Len(t, deleted, 1)
+		assert.NotNil(t, deleted[0].DeletedAt)
+	})
+
+	t.Run("hard delete", func(t *testing.T) {
+		mockRepo.Reset()
+
+		now := time.Now()
+		cdp := &models.CostDataPoint{ID: "test-id-1", Category: "Housing", ItemName: "Test Apartment", Price: 50000, RecordedAt: now, Source: "manual"}
+		require.NoError(t, mockRepo.Create(context.Background(), cdp))
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/cost-data-points/test-id-1", nil)
+		req = req.WithContext(auth.WithKey(req.Context(), &models.APIKey{Role: models.RoleAdmin}))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("test-id-1")
+		c.QueryParams().Add("recorded_at", now.Format(time.RFC3339))
+		c.QueryParams().Add("hard", "true")
+
+		err := handler.Delete(c)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
 		assert.Equal(t, 1, mockRepo.GetCallCount("Delete"))
+
+		deleted, err := mockRepo.List(context.Background(), repository.ListFilter{ID: "test-id-1", IncludeDeleted: true})
+		require.NoError(t, err)
+		assert.Empty(t, deleted)
+	})
+
+	t.Run("hard delete needs an admin key", func(t *testing.T) {
+		mockRepo.Reset()
+
+		now := time.Now()
+		cdp := &models.CostDataPoint{ID: "test-id-1", Category: "Housing", ItemName: "Test Apartment", Price: 50000, RecordedAt: now, Source: "manual"}
+		require.NoError(t, mockRepo.Create(context.Background(), cdp))
+
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/cost-data-points/test-id-1", nil)
+		req = req.WithContext(auth.WithKey(req.Context(), &models.APIKey{Role: models.RoleContributor}))
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues("test-id-1")
+		c.QueryParams().Add("recorded_at", now.Format(time.RFC3339))
+		c.QueryParams().Add("hard", "true")
+
+		err := handler.Delete(c)
+		require.Error(t, err)
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusForbidden, httpErr.Code)
+		assert.Zero(t, mockRepo.GetCallCount("Delete"))
+
+		_, err = mockRepo.GetByID(context.Background(), "test-id-1", now)
+		assert.NoError(t, err, "the data point is kept")
 	})
 
 	t.Run("delete not found", func(t *testing.T) {
@@ -589,3 +917,54 @@ func TestCostDataPointHandler_Delete(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
 	})
 }
+
+func TestCostDataPointHandler_Restore(t *testing.T) {
+	e := echo.New()
+	mockRepo := mock.NewCostDataPointRepository()
+	handler := NewCostDataPointHandler(mockRepo)
+
+	now := time.Now()
+	restore := func(id string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/cost-data-points/"+id+"/restore", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		c.QueryParams().Add("recorded_at", now.Format(time.RFC3339))
+		return rec, handler.Restore(c)
+	}
+
+	t.Run("successful restore", func(t *testing.T) {
+		mockRepo.Reset()
+
+		cdp := &models.CostDataPoint{ID: "test-id-1", Category: "Housing", ItemName: "Test Apartment", Price: 50000, RecordedAt: now, Source: "manual"}
+		require.NoError(t, mockRepo.Create(context.Background(), cdp))
+		require.NoError(t, mockRepo.SoftDelete(context.Background(), "test-id-1", now))
+
+		rec, err := restore("test-id-1")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response dto.CostDataPointResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, "test-id-1", response.ID)
+		assert.Nil(t, response.DeletedAt)
+
+		_, err = mockRepo.GetByID(context.Background(), "test-id-1", now)
+		assert.NoError(t, err)
+	})
+
+	t.Run("record not deleted", func(t *testing.T) {
+		mockRepo.Reset()
+
+		cdp := &models.CostDataPoint{ID: "test-id-1", Category: "Housing", ItemName: "Test Apartment", Price: 50000, RecordedAt: now, Source: "manual"}
+		require.NoError(t, mockRepo.Create(context.Background(), cdp))
+
+		_, err := restore("test-id-1")
+		require.Error(t, err)
+
+		httpErr, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusNotFound, httpErr.Code)
+	})
+}
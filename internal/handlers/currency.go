@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/adonese/cost-of-living/internal/fx"
+	"github.com/adonese/cost-of-living/internal/handlers/dto"
+)
+
+// rate returns the conversion requested by the currency query parameter, or
+// nil when no conversion is needed
+func (h *CostDataPointHandler) rate(c echo.Context) (*fx.Rate, error) {
+	currency := c.QueryParam("currency")
+	code := fx.NormalizeCode(currency)
+	if currency == "" || code == fx.BaseCurrency {
+		return nil, nil
+	}
+	if code == "" || h.rates == nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unsupported currency %q", currency))
+	}
+
+	rate, err := h.rates.Rate(c.Request().Context(), fx.BaseCurrency, code)
+	if errors.Is(err, fx.ErrUnsupportedCurrency) {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unsupported currency %q", currency))
+	}
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusServiceUnavailable, "Exchange rates unavailable")
+	}
+	return &rate, nil
+}
+
+// convertPrices converts the prices of a data point quoted in AED. Prices in
+// other currencies and rates without one, such as a sewerage charge that is
+// a fraction of the water bill, are left unchanged.
+func convertPrices(resp *dto.CostDataPointResponse, rate fx.Rate) {
+	if resp.Currency != rate.From {
+		return
+	}
+
+	resp.Currency = rate.To
+	resp.Price = convertAmount(resp.Price, rate.Rate)
+	resp.MinPrice = convertAmount(resp.MinPrice, rate.Rate)
+	resp.MaxPrice = convertAmount(resp.MaxPrice, rate.Rate)
+	resp.MedianPrice = convertAmount(resp.MedianPrice, rate.Rate)
+}
+
+// convertAmount keeps four decimal places so per-unit tariffs stay
+// meaningful after conversion
+func convertAmount(amount, factor float64) float64 {
+	return math.Round(amount*factor*1e4) / 1e4
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "github.com/adonese/cost-of-living/internal/models"
+
+// CreateAPIKeyRequest is the payload accepted by POST /api/v1/admin/api-keys
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+	Role string `json:"role" validate:"required,oneof=reader contributor admin"`
+}
+
+// APIKeyResponse describes a key without its secret
+type APIKeyResponse struct {
+	*models.APIKey
+	Revoked bool `json:"revoked"`
+}
+
+// IssuedAPIKeyResponse is returned once, when a key is issued. Key is the
+// only copy of the plaintext; it cannot be retrieved again.
+type IssuedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+// APIKeyListResponse represents a list of keys
+type APIKeyListResponse struct {
+	Keys  []APIKeyResponse `json:"keys"`
+	Count int              `json:"count"`
+}
+
+// FromAPIKey converts a key into its response
+func FromAPIKey(key *models.APIKey) APIKeyResponse {
+	return APIKeyResponse{APIKey: key, Revoked: key.Revoked()}
+}
+
+// FromAPIKeys converts keys into a list response
+func FromAPIKeys(keys []*models.APIKey) APIKeyListResponse {
+	response := APIKeyListResponse{Keys: make([]APIKeyResponse, 0, len(keys)), Count: len(keys)}
+	for _, key := range keys {
+		response.Keys = append(response.Keys, FromAPIKey(key))
+	}
+	return response
+}
@@ -3,6 +3,7 @@ package dto
 import (
 	"time"
 
+	"github.com/adonese/cost-of-living/internal/fx"
 	"github.com/adonese/cost-of-living/internal/models"
 )
 
@@ -23,7 +24,9 @@ type CreateCostDataPointRequest struct {
 	Source      string                 `json:"source" validate:"required"`
 	SourceURL   string                 `json:"source_url,omitempty"`
 	Confidence  float32                `json:"confidence,omitempty"`
+	Currency    string                 `json:"currency,omitempty"`
 	Unit        string                 `json:"unit,omitempty"`
+	Period      string                 `json:"period,omitempty"`
 	Tags        []string               `json:"tags,omitempty"`
 	Attributes  map[string]interface{} `json:"attributes,omitempty"`
 }
@@ -44,17 +47,21 @@ type UpdateCostDataPointRequest struct {
 	Source      string                 `json:"source"`
 	SourceURL   string                 `json:"source_url,omitempty"`
 	Confidence  float32                `json:"confidence,omitempty"`
+	Currency    string                 `json:"currency,omitempty"`
 	Unit        string                 `json:"unit,omitempty"`
+	Period      string                 `json:"period,omitempty"`
 	Tags        []string               `json:"tags,omitempty"`
 	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+	// Reason explains the change and is recorded in the revision history
+	Reason string `json:"reason,omitempty"`
 }
 
 // LocationDTO represents the location information
 type LocationDTO struct {
-	Emirate     string        `json:"emirate" validate:"required"`
-	City        string        `json:"city,omitempty"`
-	Area        string        `json:"area,omitempty"`
-	Coordinates *GeoPointDTO  `json:"coordinates,omitempty"`
+	Emirate     string       `json:"emirate" validate:"required"`
+	City        string       `json:"city,omitempty"`
+	Area        string       `json:"area,omitempty"`
+	Coordinates *GeoPointDTO `json:"coordinates,omitempty"`
 }
 
 // GeoPointDTO represents geographic coordinates
@@ -65,35 +72,64 @@ type GeoPointDTO struct {
 
 // CostDataPointResponse represents the response body for a cost data point
 type CostDataPointResponse struct {
-	ID          string                 `json:"id"`
-	Category    string                 `json:"category"`
-	SubCategory string                 `json:"sub_category,omitempty"`
-	ItemName    string                 `json:"item_name"`
-	Price       float64                `json:"price"`
-	MinPrice    float64                `json:"min_price,omitempty"`
-	MaxPrice    float64                `json:"max_price,omitempty"`
-	MedianPrice float64                `json:"median_price,omitempty"`
-	SampleSize  int                    `json:"sample_size"`
-	Location    LocationDTO            `json:"location"`
-	RecordedAt  time.Time              `json:"recorded_at"`
-	ValidFrom   time.Time              `json:"valid_from"`
-	ValidTo     *time.Time             `json:"valid_to,omitempty"`
-	Source      string                 `json:"source"`
-	SourceURL   string                 `json:"source_url,omitempty"`
-	Confidence  float32                `json:"confidence"`
-	Unit        string                 `json:"unit"`
-	Tags        []string               `json:"tags,omitempty"`
-	Attributes  map[string]interface{} `json:"attributes,omitempty"`
-	CreatedAt   time.Time              `json:"created_at"`
-	UpdatedAt   time.Time              `json:"updated_at"`
+	ID           string                 `json:"id"`
+	Category     string                 `json:"category"`
+	SubCategory  string                 `json:"sub_category,omitempty"`
+	ItemName     string                 `json:"item_name"`
+	Price        float64                `json:"price"`
+	MinPrice     float64                `json:"min_price,omitempty"`
+	MaxPrice     float64                `json:"max_price,omitempty"`
+	MedianPrice  float64                `json:"median_price,omitempty"`
+	SampleSize   int                    `json:"sample_size"`
+	Location     LocationDTO            `json:"location"`
+	RecordedAt   time.Time              `json:"recorded_at"`
+	ValidFrom    time.Time              `json:"valid_from"`
+	ValidTo      *time.Time             `json:"valid_to,omitempty"`
+	Source       string                 `json:"source"`
+	SourceURL    string                 `json:"source_url,omitempty"`
+	Confidence   float32                `json:"confidence"`
+	Currency     string                 `json:"currency,omitempty"`
+	Unit         string                 `json:"unit,omitempty"`
+	Period       string                 `json:"period,omitempty"`
+	Tags         []string               `json:"tags,omitempty"`
+	Attributes   map[string]interface{} `json:"attributes,omitempty"`
+	RunID        string                 `json:"run_id,omitempty"`
+	FirstSeenAt  time.Time              `json:"first_seen_at"`
+	LastSeenAt   time.Time              `json:"last_seen_at"`
+	DaysOnMarket *int                   `json:"days_on_market,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+	DeletedAt    *time.Time             `json:"deleted_at,omitempty"`
 }
 
-// ListResponse represents a paginated list response
+// ListResponse represents a paginated list response. FXRate is set when
+// prices were converted to a requested currency.
 type ListResponse struct {
 	Data       []CostDataPointResponse `json:"data"`
-	TotalCount int                     `json:"total_count"`
+	TotalCount int64                   `json:"total_count"`
 	Limit      int                     `json:"limit"`
 	Offset     int                     `json:"offset"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+	FXRate     *fx.Rate                `json:"fx_rate,omitempty"`
+}
+
+// HistoryResponse lists the revisions of a cost data point, newest first
+type HistoryResponse struct {
+	ID        string             `json:"id"`
+	Revisions []*models.Revision `json:"revisions"`
+	Count     int                `json:"count"`
+}
+
+// FromRevisions converts the revisions of a cost data point to a HistoryResponse
+func FromRevisions(id string, revisions []*models.Revision) HistoryResponse {
+	if revisions == nil {
+		revisions = []*models.Revision{}
+	}
+	return HistoryResponse{
+		ID:        id,
+		Revisions: revisions,
+		Count:     len(revisions),
+	}
 }
 
 // ToModel converts CreateCostDataPointRequest to models.CostDataPoint
@@ -111,10 +147,13 @@ func (r *CreateCostDataPointRequest) ToModel() *models.CostDataPoint {
 		Source:      r.Source,
 		SourceURL:   r.SourceURL,
 		Confidence:  r.Confidence,
+		Currency:    r.Currency,
 		Unit:        r.Unit,
+		Period:      r.Period,
 		Tags:        r.Tags,
 		Attributes:  r.Attributes,
 	}
+	cdp.NormalizeUnit()
 
 	if r.RecordedAt != nil {
 		cdp.RecordedAt = *r.RecordedAt
@@ -149,7 +188,7 @@ func (l *LocationDTO) ToModel() models.Location {
 
 // FromModel converts models.CostDataPoint to CostDataPointResponse
 func FromModel(cdp *models.CostDataPoint) CostDataPointResponse {
-	return CostDataPointResponse{
+	resp := CostDataPointResponse{
 		ID:          cdp.ID,
 		Category:    cdp.Category,
 		SubCategory: cdp.SubCategory,
@@ -166,12 +205,26 @@ func FromModel(cdp *models.CostDataPoint) CostDataPointResponse {
 		Source:      cdp.Source,
 		SourceURL:   cdp.SourceURL,
 		Confidence:  cdp.Confidence,
+		Currency:    cdp.Currency,
 		Unit:        cdp.Unit,
+		Period:      cdp.Period,
 		Tags:        cdp.Tags,
 		Attributes:  cdp.Attributes,
+		RunID:       cdp.RunID,
+		FirstSeenAt: cdp.FirstSeenAt,
+		LastSeenAt:  cdp.LastSeenAt,
 		CreatedAt:   cdp.CreatedAt,
 		UpdatedAt:   cdp.UpdatedAt,
+		DeletedAt:   cdp.DeletedAt,
 	}
+
+	// Days on market only makes sense for individual listings
+	if cdp.IsListing() {
+		days := cdp.DaysOnMarket(time.Now())
+		resp.DaysOnMarket = &days
+	}
+
+	return resp
 }
 
 // FromLocationModel converts models.Location to LocationDTO
@@ -237,9 +290,15 @@ func (r *UpdateCostDataPointRequest) ApplyUpdate(cdp *models.CostDataPoint) {
 	if r.Confidence > 0 {
 		cdp.Confidence = r.Confidence
 	}
+	if r.Currency != "" {
+		cdp.Currency = r.Currency
+	}
 	if r.Unit != "" {
 		cdp.Unit = r.Unit
 	}
+	if r.Period != "" {
+		cdp.Period = r.Period
+	}
 	if len(r.Tags) > 0 {
 		cdp.Tags = r.Tags
 	}
@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	customMiddleware "github.com/adonese/cost-of-living/internal/middleware"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/pkg/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// repositoryError converts an error returned by a repository into an HTTP
+// error. Known kinds keep their status and show the repository's public
+// message, with notFound as the message for missing records; anything else
+// is a 500 reporting failed. The full error, which may quote SQL or
+// constraint names, is only logged, and kept as Internal so ErrorHandler
+// can report its error code.
+func repositoryError(err error, notFound, failed string) *echo.HTTPError {
+	status, _, ok := customMiddleware.StatusFor(err)
+
+	message := failed
+	switch {
+	case !ok:
+	case errors.Is(err, repository.ErrNotFound):
+		message = notFound
+	case errors.Is(err, repository.ErrUnavailable):
+		message = "Database temporarily unavailable"
+	default:
+		message = repository.PublicMessage(err)
+	}
+
+	switch {
+	case status >= http.StatusInternalServerError:
+		logger.Error(failed, "status", status, "error", err)
+	case status != http.StatusNotFound:
+		logger.Warn(failed, "status", status, "error", err)
+	}
+
+	if status == http.StatusInternalServerError {
+		message = failed
+	}
+
+	return echo.NewHTTPError(status, message).SetInternal(err)
+}
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryError(t *testing.T) {
+	logger.Init()
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+	}{
+		{"not found", repository.NotFound("cost data point"), http.StatusNotFound, "Cost data point not found"},
+		{"conflict", repository.Conflict(errors.New("duplicate cost data point")), http.StatusConflict, "duplicate cost data point"},
+		{"validation", repository.ValidationFailed(errors.New("malformed cursor")), http.StatusBadRequest, "malformed cursor"},
+		{"unavailable", repository.Unavailable(errors.New("connection refused")), http.StatusServiceUnavailable, "Database temporarily unavailable"},
+		{"unclassified", errors.New("scan failed"), http.StatusInternalServerError, "Failed to update cost data point"},
+		{"wrapped", fmt.Errorf("invalid filter: %w", repository.ValidationFailed(errors.New("malformed cursor"))), http.StatusBadRequest, "malformed cursor"},
+		{"driver detail", repository.DriverError(repository.ErrValidationFailed, errors.New(`pq: new row violates check constraint "cost_data_points_price_check"`)), http.StatusBadRequest, "rejected by the database"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpErr := repositoryError(tt.err, "Cost data point not found", "Failed to update cost data point")
+			assert.Equal(t, tt.status, httpErr.Code)
+			assert.Equal(t, tt.message, httpErr.Message)
+			assert.ErrorIs(t, httpErr.Internal, tt.err)
+		})
+	}
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -24,7 +26,8 @@ func NewEstimatorHandler(service *estimator.Service) *EstimatorHandler {
 	}
 }
 
-// Estimate aggregates cost breakdown based on the persona payload.
+// Estimate aggregates cost breakdown based on the persona payload. The
+// optional currency query parameter converts amounts out of AED.
 func (h *EstimatorHandler) Estimate(c echo.Context) error {
 	var req dto.EstimateRequest
 	if err := c.Bind(&req); err != nil {
@@ -35,7 +38,7 @@ func (h *EstimatorHandler) Estimate(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
-	result, err := h.service.Estimate(c.Request().Context(), req.ToPersona())
+	result, err := h.service.EstimateIn(c.Request().Context(), req.ToPersona(), c.QueryParam("currency"))
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
@@ -57,3 +60,86 @@ func (h *EstimatorHandler) Summary(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, snap)
 }
+
+// Trends returns a bucketed price series for a category, optionally
+// narrowed to an emirate, area and bedroom count.
+func (h *EstimatorHandler) Trends(c echo.Context) error {
+	query := estimator.TrendQuery{
+		Category:    c.QueryParam("category"),
+		SubCategory: c.QueryParam("sub_category"),
+		Emirate:     c.QueryParam("emirate"),
+		Area:        c.QueryParam("area"),
+		Unit:        c.QueryParam("unit"),
+		Bucket:      estimator.TrendBucket(c.QueryParam("bucket")),
+		Aggregate:   estimator.TrendAggregate(c.QueryParam("aggregate")),
+	}
+
+	if bedroomsStr := c.QueryParam("bedrooms"); bedroomsStr != "" {
+		bedrooms, err := strconv.Atoi(bedroomsStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid bedrooms parameter")
+		}
+		query.Bedrooms = &bedrooms
+	}
+	if startDateStr := c.QueryParam("start_date"); startDateStr != "" {
+		startDate, err := time.Parse(time.RFC3339, startDateStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid start_date format, use RFC3339")
+		}
+		query.Start = &startDate
+	}
+	if endDateStr := c.QueryParam("end_date"); endDateStr != "" {
+		endDate, err := time.Parse(time.RFC3339, endDateStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid end_date format, use RFC3339")
+		}
+		query.End = &endDate
+	}
+
+	result, err := h.service.Trend(c.Request().Context(), query)
+	if err != nil {
+		return repositoryError(err, "No data found", "Failed to compute trend")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// CompareAreas ranks the communities of an emirate by rent and commute
+// cost against an optional monthly budget.
+func (h *EstimatorHandler) CompareAreas(c echo.Context) error {
+	query := estimator.AreaQuery{
+		Emirate:       c.QueryParam("emirate"),
+		HousingType:   estimator.HousingType(c.QueryParam("housing_type")),
+		Target:        c.QueryParam("target"),
+		TransportMode: estimator.TransportMode(c.QueryParam("transport_mode")),
+	}
+
+	if bedroomsStr := c.QueryParam("bedrooms"); bedroomsStr != "" {
+		bedrooms, err := strconv.Atoi(bedroomsStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid bedrooms parameter")
+		}
+		query.Bedrooms = &bedrooms
+	}
+	if budgetStr := c.QueryParam("budget"); budgetStr != "" {
+		budget, err := strconv.ParseFloat(budgetStr, 64)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid budget parameter")
+		}
+		query.Budget = budget
+	}
+	if daysStr := c.QueryParam("work_days_per_week"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "Invalid work_days_per_week parameter")
+		}
+		query.WorkDaysPerWeek = days
+	}
+
+	result, err := h.service.CompareAreas(c.Request().Context(), query)
+	if err != nil {
+		return repositoryError(err, "No data found", "Failed to compare areas")
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
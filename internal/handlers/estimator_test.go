@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/internal/services/estimator"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimatorHandler_Trends(t *testing.T) {
+	e := echo.New()
+	mockRepo := mock.NewCostDataPointRepository()
+	handler := NewEstimatorHandler(estimator.NewService(mockRepo, nil))
+
+	recordedAt := time.Now().UTC().AddDate(0, -1, 0)
+	require.NoError(t, mockRepo.Create(context.Background(), &models.CostDataPoint{
+		ID:          "marina-2br",
+		Category:    "Housing",
+		SubCategory: "Rent",
+		Price:       9500,
+		Location:    models.Location{Emirate: "Dubai", Area: "Dubai Marina"},
+		RecordedAt:  recordedAt,
+		Source:      "bayut",
+		Currency:    "AED",
+		Period:      "month",
+		Attributes:  map[string]interface{}{"bedrooms": "2"},
+	}))
+
+	trends := func(query string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/trends?"+query, nil)
+		rec := httptest.NewRecorder()
+		return rec, handler.Trends(e.NewContext(req, rec))
+	}
+
+	rec, err := trends("category=Housing&area=Dubai+Marina&bedrooms=2&bucket=week&aggregate=mean")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var res estimator.TrendResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, estimator.BucketWeek, res.Bucket)
+	require.Len(t, res.Series, 1)
+	assert.Equal(t, 9500.0, res.Series[0].Value)
+	require.NotNil(t, res.Bedrooms)
+	assert.Equal(t, 2, *res.Bedrooms)
+
+	rec, err = trends("category=Housing&bedrooms=3")
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Empty(t, res.Series)
+
+	for query, message := range map[string]string{
+		"bucket=month":                    "category is required",
+		"category=Housing&bucket=year":    `unsupported bucket "year"`,
+		"category=Housing&bedrooms=two":   "Invalid bedrooms parameter",
+		"category=Housing&start_date=May": "Invalid start_date format, use RFC3339",
+	} {
+		_, err := trends(query)
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr, query)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code, query)
+		assert.Contains(t, httpErr.Message, message, query)
+	}
+}
+
+func TestEstimatorHandler_CompareAreas(t *testing.T) {
+	e := echo.New()
+	mockRepo := mock.NewCostDataPointRepository()
+	handler := NewEstimatorHandler(estimator.NewService(mockRepo, nil))
+
+	for _, listing := range []struct {
+		id, area string
+		price    float64
+	}{
+		{"marina", "Dubai Marina", 9500},
+		{"jvc", "JVC", 6000},
+	} {
+		require.NoError(t, mockRepo.Create(context.Background(), &models.CostDataPoint{
+			ID:          listing.id,
+			Category:    "Housing",
+			SubCategory: "Rent",
+			Price:       listing.price,
+			Location:    models.Location{Emirate: "Dubai", Area: listing.area},
+			RecordedAt:  time.Now(),
+			Source:      "bayut",
+			Currency:    "AED",
+			Period:      "month",
+			Confidence:  0.9,
+			Attributes:  map[string]interface{}{"bedrooms": "1"},
+		}))
+	}
+
+	compare := func(query string) (*httptest.ResponseRecorder, error) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/areas/compare?"+query, nil)
+		rec := httptest.NewRecorder()
+		return rec, handler.CompareAreas(e.NewContext(req, rec))
+	}
+
+	rec, err := compare("emirate=Dubai&bedrooms=1&budget=8000&target=Dubai+Marina&transport_mode=public")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var res estimator.AreaComparison
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, "Dubai Marina", res.Target)
+	require.Len(t, res.Areas, 2)
+	assert.Equal(t, "Jumeirah Village Circle", res.Areas[0].Area)
+	assert.True(t, res.Areas[0].WithinBudget)
+	assert.False(t, res.Areas[1].WithinBudget)
+
+	for query, message := range map[string]string{
+		"bedrooms=1":                         "emirate is required",
+		"emirate=Dubai&budget=lots":          "Invalid budget parameter",
+		"emirate=Dubai&bedrooms=two":         "Invalid bedrooms parameter",
+		"emirate=Dubai&work_days_per_week=x": "Invalid work_days_per_week parameter",
+		"emirate=Dubai&target=Atlantis":      `unknown target area "Atlantis"`,
+		"emirate=Dubai&housing_type=castle":  `unsupported housing_type "castle"`,
+	} {
+		_, err := compare(query)
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr, query)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code, query)
+		assert.Contains(t, httpErr.Message, message, query)
+	}
+}
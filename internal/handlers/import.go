@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/adonese/cost-of-living/internal/export"
+	"github.com/adonese/cost-of-living/internal/importer"
+	customMiddleware "github.com/adonese/cost-of-living/internal/middleware"
+	"github.com/labstack/echo/v4"
+)
+
+// maxImportSize caps the size of an uploaded import file
+const maxImportSize = 64 << 20
+
+// ImportHandler handles bulk imports of cost data points
+type ImportHandler struct {
+	importer *importer.Importer
+}
+
+// NewImportHandler creates a new import handler
+func NewImportHandler(imp *importer.Importer) *ImportHandler {
+	return &ImportHandler{importer: imp}
+}
+
+// Import handles POST /api/v1/cost-data-points/import.
+//
+// The file is either the raw request body or the "file" field of a
+// multipart form. The mapping spec is passed as JSON in the "mapping" form
+// field or query parameter. format (csv or ndjson) is taken from the query,
+// the file name or the Content-Type, and dry_run=true validates without
+// writing. The response is the per-row import report.
+func (h *ImportHandler) Import(c echo.Context) error {
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, maxImportSize)
+
+	dryRun, err := parseBoolParam(c, "dry_run")
+	if err != nil {
+		return err
+	}
+	enforceMaxAge, err := parseBoolParam(c, "enforce_max_age")
+	if err != nil {
+		return err
+	}
+
+	body, filename, mappingSpec, err := importBody(c)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	format, err := importFormat(c, filename)
+	if err != nil {
+		return err
+	}
+
+	mapping, err := importer.ParseMapping([]byte(firstNonEmpty(mappingSpec, c.QueryParam("mapping"))))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	report, err := h.importer.Import(req.Context(), body, importer.Options{
+		Format:        format,
+		Mapping:       mapping,
+		DryRun:        dryRun,
+		EnforceMaxAge: enforceMaxAge,
+	})
+	if err != nil {
+		return importError(c, report, err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+// importFailure is the error body of an import that stopped part way, with
+// the report of the rows handled before it stopped
+type importFailure struct {
+	customMiddleware.ErrorResponse
+	Report *importer.Report `json:"report"`
+}
+
+// importError maps an import error to an HTTP error. Unreadable input is a
+// 400 and anything else goes through repositoryError, so outages surface as
+// 503. Earlier batches may already be committed, so a partial report is
+// written with the error instead of being dropped.
+func importError(c echo.Context, report *importer.Report, err error) error {
+	var he *echo.HTTPError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		he = echo.NewHTTPError(http.StatusRequestEntityTooLarge, fmt.Sprintf("Import file exceeds %d bytes", tooLarge.Limit))
+	case errors.Is(err, importer.ErrInvalidInput):
+		he = echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		he = repositoryError(err, "Import failed", "Import failed")
+	}
+
+	if report == nil {
+		return he
+	}
+	return c.JSON(he.Code, importFailure{
+		ErrorResponse: customMiddleware.ResponseFor(he.SetInternal(err)),
+		Report:        report,
+	})
+}
+
+// importBody returns the uploaded file, its name and the mapping form field.
+// Only multipart forms are parsed as forms, so raw bodies sent with a form
+// Content-Type (curl --data-binary) are read as-is.
+func importBody(c echo.Context) (io.ReadCloser, string, string, error) {
+	if !strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+		return c.Request().Body, "", "", nil
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, "", "", echo.NewHTTPError(http.StatusBadRequest, "Multipart imports require a file field")
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, "", "", echo.NewHTTPError(http.StatusBadRequest, "Failed to read uploaded file")
+	}
+	return file, header.Filename, c.FormValue("mapping"), nil
+}
+
+// importFormat resolves the input format from the query, file name or
+// Content-Type, defaulting to CSV
+func importFormat(c echo.Context, filename string) (export.Format, error) {
+	name := c.QueryParam("format")
+	if name == "" {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".ndjson", ".jsonl":
+			name = string(export.FormatNDJSON)
+		}
+	}
+	if name == "" {
+		contentType := c.Request().Header.Get(echo.HeaderContentType)
+		if strings.Contains(contentType, "ndjson") || strings.Contains(contentType, "jsonl") {
+			name = string(export.FormatNDJSON)
+		}
+	}
+
+	format, err := export.ParseFormat(name)
+	if err != nil || format == export.FormatParquetLite {
+		return "", echo.NewHTTPError(http.StatusBadRequest, "Invalid format parameter, use csv or ndjson")
+	}
+	return format, nil
+}
+
+// parseBoolParam parses an optional boolean query parameter
+func parseBoolParam(c echo.Context, name string) (bool, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid %s parameter", name))
+	}
+	return b, nil
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/adonese/cost-of-living/internal/importer"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/pkg/logger"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportHandler_Import(t *testing.T) {
+	logger.Init()
+	e := echo.New()
+	mockRepo := mock.NewCostDataPointRepository()
+	handler := NewImportHandler(importer.NewImporter(mockRepo))
+
+	csvBody := "Item,Fare,Emirate\nMetro,4,Dubai\nTaxi,abc,Dubai\n"
+	mapping := `{"columns":{"item_name":"Item","price":"Fare","location_emirate":"Emirate"},"defaults":{"category":"Transportation","source":"rta"}}`
+
+	t.Run("raw csv body dry run", func(t *testing.T) {
+		mockRepo.Reset()
+
+		target := "/api/v1/cost-data-points/import?dry_run=true&mapping=" + url.QueryEscape(mapping)
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(csvBody))
+		req.Header.Set(echo.HeaderContentType, "text/csv")
+		rec := httptest.NewRecorder()
+
+		require.NoError(t, handler.Import(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var report importer.Report
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 2, report.Total)
+		assert.Equal(t, 1, report.Accepted)
+		assert.Equal(t, importer.RowRejected, report.Rows[1].Status)
+		assert.Equal(t, 0, mockRepo.GetCallCount("CreateBatch"))
+	})
+
+	t.Run("multipart upload writes accepted rows", func(t *testing.T) {
+		mockRepo.Reset()
+
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile("file", "fares.csv")
+		require.NoError(t, err)
+		_, err = part.Write([]byte(csvBody))
+		require.NoError(t, err)
+		require.NoError(t, form.WriteField("mapping", mapping))
+		require.NoError(t, form.Close())
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/cost-data-points/import", &body)
+		req.Header.Set(echo.HeaderContentType, form.FormDataContentType())
+		rec := httptest.NewRecorder()
+
+		require.NoError(t, handler.Import(e.NewContext(req, rec)))
+
+		var report importer.Report
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, 1, report.Accepted)
+		assert.NotEmpty(t, report.Rows[0].ID)
+
+		stored, err := mockRepo.List(context.Background(), repository.ListFilter{})
+		require.NoError(t, err)
+		assert.Len(t, stored, 1)
+	})
+
+	t.Run("invalid mapping", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/cost-data-points/import?mapping="+url.QueryEscape(`{"columns":{"cost":"Fare"}}`), strings.NewReader(csvBody))
+		rec := httptest.NewRecorder()
+
+		err := handler.Import(e.NewContext(req, rec))
+		require.Error(t, err)
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+	})
+
+	t.Run("empty input is a bad request", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/cost-data-points/import", strings.NewReader(""))
+		rec := httptest.NewRecorder()
+
+		err := handler.Import(e.NewContext(req, rec))
+		require.Error(t, err)
+		he, ok := err.(*echo.HTTPError)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, he.Code)
+	})
+
+	t.Run("outage returns 503 with the partial report", func(t *testing.T) {
+		mockRepo.Reset()
+		imp := importer.NewImporter(&unavailableAfterRepo{CostDataPointRepository: mockRepo, ok: 1})
+		imp.SetBatchSize(1)
+
+		body := csvBody + "Tram,3,Dubai\n"
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/cost-data-points/import?mapping="+url.QueryEscape(mapping), strings.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		require.NoError(t, NewImportHandler(imp).Import(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "connection refused")
+
+		var response struct {
+			ErrorCode string          `json:"error_code"`
+			Report    importer.Report `json:"report"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+		assert.Equal(t, "unavailable", response.ErrorCode)
+		assert.Equal(t, 1, response.Report.Accepted)
+		assert.Equal(t, importer.RowRejected, response.Report.Rows[2].Status)
+	})
+
+	t.Run("parquet-lite is export only", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/cost-data-points/import?format=parquet-lite", strings.NewReader(csvBody))
+		rec := httptest.NewRecorder()
+
+		err := handler.Import(e.NewContext(req, rec))
+		require.Error(t, err)
+	})
+}
+
+// unavailableAfterRepo reports an outage on every CreateBatch after the
+// first ok calls
+type unavailableAfterRepo struct {
+	repository.CostDataPointRepository
+	ok    int
+	calls int
+}
+
+func (r *unavailableAfterRepo) CreateBatch(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	r.calls++
+	if r.calls > r.ok {
+		return nil, repository.Unavailable(errors.New("connection refused"))
+	}
+	return r.CostDataPointRepository.CreateBatch(ctx, cdps)
+}
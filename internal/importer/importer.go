@@ -0,0 +1,318 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/export"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/validation"
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+// DefaultBatchSize is the number of rows validated and written together
+const DefaultBatchSize = 500
+
+// ErrInvalidInput reports a mapping or input file the importer cannot read.
+// Other errors from Import come from validation or the repository.
+var ErrInvalidInput = errors.New("invalid import input")
+
+// RowStatus is the outcome of importing one row
+type RowStatus string
+
+const (
+	RowAccepted RowStatus = "accepted"
+	RowRejected RowStatus = "rejected"
+)
+
+// Options controls a single import
+type Options struct {
+	// Format is the input format: csv or ndjson
+	Format export.Format
+
+	// Mapping maps input columns to cost data point fields
+	Mapping Mapping
+
+	// DryRun parses and validates every row without writing anything
+	DryRun bool
+
+	// EnforceMaxAge applies the validator's one-year age limit to
+	// recorded_at. Imports usually carry historical data, so by default any
+	// past timestamp is accepted.
+	EnforceMaxAge bool
+}
+
+// RowResult reports what happened to one input row
+type RowResult struct {
+	Line     int       `json:"line"`
+	Status   RowStatus `json:"status"`
+	ID       string    `json:"id,omitempty"`
+	Errors   []string  `json:"errors,omitempty"`
+	Warnings []string  `json:"warnings,omitempty"`
+}
+
+// Report summarises an import
+type Report struct {
+	DryRun   bool        `json:"dry_run"`
+	Total    int         `json:"total"`
+	Accepted int         `json:"accepted"`
+	Rejected int         `json:"rejected"`
+	Rows     []RowResult `json:"rows"`
+	Duration string      `json:"duration"`
+}
+
+// Importer loads cost data points from CSV or NDJSON files
+type Importer struct {
+	repo      repository.CostDataPointRepository
+	batchSize int
+}
+
+// NewImporter creates an importer that writes through repo.CreateBatch. repo
+// may be nil when the importer is only used for dry runs.
+func NewImporter(repo repository.CostDataPointRepository) *Importer {
+	return &Importer{
+		repo:      repo,
+		batchSize: DefaultBatchSize,
+	}
+}
+
+// SetBatchSize overrides the number of rows per batch
+func (i *Importer) SetBatchSize(size int) {
+	if size > 0 {
+		i.batchSize = size
+	}
+}
+
+// pendingRow is a parsed row waiting for validation and insertion
+type pendingRow struct {
+	result *RowResult
+	cdp    *models.CostDataPoint
+}
+
+// Import reads every row from r, validates it and, unless DryRun is set,
+// writes the accepted rows in batches. Row problems are reported in the
+// returned report; the error is reserved for unreadable input and failures
+// of a whole batch. Batches are committed as they fill, so when a later
+// batch fails the partial report is returned alongside the error: rows
+// from committed batches are accepted and the rest are rejected.
+func (i *Importer) Import(ctx context.Context, r io.Reader, opts Options) (*Report, error) {
+	start := time.Now()
+
+	if err := opts.Mapping.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+	if !opts.DryRun && i.repo == nil {
+		return nil, fmt.Errorf("importer has no repository")
+	}
+
+	rows, err := newRowReader(opts.Format, r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidInput, err)
+	}
+
+	validator := newValidator(opts)
+	var results []*RowResult
+	var batch []pendingRow
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := i.processBatch(ctx, validator, batch, opts.DryRun)
+		batch = batch[:0]
+		return err
+	}
+
+	for {
+		rec, line, err := rows.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		result := &RowResult{Line: line, Status: RowRejected}
+		results = append(results, result)
+
+		if err != nil {
+			var rowErr errRow
+			if !errors.As(err, &rowErr) {
+				result.Errors = []string{"row not imported: input could not be read"}
+				markNotImported(batch)
+				return buildReport(results, opts.DryRun, start), fmt.Errorf("%w: %w", ErrInvalidInput, err)
+			}
+			result.Errors = []string{rowErr.Error()}
+			continue
+		}
+
+		cdp, parseErrs := opts.Mapping.toModel(rec)
+		if len(parseErrs) > 0 {
+			result.Errors = parseErrs
+			continue
+		}
+		applyDefaults(cdp)
+
+		batch = append(batch, pendingRow{result: result, cdp: cdp})
+		if len(batch) >= i.batchSize {
+			if err := flush(); err != nil {
+				return buildReport(results, opts.DryRun, start), err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return buildReport(results, opts.DryRun, start), err
+	}
+
+	report := buildReport(results, opts.DryRun, start)
+	logger.Info("Import finished",
+		"format", opts.Format,
+		"dry_run", opts.DryRun,
+		"total", report.Total,
+		"accepted", report.Accepted,
+		"rejected", report.Rejected)
+
+	return report, nil
+}
+
+// buildReport summarises the row results collected so far
+func buildReport(results []*RowResult, dryRun bool, start time.Time) *Report {
+	report := &Report{DryRun: dryRun, Rows: make([]RowResult, len(results))}
+	for n, result := range results {
+		report.Rows[n] = *result
+		report.Total++
+		if result.Status == RowAccepted {
+			report.Accepted++
+		} else {
+			report.Rejected++
+		}
+	}
+	report.Duration = time.Since(start).String()
+	return report
+}
+
+// processBatch validates a batch and writes the rows that pass. When the
+// batch as a whole fails, every row in it is marked as not imported.
+func (i *Importer) processBatch(ctx context.Context, validator validation.Validator, batch []pendingRow, dryRun bool) error {
+	points := make([]*models.CostDataPoint, len(batch))
+	for n, row := range batch {
+		points[n] = row.cdp
+	}
+
+	results, err := validator.ValidateBatch(ctx, points)
+	if err != nil {
+		markNotImported(batch)
+		return fmt.Errorf("failed to validate batch: %w", err)
+	}
+
+	var valid []pendingRow
+	for n, result := range results {
+		row := batch[n]
+		row.result.Warnings = append(row.result.Warnings, result.Warnings...)
+		for _, verr := range result.Errors {
+			if verr.Severity == validation.SeverityError {
+				row.result.Errors = append(row.result.Errors, fmt.Sprintf("%s: %s", verr.Field, verr.Message))
+			} else {
+				row.result.Warnings = append(row.result.Warnings, fmt.Sprintf("%s: %s", verr.Field, verr.Message))
+			}
+		}
+		if result.IsValid {
+			valid = append(valid, row)
+		}
+	}
+
+	if dryRun {
+		for _, row := range valid {
+			row.result.Status = RowAccepted
+		}
+		return nil
+	}
+	if len(valid) == 0 {
+		return nil
+	}
+
+	toWrite := make([]*models.CostDataPoint, len(valid))
+	for n, row := range valid {
+		toWrite[n] = row.cdp
+	}
+
+	written, err := i.repo.CreateBatch(ctx, toWrite)
+	if err != nil {
+		markNotImported(valid)
+		return fmt.Errorf("failed to write batch: %w", err)
+	}
+
+	failed := make(map[int]error, len(written.Failed))
+	for _, f := range written.Failed {
+		failed[f.Index] = f.Err
+	}
+	for n, row := range valid {
+		if err, ok := failed[n]; ok {
+			row.result.Errors = append(row.result.Errors, err.Error())
+			continue
+		}
+		row.result.Status = RowAccepted
+		row.result.ID = row.cdp.ID
+	}
+
+	return nil
+}
+
+// markNotImported records that rows were dropped with their failed batch
+func markNotImported(rows []pendingRow) {
+	for _, row := range rows {
+		row.result.Errors = append(row.result.Errors, "row not imported: batch failed")
+	}
+}
+
+// applyDefaults fills fields the validator requires but imports commonly
+// omit
+func applyDefaults(cdp *models.CostDataPoint) {
+	if cdp.RecordedAt.IsZero() {
+		cdp.RecordedAt = time.Now()
+	}
+	if cdp.ValidFrom.IsZero() {
+		cdp.ValidFrom = cdp.RecordedAt
+	}
+	if cdp.SampleSize == 0 {
+		cdp.SampleSize = 1
+	}
+	if cdp.Confidence == 0 {
+		cdp.Confidence = 1.0
+	}
+	cdp.NormalizeUnit()
+}
+
+// newValidator returns the default validator, relaxed for historical data
+// unless the caller enforces the age limit
+func newValidator(opts Options) validation.Validator {
+	if opts.EnforceMaxAge {
+		return validation.NewValidator()
+	}
+
+	config := validation.DefaultValidatorConfig()
+	config.EnableFreshnessCheck = false
+	v := validation.NewValidatorWithConfig(config)
+	v.RemoveRule(timestampRule.Name)
+	v.AddRule(timestampRule)
+	return v
+}
+
+// timestampRule replaces the default valid_timestamp rule for imports: old
+// data is expected, future timestamps are still rejected
+var timestampRule = validation.Rule{
+	Name:     "valid_timestamp",
+	Category: "all",
+	Field:    "RecordedAt",
+	Severity: validation.SeverityError,
+	Validator: func(dp *models.CostDataPoint) error {
+		if dp.RecordedAt.IsZero() {
+			return fmt.Errorf("recorded_at timestamp is required")
+		}
+		if dp.RecordedAt.After(time.Now()) {
+			return fmt.Errorf("recorded_at cannot be in the future")
+		}
+		return nil
+	},
+}
@@ -0,0 +1,213 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/export"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rentCSV = `Property,Annual Rent,Emirate,Community,Date,Beds
+"1BR Marina","85,000",Dubai,Marina,2019-03-01,1
+Studio JVC,not-a-price,Dubai,JVC,2019-03-01,0
+Villa,45,Dubai,Arabian Ranches,2019-03-01,4
+`
+
+var rentMapping = Mapping{
+	Columns: map[string]string{
+		"item_name":        "Property",
+		"price":            "Annual Rent",
+		"location_emirate": "Emirate",
+		"location_area":    "Community",
+		"recorded_at":      "Date",
+		"attr_bedrooms":    "Beds",
+	},
+	Defaults: map[string]string{
+		"category": "Housing",
+		"source":   "historical-rent",
+		"unit":     "AED/year",
+	},
+}
+
+func TestImportCSVWithMapping(t *testing.T) {
+	logger.Init()
+	repo := mock.NewCostDataPointRepository()
+	imp := NewImporter(repo)
+
+	report, err := imp.Import(context.Background(), strings.NewReader(rentCSV), Options{
+		Format:  export.FormatCSV,
+		Mapping: rentMapping,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 1, report.Accepted)
+	assert.Equal(t, 2, report.Rejected)
+
+	require.Len(t, report.Rows, 3)
+	assert.Equal(t, 2, report.Rows[0].Line)
+	assert.Equal(t, RowAccepted, report.Rows[0].Status)
+	assert.NotEmpty(t, report.Rows[0].ID)
+	assert.Equal(t, RowRejected, report.Rows[1].Status)
+	assert.Contains(t, report.Rows[1].Errors[0], "price")
+	assert.Equal(t, RowRejected, report.Rows[2].Status, "housing price range applies")
+
+	stored, err := repo.List(context.Background(), repository.ListFilter{})
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, 85000.0, stored[0].Price)
+	assert.Equal(t, "AED", stored[0].Currency, "legacy units are split on import")
+	assert.Equal(t, "", stored[0].Unit)
+	assert.Equal(t, "year", stored[0].Period)
+	assert.Equal(t, "Marina", stored[0].Location.Area)
+	assert.Equal(t, 1.0, stored[0].Attributes["bedrooms"])
+	assert.Equal(t, time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC), stored[0].RecordedAt)
+	assert.Equal(t, 1, repo.GetCallCount("CreateBatch"))
+}
+
+func TestImportDryRunWritesNothing(t *testing.T) {
+	logger.Init()
+	repo := mock.NewCostDataPointRepository()
+	imp := NewImporter(repo)
+
+	report, err := imp.Import(context.Background(), strings.NewReader(rentCSV), Options{
+		Format:  export.FormatCSV,
+		Mapping: rentMapping,
+		DryRun:  true,
+	})
+	require.NoError(t, err)
+
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.Accepted)
+	assert.Empty(t, report.Rows[0].ID)
+	assert.Equal(t, 0, repo.GetCallCount("CreateBatch"))
+}
+
+func TestImportEnforceMaxAge(t *testing.T) {
+	logger.Init()
+	imp := NewImporter(nil)
+
+	report, err := imp.Import(context.Background(), strings.NewReader(rentCSV), Options{
+		Format:        export.FormatCSV,
+		Mapping:       rentMapping,
+		DryRun:        true,
+		EnforceMaxAge: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Accepted)
+}
+
+func TestImportNDJSON(t *testing.T) {
+	logger.Init()
+	repo := mock.NewCostDataPointRepository()
+	imp := NewImporter(repo)
+	imp.SetBatchSize(1)
+
+	input := `{"category":"Utilities","item_name":"Electricity slab 1","price":0.23,"source":"dewa","unit":"AED/kWh","location":{"emirate":"Dubai"},"tags":["electricity"],"recorded_at":"2024-01-01T00:00:00Z"}
+
+{"category":"Transportation","item_name":"Metro","price":4,"source":"rta","location_emirate":"Dubai","attr_zones":1}
+{not json}
+`
+	report, err := imp.Import(context.Background(), strings.NewReader(input), Options{Format: export.FormatNDJSON})
+	require.NoError(t, err)
+
+	require.Len(t, report.Rows, 3)
+	assert.Equal(t, 3, report.Rows[1].Line, "blank lines still count")
+	assert.Equal(t, RowRejected, report.Rows[2].Status)
+	assert.Contains(t, report.Rows[2].Errors[0], "invalid json")
+
+	stored, err := repo.List(context.Background(), repository.ListFilter{Source: "rta"})
+	require.NoError(t, err)
+	if assert.Len(t, stored, 1) {
+		assert.Equal(t, 1.0, stored[0].Attributes["zones"])
+	}
+}
+
+func TestImportReportsBatchFailures(t *testing.T) {
+	logger.Init()
+	repo := mock.NewCostDataPointRepository()
+	imp := NewImporter(repo)
+
+	// The same id and timestamp twice collide on the primary key
+	input := `id,category,item_name,price,source,location_emirate,recorded_at
+dup,Transportation,Metro,4,rta,Dubai,2024-01-01T00:00:00Z
+dup,Transportation,Metro,4,rta,Dubai,2024-01-01T00:00:00Z
+`
+	report, err := imp.Import(context.Background(), strings.NewReader(input), Options{Format: export.FormatCSV})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Accepted)
+	assert.Equal(t, 1, report.Rejected)
+	assert.Equal(t, RowRejected, report.Rows[1].Status)
+}
+
+// failingRepo fails every CreateBatch after the first ok calls
+type failingRepo struct {
+	repository.CostDataPointRepository
+	ok    int
+	calls int
+}
+
+func (r *failingRepo) CreateBatch(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	r.calls++
+	if r.calls > r.ok {
+		return nil, repository.Unavailable(errors.New("connection refused"))
+	}
+	return r.CostDataPointRepository.CreateBatch(ctx, cdps)
+}
+
+func TestImportReturnsPartialReportWhenBatchFails(t *testing.T) {
+	logger.Init()
+	repo := &failingRepo{CostDataPointRepository: mock.NewCostDataPointRepository(), ok: 1}
+	imp := NewImporter(repo)
+	imp.SetBatchSize(1)
+
+	input := rentCSV + `"2BR Marina","120,000",Dubai,Marina,2019-03-01,2
+`
+	report, err := imp.Import(context.Background(), strings.NewReader(input), Options{Format: export.FormatCSV, Mapping: rentMapping})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, repository.ErrUnavailable)
+	assert.NotErrorIs(t, err, ErrInvalidInput)
+
+	require.NotNil(t, report)
+	assert.Equal(t, 1, report.Accepted, "the committed batch is reported")
+	assert.NotEmpty(t, report.Rows[0].ID)
+	last := report.Rows[len(report.Rows)-1]
+	assert.Equal(t, RowRejected, last.Status)
+	assert.Contains(t, last.Errors, "row not imported: batch failed")
+}
+
+func TestImportInvalidInput(t *testing.T) {
+	logger.Init()
+	imp := NewImporter(nil)
+
+	_, err := imp.Import(context.Background(), strings.NewReader(""), Options{Format: export.FormatCSV, DryRun: true})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	_, err = imp.Import(context.Background(), strings.NewReader(rentCSV), Options{
+		Format:  export.FormatCSV,
+		Mapping: Mapping{Columns: map[string]string{"cost": "Rent"}},
+		DryRun:  true,
+	})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
+
+func TestParseMapping(t *testing.T) {
+	m, err := ParseMapping([]byte(`{"columns":{"price":"Rent","attr_view":"View"},"defaults":{"source":"manual"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "Rent", m.Columns["price"])
+
+	_, err = ParseMapping([]byte(`{"columns":{"cost":"Rent"}}`))
+	assert.Error(t, err)
+
+	_, err = ParseMapping([]byte(`{"colums":{}}`))
+	assert.Error(t, err)
+}
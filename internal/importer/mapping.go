@@ -0,0 +1,321 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/export"
+	"github.com/adonese/cost-of-living/internal/models"
+)
+
+// Mapping describes how input columns become cost data point fields.
+//
+// Field names match the export columns (item_name, location_emirate,
+// attr_bedrooms, ...) so an export can be imported back unchanged. Columns
+// maps a field to the input column holding it; fields without an entry are
+// read from a column of the same name. Defaults supplies constant values for
+// fields missing from a row, e.g. {"source": "historical-rent-2019"}.
+type Mapping struct {
+	Columns  map[string]string `json:"columns,omitempty"`
+	Defaults map[string]string `json:"defaults,omitempty"`
+
+	// TimeLayouts are tried in order when parsing timestamps. RFC 3339 and
+	// plain dates (2006-01-02) are always accepted.
+	TimeLayouts []string `json:"time_layouts,omitempty"`
+
+	// TagSeparator splits tags given as a single string; defaults to ";"
+	TagSeparator string `json:"tag_separator,omitempty"`
+}
+
+// ParseMapping decodes a JSON mapping spec and checks its field names
+func ParseMapping(data []byte) (Mapping, error) {
+	var m Mapping
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return m, nil
+	}
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&m); err != nil {
+		return m, fmt.Errorf("invalid mapping: %w", err)
+	}
+	return m, m.Validate()
+}
+
+// Validate rejects mappings that target unknown fields
+func (m Mapping) Validate() error {
+	for _, fields := range []map[string]string{m.Columns, m.Defaults} {
+		for field := range fields {
+			if !IsField(field) {
+				return fmt.Errorf("unknown mapping field %q", field)
+			}
+		}
+	}
+	return nil
+}
+
+// Fields lists the fixed fields a mapping can target. Attributes are
+// targeted as attr_<name>.
+var Fields = func() []string {
+	var fields []string
+	for _, col := range export.Columns(nil) {
+		fields = append(fields, col.Name)
+	}
+	return fields
+}()
+
+// IsField reports whether name is a mappable field
+func IsField(name string) bool {
+	if strings.HasPrefix(name, export.AttributePrefix) {
+		return len(name) > len(export.AttributePrefix)
+	}
+	for _, field := range Fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// record is one input row keyed by column name. CSV values are strings;
+// NDJSON values keep their JSON types.
+type record map[string]interface{}
+
+// lookup returns the raw value for a field, applying the column mapping and
+// defaults. Empty values count as missing.
+func (m Mapping) lookup(rec record, field string) (interface{}, bool) {
+	column := field
+	if mapped, ok := m.Columns[field]; ok {
+		column = mapped
+	}
+	if v, ok := rec[column]; ok && !isEmpty(v) {
+		return v, true
+	}
+	if def, ok := m.Defaults[field]; ok && def != "" {
+		return def, true
+	}
+	return nil, false
+}
+
+// attributeFields returns the attr_ fields present in the row or mapping,
+// sorted for stable output
+func (m Mapping) attributeFields(rec record) []string {
+	seen := make(map[string]bool)
+	for field := range m.Columns {
+		if strings.HasPrefix(field, export.AttributePrefix) {
+			seen[field] = true
+		}
+	}
+	for field := range m.Defaults {
+		if strings.HasPrefix(field, export.AttributePrefix) {
+			seen[field] = true
+		}
+	}
+	for column := range rec {
+		if strings.HasPrefix(column, export.AttributePrefix) && IsField(column) {
+			if _, remapped := m.Columns[column]; !remapped {
+				seen[column] = true
+			}
+		}
+	}
+
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// toModel converts a row into a cost data point, collecting every field
+// that fails to parse
+func (m Mapping) toModel(rec record) (*models.CostDataPoint, []string) {
+	p := parser{mapping: m, rec: rec}
+	cdp := &models.CostDataPoint{
+		ID:          p.str("id"),
+		Category:    p.str("category"),
+		SubCategory: p.str("sub_category"),
+		ItemName:    p.str("item_name"),
+		Price:       p.float("price"),
+		MinPrice:    p.float("min_price"),
+		MaxPrice:    p.float("max_price"),
+		MedianPrice: p.float("median_price"),
+		SampleSize:  p.int("sample_size"),
+		Currency:    p.str("currency"),
+		Unit:        p.str("unit"),
+		Period:      p.str("period"),
+		Confidence:  float32(p.float("confidence")),
+		Source:      p.str("source"),
+		SourceURL:   p.str("source_url"),
+		Location: models.Location{
+			Emirate: p.str("location_emirate"),
+			City:    p.str("location_city"),
+			Area:    p.str("location_area"),
+		},
+		Tags:       p.strings("tags"),
+		RecordedAt: p.time("recorded_at"),
+		ValidFrom:  p.time("valid_from"),
+		RunID:      p.str("run_id"),
+	}
+
+	if validTo := p.time("valid_to"); !validTo.IsZero() {
+		cdp.ValidTo = &validTo
+	}
+	cdp.FirstSeenAt = p.time("first_seen_at")
+	cdp.LastSeenAt = p.time("last_seen_at")
+
+	_, hasLat := m.lookup(rec, "location_lat")
+	_, hasLon := m.lookup(rec, "location_lon")
+	if hasLat || hasLon {
+		cdp.Location.Coordinates = &models.GeoPoint{Lat: p.float("location_lat"), Lon: p.float("location_lon")}
+	}
+
+	for _, field := range m.attributeFields(rec) {
+		v, ok := m.lookup(rec, field)
+		if !ok {
+			continue
+		}
+		if cdp.Attributes == nil {
+			cdp.Attributes = make(map[string]interface{})
+		}
+		cdp.Attributes[strings.TrimPrefix(field, export.AttributePrefix)] = attributeValue(v)
+	}
+
+	return cdp, p.errs
+}
+
+// parser converts mapped values, recording a message per bad field
+type parser struct {
+	mapping Mapping
+	rec     record
+	errs    []string
+}
+
+func (p *parser) fail(field string, v interface{}, kind string) {
+	p.errs = append(p.errs, fmt.Sprintf("%s: cannot parse %v as %s", field, v, kind))
+}
+
+func (p *parser) str(field string) string {
+	v, ok := p.mapping.lookup(p.rec, field)
+	if !ok {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return fmt.Sprint(v)
+}
+
+func (p *parser) float(field string) float64 {
+	v, ok := p.mapping.lookup(p.rec, field)
+	if !ok {
+		return 0
+	}
+	switch value := v.(type) {
+	case float64:
+		return value
+	case string:
+		// Spreadsheets often carry thousands separators
+		f, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), 64)
+		if err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return f
+		}
+	}
+	p.fail(field, v, "number")
+	return 0
+}
+
+func (p *parser) int(field string) int {
+	f := p.float(field)
+	if f != math.Trunc(f) {
+		p.fail(field, f, "integer")
+		return 0
+	}
+	return int(f)
+}
+
+func (p *parser) time(field string) time.Time {
+	v, ok := p.mapping.lookup(p.rec, field)
+	if !ok {
+		return time.Time{}
+	}
+	s, isString := v.(string)
+	if isString {
+		s = strings.TrimSpace(s)
+		layouts := append(append([]string{}, p.mapping.TimeLayouts...), time.RFC3339Nano, "2006-01-02")
+		for _, layout := range layouts {
+			if t, err := time.Parse(layout, s); err == nil {
+				return t
+			}
+		}
+	}
+	p.fail(field, v, "timestamp")
+	return time.Time{}
+}
+
+func (p *parser) strings(field string) []string {
+	v, ok := p.mapping.lookup(p.rec, field)
+	if !ok {
+		return nil
+	}
+
+	var parts []string
+	switch value := v.(type) {
+	case []interface{}:
+		for _, item := range value {
+			parts = append(parts, fmt.Sprint(item))
+		}
+	case string:
+		sep := p.mapping.TagSeparator
+		if sep == "" {
+			sep = ";"
+		}
+		parts = strings.Split(value, sep)
+	default:
+		p.fail(field, v, "list")
+		return nil
+	}
+
+	tags := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			tags = append(tags, part)
+		}
+	}
+	return tags
+}
+
+// attributeValue keeps typed JSON values and infers numbers and booleans
+// from strings, matching how scrapers store attributes
+func attributeValue(v interface{}) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	s = strings.TrimSpace(s)
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	switch strings.ToLower(s) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+	return s
+}
+
+func isEmpty(v interface{}) bool {
+	switch value := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(value) == ""
+	default:
+		return false
+	}
+}
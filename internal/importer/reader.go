@@ -0,0 +1,154 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/adonese/cost-of-living/internal/export"
+)
+
+// maxLineSize bounds a single NDJSON line
+const maxLineSize = 1 << 20
+
+// rowReader yields input rows one at a time. line is the 1-based position
+// of the row in the input, counting the CSV header.
+type rowReader interface {
+	next() (rec record, line int, err error)
+}
+
+// errRow wraps a row that could not be decoded; reading can continue
+type errRow struct {
+	err error
+}
+
+func (e errRow) Error() string { return e.err.Error() }
+
+func newRowReader(format export.Format, r io.Reader) (rowReader, error) {
+	switch format {
+	case export.FormatCSV:
+		return newCSVRowReader(r)
+	case export.FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+		return &ndjsonRowReader{scanner: scanner}, nil
+	default:
+		return nil, fmt.Errorf("unsupported import format %q", format)
+	}
+}
+
+type csvRowReader struct {
+	r      *csv.Reader
+	header []string
+	line   int
+}
+
+func newCSVRowReader(r io.Reader) (*csvRowReader, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("csv input is empty")
+		}
+		return nil, fmt.Errorf("failed to read csv header: %w", err)
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff"))
+	}
+
+	return &csvRowReader{r: cr, header: header, line: 1}, nil
+}
+
+func (cr *csvRowReader) next() (record, int, error) {
+	for {
+		values, err := cr.r.Read()
+		cr.line++
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, cr.line, io.EOF
+			}
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, cr.line, errRow{err}
+			}
+			return nil, cr.line, err
+		}
+
+		// Skip blank lines left by spreadsheet exports
+		if len(values) == 1 && strings.TrimSpace(values[0]) == "" {
+			continue
+		}
+		if len(values) > len(cr.header) {
+			return nil, cr.line, errRow{fmt.Errorf("row has %d fields, header has %d", len(values), len(cr.header))}
+		}
+
+		rec := make(record, len(values))
+		for i, value := range values {
+			rec[cr.header[i]] = value
+		}
+		return rec, cr.line, nil
+	}
+}
+
+type ndjsonRowReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (nr *ndjsonRowReader) next() (record, int, error) {
+	for nr.scanner.Scan() {
+		nr.line++
+		text := strings.TrimSpace(nr.scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var rec record
+		if err := json.Unmarshal([]byte(text), &rec); err != nil {
+			return nil, nr.line, errRow{fmt.Errorf("invalid json: %w", err)}
+		}
+		return flatten(rec), nr.line, nil
+	}
+	if err := nr.scanner.Err(); err != nil {
+		return nil, nr.line, fmt.Errorf("failed to read ndjson: %w", err)
+	}
+	return nil, nr.line, io.EOF
+}
+
+// flatten accepts the API's nested shape as well as the flat export shape:
+// location.* becomes location_* and attributes.* becomes attr_*
+func flatten(rec record) record {
+	if location, ok := rec["location"].(map[string]interface{}); ok {
+		delete(rec, "location")
+		for key, value := range location {
+			if key == "coordinates" {
+				if coords, ok := value.(map[string]interface{}); ok {
+					setMissing(rec, "location_lat", coords["lat"])
+					setMissing(rec, "location_lon", coords["lon"])
+				}
+				continue
+			}
+			setMissing(rec, "location_"+key, value)
+		}
+	}
+	if attributes, ok := rec["attributes"].(map[string]interface{}); ok {
+		delete(rec, "attributes")
+		for key, value := range attributes {
+			setMissing(rec, export.AttributePrefix+key, value)
+		}
+	}
+	return rec
+}
+
+func setMissing(rec record, key string, value interface{}) {
+	if _, ok := rec[key]; !ok && value != nil {
+		rec[key] = value
+	}
+}
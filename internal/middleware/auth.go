@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/adonese/cost-of-living/internal/auth"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/pkg/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// APIKeyHeader is an alternative to "Authorization: Bearer <key>" for
+// clients that cannot set the Authorization header
+const APIKeyHeader = "X-API-Key"
+
+// Authenticator verifies an API key
+type Authenticator interface {
+	Authenticate(ctx context.Context, key string) (*models.APIKey, error)
+}
+
+// Authenticate returns a middleware that verifies the API key sent with a
+// request and stores it in the request context. Requests without a key
+// continue anonymously; routes that need a key are guarded by RequireRole.
+func Authenticate(authenticator Authenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			plaintext := requestKey(c.Request())
+			if plaintext == "" {
+				return next(c)
+			}
+
+			req := c.Request()
+			key, err := authenticator.Authenticate(req.Context(), plaintext)
+			if errors.Is(err, auth.ErrInvalidKey) {
+				return unauthorized(c, "Invalid API key")
+			}
+			if err != nil {
+				status, _, _ := StatusFor(err)
+				return echo.NewHTTPError(status, "Failed to verify API key").SetInternal(err)
+			}
+
+			c.SetRequest(req.WithContext(auth.WithKey(req.Context(), key)))
+
+			err = next(c)
+			if req.Method != http.MethodGet && req.Method != http.MethodHead {
+				logger.Info("API write",
+					"actor", key.Actor(),
+					"role", key.Role,
+					"method", req.Method,
+					"path", c.Path(),
+					"status", c.Response().Status,
+				)
+			}
+			return err
+		}
+	}
+}
+
+// RequireRole returns a middleware that rejects anonymous requests with 401
+// and requests whose key lacks role with 403
+func RequireRole(role models.Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := auth.KeyFromContext(c.Request().Context())
+			if key == nil {
+				return unauthorized(c, "API key required")
+			}
+			if !key.Role.Allows(role) {
+				return echo.NewHTTPError(http.StatusForbidden, "API key role "+string(key.Role)+" cannot perform this action; "+string(role)+" required")
+			}
+			return next(c)
+		}
+	}
+}
+
+// requestKey returns the key from the Authorization or X-API-Key header
+func requestKey(req *http.Request) string {
+	if header := req.Header.Get(echo.HeaderAuthorization); header != "" {
+		scheme, key, found := strings.Cut(header, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(key)
+		}
+	}
+	return strings.TrimSpace(req.Header.Get(APIKeyHeader))
+}
+
+func unauthorized(c echo.Context, message string) error {
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="api"`)
+	return echo.NewHTTPError(http.StatusUnauthorized, message)
+}
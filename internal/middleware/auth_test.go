@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adonese/cost-of-living/internal/auth"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/pkg/logger"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingAuthenticator struct{}
+
+func (failingAuthenticator) Authenticate(ctx context.Context, key string) (*models.APIKey, error) {
+	return nil, repository.Unavailable(errors.New("connection refused"))
+}
+
+func TestAuthenticateAndRequireRole(t *testing.T) {
+	logger.Init()
+
+	svc := auth.NewService(mock.NewAPIKeyRepository())
+	ctx := context.Background()
+	_, readerKey, err := svc.Issue(ctx, "reader", models.RoleReader, "")
+	require.NoError(t, err)
+	_, contributorKey, err := svc.Issue(ctx, "contributor", models.RoleContributor, "")
+	require.NoError(t, err)
+	revoked, revokedKey, err := svc.Issue(ctx, "revoked", models.RoleAdmin, "")
+	require.NoError(t, err)
+	_, err = svc.Revoke(ctx, revoked.ID)
+	require.NoError(t, err)
+
+	e := echo.New()
+	e.Use(ErrorHandler())
+	e.Use(Authenticate(svc))
+	e.GET("/open", func(c echo.Context) error {
+		if key := auth.KeyFromContext(c.Request().Context()); key != nil {
+			return c.String(http.StatusOK, key.Name)
+		}
+		return c.String(http.StatusOK, "anonymous")
+	})
+	e.POST("/write", func(c echo.Context) error {
+		return c.String(http.StatusOK, auth.KeyFromContext(c.Request().Context()).Actor())
+	}, RequireRole(models.RoleContributor))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header string
+		value  string
+		status int
+		body   string
+	}{
+		{name: "anonymous read", method: http.MethodGet, path: "/open", status: http.StatusOK, body: "anonymous"},
+		{name: "bearer read", method: http.MethodGet, path: "/open", header: echo.HeaderAuthorization, value: "Bearer " + readerKey, status: http.StatusOK, body: "reader"},
+		{name: "x-api-key read", method: http.MethodGet, path: "/open", header: APIKeyHeader, value: readerKey, status: http.StatusOK, body: "reader"},
+		{name: "invalid key rejected even on open routes", method: http.MethodGet, path: "/open", header: APIKeyHeader, value: "col_00000000_nope", status: http.StatusUnauthorized},
+		{name: "revoked key", method: http.MethodGet, path: "/open", header: APIKeyHeader, value: revokedKey, status: http.StatusUnauthorized},
+		{name: "anonymous write", method: http.MethodPost, path: "/write", status: http.StatusUnauthorized},
+		{name: "reader cannot write", method: http.MethodPost, path: "/write", header: APIKeyHeader, value: readerKey, status: http.StatusForbidden},
+		{name: "contributor writes", method: http.MethodPost, path: "/write", header: echo.HeaderAuthorization, value: "bearer " + contributorKey, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+			if tt.body != "" {
+				assert.Equal(t, tt.body, rec.Body.String())
+			}
+			if tt.status == http.StatusUnauthorized {
+				assert.NotEmpty(t, rec.Header().Get(echo.HeaderWWWAuthenticate))
+			}
+		})
+	}
+}
+
+func TestAuthenticateRepositoryFailure(t *testing.T) {
+	e := echo.New()
+	e.Use(ErrorHandler())
+	e.Use(Authenticate(failingAuthenticator{}))
+	e.GET("/open", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/open", nil)
+	req.Header.Set(APIKeyHeader, "col_00000000_secret")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -1,16 +1,92 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 
+	"github.com/adonese/cost-of-living/internal/repository"
 	"github.com/labstack/echo/v4"
 )
 
+// Machine-readable error codes for repository failures
+const (
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeValidationFailed = "validation_failed"
+	CodeUnavailable      = "unavailable"
+	CodeInternal         = "internal_error"
+)
+
 // ErrorResponse represents a standardized error response
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
 	Code    int    `json:"code"`
+	// ErrorCode is a stable, machine-readable identifier clients can branch on
+	ErrorCode string `json:"error_code"`
+}
+
+// repositoryErrors maps repository error kinds to HTTP statuses and codes
+var repositoryErrors = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{repository.ErrNotFound, http.StatusNotFound, CodeNotFound},
+	{repository.ErrConflict, http.StatusConflict, CodeConflict},
+	{repository.ErrValidationFailed, http.StatusBadRequest, CodeValidationFailed},
+	{repository.ErrUnavailable, http.StatusServiceUnavailable, CodeUnavailable},
+}
+
+// StatusFor returns the HTTP status and error code for a repository error.
+// ok is false when err is not of a known repository kind.
+func StatusFor(err error) (status int, code string, ok bool) {
+	for _, mapping := range repositoryErrors {
+		if errors.Is(err, mapping.kind) {
+			return mapping.status, mapping.code, true
+		}
+	}
+	return http.StatusInternalServerError, CodeInternal, false
+}
+
+// codeForStatus derives an error code from an HTTP status, e.g. 404 becomes
+// "not_found" and 413 "request_entity_too_large"
+func codeForStatus(status int) string {
+	if status == http.StatusInternalServerError {
+		return CodeInternal
+	}
+	text := http.StatusText(status)
+	if text == "" {
+		return CodeInternal
+	}
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
+
+// ResponseFor builds the error response for an HTTP error. Handlers that
+// need to add fields to an error body use it to keep the standard shape.
+func ResponseFor(he *echo.HTTPError) ErrorResponse {
+	// Convert message to string if needed
+	msg, ok := he.Message.(string)
+	if !ok {
+		msg = "An error occurred"
+	}
+
+	// Handlers keep the repository error as Internal so its kind still
+	// reaches the client
+	errorCode := codeForStatus(he.Code)
+	if he.Internal != nil {
+		if _, repoCode, ok := StatusFor(he.Internal); ok {
+			errorCode = repoCode
+		}
+	}
+
+	return ErrorResponse{
+		Error:     http.StatusText(he.Code),
+		Message:   msg,
+		Code:      he.Code,
+		ErrorCode: errorCode,
+	}
 }
 
 // ErrorHandler returns a middleware that handles errors consistently
@@ -21,29 +97,26 @@ func ErrorHandler() echo.MiddlewareFunc {
 			if err != nil {
 				// Check if it's already an echo.HTTPError
 				if he, ok := err.(*echo.HTTPError); ok {
-					code := he.Code
-					message := he.Message
-
-					// Convert message to string if needed
-					var msg string
-					if m, ok := message.(string); ok {
-						msg = m
-					} else {
-						msg = "An error occurred"
-					}
-
-					return c.JSON(code, ErrorResponse{
-						Error:   http.StatusText(code),
-						Message: msg,
-						Code:    code,
+					return c.JSON(he.Code, ResponseFor(he))
+				}
+
+				// Repository errors carry their own status and a message
+				// that leaves out driver detail
+				if status, errorCode, ok := StatusFor(err); ok {
+					return c.JSON(status, ErrorResponse{
+						Error:     http.StatusText(status),
+						Message:   repository.PublicMessage(err),
+						Code:      status,
+						ErrorCode: errorCode,
 					})
 				}
 
 				// For other errors, return 500
 				return c.JSON(http.StatusInternalServerError, ErrorResponse{
-					Error:   "Internal Server Error",
-					Message: err.Error(),
-					Code:    http.StatusInternalServerError,
+					Error:     "Internal Server Error",
+					Message:   err.Error(),
+					Code:      http.StatusInternalServerError,
+					ErrorCode: CodeInternal,
 				})
 			}
 			return nil
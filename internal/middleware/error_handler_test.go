@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorHandler(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		status    int
+		errorCode string
+		message   string
+	}{
+		{
+			name:      "repository not found",
+			err:       fmt.Errorf("failed to load: %w", repository.NotFound("cost data point")),
+			status:    http.StatusNotFound,
+			errorCode: CodeNotFound,
+			message:   "cost data point not found",
+		},
+		{
+			name:      "driver detail is not echoed",
+			err:       fmt.Errorf("failed to create: %w", repository.DriverError(repository.ErrConflict, errors.New(`pq: duplicate key value violates unique constraint "cost_data_points_pkey"`))),
+			status:    http.StatusConflict,
+			errorCode: CodeConflict,
+			message:   "conflicts with an existing record",
+		},
+		{
+			name:      "repository conflict",
+			err:       repository.Conflict(errors.New("duplicate key")),
+			status:    http.StatusConflict,
+			errorCode: CodeConflict,
+			message:   "duplicate key",
+		},
+		{
+			name:      "repository validation",
+			err:       repository.ValidationFailed(errors.New("unsupported order field")),
+			status:    http.StatusBadRequest,
+			errorCode: CodeValidationFailed,
+			message:   "unsupported order field",
+		},
+		{
+			name:      "repository unavailable",
+			err:       repository.Unavailable(errors.New("connection refused")),
+			status:    http.StatusServiceUnavailable,
+			errorCode: CodeUnavailable,
+			message:   "connection refused",
+		},
+		{
+			name:      "http error keeps its status",
+			err:       echo.NewHTTPError(http.StatusRequestEntityTooLarge, "Import body too large"),
+			status:    http.StatusRequestEntityTooLarge,
+			errorCode: "request_entity_too_large",
+			message:   "Import body too large",
+		},
+		{
+			name:      "http error with repository cause",
+			err:       echo.NewHTTPError(http.StatusNotFound, "Cost data point not found").SetInternal(repository.NotFound("cost data point")),
+			status:    http.StatusNotFound,
+			errorCode: CodeNotFound,
+			message:   "Cost data point not found",
+		},
+		{
+			name:      "unclassified error",
+			err:       errors.New("boom"),
+			status:    http.StatusInternalServerError,
+			errorCode: CodeInternal,
+			message:   "boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			handler := ErrorHandler()(func(c echo.Context) error { return tt.err })
+			require.NoError(t, handler(c))
+
+			assert.Equal(t, tt.status, rec.Code)
+
+			var response ErrorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, tt.status, response.Code)
+			assert.Equal(t, tt.errorCode, response.ErrorCode)
+			assert.Equal(t, tt.message, response.Message)
+		})
+	}
+}
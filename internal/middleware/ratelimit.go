@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/auth"
+	"github.com/adonese/cost-of-living/internal/ratelimit"
+	"github.com/adonese/cost-of-living/pkg/logger"
+	"github.com/adonese/cost-of-living/pkg/metrics"
+	"github.com/labstack/echo/v4"
+)
+
+// Rate limit response headers
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// RateLimit returns a middleware that limits requests per API key, or per
+// client IP for anonymous requests. It must run after Authenticate so the
+// key is known. Rejected requests get 429 with Retry-After; every limited
+// response carries the X-RateLimit-* headers. If the store fails the
+// request is let through rather than taking the API down with it.
+func RateLimit(store ratelimit.Store, cfg ratelimit.Config) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			client, id, limit := "ip", "ip:"+c.RealIP(), cfg.Anonymous
+			if key := auth.KeyFromContext(c.Request().Context()); key != nil {
+				client, id, limit = "api_key", "key:"+key.Prefix, cfg.APIKey
+			}
+			if !limit.Enabled() {
+				return next(c)
+			}
+
+			result, err := store.Take(c.Request().Context(), id, limit)
+			if err != nil {
+				logger.Warn("Rate limiter unavailable, allowing request", "error", err, "client", client)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+			header.Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+			header.Set(HeaderRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+
+			if !result.Allowed {
+				metrics.RateLimitRejectedTotal.WithLabelValues(c.Path(), client).Inc()
+
+				retryAfter := ceilSeconds(result.RetryAfter)
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(retryAfter))
+				return echo.NewHTTPError(http.StatusTooManyRequests,
+					"Rate limit of "+limit.String()+" exceeded; retry in "+strconv.Itoa(retryAfter)+"s")
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// ceilSeconds rounds d up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/auth"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/ratelimit"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/pkg/logger"
+	"github.com/adonese/cost-of-living/pkg/metrics"
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func TestRateLimit(t *testing.T) {
+	logger.Init()
+
+	svc := auth.NewService(mock.NewAPIKeyRepository())
+	_, plaintext, err := svc.Issue(context.Background(), "bot", models.RoleReader, "")
+	require.NoError(t, err)
+
+	cfg := ratelimit.Config{
+		Anonymous: ratelimit.Limit{Requests: 2, Period: time.Minute},
+		APIKey:    ratelimit.Limit{Requests: 3, Period: time.Minute},
+	}
+
+	e := echo.New()
+	e.IPExtractor = echo.ExtractIPDirect()
+	e.Use(ErrorHandler())
+	e.Use(Authenticate(svc))
+	e.Use(RateLimit(ratelimit.NewMemoryStore(time.Minute), cfg))
+	e.GET("/limited", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	get := func(remoteAddr, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+		req.RemoteAddr = remoteAddr
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rejected := testutil.ToFloat64(metrics.RateLimitRejectedTotal.WithLabelValues("/limited", "ip"))
+
+	rec := get("192.0.2.1:1234", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get(HeaderRateLimitLimit))
+	assert.Equal(t, "1", rec.Header().Get(HeaderRateLimitRemaining))
+	assert.Equal(t, "30", rec.Header().Get(HeaderRateLimitReset))
+
+	assert.Equal(t, http.StatusOK, get("192.0.2.1:1234", "").Code)
+
+	rec = get("192.0.2.1:5678", "")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code, "the limit is per IP, not per connection")
+	assert.Equal(t, "30", rec.Header().Get(echo.HeaderRetryAfter))
+	assert.Equal(t, "0", rec.Header().Get(HeaderRateLimitRemaining))
+	assert.Contains(t, rec.Body.String(), "too_many_requests")
+	assert.Equal(t, rejected+1, testutil.ToFloat64(metrics.RateLimitRejectedTotal.WithLabelValues("/limited", "ip")))
+
+	assert.Equal(t, http.StatusOK, get("192.0.2.2:1234", "").Code, "other IPs have their own bucket")
+
+	// A key has its own, larger bucket wherever it is used from
+	for i := 0; i < 3; i++ {
+		rec := get("192.0.2.1:1234", plaintext)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get(HeaderRateLimitLimit))
+	}
+	assert.Equal(t, http.StatusTooManyRequests, get("192.0.2.3:1234", plaintext).Code)
+}
+
+func TestRateLimitDisabledAndStoreFailure(t *testing.T) {
+	logger.Init()
+
+	e := echo.New()
+	e.Use(RateLimit(failingStore{}, ratelimit.Config{Anonymous: ratelimit.Limit{Requests: 1, Period: time.Minute}}))
+	e.GET("/limited", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/limited", nil))
+	assert.Equal(t, http.StatusOK, rec.Code, "requests are allowed when the store fails")
+
+	e = echo.New()
+	e.Use(RateLimit(failingStore{}, ratelimit.Config{}))
+	e.GET("/limited", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/limited", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(HeaderRateLimitLimit), "disabled limits add no headers")
+}
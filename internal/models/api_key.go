@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Role is the set of API operations a key may perform. Roles are ordered:
+// each one includes the permissions of the roles before it.
+type Role string
+
+const (
+	// RoleReader may read cost data points and request estimates
+	RoleReader Role = "reader"
+	// RoleContributor may also create, update, import, delete and restore
+	// cost data points
+	RoleContributor Role = "contributor"
+	// RoleAdmin may also issue and revoke API keys
+	RoleAdmin Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleReader:      1,
+	RoleContributor: 2,
+	RoleAdmin:       3,
+}
+
+// Valid reports whether r is one of the roles above
+func (r Role) Valid() bool {
+	return roleRanks[r] > 0
+}
+
+// Allows reports whether r includes the permissions of required
+func (r Role) Allows(required Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[required]
+}
+
+// APIKey is a credential for the API. Only a hash of the secret is stored;
+// the key itself is shown once, when it is issued.
+type APIKey struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+
+	// Prefix is the public part of the key. It identifies the key in logs
+	// and the revision history and is used to look it up.
+	Prefix string `json:"prefix"`
+
+	// Hash is the hex SHA-256 digest of the full key
+	Hash string `json:"-"`
+
+	Role       Role       `json:"role"`
+	CreatedBy  string     `json:"created_by,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Revoked reports whether the key has been revoked
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// Actor identifies the key as the actor of a change, e.g. "apikey:3f9a1c2b"
+func (k *APIKey) Actor() string {
+	return "apikey:" + k.Prefix
+}
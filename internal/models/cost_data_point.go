@@ -1,8 +1,18 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
 
-// CostDataPoint represents a single cost data point with temporal characteristics
+	"github.com/adonese/cost-of-living/internal/units"
+)
+
+// CostDataPoint represents a single cost data point with temporal characteristics.
+// Price is an amount of Currency (an ISO 4217 code, empty when the price is
+// not money) per Unit, or for the whole item when Unit is empty; see package
+// units. Recurring charges such as rent carry the Period they cover.
 type CostDataPoint struct {
 	ID          string                 `json:"id"`
 	Category    string                 `json:"category"`
@@ -20,11 +30,18 @@ type CostDataPoint struct {
 	Source      string                 `json:"source"`
 	SourceURL   string                 `json:"source_url,omitempty"`
 	Confidence  float32                `json:"confidence"`
+	Currency    string                 `json:"currency"`
 	Unit        string                 `json:"unit"`
+	Period      string                 `json:"period,omitempty"`
 	Tags        []string               `json:"tags,omitempty"`
 	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+	RunID       string                 `json:"run_id,omitempty"`
+	FirstSeenAt time.Time              `json:"first_seen_at"`
+	LastSeenAt  time.Time              `json:"last_seen_at"`
 	CreatedAt   time.Time              `json:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at"`
+	// DeletedAt is set while the data point is soft deleted
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 // Location represents the geographic location of a cost data point
@@ -40,3 +57,118 @@ type GeoPoint struct {
 	Lat float64 `json:"lat"`
 	Lon float64 `json:"lon"`
 }
+
+// DefaultCurrency is the currency prices are collected in
+const DefaultCurrency = "AED"
+
+// EarthRadiusKm is the mean radius of the earth used for distances
+const EarthRadiusKm = 6371.0
+
+// Valid reports whether the point is a real latitude and longitude. The
+// zero point, which scrapers produce when a listing has no map pin, is not
+// considered valid.
+func (p GeoPoint) Valid() bool {
+	if p.Lat == 0 && p.Lon == 0 {
+		return false
+	}
+	return p.Lat >= -90 && p.Lat <= 90 && p.Lon >= -180 && p.Lon <= 180
+}
+
+// DistanceKm returns the great-circle distance to q using the haversine
+// formula
+func (p GeoPoint) DistanceKm(q GeoPoint) float64 {
+	lat1, lat2 := p.Lat*math.Pi/180, q.Lat*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (q.Lon - p.Lon) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+// NaturalKey returns the stable identity of the thing this data point
+// describes, independent of when it was scraped. Housing listings are keyed
+// on their listing ID (or URL); everything else, such as utility tariffs and
+// fares, is keyed on the item name and location. Re-scraping the same listing
+// or tariff yields the same key.
+func (c *CostDataPoint) NaturalKey() string {
+	if c.IsListing() {
+		return strings.Join([]string{c.Source, "listing", c.listingRef()}, "|")
+	}
+
+	return strings.ToLower(strings.Join([]string{
+		c.Source,
+		"item",
+		strings.TrimSpace(c.ItemName),
+		c.Location.Emirate,
+		c.Location.City,
+		c.Location.Area,
+	}, "|"))
+}
+
+// IsListing reports whether the data point describes an individual market
+// listing (as opposed to a published tariff or fare) whose lifecycle is tracked
+func (c *CostDataPoint) IsListing() bool {
+	return c.Category == "Housing" && c.listingRef() != ""
+}
+
+// DaysOnMarket returns the whole days between the listing first being seen and
+// either its delisting (valid_to) or now, whichever is earlier
+func (c *CostDataPoint) DaysOnMarket(now time.Time) int {
+	if c.FirstSeenAt.IsZero() {
+		return 0
+	}
+
+	end := now
+	if c.ValidTo != nil && c.ValidTo.Before(end) {
+		end = *c.ValidTo
+	}
+	if end.Before(c.FirstSeenAt) {
+		return 0
+	}
+
+	return int(end.Sub(c.FirstSeenAt).Hours() / 24)
+}
+
+// NormalizeUnit moves a legacy unit string such as "AED/year" or
+// "fils_per_kwh" into Currency, Unit and Period, rescaling the prices of
+// units quoted in fils or per 1000 gallons, and defaults Currency to
+// DefaultCurrency for prices that are amounts of money. It is idempotent.
+func (c *CostDataPoint) NormalizeUnit() {
+	if m, ok := units.ParseLegacy(c.Unit); ok {
+		if c.Currency == "" {
+			c.Currency = m.Currency
+		}
+		c.Unit = m.Unit
+		if c.Period == "" {
+			c.Period = m.Period
+		}
+		c.Price *= m.Scale
+		c.MinPrice *= m.Scale
+		c.MaxPrice *= m.Scale
+		c.MedianPrice *= m.Scale
+	}
+
+	if c.Currency == "" && c.Unit != units.Fraction {
+		c.Currency = DefaultCurrency
+	}
+}
+
+// listingRef identifies a listing by its listing_id attribute, falling back to
+// the source URL without query string, fragment or trailing slash
+func (c *CostDataPoint) listingRef() string {
+	if id, ok := c.Attributes["listing_id"]; ok && id != nil {
+		if ref := fmt.Sprint(id); ref != "" {
+			return ref
+		}
+	}
+
+	ref := c.SourceURL
+	if i := strings.IndexByte(ref, '#'); i >= 0 {
+		ref = ref[:i]
+	}
+	if i := strings.IndexByte(ref, '?'); i >= 0 {
+		ref = ref[:i]
+	}
+	return strings.TrimRight(ref, "/")
+}
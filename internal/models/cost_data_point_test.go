@@ -0,0 +1,113 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNaturalKeyListing(t *testing.T) {
+	a := &CostDataPoint{
+		Category:  "Housing",
+		Source:    "bayut",
+		ItemName:  "2BR in Marina",
+		SourceURL: "https://www.bayut.com/property/details-123.html?utm=feed#photos",
+	}
+	b := &CostDataPoint{
+		Category:  "Housing",
+		Source:    "bayut",
+		ItemName:  "Spacious 2BR, Marina view",
+		SourceURL: "https://www.bayut.com/property/details-123.html/",
+	}
+
+	assert.Equal(t, "bayut|listing|https://www.bayut.com/property/details-123.html", a.NaturalKey())
+	assert.Equal(t, a.NaturalKey(), b.NaturalKey(), "title changes must not change the listing identity")
+
+	withID := &CostDataPoint{
+		Category:   "Housing",
+		Source:     "dubizzle",
+		SourceURL:  "https://dubai.dubizzle.com/property-for-rent/abc",
+		Attributes: map[string]interface{}{"listing_id": "98765"},
+	}
+	assert.Equal(t, "dubizzle|listing|98765", withID.NaturalKey())
+}
+
+func TestNaturalKeyTariff(t *testing.T) {
+	slab := func(area string) *CostDataPoint {
+		return &CostDataPoint{
+			Category:  "Utilities",
+			Source:    "dewa",
+			ItemName:  " Electricity Slab 1 ",
+			SourceURL: "https://www.dewa.gov.ae/tariff",
+			Location:  Location{Emirate: "Dubai", City: "Dubai", Area: area},
+		}
+	}
+
+	assert.Equal(t, "dewa|item|electricity slab 1|dubai|dubai|", slab("").NaturalKey())
+	assert.NotEqual(t, slab("").NaturalKey(), slab("Deira").NaturalKey())
+
+	// Housing rows without a URL or listing ID fall back to the item key
+	unlinked := &CostDataPoint{Category: "Housing", Source: "bayut", ItemName: "Studio"}
+	assert.Equal(t, "bayut|item|studio|||", unlinked.NaturalKey())
+}
+
+func TestDaysOnMarket(t *testing.T) {
+	now := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	active := &CostDataPoint{FirstSeenAt: now.AddDate(0, 0, -14)}
+	assert.Equal(t, 14, active.DaysOnMarket(now))
+
+	delistedAt := now.AddDate(0, 0, -4)
+	delisted := &CostDataPoint{FirstSeenAt: now.AddDate(0, 0, -14), ValidTo: &delistedAt}
+	assert.Equal(t, 10, delisted.DaysOnMarket(now))
+
+	assert.Equal(t, 0, (&CostDataPoint{}).DaysOnMarket(now))
+}
+
+func TestGeoPointDistanceKm(t *testing.T) {
+	marina := GeoPoint{Lat: 25.0805, Lon: 55.1403}
+	downtown := GeoPoint{Lat: 25.1972, Lon: 55.2744}
+
+	// Dubai Marina to Downtown Dubai is roughly 18 km as the crow flies
+	assert.InDelta(t, 18.8, marina.DistanceKm(downtown), 0.5)
+	assert.InDelta(t, marina.DistanceKm(downtown), downtown.DistanceKm(marina), 1e-9)
+	assert.Zero(t, marina.DistanceKm(marina))
+}
+
+func TestGeoPointValid(t *testing.T) {
+	assert.True(t, GeoPoint{Lat: 25.08, Lon: 55.14}.Valid())
+	assert.False(t, GeoPoint{}.Valid(), "the zero point is a missing map pin")
+	assert.False(t, GeoPoint{Lat: 91, Lon: 55}.Valid())
+	assert.False(t, GeoPoint{Lat: 25, Lon: 181}.Valid())
+}
+
+func TestNormalizeUnit(t *testing.T) {
+	water := &CostDataPoint{Price: 8.55, MinPrice: 2.09, Unit: "AED per 1000 IG"}
+	water.NormalizeUnit()
+	assert.Equal(t, "AED", water.Currency)
+	assert.Equal(t, "IG", water.Unit)
+	assert.InDelta(t, 0.00855, water.Price, 1e-12)
+	assert.InDelta(t, 0.00209, water.MinPrice, 1e-12)
+
+	// Normalising twice does not rescale again
+	water.NormalizeUnit()
+	assert.InDelta(t, 0.00855, water.Price, 1e-12)
+
+	rent := &CostDataPoint{Price: 85000, Unit: "AED/year"}
+	rent.NormalizeUnit()
+	assert.Equal(t, "AED", rent.Currency)
+	assert.Equal(t, "", rent.Unit)
+	assert.Equal(t, "year", rent.Period)
+	assert.Equal(t, 85000.0, rent.Price)
+
+	sewerage := &CostDataPoint{Price: 0.5, Unit: "percentage of water charge"}
+	sewerage.NormalizeUnit()
+	assert.Equal(t, "", sewerage.Currency)
+	assert.Equal(t, "fraction", sewerage.Unit)
+
+	usd := &CostDataPoint{Price: 10, Currency: "USD", Unit: "km"}
+	usd.NormalizeUnit()
+	assert.Equal(t, "USD", usd.Currency)
+	assert.Equal(t, "km", usd.Unit)
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// RollupInterval is the bucket width of a price rollup
+type RollupInterval string
+
+const (
+	RollupDaily  RollupInterval = "day"
+	RollupWeekly RollupInterval = "week"
+)
+
+// PriceRollup holds pre-aggregated price statistics for one time bucket of a
+// category, sub category, emirate and area. Prices are only aggregated
+// together when they share a currency, unit and period.
+type PriceRollup struct {
+	Interval    RollupInterval `json:"interval"`
+	Bucket      time.Time      `json:"bucket"`
+	Category    string         `json:"category"`
+	SubCategory string         `json:"sub_category,omitempty"`
+	Emirate     string         `json:"emirate,omitempty"`
+	Area        string         `json:"area,omitempty"`
+	Currency    string         `json:"currency"`
+	Unit        string         `json:"unit,omitempty"`
+	Period      string         `json:"period,omitempty"`
+	Count       int64          `json:"count"`
+	AvgPrice    float64        `json:"avg_price"`
+	MinPrice    float64        `json:"min_price"`
+	MaxPrice    float64        `json:"max_price"`
+	P25Price    float64        `json:"p25_price"`
+	MedianPrice float64        `json:"median_price"`
+	P75Price    float64        `json:"p75_price"`
+	// StdDevPrice is the sample standard deviation; zero for single samples
+	StdDevPrice float64 `json:"stddev_price"`
+}
+
+// Volatility returns the coefficient of variation of the bucket
+func (r *PriceRollup) Volatility() float64 {
+	if r.AvgPrice == 0 {
+		return 0
+	}
+	return r.StdDevPrice / r.AvgPrice
+}
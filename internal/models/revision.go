@@ -0,0 +1,105 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// RevisionAction is the kind of change a revision records
+type RevisionAction string
+
+const (
+	RevisionUpdate  RevisionAction = "update"
+	RevisionDelete  RevisionAction = "delete"
+	RevisionRestore RevisionAction = "restore"
+)
+
+// Revision records one change to a cost data point: the values before and
+// after, who made it and why. OldValues is nil only if the point did not
+// exist before; NewValues is nil for permanent deletes. A soft delete is a
+// delete revision whose NewValues carry the deleted_at time.
+type Revision struct {
+	ID            int64          `json:"id"`
+	DataPointID   string         `json:"data_point_id"`
+	RecordedAt    time.Time      `json:"recorded_at"`
+	Action        RevisionAction `json:"action"`
+	Actor         string         `json:"actor"`
+	Reason        string         `json:"reason,omitempty"`
+	ChangedFields []string       `json:"changed_fields,omitempty"`
+	OldValues     *CostDataPoint `json:"old_values,omitempty"`
+	NewValues     *CostDataPoint `json:"new_values,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// NewRevision builds a revision of the change from oldValues to newValues,
+// filling ChangedFields. Either side may be nil.
+func NewRevision(action RevisionAction, oldValues, newValues *CostDataPoint, actor, reason string) *Revision {
+	rev := &Revision{
+		Action:        action,
+		Actor:         actor,
+		Reason:        reason,
+		ChangedFields: ChangedFields(oldValues, newValues),
+		OldValues:     oldValues,
+		NewValues:     newValues,
+	}
+	ref := oldValues
+	if ref == nil {
+		ref = newValues
+	}
+	if ref != nil {
+		rev.DataPointID = ref.ID
+		rev.RecordedAt = ref.RecordedAt
+	}
+	return rev
+}
+
+// revisionIgnoredFields are maintained by the database and change on every
+// write, so they are not reported as changed
+var revisionIgnoredFields = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// ChangedFields returns the sorted JSON names of the fields that differ
+// between two versions of a cost data point. A nil side counts as having
+// no fields, so a delete reports every field that was set.
+func ChangedFields(oldValues, newValues *CostDataPoint) []string {
+	before := fieldValues(oldValues)
+	after := fieldValues(newValues)
+
+	var changed []string
+	for name, value := range before {
+		if revisionIgnoredFields[name] {
+			continue
+		}
+		if other, ok := after[name]; !ok || !bytes.Equal(value, other) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range after {
+		if revisionIgnoredFields[name] {
+			continue
+		}
+		if _, ok := before[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// fieldValues returns the JSON encoding of each field of cdp, keyed by name
+func fieldValues(cdp *CostDataPoint) map[string]json.RawMessage {
+	values := map[string]json.RawMessage{}
+	if cdp == nil {
+		return values
+	}
+	data, err := json.Marshal(cdp)
+	if err != nil {
+		return values
+	}
+	_ = json.Unmarshal(data, &values)
+	return values
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChangedFields(t *testing.T) {
+	now := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	old := &CostDataPoint{
+		ID:         "a",
+		Category:   "Utilities",
+		ItemName:   "Water",
+		Price:      10,
+		Location:   Location{Emirate: "Dubai"},
+		RecordedAt: now,
+		Source:     "dewa",
+		UpdatedAt:  now,
+	}
+
+	updated := *old
+	updated.Price = 12
+	updated.Location.Area = "Deira"
+	updated.UpdatedAt = now.Add(time.Hour)
+
+	got := ChangedFields(old, &updated)
+	if len(got) != 2 || got[0] != "location" || got[1] != "price" {
+		t.Errorf("ChangedFields() = %v, want [location price]", got)
+	}
+
+	if got := ChangedFields(old, old); len(got) != 0 {
+		t.Errorf("ChangedFields() of identical points = %v, want none", got)
+	}
+
+	deleted := ChangedFields(old, nil)
+	if len(deleted) == 0 {
+		t.Fatal("ChangedFields() of a delete reported no fields")
+	}
+	for _, name := range deleted {
+		if name == "updated_at" || name == "created_at" {
+			t.Errorf("ChangedFields() reported database managed field %q", name)
+		}
+	}
+}
+
+func TestNewRevision(t *testing.T) {
+	recordedAt := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	old := &CostDataPoint{ID: "a", RecordedAt: recordedAt, Price: 10}
+
+	rev := NewRevision(RevisionDelete, old, nil, "ops", "duplicate")
+	if rev.DataPointID != "a" || !rev.RecordedAt.Equal(recordedAt) {
+		t.Errorf("NewRevision() identified %s at %v", rev.DataPointID, rev.RecordedAt)
+	}
+	if rev.Actor != "ops" || rev.Reason != "duplicate" || rev.Action != RevisionDelete {
+		t.Errorf("NewRevision() = %+v", rev)
+	}
+}
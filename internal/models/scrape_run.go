@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// ScrapeRunStatus describes the lifecycle state of a scrape run
+type ScrapeRunStatus string
+
+const (
+	ScrapeRunRunning     ScrapeRunStatus = "running"
+	ScrapeRunSucceeded   ScrapeRunStatus = "succeeded"
+	ScrapeRunFailed      ScrapeRunStatus = "failed"
+	ScrapeRunCompensated ScrapeRunStatus = "compensated"
+)
+
+// CompensationAction describes how the data of a failed run was rolled back
+type CompensationAction string
+
+const (
+	// CompensationInvalidate closes valid_to on every row written by the run
+	CompensationInvalidate CompensationAction = "invalidate"
+	// CompensationDelete removes every row written by the run
+	CompensationDelete CompensationAction = "delete"
+)
+
+// ScrapeRun records a single execution of a scraper so that every persisted
+// data point can be traced back to the run that produced it
+type ScrapeRun struct {
+	ID            string              `json:"id"`
+	ScraperName   string              `json:"scraper_name"`
+	Status        ScrapeRunStatus     `json:"status"`
+	StartedAt     time.Time           `json:"started_at"`
+	FinishedAt    *time.Time          `json:"finished_at,omitempty"`
+	Fetched       int                 `json:"fetched"`
+	Validated     int                 `json:"validated"`
+	Saved         int                 `json:"saved"`
+	SaveFailures  int                 `json:"save_failures"`
+	Validation    ScrapeRunValidation `json:"validation"`
+	Errors        []string            `json:"errors,omitempty"`
+	WorkflowID    string              `json:"workflow_id,omitempty"`
+	WorkflowRunID string              `json:"workflow_run_id,omitempty"`
+
+	// Compensation outcome, set when a failed run is rolled back
+	CompensatedAt      *time.Time         `json:"compensated_at,omitempty"`
+	CompensationAction CompensationAction `json:"compensation_action,omitempty"`
+	CompensatedRows    int64              `json:"compensated_rows,omitempty"`
+	CompensationError  string             `json:"compensation_error,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ScrapeRunValidation is the validation summary persisted with a scrape run
+type ScrapeRunValidation struct {
+	Total      int  `json:"total"`
+	Valid      int  `json:"valid"`
+	Invalid    int  `json:"invalid"`
+	LowQuality int  `json:"low_quality"`
+	Skipped    bool `json:"skipped"`
+}
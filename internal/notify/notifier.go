@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+// Severity indicates how urgently a notification should be handled
+type Severity string
+
+const (
+	SeverityInfo     Severity = "info"
+	SeverityWarning  Severity = "warning"
+	SeverityCritical Severity = "critical"
+)
+
+// Notification is a single operator-facing message
+type Notification struct {
+	Title    string            `json:"title"`
+	Message  string            `json:"message"`
+	Severity Severity          `json:"severity"`
+	Fields   map[string]string `json:"fields,omitempty"`
+	SentAt   time.Time         `json:"sent_at"`
+}
+
+// Notifier delivers notifications to operators
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// LogNotifier writes notifications to the structured logger. It is the
+// default notifier when nothing else is configured.
+type LogNotifier struct{}
+
+// NewLogNotifier creates a notifier that only logs
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+// Notify implements Notifier
+func (LogNotifier) Notify(ctx context.Context, n Notification) error {
+	args := []any{"title", n.Title, "severity", n.Severity}
+	for k, v := range n.Fields {
+		args = append(args, k, v)
+	}
+
+	switch n.Severity {
+	case SeverityCritical:
+		logger.Error(n.Message, args...)
+	case SeverityWarning:
+		logger.Warn(n.Message, args...)
+	default:
+		logger.Info(n.Message, args...)
+	}
+	return nil
+}
+
+// WebhookNotifier posts notifications as JSON to an HTTP endpoint
+// (Slack-compatible incoming webhooks, Alertmanager receivers, etc.)
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier that posts to the given URL. When
+// client is nil a client with a 10 second timeout is used.
+func NewWebhookNotifier(url string, client *http.Client) *WebhookNotifier {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &WebhookNotifier{url: url, client: client}
+}
+
+// webhookPayload adds a plain text field understood by most chat webhooks
+type webhookPayload struct {
+	Text string `json:"text"`
+	Notification
+}
+
+// Notify implements Notifier
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	if n.SentAt.IsZero() {
+		n.SentAt = time.Now()
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		Text:         fmt.Sprintf("[%s] %s: %s", n.Severity, n.Title, n.Message),
+		Notification: n,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// MultiNotifier fans a notification out to several notifiers
+type MultiNotifier []Notifier
+
+// Notify implements Notifier. Every notifier is attempted; errors are joined.
+func (m MultiNotifier) Notify(ctx context.Context, n Notification) error {
+	var errs []error
+	for _, notifier := range m {
+		if notifier == nil {
+			continue
+		}
+		if err := notifier.Notify(ctx, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,67 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var received webhookPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier(server.URL, server.Client())
+	err := notifier.Notify(context.Background(), Notification{
+		Title:    "Scrape compensation: bayut",
+		Message:  "Rolled back 3 rows",
+		Severity: SeverityWarning,
+		Fields:   map[string]string{"scraper": "bayut"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "[warning] Scrape compensation: bayut: Rolled back 3 rows", received.Text)
+	assert.Equal(t, "bayut", received.Fields["scraper"])
+	assert.False(t, received.SentAt.IsZero())
+}
+
+func TestWebhookNotifierErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := NewWebhookNotifier(server.URL, server.Client()).Notify(context.Background(), Notification{Title: "t"})
+	assert.Error(t, err)
+}
+
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(ctx context.Context, n Notification) error {
+	return errors.New("unreachable")
+}
+
+type countingNotifier struct{ calls int }
+
+func (c *countingNotifier) Notify(ctx context.Context, n Notification) error {
+	c.calls++
+	return nil
+}
+
+func TestMultiNotifierAttemptsAll(t *testing.T) {
+	counter := &countingNotifier{}
+	err := MultiNotifier{failingNotifier{}, counter}.Notify(context.Background(), Notification{})
+	assert.Error(t, err)
+	assert.Equal(t, 1, counter.calls)
+}
@@ -0,0 +1,121 @@
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:embed docs.html
+var docsHTML string
+
+var docsTemplate = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"upper":      strings.ToUpper,
+	"schemaName": schemaName,
+}).Parse(docsHTML))
+
+// methodOrder lists operations on a path in the order the docs show them
+var methodOrder = []string{"get", "post", "put", "patch", "delete"}
+
+// docsOperation is an operation with the method and path it is served on
+type docsOperation struct {
+	Method string
+	Path   string
+	*Operation
+}
+
+// docsSchema is a named component schema
+type docsSchema struct {
+	Name string
+	*Schema
+}
+
+// SpecHandler serves doc as JSON
+func SpecHandler(doc *Document) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, doc)
+	}
+}
+
+// DocsHandler serves an HTML page describing doc, linking to the JSON at
+// specURL. The page is rendered once and needs no scripts or external
+// assets.
+func DocsHandler(doc *Document, specURL string) echo.HandlerFunc {
+	page, err := renderDocs(doc, specURL)
+
+	return func(c echo.Context) error {
+		if err != nil {
+			return err
+		}
+		return c.HTMLBlob(http.StatusOK, page)
+	}
+}
+
+func renderDocs(doc *Document, specURL string) ([]byte, error) {
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var operations []docsOperation
+	for _, path := range paths {
+		item := *doc.Paths[path]
+		for _, method := range methodOrder {
+			if op := item[method]; op != nil {
+				operations = append(operations, docsOperation{Method: method, Path: path, Operation: op})
+			}
+		}
+	}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	schemas := make([]docsSchema, len(names))
+	for i, name := range names {
+		schemas[i] = docsSchema{Name: name, Schema: doc.Components.Schemas[name]}
+	}
+
+	var buf bytes.Buffer
+	err := docsTemplate.Execute(&buf, map[string]any{
+		"Info":       doc.Info,
+		"Tags":       doc.Tags,
+		"Operations": operations,
+		"Schemas":    schemas,
+		"SpecURL":    specURL,
+	})
+	return buf.Bytes(), err
+}
+
+// schemaName describes s in a line, such as CostDataPointResponse,
+// string (date-time) or array of string
+func schemaName(s *Schema) string {
+	switch {
+	case s == nil:
+		return ""
+	case s.Ref != "":
+		return strings.TrimPrefix(s.Ref, refPrefix)
+	case s.Type == "array":
+		return "array of " + schemaName(s.Items)
+	case s.Type == "object" && s.AdditionalProperties != nil:
+		return "map of " + schemaName(s.AdditionalProperties)
+	case s.Type == "":
+		return "any"
+	}
+
+	name := s.Type
+	if s.Format != "" {
+		name += " (" + s.Format + ")"
+	}
+	if len(s.Enum) > 0 {
+		name += ": " + strings.Join(s.Enum, " | ")
+	}
+	return name
+}
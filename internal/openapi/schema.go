@@ -0,0 +1,201 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// generator builds schemas from Go types. Structs become component schemas
+// named after the type and are referred to with $ref, which also keeps
+// recursive types finite.
+type generator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+
+	// enums lists the values of named string types, which reflection
+	// cannot discover
+	enums map[reflect.Type][]string
+
+	// descriptions documents struct fields by type and JSON name, for
+	// fields whose meaning the type alone does not carry
+	descriptions map[reflect.Type]map[string]string
+}
+
+func newGenerator() *generator {
+	return &generator{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+		enums:   make(map[reflect.Type][]string),
+
+		descriptions: make(map[reflect.Type]map[string]string),
+	}
+}
+
+// enum records the values of a named string type
+func (g *generator) enum(value any, values ...string) {
+	g.enums[reflect.TypeOf(value)] = values
+}
+
+// describe records the description of the field of a struct type with the
+// given JSON name
+func (g *generator) describe(value any, field, description string) {
+	t := reflect.TypeOf(value)
+	if g.descriptions[t] == nil {
+		g.descriptions[t] = make(map[string]string)
+	}
+	g.descriptions[t][field] = description
+}
+
+// ref returns a schema for the type of value
+func (g *generator) ref(value any) *Schema {
+	return g.schemaFor(reflect.TypeOf(value))
+}
+
+func (g *generator) schemaFor(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawJSONType:
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := g.schemaFor(t.Elem())
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	case reflect.String:
+		return &Schema{Type: "string", Enum: g.enums[t]}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		return &Schema{Ref: refPrefix + g.component(t)}
+	default:
+		// interface{} and anything else accept any value
+		return &Schema{}
+	}
+}
+
+// component registers the schema of a struct type and returns its name
+func (g *generator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := g.schemas[name]; taken || name == "" {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + name
+	}
+
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.names[t] = name
+	g.schemas[name] = schema
+	g.addFields(schema, t)
+
+	return name
+}
+
+// addFields adds the JSON fields of struct type t to schema, flattening
+// embedded structs the way encoding/json does
+func (g *generator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(schema, embedded)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := g.schemaFor(field.Type)
+		if property.Ref == "" {
+			property.Description = g.descriptions[t][name]
+		}
+		if applyValidateTag(property, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// applyValidateTag copies the go-playground/validator rules that have an
+// OpenAPI equivalent onto s and reports whether the field is required
+func applyValidateTag(s *Schema, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		number, numErr := strconv.ParseFloat(param, 64)
+		length, lenErr := strconv.Atoi(param)
+
+		switch {
+		case name == "required":
+			required = true
+			if s.Type == "string" && s.MinLength == nil {
+				one := 1
+				s.MinLength = &one
+			}
+		case name == "oneof":
+			s.Enum = strings.Fields(param)
+		case s.Type == "string" && (name == "min" || name == "gte") && lenErr == nil:
+			s.MinLength = &length
+		case s.Type == "string" && (name == "max" || name == "lte") && lenErr == nil:
+			s.MaxLength = &length
+		case (s.Type == "number" || s.Type == "integer") && numErr == nil:
+			switch name {
+			case "gt":
+				s.Minimum, s.ExclusiveMinimum = &number, true
+			case "gte", "min":
+				s.Minimum = &number
+			case "lte", "max":
+				s.Maximum = &number
+			}
+		}
+	}
+
+	return required
+}
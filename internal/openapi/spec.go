@@ -0,0 +1,390 @@
+package openapi
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/adonese/cost-of-living/internal/handlers/dto"
+	"github.com/adonese/cost-of-living/internal/importer"
+	customMiddleware "github.com/adonese/cost-of-living/internal/middleware"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/services/estimator"
+)
+
+// BasePath prefixes every documented path
+const BasePath = "/api/v1"
+
+var (
+	specOnce sync.Once
+	spec     *Document
+)
+
+// Spec returns the OpenAPI document of the /api/v1 routes. It is built once;
+// callers must not modify it.
+func Spec() *Document {
+	specOnce.Do(func() { spec = build() })
+	return spec
+}
+
+// Security requirements: either header carries the same key
+var keyAuth = []map[string][]string{{"bearerAuth": {}}, {"apiKeyHeader": {}}}
+
+// build declares the operations. Keep it in step with registerAPIRoutes in
+// cmd/api; TestRoutesMatchSpec fails when they differ.
+func build() *Document {
+	g := newGenerator()
+	g.enum(models.Role(""), string(models.RoleReader), string(models.RoleContributor), string(models.RoleAdmin))
+	g.enum(models.RevisionAction(""), string(models.RevisionUpdate), string(models.RevisionDelete), string(models.RevisionRestore))
+	g.enum(importer.RowStatus(""), string(importer.RowAccepted), string(importer.RowRejected))
+	g.enum(estimator.HousingType(""), string(estimator.HousingApartment), string(estimator.HousingVilla), string(estimator.HousingShared))
+	g.enum(estimator.Lifestyle(""), string(estimator.LifestyleBudget), string(estimator.LifestyleModerate), string(estimator.LifestylePremium))
+	g.enum(estimator.TrendBucket(""), string(estimator.BucketDay), string(estimator.BucketWeek), string(estimator.BucketMonth))
+	g.enum(estimator.TrendAggregate(""), string(estimator.AggregateMedian), string(estimator.AggregateMean), string(estimator.AggregateP25), string(estimator.AggregateP75))
+	g.enum(estimator.TrendDirection(""), string(estimator.TrendUp), string(estimator.TrendDown), string(estimator.TrendStable))
+	g.enum(estimator.TransportMode(""), string(estimator.TransportPublic), string(estimator.TransportRideshare), string(estimator.TransportMixed))
+	g.describe(estimator.TrendResult{}, "unit", "Unit the prices are quoted per, empty for whole-item prices")
+	g.describe(estimator.TrendResult{}, "approximate",
+		"Set when some bucket combined several daily rollups, so p25, median and p75 values are sample-weighted means of daily percentiles rather than true percentiles")
+	g.describe(estimator.TrendPoint{}, "value", "AED per unit of the trend; approximate for percentiles when the trend is approximate")
+
+	errorResponse := g.ref(customMiddleware.ErrorResponse{})
+	errorsFor := func(statuses ...int) map[string]*Response {
+		responses := make(map[string]*Response, len(statuses))
+		for _, status := range statuses {
+			responses[statusKey(status)] = &Response{
+				Description: http.StatusText(status),
+				Content:     jsonContent(errorResponse),
+			}
+		}
+		responses[statusKey(http.StatusTooManyRequests)] = &Response{
+			Description: "Rate limit exceeded",
+			Headers: map[string]*Header{
+				"Retry-After": {Description: "Seconds until a request can succeed", Schema: integer()},
+			},
+			Content: jsonContent(errorResponse),
+		}
+		return responses
+	}
+	with := func(responses map[string]*Response, status int, response *Response) map[string]*Response {
+		responses[statusKey(status)] = response
+		return responses
+	}
+
+	id := &Parameter{Name: "id", In: "path", Required: true, Description: "Cost data point ID", Schema: str()}
+	recordedAt := func(required bool) *Parameter {
+		return &Parameter{
+			Name: "recorded_at", In: "query", Required: required, Schema: dateTime(),
+			Description: "Identifies the row together with id. Without it the latest row is used.",
+		}
+	}
+	reason := &Parameter{Name: "reason", In: "query", Schema: str(), Description: "Why the change was made, recorded in the revision history"}
+	currency := &Parameter{Name: "currency", In: "query", Schema: str(), Description: "ISO 4217 code to convert prices into from AED"}
+
+	dataPoint := g.ref(dto.CostDataPointResponse{})
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:   "UAE Cost of Living API",
+			Version: "1.0.0",
+			Description: "Prices collected across the UAE and the estimates built from them. " +
+				"Reads are public unless the server requires a reader key; writes need a contributor key " +
+				"and key management an admin key. Requests are rate limited per key or, without one, per IP.",
+		},
+		Tags: []Tag{
+			{Name: "cost-data-points", Description: "Collected prices"},
+			{Name: "estimates", Description: "Monthly cost estimates"},
+			{Name: "trends", Description: "Price movement over time"},
+			{Name: "areas", Description: "Communities compared by affordability"},
+			{Name: "admin", Description: "API key management"},
+		},
+		Paths: map[string]*PathItem{
+			BasePath + "/cost-data-points": {
+				"get": {
+					OperationID: "listCostDataPoints",
+					Summary:     "List cost data points",
+					Tags:        []string{"cost-data-points"},
+					Parameters: append(listFilterParameters(),
+						&Parameter{Name: "limit", In: "query", Schema: minInteger(1), Description: "Page size, default 10; values above 100 are capped"},
+						&Parameter{Name: "offset", In: "query", Schema: minInteger(0), Description: "Rows to skip; not with cursor"},
+						&Parameter{Name: "cursor", In: "query", Schema: str(), Description: "next_cursor of the previous page (recorded_at ordering only)"},
+						currency,
+					),
+					Responses: with(errorsFor(400, 500, 503), 200, jsonResponse("A page of cost data points", g.ref(dto.ListResponse{}))),
+				},
+				"post": {
+					OperationID: "createCostDataPoint",
+					Summary:     "Create a cost data point",
+					Description: "Requires a contributor key.",
+					Tags:        []string{"cost-data-points"},
+					RequestBody: jsonBody(g.ref(dto.CreateCostDataPointRequest{})),
+					Responses:   with(errorsFor(400, 401, 403, 409, 500, 503), 201, jsonResponse("The created cost data point", dataPoint)),
+					Security:    keyAuth,
+				},
+			},
+			BasePath + "/cost-data-points/export": {
+				"get": {
+					OperationID: "exportCostDataPoints",
+					Summary:     "Export every matching cost data point",
+					Description: "Streams rows as CSV, NDJSON or parquet-lite, gzipped when requested.",
+					Tags:        []string{"cost-data-points"},
+					Parameters: append(listFilterParameters(),
+						&Parameter{Name: "format", In: "query", Schema: enum("csv", "ndjson", "parquet-lite"), Description: "Output format, default csv"},
+						&Parameter{Name: "attributes", In: "query", Schema: str(), Description: "Comma separated attribute keys to add as attr_<name> columns"},
+						&Parameter{Name: "limit", In: "query", Schema: minInteger(1), Description: "Maximum rows, default all"},
+						&Parameter{Name: "gzip", In: "query", Schema: boolean(), Description: "Force gzip on or off; otherwise follows Accept-Encoding"},
+					),
+					Responses: with(errorsFor(400, 500, 503), 200, &Response{
+						Description: "The exported rows",
+						Content: map[string]*MediaType{
+							"text/csv":             {Schema: str()},
+							"application/x-ndjson": {Schema: str()},
+						},
+					}),
+				},
+			},
+			BasePath + "/cost-data-points/import": {
+				"post": {
+					OperationID: "importCostDataPoints",
+					Summary:     "Import cost data points from CSV or NDJSON",
+					Description: "Requires a contributor key. The file is the raw body or the file field of a multipart form. Batches are committed as they fill, so an error after some rows were written carries the partial per-row report in its report field.",
+					Tags:        []string{"cost-data-points"},
+					Parameters: []*Parameter{
+						{Name: "format", In: "query", Schema: enum("csv", "ndjson"), Description: "Input format; default from the file name or Content-Type, else csv"},
+						{Name: "mapping", In: "query", Schema: str(), Description: "JSON column mapping; also accepted as a multipart field"},
+						{Name: "dry_run", In: "query", Schema: boolean(), Description: "Validate without writing"},
+						{Name: "enforce_max_age", In: "query", Schema: boolean(), Description: "Reject rows recorded more than a year ago"},
+					},
+					RequestBody: &RequestBody{
+						Required: true,
+						Content: map[string]*MediaType{
+							"text/csv":             {Schema: str()},
+							"application/x-ndjson": {Schema: str()},
+							"multipart/form-data": {Schema: &Schema{
+								Type: "object",
+								Properties: map[string]*Schema{
+									"file":    {Type: "string", Format: "binary"},
+									"mapping": g.ref(importer.Mapping{}),
+								},
+								Required: []string{"file"},
+							}},
+						},
+					},
+					Responses: with(errorsFor(400, 401, 403, 413, 500, 503), 200, jsonResponse("Per-row import report", g.ref(importer.Report{}))),
+					Security:  keyAuth,
+				},
+			},
+			BasePath + "/cost-data-points/{id}": {
+				"get": {
+					OperationID: "getCostDataPoint",
+					Summary:     "Get a cost data point",
+					Tags:        []string{"cost-data-points"},
+					Parameters:  []*Parameter{id, recordedAt(false)},
+					Responses:   with(errorsFor(400, 404, 500, 503), 200, jsonResponse("The cost data point", dataPoint)),
+				},
+				"put": {
+					OperationID: "updateCostDataPoint",
+					Summary:     "Update a cost data point",
+					Description: "Requires a contributor key. Only non-empty fields are changed; the change is recorded in the revision history.",
+					Tags:        []string{"cost-data-points"},
+					Parameters:  []*Parameter{id, recordedAt(true), reason},
+					RequestBody: jsonBody(g.ref(dto.UpdateCostDataPointRequest{})),
+					Responses:   with(errorsFor(400, 401, 403, 404, 409, 500, 503), 200, jsonResponse("The updated cost data point", dataPoint)),
+					Security:    keyAuth,
+				},
+				"delete": {
+					OperationID: "deleteCostDataPoint",
+					Summary:     "Delete a cost data point",
+					Description: "Requires a contributor key. Soft deletes unless hard=true, which requires an admin key.",
+					Tags:        []string{"cost-data-points"},
+					Parameters: []*Parameter{id, recordedAt(true), reason,
+						{Name: "hard", In: "query", Schema: boolean(), Description: "Remove the row permanently; admin keys only"},
+					},
+					Responses: with(errorsFor(400, 401, 403, 404, 500, 503), 204, &Response{Description: "Deleted"}),
+					Security:  keyAuth,
+				},
+			},
+			BasePath + "/cost-data-points/{id}/restore": {
+				"post": {
+					OperationID: "restoreCostDataPoint",
+					Summary:     "Restore a soft-deleted cost data point",
+					Description: "Requires a contributor key.",
+					Tags:        []string{"cost-data-points"},
+					Parameters:  []*Parameter{id, recordedAt(true), reason},
+					Responses:   with(errorsFor(400, 401, 403, 404, 500, 503), 200, jsonResponse("The restored cost data point", dataPoint)),
+					Security:    keyAuth,
+				},
+			},
+			BasePath + "/cost-data-points/{id}/history": {
+				"get": {
+					OperationID: "getCostDataPointHistory",
+					Summary:     "List the revisions of a cost data point",
+					Tags:        []string{"cost-data-points"},
+					Parameters:  []*Parameter{id},
+					Responses:   with(errorsFor(400, 404, 500, 503), 200, jsonResponse("Revisions, newest first", g.ref(dto.HistoryResponse{}))),
+				},
+			},
+			BasePath + "/estimates": {
+				"post": {
+					OperationID: "createEstimate",
+					Summary:     "Estimate monthly costs for a household",
+					Tags:        []string{"estimates"},
+					Parameters:  []*Parameter{currency},
+					RequestBody: jsonBody(g.ref(dto.EstimateRequest{})),
+					Responses:   with(errorsFor(400), 200, jsonResponse("Monthly breakdown by category", g.ref(estimator.EstimateResult{}))),
+				},
+			},
+			BasePath + "/estimates/summary": {
+				"get": {
+					OperationID: "getEstimateSummary",
+					Summary:     "Summarise the data behind estimates for an emirate",
+					Tags:        []string{"estimates"},
+					Parameters: []*Parameter{
+						{Name: "emirate", In: "query", Required: true, Schema: nonEmptyString()},
+					},
+					Responses: with(errorsFor(400), 200, jsonResponse("Dataset coverage and freshness", g.ref(estimator.DatasetSnapshot{}))),
+				},
+			},
+			BasePath + "/trends": {
+				"get": {
+					OperationID: "getPriceTrend",
+					Summary:     "Bucketed price series for a category",
+					Description: "Each bucket is reduced to the chosen aggregate of AED prices, monthly for prices quoted per month or year. " +
+						"Only prices in one unit are included: kWh for electricity and utilities, IG for water, trip for transportation and whole items otherwise, unless unit says. " +
+						"Buckets without prices are left out. Without start_date the series covers 30 days, 26 weeks or 12 months. " +
+						"Percentiles of buckets built from several daily rollups are approximate, and the response is marked so.",
+					Tags: []string{"trends"},
+					Parameters: []*Parameter{
+						{Name: "category", In: "query", Required: true, Schema: nonEmptyString()},
+						{Name: "sub_category", In: "query", Schema: str()},
+						{Name: "emirate", In: "query", Schema: str()},
+						{Name: "area", In: "query", Schema: str()},
+						{Name: "bedrooms", In: "query", Schema: minInteger(0), Description: "Match the bedrooms attribute of listings"},
+						{Name: "unit", In: "query", Schema: str(), Description: "Unit prices are quoted per, such as kWh, IG, m3, km, minute or trip; defaults by category"},
+						{Name: "bucket", In: "query", Schema: g.ref(estimator.TrendBucket("")), Description: "Bucket width, default month"},
+						{Name: "aggregate", In: "query", Schema: g.ref(estimator.TrendAggregate("")), Description: "Statistic per bucket, default median"},
+						{Name: "start_date", In: "query", Schema: dateTime()},
+						{Name: "end_date", In: "query", Schema: dateTime(), Description: "Default now"},
+					},
+					Responses: with(errorsFor(400, 500, 503), 200, jsonResponse("The series with its percentage change and direction", g.ref(estimator.TrendResult{}))),
+				},
+			},
+			BasePath + "/areas/compare": {
+				"get": {
+					OperationID: "compareAreas",
+					Summary:     "Rank the communities of an emirate by affordability",
+					Description: "Each area's median monthly rent over the estimate lookback, plus the monthly commute to target when given. " +
+						"Areas within budget rank first, then by an affordability score weighed by how much data backs the area.",
+					Tags: []string{"areas"},
+					Parameters: []*Parameter{
+						{Name: "emirate", In: "query", Required: true, Schema: nonEmptyString()},
+						{Name: "bedrooms", In: "query", Schema: minInteger(0), Description: "Match the bedrooms attribute of listings"},
+						{Name: "housing_type", In: "query", Schema: g.ref(estimator.HousingType("")), Description: "Default apartment"},
+						{Name: "budget", In: "query", Schema: minNumber(0), Description: "Monthly AED for rent and commute"},
+						{Name: "target", In: "query", Schema: str(), Description: "Community commuted to, such as the place of work"},
+						{Name: "transport_mode", In: "query", Schema: g.ref(estimator.TransportMode("")), Description: "Default mixed"},
+						{Name: "work_days_per_week", In: "query", Schema: minInteger(0), Description: "Default 5"},
+					},
+					Responses: with(errorsFor(400, 500, 503), 200, jsonResponse("Areas in rank order", g.ref(estimator.AreaComparison{}))),
+				},
+			},
+			BasePath + "/admin/api-keys": {
+				"get": {
+					OperationID: "listAPIKeys",
+					Summary:     "List API keys",
+					Description: "Requires an admin key. Secrets are never returned.",
+					Tags:        []string{"admin"},
+					Responses:   with(errorsFor(401, 403, 500, 503), 200, jsonResponse("Every key, newest first", g.ref(dto.APIKeyListResponse{}))),
+					Security:    keyAuth,
+				},
+				"post": {
+					OperationID: "createAPIKey",
+					Summary:     "Issue an API key",
+					Description: "Requires an admin key. The plaintext key is returned once.",
+					Tags:        []string{"admin"},
+					RequestBody: jsonBody(g.ref(dto.CreateAPIKeyRequest{})),
+					Responses:   with(errorsFor(400, 401, 403, 409, 500, 503), 201, jsonResponse("The issued key and its plaintext", g.ref(dto.IssuedAPIKeyResponse{}))),
+					Security:    keyAuth,
+				},
+			},
+			BasePath + "/admin/api-keys/{id}": {
+				"delete": {
+					OperationID: "revokeAPIKey",
+					Summary:     "Revoke an API key",
+					Description: "Requires an admin key.",
+					Tags:        []string{"admin"},
+					Parameters:  []*Parameter{{Name: "id", In: "path", Required: true, Description: "API key ID", Schema: str()}},
+					Responses:   with(errorsFor(400, 401, 403, 404, 500, 503), 204, &Response{Description: "Revoked"}),
+					Security:    keyAuth,
+				},
+			},
+		},
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth":   {Type: "http", Scheme: "bearer", Description: "API key sent as Authorization: Bearer <key>"},
+				"apiKeyHeader": {Type: "apiKey", In: "header", Name: customMiddleware.APIKeyHeader, Description: "API key sent in the X-API-Key header"},
+			},
+		},
+	}
+
+	return doc
+}
+
+// listFilterParameters are the filters shared by list and export
+func listFilterParameters() []*Parameter {
+	return []*Parameter{
+		{Name: "category", In: "query", Schema: str()},
+		{Name: "sub_category", In: "query", Schema: str()},
+		{Name: "source", In: "query", Schema: str()},
+		{Name: "emirate", In: "query", Schema: str()},
+		{Name: "city", In: "query", Schema: str()},
+		{Name: "area", In: "query", Schema: str()},
+		{Name: "tags", In: "query", Schema: str(), Description: "Comma separated or repeated tags"},
+		{Name: "tag_match", In: "query", Schema: enum(string(repository.TagMatchAny), string(repository.TagMatchAll)), Description: "Require any (default) or all tags"},
+		{Name: "min_price", In: "query", Schema: number(), Description: "Minimum price in AED"},
+		{Name: "max_price", In: "query", Schema: number(), Description: "Maximum price in AED"},
+		{Name: "near", In: "query", Schema: str(), Description: "lat,lon centre of a radius search; requires radius_km"},
+		{Name: "radius_km", In: "query", Schema: number(), Description: "Radius around near in kilometres"},
+		{Name: "bbox", In: "query", Schema: str(), Description: "min_lat,min_lon,max_lat,max_lon"},
+		{Name: "start_date", In: "query", Schema: dateTime()},
+		{Name: "end_date", In: "query", Schema: dateTime()},
+		{Name: "order_by", In: "query", Schema: enum(repository.SortFields...)},
+		{Name: "order_dir", In: "query", Schema: enum("asc", "desc"), Description: "Default desc; order is accepted as an alias"},
+		{Name: "include_deleted", In: "query", Schema: boolean(), Description: "Also return soft-deleted rows"},
+	}
+}
+
+func statusKey(status int) string {
+	return strconv.Itoa(status)
+}
+
+func jsonContent(schema *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: schema}}
+}
+
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{Required: true, Content: jsonContent(schema)}
+}
+
+func jsonResponse(description string, schema *Schema) *Response {
+	return &Response{Description: description, Content: jsonContent(schema)}
+}
+
+func str() *Schema      { return &Schema{Type: "string"} }
+func number() *Schema   { return &Schema{Type: "number"} }
+func integer() *Schema  { return &Schema{Type: "integer"} }
+func boolean() *Schema  { return &Schema{Type: "boolean"} }
+func dateTime() *Schema { return &Schema{Type: "string", Format: "date-time"} }
+
+func enum(values ...string) *Schema { return &Schema{Type: "string", Enum: values} }
+
+func minInteger(min float64) *Schema { return &Schema{Type: "integer", Minimum: &min} }
+func minNumber(min float64) *Schema  { return &Schema{Type: "number", Minimum: &min} }
+
+func nonEmptyString() *Schema {
+	one := 1
+	return &Schema{Type: "string", MinLength: &one}
+}
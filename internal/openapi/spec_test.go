@@ -0,0 +1,103 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSpecReferencesResolve walks every schema in the document and fails on
+// a $ref to a component that does not exist
+func TestSpecReferencesResolve(t *testing.T) {
+	doc := Spec()
+
+	var walk func(s *Schema, where string)
+	walk = func(s *Schema, where string) {
+		if s == nil {
+			return
+		}
+		if s.Ref != "" {
+			assert.NotNil(t, doc.Resolve(s), "%s: unresolved %s", where, s.Ref)
+			return
+		}
+		walk(s.Items, where)
+		walk(s.AdditionalProperties, where)
+		for name, property := range s.Properties {
+			walk(property, where+"."+name)
+		}
+	}
+
+	for name, schema := range doc.Components.Schemas {
+		walk(schema, name)
+	}
+	for path, item := range doc.Paths {
+		for method, op := range *item {
+			where := strings.ToUpper(method) + " " + path
+			assert.NotEmpty(t, op.OperationID, where)
+			for _, param := range op.Parameters {
+				walk(param.Schema, where+" "+param.Name)
+			}
+			if op.RequestBody != nil {
+				for _, media := range op.RequestBody.Content {
+					walk(media.Schema, where+" body")
+				}
+			}
+			for status, response := range op.Responses {
+				for _, media := range response.Content {
+					walk(media.Schema, where+" "+status)
+				}
+			}
+		}
+	}
+
+	_, err := json.Marshal(doc)
+	require.NoError(t, err)
+}
+
+func TestSchemasFollowDTOs(t *testing.T) {
+	doc := Spec()
+
+	create := doc.Components.Schemas["CreateCostDataPointRequest"]
+	require.NotNil(t, create)
+	assert.ElementsMatch(t, []string{"category", "item_name", "price", "location", "source"}, create.Required)
+	price := create.Properties["price"]
+	require.NotNil(t, price.Minimum)
+	assert.Equal(t, 0.0, *price.Minimum)
+	assert.True(t, price.ExclusiveMinimum, "gt=0 is an exclusive minimum")
+	assert.Equal(t, refPrefix+"LocationDTO", create.Properties["location"].Ref)
+
+	estimate := doc.Components.Schemas["EstimateRequest"]
+	require.NotNil(t, estimate)
+	assert.Equal(t, []string{"apartment", "villa", "shared"}, estimate.Properties["housing_type"].Enum)
+	assert.NotContains(t, estimate.Required, "children", "min=0 alone does not make a field required")
+
+	// Embedded structs are flattened and json:"-" fields are left out
+	issued := doc.Components.Schemas["IssuedAPIKeyResponse"]
+	require.NotNil(t, issued)
+	for _, name := range []string{"id", "name", "role", "prefix", "revoked", "key"} {
+		assert.Contains(t, issued.Properties, name)
+	}
+	assert.NotContains(t, issued.Properties, "Hash")
+	assert.NotContains(t, issued.Properties, "key_hash")
+	assert.Equal(t, []string{"reader", "contributor", "admin"}, issued.Properties["role"].Enum)
+
+	assert.True(t, doc.Components.Schemas["Revision"].Properties["old_values"].Ref != "",
+		"pointers to structs are references")
+
+	trend := doc.Components.Schemas["TrendResult"]
+	require.NotNil(t, trend)
+	assert.Contains(t, trend.Properties["approximate"].Description, "rather than true percentiles")
+	assert.Empty(t, trend.Properties["direction"].Description)
+}
+
+func TestFromEchoPath(t *testing.T) {
+	assert.Equal(t, "/api/v1/cost-data-points/{id}/history", FromEchoPath("/api/v1/cost-data-points/:id/history"))
+	assert.Equal(t, "/api/v1/estimates", FromEchoPath("/api/v1/estimates"))
+
+	assert.NotNil(t, Spec().Operation("DELETE", "/api/v1/cost-data-points/:id"))
+	assert.Nil(t, Spec().Operation("PATCH", "/api/v1/cost-data-points/:id"))
+	assert.Nil(t, Spec().Operation("GET", "/api/v1/unknown"))
+}
@@ -0,0 +1,287 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ValidateRequests rejects requests whose query parameters or JSON body do
+// not match the operation doc declares for the matched route. Routes the
+// document does not describe, undeclared query parameters and non-JSON
+// bodies are passed through to the handler unchanged.
+func ValidateRequests(doc *Document) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			op := doc.Operation(c.Request().Method, c.Path())
+			if op == nil {
+				return next(c)
+			}
+
+			if err := validateQuery(op, c.QueryParams()); err != nil {
+				return err
+			}
+			if err := validateBody(doc, op, c.Request()); err != nil {
+				return err
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// validateQuery checks every declared query parameter. Enum values are
+// compared case-insensitively because handlers normalise case.
+func validateQuery(op *Operation, query map[string][]string) error {
+	for _, param := range op.Parameters {
+		if param.In != "query" {
+			continue
+		}
+
+		values := query[param.Name]
+		if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+			if param.Required {
+				return badRequest("%s query parameter is required", param.Name)
+			}
+			continue
+		}
+
+		for _, value := range values {
+			if err := validateQueryValue(param.Schema, value); err != nil {
+				return badRequest("Invalid %s parameter: %v", param.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateQueryValue(s *Schema, value string) error {
+	switch s.Type {
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		return checkRange(s, float64(n))
+	case "number":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		return checkRange(s, n)
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+	case "string":
+		if len(s.Enum) > 0 && !containsFold(s.Enum, value) {
+			return fmt.Errorf("must be one of %s", strings.Join(s.Enum, ", "))
+		}
+		return checkString(s, value)
+	}
+	return nil
+}
+
+// validateBody checks a JSON request body against the operation's schema
+// and restores it for the handler to bind
+func validateBody(doc *Document, op *Operation, req *http.Request) error {
+	if op.RequestBody == nil || op.RequestBody.Content["application/json"] == nil {
+		return nil
+	}
+	if !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		return nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return badRequest("Invalid request body")
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		if op.RequestBody.Required {
+			return badRequest("Request body is required")
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return badRequest("Invalid request body")
+	}
+
+	v := validator{doc: doc}
+	if err := v.value(op.RequestBody.Content["application/json"].Schema, value, ""); err != nil {
+		return badRequest("Validation error: %v", err)
+	}
+	return nil
+}
+
+type validator struct {
+	doc *Document
+}
+
+// value checks a decoded JSON value against s. path names the value in
+// error messages, e.g. location.emirate or tags[2].
+func (v validator) value(s *Schema, value any, path string) error {
+	s = v.doc.Resolve(s)
+	if s == nil || value == nil {
+		// Unknown schemas accept anything; null leaves the Go field zero
+		return nil
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return typeError(path, "an object")
+		}
+		for _, name := range s.Required {
+			if object[name] == nil {
+				return fmt.Errorf("%s is required", join(path, name))
+			}
+		}
+		for _, name := range sortedKeys(object) {
+			property := s.Properties[name]
+			if property == nil {
+				property = s.AdditionalProperties
+			}
+			if err := v.value(property, object[name], join(path, name)); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return typeError(path, "an array")
+		}
+		for i, item := range array {
+			if err := v.value(s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return typeError(path, "a string")
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			return fmt.Errorf("%s must be one of %s", path, strings.Join(s.Enum, ", "))
+		}
+		if err := checkString(s, str); err != nil {
+			return fmt.Errorf("%s %v", path, err)
+		}
+	case "number", "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return typeError(path, "a "+s.Type)
+		}
+		if s.Type == "integer" {
+			if _, err := number.Int64(); err != nil {
+				return typeError(path, "an integer")
+			}
+		}
+		n, err := number.Float64()
+		if err != nil {
+			return typeError(path, "a number")
+		}
+		if err := checkRange(s, n); err != nil {
+			return fmt.Errorf("%s %v", path, err)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return typeError(path, "a boolean")
+		}
+	}
+	return nil
+}
+
+func checkRange(s *Schema, n float64) error {
+	if s.Minimum != nil {
+		if s.ExclusiveMinimum && n <= *s.Minimum {
+			return fmt.Errorf("must be greater than %v", *s.Minimum)
+		}
+		if n < *s.Minimum {
+			return fmt.Errorf("must be at least %v", *s.Minimum)
+		}
+	}
+	if s.Maximum != nil && n > *s.Maximum {
+		return fmt.Errorf("must be at most %v", *s.Maximum)
+	}
+	return nil
+}
+
+func checkString(s *Schema, str string) error {
+	length := utf8.RuneCountInString(str)
+	if s.MinLength != nil && length < *s.MinLength {
+		if *s.MinLength == 1 {
+			return fmt.Errorf("must not be empty")
+		}
+		return fmt.Errorf("must be at least %d characters", *s.MinLength)
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		return fmt.Errorf("must be at most %d characters", *s.MaxLength)
+	}
+	if s.Format == "date-time" {
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			return fmt.Errorf("must be an RFC3339 date-time")
+		}
+	}
+	return nil
+}
+
+func badRequest(format string, args ...any) error {
+	return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf(format, args...))
+}
+
+func typeError(path, want string) error {
+	if path == "" {
+		return fmt.Errorf("body must be %s", want)
+	}
+	return fmt.Errorf("%s must be %s", path, want)
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func sortedKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
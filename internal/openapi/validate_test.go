@@ -0,0 +1,80 @@
+package openapi
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRequests(t *testing.T) {
+	var received string
+	echoBody := func(c echo.Context) error {
+		body, err := io.ReadAll(c.Request().Body)
+		require.NoError(t, err)
+		received = string(body)
+		return c.NoContent(http.StatusOK)
+	}
+
+	e := echo.New()
+	api := e.Group(BasePath, ValidateRequests(Spec()))
+	api.GET("/cost-data-points", echoBody)
+	api.POST("/cost-data-points", echoBody)
+	api.DELETE("/cost-data-points/:id", echoBody)
+	api.GET("/estimates/summary", echoBody)
+	api.GET("/undocumented", echoBody)
+
+	validPoint := `{"category": "Housing", "item_name": "1BR", "price": 5000, "source": "manual", "location": {"emirate": "Dubai"}}`
+
+	tests := []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		status  int
+		message string
+	}{
+		{name: "valid query", method: http.MethodGet, target: "/cost-data-points?limit=5&order_dir=DESC&include_deleted=true&min_price=10.5", status: http.StatusOK},
+		{name: "undeclared query parameters pass", method: http.MethodGet, target: "/cost-data-points?attr.bedrooms=2&order=asc", status: http.StatusOK},
+		{name: "bad integer", method: http.MethodGet, target: "/cost-data-points?offset=-1", status: http.StatusBadRequest, message: "Invalid offset parameter: must be at least 0"},
+		{name: "bad enum", method: http.MethodGet, target: "/cost-data-points?order_by=colour", status: http.StatusBadRequest, message: "Invalid order_by parameter"},
+		{name: "bad date", method: http.MethodGet, target: "/cost-data-points?start_date=yesterday", status: http.StatusBadRequest, message: "must be an RFC3339 date-time"},
+		{name: "bad boolean", method: http.MethodDelete, target: "/cost-data-points/cdp-1?recorded_at=2025-01-01T00:00:00Z&hard=maybe", status: http.StatusBadRequest, message: "Invalid hard parameter"},
+		{name: "missing required query", method: http.MethodDelete, target: "/cost-data-points/cdp-1", status: http.StatusBadRequest, message: "recorded_at query parameter is required"},
+		{name: "missing emirate", method: http.MethodGet, target: "/estimates/summary", status: http.StatusBadRequest, message: "emirate query parameter is required"},
+		{name: "valid body", method: http.MethodPost, target: "/cost-data-points", body: validPoint, status: http.StatusOK},
+		{name: "malformed body", method: http.MethodPost, target: "/cost-data-points", body: `{"category":`, status: http.StatusBadRequest, message: "Invalid request body"},
+		{name: "missing field", method: http.MethodPost, target: "/cost-data-points", body: `{"category": "Housing"}`, status: http.StatusBadRequest, message: "item_name is required"},
+		{name: "empty required string", method: http.MethodPost, target: "/cost-data-points", body: strings.Replace(validPoint, `"1BR"`, `""`, 1), status: http.StatusBadRequest, message: "item_name must not be empty"},
+		{name: "wrong type", method: http.MethodPost, target: "/cost-data-points", body: strings.Replace(validPoint, `5000`, `"5000"`, 1), status: http.StatusBadRequest, message: "price must be a number"},
+		{name: "exclusive minimum", method: http.MethodPost, target: "/cost-data-points", body: strings.Replace(validPoint, `5000`, `0`, 1), status: http.StatusBadRequest, message: "price must be greater than 0"},
+		{name: "nested", method: http.MethodPost, target: "/cost-data-points", body: strings.Replace(validPoint, `"Dubai"`, `""`, 1), status: http.StatusBadRequest, message: "location.emirate must not be empty"},
+		{name: "array items", method: http.MethodPost, target: "/cost-data-points", body: strings.Replace(validPoint, `}}`, `}, "tags": ["a", 2]}`, 1), status: http.StatusBadRequest, message: "tags[1] must be a string"},
+		{name: "undocumented route", method: http.MethodGet, target: "/undocumented?limit=ten", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			req := httptest.NewRequest(tt.method, BasePath+tt.target, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+			if tt.message != "" {
+				assert.Contains(t, rec.Body.String(), tt.message)
+			}
+			if tt.status == http.StatusOK {
+				assert.Equal(t, tt.body, received, "the handler sees the original body")
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps buckets in process memory. Each API instance limits
+// clients independently.
+type MemoryStore struct {
+	idle time.Duration
+	now  func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	takes   int
+}
+
+// NewMemoryStore creates a store that drops buckets unused for longer than
+// idle, by which time they are full again
+func NewMemoryStore(idle time.Duration) *MemoryStore {
+	return &MemoryStore{
+		idle:    idle,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Take implements Store
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	s.takes++
+	if s.takes%sweepEvery == 0 {
+		s.sweep(now)
+	}
+
+	b, ok := s.buckets[key]
+	if !ok {
+		fresh := newBucket(limit, now)
+		b = &fresh
+		s.buckets[key] = b
+	}
+
+	return b.take(limit, now), nil
+}
+
+// sweep drops idle buckets; the caller holds mu
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, b := range s.buckets {
+		if now.Sub(b.updated) > s.idle {
+			delete(s.buckets, key)
+		}
+	}
+}
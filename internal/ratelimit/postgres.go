@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so that every
+// API instance draws from the same bucket. Time is taken from the database
+// so instance clocks do not need to agree.
+type PostgresStore struct {
+	db    *sql.DB
+	idle  time.Duration
+	takes atomic.Int64
+}
+
+// NewPostgresStore creates a store on db that deletes buckets unused for
+// longer than idle, by which time they are full again
+func NewPostgresStore(db *sql.DB, idle time.Duration) *PostgresStore {
+	return &PostgresStore{db: db, idle: idle}
+}
+
+// Take implements Store
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if s.takes.Add(1)%sweepEvery == 0 {
+		if err := s.sweep(ctx); err != nil {
+			logger.Warn("Failed to delete idle rate limit buckets", "error", err)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Result{}, fmt.Errorf("begin rate limit transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The no-op update locks an existing row until commit, so concurrent
+	// requests for the same key take their tokens one after another
+	var b bucket
+	var now time.Time
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (key) DO UPDATE SET key = EXCLUDED.key
+		RETURNING tokens, updated_at, NOW()
+	`, key, float64(limit.Requests)).Scan(&b.tokens, &b.updated, &now)
+	if err != nil {
+		return Result{}, fmt.Errorf("load rate limit bucket: %w", err)
+	}
+
+	result := b.take(limit, now)
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE rate_limit_buckets SET tokens = $2, updated_at = $3 WHERE key = $1`,
+		key, b.tokens, b.updated)
+	if err != nil {
+		return Result{}, fmt.Errorf("save rate limit bucket: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Result{}, fmt.Errorf("commit rate limit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// sweep deletes idle buckets
+func (s *PostgresStore) sweep(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx,
+		`DELETE FROM rate_limit_buckets WHERE updated_at < NOW() - make_interval(secs => $1)`,
+		s.idle.Seconds())
+	return err
+}
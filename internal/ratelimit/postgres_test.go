@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adonese/cost-of-living/pkg/database"
+)
+
+// TestPostgresStoreTake requires a migrated PostgreSQL instance
+func TestPostgresStoreTake(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	cfg := database.NewConfigFromEnv()
+	if cfg.Driver != database.DriverPostgres {
+		t.Skip("Skipping test - requires PostgreSQL")
+	}
+	db, err := database.Connect(cfg)
+	if err != nil {
+		t.Skipf("Skipping test - database not available: %v", err)
+	}
+	defer db.Close()
+
+	const key = "test:postgres-store"
+	cleanup := func() { _, _ = db.GetConn().Exec("DELETE FROM rate_limit_buckets WHERE key = $1", key) }
+	cleanup()
+	defer cleanup()
+
+	store := NewPostgresStore(db.GetConn(), time.Hour)
+	limit := Limit{Requests: 2, Period: time.Hour}
+	ctx := context.Background()
+
+	for i := 1; i >= 0; i-- {
+		result, err := store.Take(ctx, key, limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, i, result.Remaining)
+	}
+
+	result, err := store.Take(ctx, key, limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.InDelta(t, 30*time.Minute, result.RetryAfter, float64(time.Second))
+}
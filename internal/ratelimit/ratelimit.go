@@ -0,0 +1,217 @@
+// Package ratelimit limits how often a client may call the API. Each client
+// has a token bucket holding up to Limit.Requests tokens that refills evenly
+// over Limit.Period; a request takes one token and is rejected when the
+// bucket is empty. Buckets live in a Store: in memory for a single instance,
+// or in PostgreSQL when several instances must share them.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults used by ConfigFromEnv
+var (
+	DefaultAnonymous = Limit{Requests: 60, Period: time.Minute}
+	DefaultAPIKey    = Limit{Requests: 600, Period: time.Minute}
+)
+
+// Store backends accepted by RATE_LIMIT_STORE
+const (
+	StoreMemory   = "memory"
+	StorePostgres = "postgres"
+)
+
+// sweepEvery is how many takes a store serves between removals of idle
+// buckets
+const sweepEvery = 1000
+
+// Limit allows Requests requests per Period, in bursts of up to Requests
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Enabled reports whether the limit restricts anything
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// String formats the limit as accepted by ParseLimit
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "0"
+	}
+	for unit, period := range periodUnits {
+		if l.Period == period {
+			return fmt.Sprintf("%d/%s", l.Requests, unit)
+		}
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// periodUnits are the single-letter periods accepted by ParseLimit
+var periodUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+}
+
+// rate returns the number of tokens added per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// ParseLimit parses a limit such as "60/m", "1000/h" or "10/30s". The unit
+// is s, m, h, d or a Go duration. "0" or "off" disables the limit.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "0" || strings.EqualFold(s, "off") {
+		return Limit{}, nil
+	}
+
+	count, unit, found := strings.Cut(s, "/")
+	if !found {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: want requests/period, e.g. 60/m", s)
+	}
+	requests, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil || requests < 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: requests must be a non-negative integer", s)
+	}
+
+	unit = strings.TrimSpace(unit)
+	period, ok := periodUnits[unit]
+	if !ok {
+		period, err = time.ParseDuration(unit)
+		if err != nil || period <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit %q: unknown period %q", s, unit)
+		}
+	}
+
+	return Limit{Requests: requests, Period: period}, nil
+}
+
+// Config holds the limits applied by the API
+type Config struct {
+	// Anonymous limits each client IP making requests without an API key
+	Anonymous Limit
+
+	// APIKey limits each API key, whichever IP it is used from
+	APIKey Limit
+
+	// Store is StoreMemory or StorePostgres
+	Store string
+
+	// TrustProxy takes the client IP from X-Forwarded-For. Only enable it
+	// behind a proxy that sets the header, or clients can pick their IP.
+	TrustProxy bool
+}
+
+// Enabled reports whether any limit applies
+func (c Config) Enabled() bool {
+	return c.Anonymous.Enabled() || c.APIKey.Enabled()
+}
+
+// IdleTTL is how long a bucket can go unused before it is full again and
+// can be dropped from a store
+func (c Config) IdleTTL() time.Duration {
+	return max(c.Anonymous.Period, c.APIKey.Period)
+}
+
+// ConfigFromEnv reads RATE_LIMIT_ANONYMOUS and RATE_LIMIT_API_KEY (see
+// ParseLimit), RATE_LIMIT_STORE and TRUST_PROXY_HEADERS
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{Anonymous: DefaultAnonymous, APIKey: DefaultAPIKey, Store: StoreMemory}
+
+	var err error
+	if s := os.Getenv("RATE_LIMIT_ANONYMOUS"); s != "" {
+		if cfg.Anonymous, err = ParseLimit(s); err != nil {
+			return cfg, fmt.Errorf("RATE_LIMIT_ANONYMOUS: %w", err)
+		}
+	}
+	if s := os.Getenv("RATE_LIMIT_API_KEY"); s != "" {
+		if cfg.APIKey, err = ParseLimit(s); err != nil {
+			return cfg, fmt.Errorf("RATE_LIMIT_API_KEY: %w", err)
+		}
+	}
+	if s := os.Getenv("RATE_LIMIT_STORE"); s != "" {
+		if s != StoreMemory && s != StorePostgres {
+			return cfg, fmt.Errorf("RATE_LIMIT_STORE: unknown store %q", s)
+		}
+		cfg.Store = s
+	}
+	if s := os.Getenv("TRUST_PROXY_HEADERS"); s != "" {
+		if cfg.TrustProxy, err = strconv.ParseBool(s); err != nil {
+			return cfg, fmt.Errorf("TRUST_PROXY_HEADERS: invalid boolean %q", s)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Result is the outcome of taking a token
+type Result struct {
+	Allowed bool
+
+	// Limit is the bucket size and Remaining the whole tokens left in it
+	Limit     int
+	Remaining int
+
+	// RetryAfter is how long until a rejected request could succeed
+	RetryAfter time.Duration
+
+	// Reset is how long until the bucket is full again
+	Reset time.Duration
+}
+
+// Store keeps token buckets by client key
+type Store interface {
+	// Take takes a token from the bucket for key, creating a full bucket
+	// for keys it has not seen
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucket is the state of one client's tokens
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newBucket returns a full bucket
+func newBucket(limit Limit, now time.Time) bucket {
+	return bucket{tokens: float64(limit.Requests), updated: now}
+}
+
+// take refills the bucket for the time since it was last updated and takes
+// a token if one is available
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	rate := limit.rate()
+	size := float64(limit.Requests)
+
+	if elapsed := now.Sub(b.updated).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(size, b.tokens+elapsed*rate)
+	}
+	b.updated = now
+
+	result := Result{Limit: limit.Requests}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((size - b.tokens) / rate)
+
+	return result
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Limit
+	}{
+		{"60/m", Limit{Requests: 60, Period: time.Minute}},
+		{"1000/h", Limit{Requests: 1000, Period: time.Hour}},
+		{" 5 / s ", Limit{Requests: 5, Period: time.Second}},
+		{"10/30s", Limit{Requests: 10, Period: 30 * time.Second}},
+		{"100000/d", Limit{Requests: 100000, Period: 24 * time.Hour}},
+		{"0", Limit{}},
+		{"off", Limit{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+
+	for _, in := range []string{"60", "x/m", "-1/m", "60/fortnight", "60/-1s", ""} {
+		_, err := ParseLimit(in)
+		assert.Error(t, err, in)
+	}
+
+	assert.Equal(t, "60/m", Limit{Requests: 60, Period: time.Minute}.String())
+	assert.Equal(t, "10/30s", Limit{Requests: 10, Period: 30 * time.Second}.String())
+	assert.Equal(t, "0", Limit{}.String())
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("RATE_LIMIT_ANONYMOUS", "30/m")
+	t.Setenv("RATE_LIMIT_API_KEY", "off")
+	t.Setenv("RATE_LIMIT_STORE", "postgres")
+	t.Setenv("TRUST_PROXY_HEADERS", "true")
+
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 30, Period: time.Minute}, cfg.Anonymous)
+	assert.False(t, cfg.APIKey.Enabled())
+	assert.Equal(t, StorePostgres, cfg.Store)
+	assert.True(t, cfg.TrustProxy)
+	assert.True(t, cfg.Enabled())
+
+	t.Setenv("RATE_LIMIT_STORE", "redis")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	store := NewMemoryStore(time.Minute)
+	now := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 3, Period: 3 * time.Second}
+	ctx := context.Background()
+
+	for i := 2; i >= 0; i-- {
+		result, err := store.Take(ctx, "ip:1.2.3.4", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, i, result.Remaining)
+	}
+
+	result, err := store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed, "the burst is spent")
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	result, err = store.Take(ctx, "ip:5.6.7.8", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed, "clients have separate buckets")
+
+	// One token is added per second
+	now = now.Add(1500 * time.Millisecond)
+	result, err = store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	result, err = store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 500*time.Millisecond, result.RetryAfter)
+
+	// Refills stop at the bucket size
+	now = now.Add(time.Hour)
+	result, err = store.Take(ctx, "ip:1.2.3.4", limit)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestMemoryStoreSweepsIdleBuckets(t *testing.T) {
+	store := NewMemoryStore(time.Minute)
+	now := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 10, Period: time.Minute}
+	ctx := context.Background()
+
+	_, err := store.Take(ctx, "ip:idle", limit)
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	for i := 1; i < sweepEvery; i++ {
+		_, err := store.Take(ctx, "ip:busy", limit)
+		require.NoError(t, err)
+	}
+
+	assert.Len(t, store.buckets, 1)
+	assert.Contains(t, store.buckets, "ip:busy")
+}
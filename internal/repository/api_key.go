@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+)
+
+// APIKeyRepository defines the interface for persisting API keys
+type APIKeyRepository interface {
+	// Create stores a new key and assigns its ID. A key whose prefix is
+	// already taken is a conflict.
+	Create(ctx context.Context, key *models.APIKey) error
+
+	// GetByID retrieves a key by ID, including revoked keys
+	GetByID(ctx context.Context, id string) (*models.APIKey, error)
+
+	// GetByPrefix retrieves a key by its public prefix, including revoked keys
+	GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error)
+
+	// List retrieves every key, newest first
+	List(ctx context.Context) ([]*models.APIKey, error)
+
+	// Revoke sets revoked_at on a key that is not already revoked
+	Revoke(ctx context.Context, id string, revokedAt time.Time) error
+
+	// Touch records when a key was last used
+	Touch(ctx context.Context, id string, usedAt time.Time) error
+}
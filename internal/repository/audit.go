@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"strings"
+)
+
+// DefaultActor is recorded for changes made without an actor in the context,
+// such as those made by scrapers and background jobs
+const DefaultActor = "system"
+
+// Audit identifies who made a change and why. It travels in the context so
+// that repositories can record it alongside the change.
+type Audit struct {
+	Actor  string
+	Reason string
+}
+
+type auditContextKey struct{}
+
+// WithAudit returns a copy of ctx carrying the actor and reason of a change
+func WithAudit(ctx context.Context, actor, reason string) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, Audit{
+		Actor:  strings.TrimSpace(actor),
+		Reason: strings.TrimSpace(reason),
+	})
+}
+
+// AuditFromContext returns the audit details carried by ctx. The actor
+// defaults to DefaultActor.
+func AuditFromContext(ctx context.Context) Audit {
+	audit, _ := ctx.Value(auditContextKey{}).(Audit)
+	if audit.Actor == "" {
+		audit.Actor = DefaultActor
+	}
+	return audit
+}
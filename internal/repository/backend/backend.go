@@ -0,0 +1,44 @@
+// Package backend picks the repository implementations that match the
+// driver a database connection was opened with, so commands can run on
+// PostgreSQL or SQLite without knowing which.
+package backend
+
+import (
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/postgres"
+	"github.com/adonese/cost-of-living/internal/repository/sqlite"
+	"github.com/adonese/cost-of-living/pkg/database"
+)
+
+// NewCostDataPointRepository returns the cost data point repository for db's driver
+func NewCostDataPointRepository(db *database.DB) repository.CostDataPointRepository {
+	if db.Driver() == database.DriverSQLite {
+		return sqlite.NewCostDataPointRepository(db.GetConn())
+	}
+	return postgres.NewCostDataPointRepository(db.GetConn())
+}
+
+// NewScrapeRunRepository returns the scrape run repository for db's driver
+func NewScrapeRunRepository(db *database.DB) repository.ScrapeRunRepository {
+	if db.Driver() == database.DriverSQLite {
+		return sqlite.NewScrapeRunRepository(db.GetConn())
+	}
+	return postgres.NewScrapeRunRepository(db.GetConn())
+}
+
+// NewAPIKeyRepository returns the API key repository for db's driver
+func NewAPIKeyRepository(db *database.DB) repository.APIKeyRepository {
+	if db.Driver() == database.DriverSQLite {
+		return sqlite.NewAPIKeyRepository(db.GetConn())
+	}
+	return postgres.NewAPIKeyRepository(db.GetConn())
+}
+
+// NewPriceRollupRepository returns the price rollup repository for db's
+// driver, or nil on SQLite, which has no continuous aggregates
+func NewPriceRollupRepository(db *database.DB) repository.PriceRollupRepository {
+	if db.Driver() == database.DriverSQLite {
+		return nil
+	}
+	return postgres.NewPriceRollupRepository(db.GetConn())
+}
@@ -0,0 +1,301 @@
+// Package cache provides a read-through caching decorator for
+// repository.CostDataPointRepository. List and Count results are kept in an
+// in-process LRU keyed by the normalised filter and dropped on every write
+// made through the decorator.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/pkg/metrics"
+)
+
+// Defaults used by ConfigFromEnv
+const (
+	DefaultSize = 1000
+	DefaultTTL  = time.Minute
+)
+
+// Config controls the size and freshness of the cache
+type Config struct {
+	// Size is the maximum number of cached results
+	Size int
+
+	// TTL is how long a result is served before it is read again. Writes
+	// made through the decorator invalidate sooner; writes made by other
+	// processes, such as the scraper, become visible after at most TTL.
+	TTL time.Duration
+}
+
+// Enabled reports whether the config caches anything
+func (c Config) Enabled() bool {
+	return c.Size > 0 && c.TTL > 0
+}
+
+// ConfigFromEnv reads CACHE_SIZE and CACHE_TTL (a Go duration such as
+// "30s"). Setting either to 0 disables the cache.
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{Size: DefaultSize, TTL: DefaultTTL}
+
+	if s := os.Getenv("CACHE_SIZE"); s != "" {
+		size, err := strconv.Atoi(s)
+		if err != nil || size < 0 {
+			return cfg, fmt.Errorf("CACHE_SIZE: invalid size %q", s)
+		}
+		cfg.Size = size
+	}
+	if s := os.Getenv("CACHE_TTL"); s != "" {
+		ttl, err := time.ParseDuration(s)
+		if err != nil || ttl < 0 {
+			return cfg, fmt.Errorf("CACHE_TTL: invalid duration %q", s)
+		}
+		cfg.TTL = ttl
+	}
+
+	return cfg, nil
+}
+
+// CostDataPointRepository caches List and Count results of the wrapped
+// repository. Other reads pass through, and every write invalidates the
+// whole cache since a single row can appear under many filters.
+type CostDataPointRepository struct {
+	next  repository.CostDataPointRepository
+	cache *lru
+
+	// mu orders stores against invalidation: a result read before a write
+	// finished is only stored if no invalidation happened in between
+	mu         sync.Mutex
+	generation uint64
+}
+
+// NewCostDataPointRepository wraps next with a cache configured by cfg
+func NewCostDataPointRepository(next repository.CostDataPointRepository, cfg Config) *CostDataPointRepository {
+	return &CostDataPointRepository{
+		next:  next,
+		cache: newLRU(cfg.Size, cfg.TTL),
+	}
+}
+
+// List returns cached results for the filter, reading through on a miss.
+// Callers receive their own copies of the data points.
+func (r *CostDataPointRepository) List(ctx context.Context, filter repository.ListFilter) ([]*models.CostDataPoint, error) {
+	key, err := filterKey("list", filter)
+	if err != nil {
+		return r.next.List(ctx, filter)
+	}
+
+	if cached, ok := r.cache.get(key); ok {
+		metrics.RepositoryCacheHitsTotal.WithLabelValues("list").Inc()
+		return clonePoints(cached.([]*models.CostDataPoint)), nil
+	}
+	metrics.RepositoryCacheMissesTotal.WithLabelValues("list").Inc()
+
+	generation := r.currentGeneration()
+	points, err := r.next.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	r.store(generation, key, clonePoints(points))
+	return points, nil
+}
+
+// Count returns the cached count for the filter, reading through on a miss
+func (r *CostDataPointRepository) Count(ctx context.Context, filter repository.ListFilter) (int64, error) {
+	key, err := filterKey("count", filter)
+	if err != nil {
+		return r.next.Count(ctx, filter)
+	}
+
+	if cached, ok := r.cache.get(key); ok {
+		metrics.RepositoryCacheHitsTotal.WithLabelValues("count").Inc()
+		return cached.(int64), nil
+	}
+	metrics.RepositoryCacheMissesTotal.WithLabelValues("count").Inc()
+
+	generation := r.currentGeneration()
+	count, err := r.next.Count(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	r.store(generation, key, count)
+	return count, nil
+}
+
+// Invalidate drops every cached result
+func (r *CostDataPointRepository) Invalidate() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	r.cache.purge()
+}
+
+func (r *CostDataPointRepository) currentGeneration() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.generation
+}
+
+// store caches value unless the cache was invalidated since generation
+func (r *CostDataPointRepository) store(generation uint64, key string, value interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if generation == r.generation {
+		r.cache.put(key, value)
+	}
+}
+
+// Create implements repository.CostDataPointRepository and invalidates the cache
+func (r *CostDataPointRepository) Create(ctx context.Context, cdp *models.CostDataPoint) error {
+	defer r.Invalidate()
+	return r.next.Create(ctx, cdp)
+}
+
+// CreateBatch implements repository.CostDataPointRepository and invalidates the cache
+func (r *CostDataPointRepository) CreateBatch(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	defer r.Invalidate()
+	return r.next.CreateBatch(ctx, cdps)
+}
+
+// Upsert implements repository.CostDataPointRepository and invalidates the cache
+func (r *CostDataPointRepository) Upsert(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	defer r.Invalidate()
+	return r.next.Upsert(ctx, cdps)
+}
+
+// GetByID implements repository.CostDataPointRepository without caching
+func (r *CostDataPointRepository) GetByID(ctx context.Context, id string, recordedAt time.Time) (*models.CostDataPoint, error) {
+	return r.next.GetByID(ctx, id, recordedAt)
+}
+
+// Stream implements repository.CostDataPointRepository without caching;
+// streamed exports are too large to hold
+func (r *CostDataPointRepository) Stream(ctx context.Context, filter repository.ListFilter, fn func(*models.CostDataPoint) error) error {
+	return r.next.Stream(ctx, filter, fn)
+}
+
+// Update implements repository.CostDataPointRepository and invalidates the cache
+func (r *CostDataPointRepository) Update(ctx context.Context, cdp *models.CostDataPoint) error {
+	defer r.Invalidate()
+	return r.next.Update(ctx, cdp)
+}
+
+// Delete implements repository.CostDataPointRepository and invalidates the cache
+func (r *CostDataPointRepository) Delete(ctx context.Context, id string, recordedAt time.Time) error {
+	defer r.Invalidate()
+	return r.next.Delete(ctx, id, recordedAt)
+}
+
+// SoftDelete implements repository.CostDataPointRepository and invalidates the cache
+func (r *CostDataPointRepository) SoftDelete(ctx context.Context, id string, recordedAt time.Time) error {
+	defer r.Invalidate()
+	return r.next.SoftDelete(ctx, id, recordedAt)
+}
+
+// Restore implements repository.CostDataPointRepository and invalidates the cache
+func (r *CostDataPointRepository) Restore(ctx context.Context, id string, recordedAt time.Time) (*models.CostDataPoint, error) {
+	defer r.Invalidate()
+	return r.next.Restore(ctx, id, recordedAt)
+}
+
+// History implements repository.CostDataPointRepository without caching
+func (r *CostDataPointRepository) History(ctx context.Context, id string) ([]*models.Revision, error) {
+	return r.next.History(ctx, id)
+}
+
+// InvalidateByRunID implements repository.CostDataPointRepository and invalidates the cache
+func (r *CostDataPointRepository) InvalidateByRunID(ctx context.Context, runID string, validTo time.Time) (int64, error) {
+	defer r.Invalidate()
+	return r.next.InvalidateByRunID(ctx, runID, validTo)
+}
+
+// DeleteByRunID implements repository.CostDataPointRepository and invalidates the cache
+func (r *CostDataPointRepository) DeleteByRunID(ctx context.Context, runID string) (int64, error) {
+	defer r.Invalidate()
+	return r.next.DeleteByRunID(ctx, runID)
+}
+
+// CloseUnseenListings implements repository.CostDataPointRepository and invalidates the cache
+func (r *CostDataPointRepository) CloseUnseenListings(ctx context.Context, scope repository.ListingScope, seenBefore, validTo time.Time) (int64, error) {
+	defer r.Invalidate()
+	return r.next.CloseUnseenListings(ctx, scope, seenBefore, validTo)
+}
+
+// filterKey returns the cache key of a filter. Filters that select the same
+// rows in the same order share a key: defaults are filled in, tags are
+// sorted and times are compared in UTC.
+func filterKey(operation string, filter repository.ListFilter) (string, error) {
+	if filter.OrderBy == "" {
+		filter.OrderBy = "recorded_at"
+	}
+	if filter.OrderDirection == "" {
+		filter.OrderDirection = repository.SortDesc
+	}
+	if filter.TagMatch == "" {
+		filter.TagMatch = repository.TagMatchAny
+	}
+	if len(filter.Tags) > 0 {
+		tags := append([]string(nil), filter.Tags...)
+		sort.Strings(tags)
+		filter.Tags = tags
+	} else {
+		filter.Tags = nil
+		filter.TagMatch = repository.TagMatchAny
+	}
+	if len(filter.Attributes) == 0 {
+		filter.Attributes = nil
+	}
+	filter.StartDate = utc(filter.StartDate)
+	filter.EndDate = utc(filter.EndDate)
+	if filter.After != nil {
+		after := *filter.After
+		after.RecordedAt = after.RecordedAt.UTC()
+		filter.After = &after
+	}
+	if operation == "count" {
+		// Count ignores ordering and pagination
+		filter.OrderBy, filter.OrderDirection = "", ""
+		filter.After, filter.Limit, filter.Offset = nil, 0, 0
+	}
+
+	// encoding/json sorts map keys, so attribute order does not matter
+	data, err := json.Marshal(filter)
+	if err != nil {
+		return "", err
+	}
+	return operation + ":" + string(data), nil
+}
+
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
+
+// clonePoints copies the slice and each data point so callers cannot modify
+// cached results
+func clonePoints(points []*models.CostDataPoint) []*models.CostDataPoint {
+	if points == nil {
+		return nil
+	}
+	clones := make([]*models.CostDataPoint, len(points))
+	for i, cdp := range points {
+		clone := *cdp
+		clones[i] = &clone
+	}
+	return clones
+}
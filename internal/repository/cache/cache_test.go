@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRepository(t *testing.T) (*CostDataPointRepository, *mock.CostDataPointRepository) {
+	t.Helper()
+
+	backing := mock.NewCostDataPointRepository()
+	for _, cdp := range []*models.CostDataPoint{
+		{Category: "Housing", ItemName: "Studio", Price: 40000, Location: models.Location{Emirate: "Dubai"}, Tags: []string{"rent", "studio"}},
+		{Category: "Housing", ItemName: "1BR", Price: 60000, Location: models.Location{Emirate: "Dubai"}, Tags: []string{"rent"}},
+		{Category: "Food", ItemName: "Bread", Price: 5, Location: models.Location{Emirate: "Sharjah"}},
+	} {
+		require.NoError(t, backing.Create(context.Background(), cdp))
+	}
+
+	return NewCostDataPointRepository(backing, Config{Size: 10, TTL: time.Minute}), backing
+}
+
+func TestListReadsThrough(t *testing.T) {
+	repo, backing := newTestRepository(t)
+	ctx := context.Background()
+	hits := testutil.ToFloat64(metrics.RepositoryCacheHitsTotal.WithLabelValues("list"))
+	misses := testutil.ToFloat64(metrics.RepositoryCacheMissesTotal.WithLabelValues("list"))
+
+	first, err := repo.List(ctx, repository.ListFilter{Category: "Housing"})
+	require.NoError(t, err)
+	second, err := repo.List(ctx, repository.ListFilter{Category: "Housing"})
+	require.NoError(t, err)
+
+	assert.Len(t, second, 2)
+	assert.Equal(t, first[0].ID, second[0].ID)
+	assert.Equal(t, 1, backing.GetCallCount("List"))
+	assert.Equal(t, hits+1, testutil.ToFloat64(metrics.RepositoryCacheHitsTotal.WithLabelValues("list")))
+	assert.Equal(t, misses+1, testutil.ToFloat64(metrics.RepositoryCacheMissesTotal.WithLabelValues("list")))
+
+	// Callers get their own copies
+	second[0].Price = 1
+	third, err := repo.List(ctx, repository.ListFilter{Category: "Housing"})
+	require.NoError(t, err)
+	assert.NotEqual(t, 1.0, third[0].Price)
+}
+
+func TestEquivalentFiltersShareEntry(t *testing.T) {
+	repo, backing := newTestRepository(t)
+	ctx := context.Background()
+
+	_, err := repo.List(ctx, repository.ListFilter{Tags: []string{"studio", "rent"}})
+	require.NoError(t, err)
+	_, err = repo.List(ctx, repository.ListFilter{
+		Tags:           []string{"rent", "studio"},
+		TagMatch:       repository.TagMatchAny,
+		OrderBy:        "recorded_at",
+		OrderDirection: repository.SortDesc,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, backing.GetCallCount("List"))
+
+	_, err = repo.List(ctx, repository.ListFilter{Tags: []string{"rent", "studio"}, TagMatch: repository.TagMatchAll})
+	require.NoError(t, err)
+	assert.Equal(t, 2, backing.GetCallCount("List"), "different tag match must not share an entry")
+}
+
+func TestCountIgnoresPagination(t *testing.T) {
+	repo, backing := newTestRepository(t)
+	ctx := context.Background()
+
+	count, err := repo.Count(ctx, repository.ListFilter{Emirate: "Dubai", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	count, err = repo.Count(ctx, repository.ListFilter{Emirate: "Dubai", Offset: 5, OrderBy: "price"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, 1, backing.GetCallCount("Count"))
+}
+
+func TestWritesInvalidate(t *testing.T) {
+	repo, backing := newTestRepository(t)
+	ctx := context.Background()
+	filter := repository.ListFilter{Emirate: "Dubai"}
+
+	points, err := repo.List(ctx, filter)
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+
+	require.NoError(t, repo.Create(ctx, &models.CostDataPoint{Category: "Food", ItemName: "Milk", Price: 7, Location: models.Location{Emirate: "Dubai"}}))
+	points, err = repo.List(ctx, filter)
+	require.NoError(t, err)
+	assert.Len(t, points, 3, "create should invalidate")
+
+	target := points[0]
+	target.Price = 99
+	require.NoError(t, repo.Update(ctx, target))
+	points, err = repo.List(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, target.ID, points[0].ID)
+	assert.Equal(t, 99.0, points[0].Price, "update should invalidate")
+
+	require.NoError(t, repo.Delete(ctx, target.ID, target.RecordedAt))
+	points, err = repo.List(ctx, filter)
+	require.NoError(t, err)
+	assert.Len(t, points, 2, "delete should invalidate")
+	assert.Equal(t, 4, backing.GetCallCount("List"))
+}
+
+func TestEntriesExpire(t *testing.T) {
+	repo, backing := newTestRepository(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	repo.cache.now = func() time.Time { return now }
+
+	_, err := repo.List(ctx, repository.ListFilter{})
+	require.NoError(t, err)
+	now = now.Add(59 * time.Second)
+	_, err = repo.List(ctx, repository.ListFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, backing.GetCallCount("List"))
+
+	now = now.Add(time.Second)
+	_, err = repo.List(ctx, repository.ListFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, backing.GetCallCount("List"))
+}
+
+func TestLRUEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newLRU(2, time.Minute)
+
+	c.put("a", 1)
+	c.put("b", 2)
+	_, _ = c.get("a")
+	c.put("c", 3)
+
+	_, ok := c.get("b")
+	assert.False(t, ok, "b was least recently used")
+	_, ok = c.get("a")
+	assert.True(t, ok)
+	_, ok = c.get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.len())
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Config{Size: DefaultSize, TTL: DefaultTTL}, cfg)
+
+	t.Setenv("CACHE_SIZE", "50")
+	t.Setenv("CACHE_TTL", "30s")
+	cfg, err = ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, Config{Size: 50, TTL: 30 * time.Second}, cfg)
+	assert.True(t, cfg.Enabled())
+
+	t.Setenv("CACHE_TTL", "0")
+	cfg, err = ConfigFromEnv()
+	require.NoError(t, err)
+	assert.False(t, cfg.Enabled())
+
+	t.Setenv("CACHE_TTL", "soon")
+	_, err = ConfigFromEnv()
+	assert.Error(t, err)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/adonese/cost-of-living/internal/models"
@@ -12,18 +13,113 @@ type CostDataPointRepository interface {
 	// Create inserts a new cost data point into the database
 	Create(ctx context.Context, cdp *models.CostDataPoint) error
 
+	// CreateBatch inserts many cost data points in a single transaction.
+	// Rows that cannot be inserted are reported in the result rather than
+	// aborting the batch; the returned error is reserved for failures that
+	// affect the whole batch (connection, transaction, commit).
+	CreateBatch(ctx context.Context, cdps []*models.CostDataPoint) (*BatchResult, error)
+
+	// Upsert writes cost data points keyed on models.CostDataPoint.NaturalKey.
+	// A still-valid row with the same key and price is refreshed in place
+	// instead of duplicated; a price change closes the old row and inserts a
+	// new one. Row failures are reported in the result as for CreateBatch.
+	Upsert(ctx context.Context, cdps []*models.CostDataPoint) (*BatchResult, error)
+
 	// GetByID retrieves a cost data point by ID and recorded_at timestamp
-	// Since the table uses composite primary key (id, recorded_at)
+	// Since the table uses composite primary key (id, recorded_at).
+	// Soft-deleted data points are not found.
 	GetByID(ctx context.Context, id string, recordedAt time.Time) (*models.CostDataPoint, error)
 
 	// List retrieves cost data points based on the provided filter
 	List(ctx context.Context, filter ListFilter) ([]*models.CostDataPoint, error)
 
-	// Update updates an existing cost data point
+	// Stream calls fn for every cost data point matching the filter, in List
+	// order, without holding the full result set in memory. Iteration stops
+	// at the first error returned by fn, which is passed back to the caller.
+	Stream(ctx context.Context, filter ListFilter, fn func(*models.CostDataPoint) error) error
+
+	// Count returns the number of cost data points matching the filter,
+	// ignoring ordering and pagination
+	Count(ctx context.Context, filter ListFilter) (int64, error)
+
+	// Update updates an existing, not soft-deleted cost data point and
+	// records a revision with the old and new values and the Audit carried
+	// by ctx
 	Update(ctx context.Context, cdp *models.CostDataPoint) error
 
-	// Delete removes a cost data point by ID and recorded_at timestamp
+	// Delete permanently removes a cost data point by ID and recorded_at
+	// timestamp, whether or not it is soft deleted, and records a revision
+	// with the removed values and the Audit carried by ctx
 	Delete(ctx context.Context, id string, recordedAt time.Time) error
+
+	// SoftDelete hides a cost data point from reads by setting deleted_at,
+	// keeping its data so it can be restored. Points that are already soft
+	// deleted are not found.
+	SoftDelete(ctx context.Context, id string, recordedAt time.Time) error
+
+	// Restore clears deleted_at on a soft-deleted cost data point and returns
+	// it. Points that are not soft deleted are not found.
+	Restore(ctx context.Context, id string, recordedAt time.Time) (*models.CostDataPoint, error)
+
+	// History returns the revisions recorded for a cost data point, newest
+	// first. A point that was never updated or deleted has no revisions.
+	History(ctx context.Context, id string) ([]*models.Revision, error)
+
+	// InvalidateByRunID closes valid_to on every still-valid data point written
+	// by the given scrape run and reopens the rows the run closed when it
+	// upserted a new price, unless their listing has a newer active row. It
+	// returns the number of rows affected.
+	InvalidateByRunID(ctx context.Context, runID string, validTo time.Time) (int64, error)
+
+	// DeleteByRunID removes every data point written by the given scrape run
+	// and reopens the rows it closed, as InvalidateByRunID does. It returns the
+	// number of rows affected.
+	DeleteByRunID(ctx context.Context, runID string) (int64, error)
+
+	// CloseUnseenListings sets valid_to on every still-valid listing in scope
+	// whose last_seen_at is before seenBefore, marking it as delisted, and
+	// returns the number of listings closed
+	CloseUnseenListings(ctx context.Context, scope ListingScope, seenBefore, validTo time.Time) (int64, error)
+}
+
+// ListingScope identifies the slice of listings covered by one scrape, such
+// as Bayut rentals in Dubai. Empty fields are not constrained.
+type ListingScope struct {
+	Source      string
+	Category    string
+	SubCategory string
+	Emirate     string
+}
+
+// BatchResult reports the outcome of a batch write
+type BatchResult struct {
+	// Inserted is the number of new rows written
+	Inserted int
+
+	// Updated is the number of existing rows refreshed in place
+	Updated int
+
+	// Failed lists the rows that could not be written
+	Failed []BatchError
+}
+
+// BatchError describes a single row that failed within a batch
+type BatchError struct {
+	// Index is the position of the row in the input slice
+	Index int
+
+	// Err is the reason the row was rejected
+	Err error
+}
+
+// Error implements the error interface
+func (e BatchError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.Index, e.Err)
+}
+
+// Unwrap returns the underlying row error
+func (e BatchError) Unwrap() error {
+	return e.Err
 }
 
 // ListFilter defines filtering options for listing cost data points
@@ -37,18 +133,151 @@ type ListFilter struct {
 	// SubCategory filters by sub category (exact match)
 	SubCategory string
 
+	// Source filters by the source that produced the data point (exact match)
+	Source string
+
 	// Emirate filters by location emirate (exact match)
 	Emirate string
 
+	// City filters by location city (exact match)
+	City string
+
+	// Area filters by location area (exact match)
+	Area string
+
+	// Tags filters by tags; see TagMatch for how multiple tags combine
+	Tags []string
+
+	// TagMatch selects whether any (default) or all of Tags must be present
+	TagMatch TagMatch
+
+	// MinPrice filters records where price >= MinPrice
+	MinPrice *float64
+
+	// MaxPrice filters records where price <= MaxPrice
+	MaxPrice *float64
+
+	// Attributes filters by attribute equality, e.g. {"bedrooms": "2"}.
+	// Numeric and boolean values also match attributes stored as JSON
+	// numbers or booleans.
+	Attributes map[string]string
+
+	// Near filters by distance from a point. Data points without
+	// coordinates never match.
+	Near *GeoRadius
+
+	// Within filters by a bounding box over coordinates. Data points
+	// without coordinates never match.
+	Within *BoundingBox
+
+	// RunID filters by the scrape run that produced the data point
+	RunID string
+
+	// ActiveOnly excludes records whose valid_to has already passed
+	ActiveOnly bool
+
+	// IncludeDeleted also returns soft-deleted records, which are excluded
+	// by default
+	IncludeDeleted bool
+
 	// StartDate filters records where recorded_at >= StartDate
 	StartDate *time.Time
 
 	// EndDate filters records where recorded_at <= EndDate
 	EndDate *time.Time
 
+	// OrderBy selects the sort field (see SortFields); defaults to recorded_at
+	OrderBy string
+
+	// OrderDirection selects the sort direction; defaults to descending
+	OrderDirection SortDirection
+
+	// After resumes a (recorded_at, id) ordered listing after the cursor
+	// position. It requires ordering by recorded_at and cannot be combined
+	// with Offset.
+	After *Cursor
+
 	// Limit specifies the maximum number of records to return
 	Limit int
 
 	// Offset specifies the number of records to skip
 	Offset int
 }
+
+// TagMatch controls how ListFilter.Tags are combined
+type TagMatch string
+
+const (
+	// TagMatchAny matches records carrying at least one of the tags
+	TagMatchAny TagMatch = "any"
+	// TagMatchAll matches records carrying every tag
+	TagMatchAll TagMatch = "all"
+)
+
+// SortDirection is the direction of ListFilter ordering
+type SortDirection string
+
+const (
+	SortAsc  SortDirection = "asc"
+	SortDesc SortDirection = "desc"
+)
+
+// SortFields lists the fields cost data points can be ordered by
+var SortFields = []string{
+	"recorded_at",
+	"price",
+	"item_name",
+	"confidence",
+	"valid_from",
+	"created_at",
+	"updated_at",
+}
+
+// IsSortField reports whether name is one of SortFields
+func IsSortField(name string) bool {
+	for _, field := range SortFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks the enumerated options of the filter
+func (f ListFilter) Validate() error {
+	if f.OrderBy != "" && !IsSortField(f.OrderBy) {
+		return ValidationFailed(fmt.Errorf("unsupported order field %q", f.OrderBy))
+	}
+	switch f.OrderDirection {
+	case "", SortAsc, SortDesc:
+	default:
+		return ValidationFailed(fmt.Errorf("unsupported order direction %q", f.OrderDirection))
+	}
+	switch f.TagMatch {
+	case "", TagMatchAny, TagMatchAll:
+	default:
+		return ValidationFailed(fmt.Errorf("unsupported tag match %q", f.TagMatch))
+	}
+	if f.After != nil {
+		if f.OrderBy != "" && f.OrderBy != "recorded_at" {
+			return ValidationFailed(fmt.Errorf("cursor pagination requires ordering by recorded_at"))
+		}
+		if f.Offset > 0 {
+			return ValidationFailed(fmt.Errorf("cursor and offset pagination cannot be combined"))
+		}
+	}
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		return ValidationFailed(fmt.Errorf("min price %v is greater than max price %v", *f.MinPrice, *f.MaxPrice))
+	}
+	if f.Near != nil {
+		if err := f.Near.Validate(); err != nil {
+			return err
+		}
+	}
+	if f.Within != nil {
+		if err := f.Within.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
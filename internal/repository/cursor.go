@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Cursor marks a position in a (recorded_at, id) ordered listing. Clients see
+// it only as an opaque string produced by Encode.
+type Cursor struct {
+	RecordedAt time.Time `json:"t"`
+	ID         string    `json:"id"`
+}
+
+// Encode returns the opaque string form of the cursor
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor parses a cursor produced by Cursor.Encode
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ValidationFailed(fmt.Errorf("malformed cursor"))
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ValidationFailed(fmt.Errorf("malformed cursor"))
+	}
+	if c.ID == "" || c.RecordedAt.IsZero() {
+		return nil, ValidationFailed(fmt.Errorf("malformed cursor"))
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	cursor := Cursor{
+		RecordedAt: time.Date(2025, 1, 15, 10, 30, 0, 123456000, time.UTC),
+		ID:         "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+	}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.True(t, cursor.RecordedAt.Equal(decoded.RecordedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, s := range []string{"", "!!!", "e30", "bm90IGpzb24"} {
+		_, err := DecodeCursor(s)
+		assert.Error(t, err, s)
+	}
+}
+
+func TestListFilterValidateCursor(t *testing.T) {
+	cursor := &Cursor{RecordedAt: time.Now(), ID: "a"}
+
+	assert.NoError(t, ListFilter{After: cursor}.Validate())
+	assert.Error(t, ListFilter{After: cursor, OrderBy: "price"}.Validate())
+	assert.Error(t, ListFilter{After: cursor, Offset: 10}.Validate())
+}
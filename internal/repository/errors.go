@@ -0,0 +1,149 @@
+package repository
+
+import "errors"
+
+// Sentinel errors classify repository failures so callers can branch with
+// errors.Is instead of matching error text
+var (
+	// ErrNotFound reports that the requested record does not exist
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict reports that a write clashes with an existing record,
+	// such as a duplicate key
+	ErrConflict = errors.New("conflict")
+
+	// ErrValidationFailed reports input the store cannot accept, such as an
+	// unsupported filter or a value that breaks a constraint
+	ErrValidationFailed = errors.New("validation failed")
+
+	// ErrUnavailable reports that the store could not be reached; the same
+	// request may succeed later
+	ErrUnavailable = errors.New("unavailable")
+)
+
+// Error is a repository failure of a given Kind. It matches its Kind and
+// its underlying cause with errors.Is and errors.As.
+type Error struct {
+	// Kind is one of the sentinel errors above
+	Kind error
+
+	// Message describes the failure; defaults to the cause's message
+	Message string
+
+	// Err is the underlying cause, if any
+	Err error
+
+	// driver is set when Err comes from the database driver, whose text
+	// names tables and constraints and must not reach clients
+	driver bool
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	switch {
+	case e.Message != "" && e.Err != nil:
+		return e.Message + ": " + e.Err.Error()
+	case e.Message != "":
+		return e.Message
+	case e.Err != nil:
+		return e.Err.Error()
+	default:
+		return e.Kind.Error()
+	}
+}
+
+// PublicMessage describes the failure for API clients: Message if set, else
+// the cause unless it is a driver error, in which case a generic description
+// of the kind stands in for it
+func (e *Error) PublicMessage() string {
+	switch {
+	case e.Message != "":
+		return e.Message
+	case e.Err != nil && !e.driver:
+		return e.Err.Error()
+	default:
+		return kindMessage(e.Kind)
+	}
+}
+
+// Unwrap returns the kind and the cause
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// NotFound returns an ErrNotFound error for the named resource, e.g.
+// NotFound("cost data point") reads "cost data point not found"
+func NotFound(resource string) error {
+	return &Error{Kind: ErrNotFound, Message: resource + " not found"}
+}
+
+// Conflict wraps err as an ErrConflict error
+func Conflict(err error) error {
+	return wrap(ErrConflict, err)
+}
+
+// ValidationFailed wraps err as an ErrValidationFailed error
+func ValidationFailed(err error) error {
+	return wrap(ErrValidationFailed, err)
+}
+
+// Unavailable wraps err as an ErrUnavailable error
+func Unavailable(err error) error {
+	return wrap(ErrUnavailable, err)
+}
+
+// DriverError classifies an error returned by the database driver as kind.
+// Its text stays in Error for logs but is left out of PublicMessage.
+func DriverError(kind, err error) error {
+	classified := wrap(kind, err)
+	if e, ok := classified.(*Error); ok && e.Err == err {
+		e.driver = true
+	}
+	return classified
+}
+
+// PublicMessage returns the client-safe description of the repository
+// error in err's chain, or "" when err is not a repository error. Wrapping
+// context added by callers, such as "failed to scan row", is left out.
+func PublicMessage(err error) string {
+	var classified *Error
+	if errors.As(err, &classified) {
+		return classified.PublicMessage()
+	}
+	for _, kind := range []error{ErrNotFound, ErrConflict, ErrValidationFailed, ErrUnavailable} {
+		if errors.Is(err, kind) {
+			return kindMessage(kind)
+		}
+	}
+	return ""
+}
+
+// kindMessage describes a kind when its cause cannot be shown
+func kindMessage(kind error) string {
+	switch kind {
+	case ErrNotFound:
+		return "record not found"
+	case ErrConflict:
+		return "conflicts with an existing record"
+	case ErrValidationFailed:
+		return "rejected by the database"
+	case ErrUnavailable:
+		return "database temporarily unavailable"
+	}
+	return kind.Error()
+}
+
+// wrap classifies err unless it is nil or already classified
+func wrap(kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	var classified *Error
+	if errors.As(err, &classified) {
+		return err
+	}
+	return &Error{Kind: kind, Err: err}
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestErrorKinds(t *testing.T) {
+	cause := errors.New("pq: duplicate key value violates unique constraint")
+
+	tests := []struct {
+		name    string
+		err     error
+		kind    error
+		message string
+	}{
+		{"not found", NotFound("cost data point"), ErrNotFound, "cost data point not found"},
+		{"conflict", Conflict(cause), ErrConflict, cause.Error()},
+		{"validation failed", ValidationFailed(fmt.Errorf("unsupported order field %q", "x")), ErrValidationFailed, `unsupported order field "x"`},
+		{"unavailable", Unavailable(cause), ErrUnavailable, cause.Error()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("failed to update cost data point: %w", tt.err)
+			if !errors.Is(wrapped, tt.kind) {
+				t.Errorf("errors.Is(%v, %v) = false", wrapped, tt.kind)
+			}
+			if tt.err.Error() != tt.message {
+				t.Errorf("Error() = %q, want %q", tt.err.Error(), tt.message)
+			}
+		})
+	}
+
+	if !errors.Is(Conflict(cause), cause) {
+		t.Error("Conflict() should keep its cause")
+	}
+	if err := Unavailable(NotFound("scrape run")); !errors.Is(err, ErrNotFound) || errors.Is(err, ErrUnavailable) {
+		t.Error("wrapping a classified error should keep its original kind")
+	}
+	if Conflict(nil) != nil {
+		t.Error("Conflict(nil) should be nil")
+	}
+}
+
+func TestPublicMessage(t *testing.T) {
+	driverErr := errors.New(`pq: duplicate key value violates unique constraint "cost_data_points_pkey"`)
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"message", fmt.Errorf("failed to get: %w", NotFound("scrape run")), "scrape run not found"},
+		{"own cause", fmt.Errorf("invalid filter: %w", ValidationFailed(errors.New("malformed cursor"))), "malformed cursor"},
+		{"driver cause", fmt.Errorf("failed to insert: %w", DriverError(ErrConflict, driverErr)), "conflicts with an existing record"},
+		{"bare kind", fmt.Errorf("lookup: %w", ErrUnavailable), "database temporarily unavailable"},
+		{"unclassified", errors.New("boom"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PublicMessage(tt.err); got != tt.want {
+				t.Errorf("PublicMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if err := DriverError(ErrConflict, driverErr); !errors.Is(err, driverErr) || err.Error() != driverErr.Error() {
+		t.Error("DriverError() should keep its cause for logs")
+	}
+	if err := DriverError(ErrUnavailable, NotFound("scrape run")); PublicMessage(err) != "scrape run not found" {
+		t.Error("DriverError() should leave classified errors alone")
+	}
+}
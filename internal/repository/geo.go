@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/adonese/cost-of-living/internal/models"
+)
+
+// kmPerDegree is the length of one degree of latitude, and of longitude at
+// the equator
+const kmPerDegree = models.EarthRadiusKm * math.Pi / 180
+
+// GeoRadius selects data points whose coordinates lie within RadiusKm of
+// Center
+type GeoRadius struct {
+	Center   models.GeoPoint
+	RadiusKm float64
+}
+
+// Validate checks the center and radius
+func (r GeoRadius) Validate() error {
+	if !validLatLon(r.Center.Lat, r.Center.Lon) {
+		return ValidationFailed(fmt.Errorf("invalid center %v,%v", r.Center.Lat, r.Center.Lon))
+	}
+	if r.RadiusKm <= 0 || math.IsNaN(r.RadiusKm) || math.IsInf(r.RadiusKm, 0) {
+		return ValidationFailed(fmt.Errorf("radius must be positive, got %v", r.RadiusKm))
+	}
+	return nil
+}
+
+// BoundingBox returns the smallest box containing the circle, clamped to
+// valid coordinates. Backends without a spatial index use it to narrow the
+// search before checking distances.
+func (r GeoRadius) BoundingBox() BoundingBox {
+	dLat := r.RadiusKm / kmPerDegree
+	box := BoundingBox{
+		MinLat: math.Max(-90, r.Center.Lat-dLat),
+		MaxLat: math.Min(90, r.Center.Lat+dLat),
+		MinLon: -180,
+		MaxLon: 180,
+	}
+
+	// Near the poles the circle covers every longitude
+	if cos := math.Cos(r.Center.Lat * math.Pi / 180); box.MinLat > -90 && box.MaxLat < 90 && cos > 0 {
+		dLon := dLat / cos
+		if dLon < 180 {
+			box.MinLon = math.Max(-180, r.Center.Lon-dLon)
+			box.MaxLon = math.Min(180, r.Center.Lon+dLon)
+		}
+	}
+
+	return box
+}
+
+// Contains reports whether p lies within the radius
+func (r GeoRadius) Contains(p models.GeoPoint) bool {
+	return r.Center.DistanceKm(p) <= r.RadiusKm
+}
+
+// BoundingBox selects data points whose coordinates lie within the box,
+// inclusive. Boxes crossing the antimeridian are not supported.
+type BoundingBox struct {
+	MinLat float64
+	MinLon float64
+	MaxLat float64
+	MaxLon float64
+}
+
+// Validate checks the corners of the box
+func (b BoundingBox) Validate() error {
+	if !validLatLon(b.MinLat, b.MinLon) || !validLatLon(b.MaxLat, b.MaxLon) {
+		return ValidationFailed(fmt.Errorf("invalid bounding box %v,%v,%v,%v", b.MinLat, b.MinLon, b.MaxLat, b.MaxLon))
+	}
+	if b.MinLat > b.MaxLat || b.MinLon > b.MaxLon {
+		return ValidationFailed(fmt.Errorf("bounding box minimum exceeds maximum"))
+	}
+	return nil
+}
+
+// Contains reports whether p lies within the box
+func (b BoundingBox) Contains(p models.GeoPoint) bool {
+	return p.Lat >= b.MinLat && p.Lat <= b.MaxLat && p.Lon >= b.MinLon && p.Lon <= b.MaxLon
+}
+
+func validLatLon(lat, lon float64) bool {
+	return lat >= -90 && lat <= 90 && lon >= -180 && lon <= 180
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeoRadiusBoundingBoxContainsCircle(t *testing.T) {
+	radius := GeoRadius{Center: models.GeoPoint{Lat: 25.08, Lon: 55.14}, RadiusKm: 3}
+	box := radius.BoundingBox()
+
+	// Walk from the center in each direction to the last point inside the
+	// radius; it must still be inside the box
+	for _, step := range []models.GeoPoint{{Lat: 0.001}, {Lat: -0.001}, {Lon: 0.001}, {Lon: -0.001}} {
+		edge := radius.Center
+		for radius.Contains(models.GeoPoint{Lat: edge.Lat + step.Lat, Lon: edge.Lon + step.Lon}) {
+			edge.Lat += step.Lat
+			edge.Lon += step.Lon
+		}
+		assert.NotEqual(t, radius.Center, edge)
+		assert.True(t, box.Contains(edge), "edge %v outside box %+v", edge, box)
+	}
+
+	assert.False(t, radius.Contains(models.GeoPoint{Lat: 25.1972, Lon: 55.2744}))
+}
+
+func TestGeoRadiusBoundingBoxNearPole(t *testing.T) {
+	box := GeoRadius{Center: models.GeoPoint{Lat: 89.99, Lon: 10}, RadiusKm: 5}.BoundingBox()
+	assert.Equal(t, 90.0, box.MaxLat)
+	assert.Equal(t, -180.0, box.MinLon)
+	assert.Equal(t, 180.0, box.MaxLon)
+}
+
+func TestListFilterValidateGeo(t *testing.T) {
+	valid := []ListFilter{
+		{Near: &GeoRadius{Center: models.GeoPoint{Lat: 25, Lon: 55}, RadiusKm: 1}},
+		{Within: &BoundingBox{MinLat: 24, MinLon: 54, MaxLat: 26, MaxLon: 56}},
+	}
+	for _, f := range valid {
+		assert.NoError(t, f.Validate())
+	}
+
+	invalid := []ListFilter{
+		{Near: &GeoRadius{Center: models.GeoPoint{Lat: 25, Lon: 55}}},
+		{Near: &GeoRadius{Center: models.GeoPoint{Lat: -91, Lon: 55}, RadiusKm: 1}},
+		{Within: &BoundingBox{MinLat: 26, MinLon: 54, MaxLat: 24, MaxLon: 56}},
+		{Within: &BoundingBox{MinLat: 24, MinLon: 54, MaxLat: 26, MaxLon: 190}},
+	}
+	for _, f := range invalid {
+		err := f.Validate()
+		assert.True(t, errors.Is(err, ErrValidationFailed), "expected validation error, got %v", err)
+	}
+}
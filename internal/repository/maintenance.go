@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+)
+
+// MaintenanceRepository manages the storage policies of the cost data point
+// hypertable
+type MaintenanceRepository interface {
+	// SetCompressionPolicy compresses chunks once all their rows are older
+	// than after, replacing any existing policy. Zero removes the policy.
+	SetCompressionPolicy(ctx context.Context, after time.Duration) error
+
+	// CountExpired returns the number of rows the retention filter selects
+	CountExpired(ctx context.Context, filter RetentionFilter) (int64, error)
+
+	// DeleteExpired deletes up to limit rows selected by the retention
+	// filter, oldest first, and returns how many were deleted. The deleted
+	// rows are passed to archive before the deletion commits; if archive
+	// returns an error the deletion is rolled back.
+	DeleteExpired(ctx context.Context, filter RetentionFilter, limit int, archive func([]*models.CostDataPoint) error) (int, error)
+}
+
+// RetentionFilter selects rows that have outlived their retention period: a
+// row expires once it was recorded before Before and was neither valid nor
+// seen by a scraper since then. Listings that are still on the market are
+// therefore kept however old their first observation is.
+type RetentionFilter struct {
+	// Category limits the filter to one category; empty matches every
+	// category
+	Category string
+
+	// ExcludeCategories skips categories that have a retention period of
+	// their own
+	ExcludeCategories []string
+
+	// Before is the retention cutoff
+	Before time.Time
+}
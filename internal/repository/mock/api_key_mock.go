@@ -0,0 +1,148 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+)
+
+// APIKeyRepository is a mock implementation of repository.APIKeyRepository
+type APIKeyRepository struct {
+	mu    sync.RWMutex
+	data  map[string]*models.APIKey
+	calls map[string]int
+}
+
+// NewAPIKeyRepository creates a new mock API key repository
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{
+		data:  make(map[string]*models.APIKey),
+		calls: make(map[string]int),
+	}
+}
+
+// Create implements repository.APIKeyRepository
+func (m *APIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["Create"]++
+
+	for _, existing := range m.data {
+		if existing.Prefix == key.Prefix {
+			return repository.Conflict(fmt.Errorf("duplicate api key prefix %s", key.Prefix))
+		}
+	}
+
+	if key.ID == "" {
+		key.ID = fmt.Sprintf("mock-key-%d", len(m.data)+1)
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+
+	copied := *key
+	m.data[key.ID] = &copied
+	return nil
+}
+
+// GetByID implements repository.APIKeyRepository
+func (m *APIKeyRepository) GetByID(ctx context.Context, id string) (*models.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.calls["GetByID"]++
+
+	key, exists := m.data[id]
+	if !exists {
+		return nil, repository.NotFound("api key")
+	}
+
+	copied := *key
+	return &copied, nil
+}
+
+// GetByPrefix implements repository.APIKeyRepository
+func (m *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.calls["GetByPrefix"]++
+
+	for _, key := range m.data {
+		if key.Prefix == prefix {
+			copied := *key
+			return &copied, nil
+		}
+	}
+	return nil, repository.NotFound("api key")
+}
+
+// List implements repository.APIKeyRepository
+func (m *APIKeyRepository) List(ctx context.Context) ([]*models.APIKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.calls["List"]++
+
+	results := make([]*models.APIKey, 0, len(m.data))
+	for _, key := range m.data {
+		copied := *key
+		results = append(results, &copied)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+
+	return results, nil
+}
+
+// Revoke implements repository.APIKeyRepository
+func (m *APIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["Revoke"]++
+
+	key, exists := m.data[id]
+	if !exists || key.Revoked() {
+		return repository.NotFound("api key")
+	}
+
+	key.RevokedAt = &revokedAt
+	return nil
+}
+
+// Touch implements repository.APIKeyRepository
+func (m *APIKeyRepository) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["Touch"]++
+
+	if key, exists := m.data[id]; exists {
+		key.LastUsedAt = &usedAt
+	}
+	return nil
+}
+
+// GetCallCount returns the number of times a method was called
+func (m *APIKeyRepository) GetCallCount(method string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.calls[method]
+}
+
+// Reset clears all data and call counts
+func (m *APIKeyRepository) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = make(map[string]*models.APIKey)
+	m.calls = make(map[string]int)
+}
@@ -1,9 +1,11 @@
 package mock
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -13,16 +15,19 @@ import (
 
 // CostDataPointRepository is a mock implementation of repository.CostDataPointRepository
 type CostDataPointRepository struct {
-	mu    sync.RWMutex
-	data  map[string]*models.CostDataPoint // key is "id:recordedAt"
-	calls map[string]int                   // track method calls for testing
+	mu        sync.RWMutex
+	data      map[string]*models.CostDataPoint // key is "id:recordedAt"
+	closedBy  map[string]string                // run that superseded a row, by key
+	revisions []*models.Revision               // oldest first
+	calls     map[string]int                   // track method calls for testing
 }
 
 // NewCostDataPointRepository creates a new mock repository
 func NewCostDataPointRepository() *CostDataPointRepository {
 	return &CostDataPointRepository{
-		data:  make(map[string]*models.CostDataPoint),
-		calls: make(map[string]int),
+		data:     make(map[string]*models.CostDataPoint),
+		closedBy: make(map[string]string),
+		calls:    make(map[string]int),
 	}
 }
 
@@ -33,6 +38,140 @@ func (m *CostDataPointRepository) Create(ctx context.Context, cdp *models.CostDa
 
 	m.calls["Create"]++
 
+	m.insert(cdp)
+	return nil
+}
+
+// CreateBatch implements repository.CostDataPointRepository. Rows whose
+// (id, recorded_at) already exist are reported as failures, mirroring the
+// primary key constraint of the real table.
+func (m *CostDataPointRepository) CreateBatch(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["CreateBatch"]++
+
+	result := &repository.BatchResult{}
+	for i, cdp := range cdps {
+		if cdp == nil {
+			result.Failed = append(result.Failed, repository.BatchError{Index: i, Err: repository.ValidationFailed(fmt.Errorf("cost data point is nil"))})
+			continue
+		}
+		if cdp.ID != "" && !cdp.RecordedAt.IsZero() {
+			if _, exists := m.data[makeKey(cdp.ID, cdp.RecordedAt)]; exists {
+				result.Failed = append(result.Failed, repository.BatchError{Index: i, Err: repository.Conflict(fmt.Errorf("duplicate cost data point %s", cdp.ID))})
+				continue
+			}
+		}
+		m.insert(cdp)
+		result.Inserted++
+	}
+
+	return result, nil
+}
+
+// Upsert implements repository.CostDataPointRepository
+func (m *CostDataPointRepository) Upsert(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["Upsert"]++
+
+	result := &repository.BatchResult{}
+	for i, cdp := range cdps {
+		if cdp == nil {
+			result.Failed = append(result.Failed, repository.BatchError{Index: i, Err: repository.ValidationFailed(fmt.Errorf("cost data point is nil"))})
+			continue
+		}
+
+		existing := m.findActiveByNaturalKey(cdp.NaturalKey())
+		if existing == nil {
+			m.insert(cdp)
+			result.Inserted++
+			continue
+		}
+
+		if existing.Price != cdp.Price {
+			if cdp.ValidFrom.IsZero() {
+				cdp.ValidFrom = time.Now()
+			}
+			closedAt := cdp.ValidFrom
+			existing.ValidTo = &closedAt
+			existing.UpdatedAt = time.Now()
+			if cdp.RunID != "" {
+				m.closedBy[makeKey(existing.ID, existing.RecordedAt)] = cdp.RunID
+			}
+			cdp.FirstSeenAt = existing.FirstSeenAt
+			m.insert(cdp)
+			result.Inserted++
+			continue
+		}
+
+		existing.MinPrice = cdp.MinPrice
+		existing.MaxPrice = cdp.MaxPrice
+		existing.MedianPrice = cdp.MedianPrice
+		if cdp.SampleSize != 0 {
+			existing.SampleSize = cdp.SampleSize
+		}
+		if cdp.Confidence != 0 {
+			existing.Confidence = cdp.Confidence
+		}
+		existing.SourceURL = cdp.SourceURL
+		existing.Tags = cdp.Tags
+		existing.Attributes = cdp.Attributes
+		existing.ValidTo = cdp.ValidTo
+		existing.LastSeenAt = cdp.LastSeenAt
+		if existing.LastSeenAt.IsZero() {
+			existing.LastSeenAt = cdp.RecordedAt
+		}
+		if existing.LastSeenAt.IsZero() {
+			existing.LastSeenAt = time.Now()
+		}
+		existing.UpdatedAt = time.Now()
+
+		cdp.ID = existing.ID
+		cdp.RecordedAt = existing.RecordedAt
+		cdp.ValidFrom = existing.ValidFrom
+		cdp.RunID = existing.RunID
+		cdp.SampleSize = existing.SampleSize
+		cdp.Confidence = existing.Confidence
+		cdp.Currency = existing.Currency
+		cdp.Unit = existing.Unit
+		cdp.Period = existing.Period
+		cdp.FirstSeenAt = existing.FirstSeenAt
+		cdp.LastSeenAt = existing.LastSeenAt
+		cdp.CreatedAt = existing.CreatedAt
+		cdp.UpdatedAt = existing.UpdatedAt
+		result.Updated++
+	}
+
+	return result, nil
+}
+
+// findActiveByNaturalKey returns the latest still-valid data point with the
+// given natural key; callers must hold the lock
+func (m *CostDataPointRepository) findActiveByNaturalKey(key string) *models.CostDataPoint {
+	var latest *models.CostDataPoint
+	now := time.Now()
+	for _, cdp := range m.data {
+		if cdp.ValidTo != nil && !cdp.ValidTo.After(now) {
+			continue
+		}
+		if cdp.DeletedAt != nil {
+			continue
+		}
+		if cdp.NaturalKey() != key {
+			continue
+		}
+		if latest == nil || cdp.RecordedAt.After(latest.RecordedAt) {
+			latest = cdp
+		}
+	}
+	return latest
+}
+
+// insert applies defaults and stores cdp; callers must hold the write lock
+func (m *CostDataPointRepository) insert(cdp *models.CostDataPoint) {
 	// Generate ID if not provided
 	if cdp.ID == "" {
 		cdp.ID = fmt.Sprintf("mock-id-%d", len(m.data)+1)
@@ -51,8 +190,12 @@ func (m *CostDataPointRepository) Create(ctx context.Context, cdp *models.CostDa
 	if cdp.Confidence == 0 {
 		cdp.Confidence = 1.0
 	}
-	if cdp.Unit == "" {
-		cdp.Unit = "AED"
+	cdp.NormalizeUnit()
+	if cdp.FirstSeenAt.IsZero() {
+		cdp.FirstSeenAt = cdp.RecordedAt
+	}
+	if cdp.LastSeenAt.IsZero() {
+		cdp.LastSeenAt = cdp.RecordedAt
 	}
 
 	cdp.CreatedAt = time.Now()
@@ -60,8 +203,6 @@ func (m *CostDataPointRepository) Create(ctx context.Context, cdp *models.CostDa
 
 	key := makeKey(cdp.ID, cdp.RecordedAt)
 	m.data[key] = cdp
-
-	return nil
 }
 
 // GetByID implements repository.CostDataPointRepository
@@ -73,11 +214,14 @@ func (m *CostDataPointRepository) GetByID(ctx context.Context, id string, record
 
 	key := makeKey(id, recordedAt)
 	cdp, exists := m.data[key]
-	if !exists {
-		return nil, fmt.Errorf("cost data point not found")
+	if !exists || cdp.DeletedAt != nil {
+		return nil, repository.NotFound("cost data point")
 	}
 
-	return cdp, nil
+	// Return a copy so callers modifying it before Update do not rewrite
+	// the stored version, as with a real database
+	stored := *cdp
+	return &stored, nil
 }
 
 // List implements repository.CostDataPointRepository
@@ -87,37 +231,51 @@ func (m *CostDataPointRepository) List(ctx context.Context, filter repository.Li
 
 	m.calls["List"]++
 
+	return m.list(filter)
+}
+
+// Stream calls fn for every data point matching the filter, in List order
+func (m *CostDataPointRepository) Stream(ctx context.Context, filter repository.ListFilter, fn func(*models.CostDataPoint) error) error {
+	m.mu.Lock()
+	m.calls["Stream"]++
+	results, err := m.list(filter)
+	m.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, cdp := range results {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(cdp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// list applies the filter, ordering and pagination; callers hold the lock
+func (m *CostDataPointRepository) list(filter repository.ListFilter) ([]*models.CostDataPoint, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
 	var results []*models.CostDataPoint
 
 	// Collect all data points
 	for _, cdp := range m.data {
-		if filter.ID != "" && cdp.ID != filter.ID {
-			continue
-		}
-		// Apply filters
-		if filter.Category != "" && cdp.Category != filter.Category {
-			continue
-		}
-		if filter.SubCategory != "" && cdp.SubCategory != filter.SubCategory {
+		if !matchesFilter(cdp, filter) {
 			continue
 		}
-		if filter.Emirate != "" && cdp.Location.Emirate != filter.Emirate {
+		if filter.After != nil && !afterCursor(cdp, filter.After, filter.OrderDirection) {
 			continue
 		}
-		if filter.StartDate != nil && cdp.RecordedAt.Before(*filter.StartDate) {
-			continue
-		}
-		if filter.EndDate != nil && cdp.RecordedAt.After(*filter.EndDate) {
-			continue
-		}
-
 		results = append(results, cdp)
 	}
 
-	// Sort by recorded_at descending to match real repository behavior
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].RecordedAt.After(results[j].RecordedAt)
-	})
+	// Sort to match real repository behavior (recorded_at descending by default)
+	sortDataPoints(results, filter.OrderBy, filter.OrderDirection)
 
 	// Apply pagination
 	start := filter.Offset
@@ -135,6 +293,158 @@ func (m *CostDataPointRepository) List(ctx context.Context, filter repository.Li
 	return results, nil
 }
 
+// Count implements repository.CostDataPointRepository
+func (m *CostDataPointRepository) Count(ctx context.Context, filter repository.ListFilter) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.calls["Count"]++
+
+	if err := filter.Validate(); err != nil {
+		return 0, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	var count int64
+	for _, cdp := range m.data {
+		if matchesFilter(cdp, filter) {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// afterCursor reports whether cdp sorts strictly after the cursor position
+// in a (recorded_at, id) listing with the given direction
+func afterCursor(cdp *models.CostDataPoint, cursor *repository.Cursor, direction repository.SortDirection) bool {
+	c := cdp.RecordedAt.Compare(cursor.RecordedAt)
+	if c == 0 {
+		c = strings.Compare(cdp.ID, cursor.ID)
+	}
+	if direction == repository.SortAsc {
+		return c > 0
+	}
+	return c < 0
+}
+
+// matchesFilter applies every ListFilter predicate except ordering and pagination
+func matchesFilter(cdp *models.CostDataPoint, filter repository.ListFilter) bool {
+	if filter.ID != "" && cdp.ID != filter.ID {
+		return false
+	}
+	if !filter.IncludeDeleted && cdp.DeletedAt != nil {
+		return false
+	}
+	if filter.Category != "" && cdp.Category != filter.Category {
+		return false
+	}
+	if filter.SubCategory != "" && cdp.SubCategory != filter.SubCategory {
+		return false
+	}
+	if filter.Source != "" && cdp.Source != filter.Source {
+		return false
+	}
+	if filter.Emirate != "" && cdp.Location.Emirate != filter.Emirate {
+		return false
+	}
+	if filter.City != "" && cdp.Location.City != filter.City {
+		return false
+	}
+	if filter.Area != "" && cdp.Location.Area != filter.Area {
+		return false
+	}
+	if len(filter.Tags) > 0 && !matchesTags(cdp.Tags, filter.Tags, filter.TagMatch) {
+		return false
+	}
+	if filter.MinPrice != nil && cdp.Price < *filter.MinPrice {
+		return false
+	}
+	if filter.MaxPrice != nil && cdp.Price > *filter.MaxPrice {
+		return false
+	}
+	for key, want := range filter.Attributes {
+		got, ok := cdp.Attributes[key]
+		if !ok || fmt.Sprint(got) != want {
+			return false
+		}
+	}
+	if filter.Near != nil || filter.Within != nil {
+		coords := cdp.Location.Coordinates
+		if coords == nil {
+			return false
+		}
+		if filter.Near != nil && !filter.Near.Contains(*coords) {
+			return false
+		}
+		if filter.Within != nil && !filter.Within.Contains(*coords) {
+			return false
+		}
+	}
+	if filter.RunID != "" && cdp.RunID != filter.RunID {
+		return false
+	}
+	if filter.ActiveOnly && cdp.ValidTo != nil && !cdp.ValidTo.After(time.Now()) {
+		return false
+	}
+	if filter.StartDate != nil && cdp.RecordedAt.Before(*filter.StartDate) {
+		return false
+	}
+	if filter.EndDate != nil && cdp.RecordedAt.After(*filter.EndDate) {
+		return false
+	}
+	return true
+}
+
+// matchesTags reports whether have contains any (or all) of want
+func matchesTags(have, want []string, match repository.TagMatch) bool {
+	set := make(map[string]bool, len(have))
+	for _, tag := range have {
+		set[tag] = true
+	}
+	for _, tag := range want {
+		if set[tag] && match != repository.TagMatchAll {
+			return true
+		}
+		if !set[tag] && match == repository.TagMatchAll {
+			return false
+		}
+	}
+	return match == repository.TagMatchAll
+}
+
+// sortDataPoints orders points by field and direction, breaking ties on ID
+func sortDataPoints(points []*models.CostDataPoint, field string, direction repository.SortDirection) {
+	compareField := func(a, b *models.CostDataPoint) int {
+		switch field {
+		case "price":
+			return cmp.Compare(a.Price, b.Price)
+		case "item_name":
+			return strings.Compare(a.ItemName, b.ItemName)
+		case "confidence":
+			return cmp.Compare(a.Confidence, b.Confidence)
+		case "valid_from":
+			return a.ValidFrom.Compare(b.ValidFrom)
+		case "created_at":
+			return a.CreatedAt.Compare(b.CreatedAt)
+		case "updated_at":
+			return a.UpdatedAt.Compare(b.UpdatedAt)
+		default:
+			return a.RecordedAt.Compare(b.RecordedAt)
+		}
+	}
+
+	sort.SliceStable(points, func(i, j int) bool {
+		c := compareField(points[i], points[j])
+		if c == 0 {
+			c = strings.Compare(points[i].ID, points[j].ID)
+		}
+		if direction == repository.SortAsc {
+			return c < 0
+		}
+		return c > 0
+	})
+}
+
 // Update implements repository.CostDataPointRepository
 func (m *CostDataPointRepository) Update(ctx context.Context, cdp *models.CostDataPoint) error {
 	m.mu.Lock()
@@ -143,12 +453,15 @@ func (m *CostDataPointRepository) Update(ctx context.Context, cdp *models.CostDa
 	m.calls["Update"]++
 
 	key := makeKey(cdp.ID, cdp.RecordedAt)
-	if _, exists := m.data[key]; !exists {
-		return fmt.Errorf("cost data point not found")
+	old, exists := m.data[key]
+	if !exists || old.DeletedAt != nil {
+		return repository.NotFound("cost data point")
 	}
 
+	cdp.NormalizeUnit()
 	cdp.UpdatedAt = time.Now()
 	m.data[key] = cdp
+	m.recordRevision(ctx, models.RevisionUpdate, old, cdp)
 
 	return nil
 }
@@ -161,14 +474,198 @@ func (m *CostDataPointRepository) Delete(ctx context.Context, id string, recorde
 	m.calls["Delete"]++
 
 	key := makeKey(id, recordedAt)
-	if _, exists := m.data[key]; !exists {
-		return fmt.Errorf("cost data point not found")
+	old, exists := m.data[key]
+	if !exists {
+		return repository.NotFound("cost data point")
 	}
 
 	delete(m.data, key)
+	m.recordRevision(ctx, models.RevisionDelete, old, nil)
 	return nil
 }
 
+// SoftDelete implements repository.CostDataPointRepository
+func (m *CostDataPointRepository) SoftDelete(ctx context.Context, id string, recordedAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["SoftDelete"]++
+
+	_, err := m.setDeletedAt(ctx, id, recordedAt, models.RevisionDelete)
+	return err
+}
+
+// Restore implements repository.CostDataPointRepository
+func (m *CostDataPointRepository) Restore(ctx context.Context, id string, recordedAt time.Time) (*models.CostDataPoint, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["Restore"]++
+
+	return m.setDeletedAt(ctx, id, recordedAt, models.RevisionRestore)
+}
+
+// setDeletedAt soft deletes or restores a data point, replacing the stored
+// version so earlier snapshots are left intact; callers must hold the write
+// lock
+func (m *CostDataPointRepository) setDeletedAt(ctx context.Context, id string, recordedAt time.Time, action models.RevisionAction) (*models.CostDataPoint, error) {
+	key := makeKey(id, recordedAt)
+	old, exists := m.data[key]
+	if !exists || (old.DeletedAt != nil) == (action == models.RevisionDelete) {
+		return nil, repository.NotFound("cost data point")
+	}
+
+	now := time.Now()
+	cdp := *old
+	cdp.UpdatedAt = now
+	cdp.DeletedAt = nil
+	if action == models.RevisionDelete {
+		cdp.DeletedAt = &now
+	}
+	m.data[key] = &cdp
+	m.recordRevision(ctx, action, old, &cdp)
+
+	result := cdp
+	return &result, nil
+}
+
+// History implements repository.CostDataPointRepository
+func (m *CostDataPointRepository) History(ctx context.Context, id string) ([]*models.Revision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.calls["History"]++
+
+	var revisions []*models.Revision
+	for i := len(m.revisions) - 1; i >= 0; i-- {
+		if m.revisions[i].DataPointID == id {
+			revisions = append(revisions, m.revisions[i])
+		}
+	}
+
+	return revisions, nil
+}
+
+// recordRevision stores copies of both sides of a change with the audit
+// details from ctx. Callers must hold the write lock.
+func (m *CostDataPointRepository) recordRevision(ctx context.Context, action models.RevisionAction, oldValues, newValues *models.CostDataPoint) {
+	snapshot := func(cdp *models.CostDataPoint) *models.CostDataPoint {
+		if cdp == nil {
+			return nil
+		}
+		c := *cdp
+		return &c
+	}
+
+	audit := repository.AuditFromContext(ctx)
+	rev := models.NewRevision(action, snapshot(oldValues), snapshot(newValues), audit.Actor, audit.Reason)
+	rev.ID = int64(len(m.revisions) + 1)
+	rev.CreatedAt = time.Now()
+	m.revisions = append(m.revisions, rev)
+}
+
+// InvalidateByRunID implements repository.CostDataPointRepository
+func (m *CostDataPointRepository) InvalidateByRunID(ctx context.Context, runID string, validTo time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["InvalidateByRunID"]++
+
+	var affected int64
+	for _, cdp := range m.data {
+		if cdp.RunID != runID {
+			continue
+		}
+		if cdp.ValidTo != nil && !cdp.ValidTo.After(validTo) {
+			continue
+		}
+		closedAt := validTo
+		cdp.ValidTo = &closedAt
+		cdp.UpdatedAt = time.Now()
+		affected++
+	}
+
+	return affected + m.reopenClosedBy(runID), nil
+}
+
+// DeleteByRunID implements repository.CostDataPointRepository
+func (m *CostDataPointRepository) DeleteByRunID(ctx context.Context, runID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["DeleteByRunID"]++
+
+	var affected int64
+	for key, cdp := range m.data {
+		if cdp.RunID == runID {
+			delete(m.data, key)
+			delete(m.closedBy, key)
+			affected++
+		}
+	}
+
+	return affected + m.reopenClosedBy(runID), nil
+}
+
+// reopenClosedBy reopens the rows runID superseded, unless their listing has
+// another active row not written by the run; callers must hold the write lock
+func (m *CostDataPointRepository) reopenClosedBy(runID string) int64 {
+	var reopened int64
+	for key, closer := range m.closedBy {
+		cdp, ok := m.data[key]
+		if closer != runID || !ok || cdp.RunID == runID || cdp.DeletedAt != nil {
+			continue
+		}
+		if newer := m.findActiveByNaturalKey(cdp.NaturalKey()); newer != nil && newer.RunID != runID {
+			continue
+		}
+		cdp.ValidTo = nil
+		cdp.UpdatedAt = time.Now()
+		delete(m.closedBy, key)
+		reopened++
+	}
+	return reopened
+}
+
+// CloseUnseenListings implements repository.CostDataPointRepository
+func (m *CostDataPointRepository) CloseUnseenListings(ctx context.Context, scope repository.ListingScope, seenBefore, validTo time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["CloseUnseenListings"]++
+
+	var affected int64
+	for _, cdp := range m.data {
+		if !cdp.IsListing() {
+			continue
+		}
+		if scope.Source != "" && cdp.Source != scope.Source {
+			continue
+		}
+		if scope.Category != "" && cdp.Category != scope.Category {
+			continue
+		}
+		if scope.SubCategory != "" && cdp.SubCategory != scope.SubCategory {
+			continue
+		}
+		if scope.Emirate != "" && cdp.Location.Emirate != scope.Emirate {
+			continue
+		}
+		if cdp.ValidTo != nil && !cdp.ValidTo.After(validTo) {
+			continue
+		}
+		if !cdp.LastSeenAt.Before(seenBefore) {
+			continue
+		}
+		closedAt := validTo
+		cdp.ValidTo = &closedAt
+		cdp.UpdatedAt = time.Now()
+		affected++
+	}
+
+	return affected, nil
+}
+
 // GetCallCount returns the number of times a method was called
 func (m *CostDataPointRepository) GetCallCount(method string) int {
 	m.mu.RLock()
@@ -181,6 +678,8 @@ func (m *CostDataPointRepository) Reset() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 	m.data = make(map[string]*models.CostDataPoint)
+	m.closedBy = make(map[string]string)
+	m.revisions = nil
 	m.calls = make(map[string]int)
 }
 
@@ -0,0 +1,130 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+)
+
+// MaintenanceRepository is a mock implementation of
+// repository.MaintenanceRepository that expires rows held by a mock
+// CostDataPointRepository
+type MaintenanceRepository struct {
+	mu            sync.Mutex
+	points        *CostDataPointRepository
+	compressAfter time.Duration
+	calls         map[string]int
+}
+
+// NewMaintenanceRepository creates a mock maintenance repository over points
+func NewMaintenanceRepository(points *CostDataPointRepository) *MaintenanceRepository {
+	return &MaintenanceRepository{
+		points: points,
+		calls:  make(map[string]int),
+	}
+}
+
+// SetCompressionPolicy implements repository.MaintenanceRepository
+func (m *MaintenanceRepository) SetCompressionPolicy(ctx context.Context, after time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["SetCompressionPolicy"]++
+	m.compressAfter = after
+	return nil
+}
+
+// CompressAfter returns the compression threshold last set
+func (m *MaintenanceRepository) CompressAfter() time.Duration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.compressAfter
+}
+
+// CountExpired implements repository.MaintenanceRepository
+func (m *MaintenanceRepository) CountExpired(ctx context.Context, filter repository.RetentionFilter) (int64, error) {
+	m.mu.Lock()
+	m.calls["CountExpired"]++
+	m.mu.Unlock()
+
+	m.points.mu.RLock()
+	defer m.points.mu.RUnlock()
+
+	return int64(len(m.expired(filter))), nil
+}
+
+// DeleteExpired implements repository.MaintenanceRepository
+func (m *MaintenanceRepository) DeleteExpired(
+	ctx context.Context,
+	filter repository.RetentionFilter,
+	limit int,
+	archive func([]*models.CostDataPoint) error,
+) (int, error) {
+	m.mu.Lock()
+	m.calls["DeleteExpired"]++
+	m.mu.Unlock()
+
+	if limit <= 0 {
+		return 0, repository.ValidationFailed(fmt.Errorf("limit must be positive"))
+	}
+
+	m.points.mu.Lock()
+	defer m.points.mu.Unlock()
+
+	expired := m.expired(filter)
+	if len(expired) > limit {
+		expired = expired[:limit]
+	}
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	if err := archive(expired); err != nil {
+		return 0, fmt.Errorf("failed to archive expired cost data points: %w", err)
+	}
+
+	for _, cdp := range expired {
+		delete(m.points.data, makeKey(cdp.ID, cdp.RecordedAt))
+	}
+	return len(expired), nil
+}
+
+// GetCallCount returns the number of times a method was called
+func (m *MaintenanceRepository) GetCallCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+
+// expired returns the matching rows oldest first; callers hold the points
+// lock
+func (m *MaintenanceRepository) expired(filter repository.RetentionFilter) []*models.CostDataPoint {
+	var results []*models.CostDataPoint
+	for _, cdp := range m.points.data {
+		lastEvidence := cdp.LastSeenAt
+		if cdp.ValidTo != nil {
+			lastEvidence = *cdp.ValidTo
+		}
+		if !cdp.RecordedAt.Before(filter.Before) || !lastEvidence.Before(filter.Before) {
+			continue
+		}
+		if filter.Category != "" && cdp.Category != filter.Category {
+			continue
+		}
+		if slices.Contains(filter.ExcludeCategories, cdp.Category) {
+			continue
+		}
+		results = append(results, cdp)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].RecordedAt.Before(results[j].RecordedAt)
+	})
+	return results
+}
@@ -0,0 +1,199 @@
+package mock
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+)
+
+// PriceRollupRepository is a mock implementation of
+// repository.PriceRollupRepository that aggregates the data held by a mock
+// CostDataPointRepository on every call, mirroring the continuous aggregates
+type PriceRollupRepository struct {
+	mu     sync.Mutex
+	points *CostDataPointRepository
+	calls  map[string]int
+}
+
+// NewPriceRollupRepository creates a mock rollup repository over points
+func NewPriceRollupRepository(points *CostDataPointRepository) *PriceRollupRepository {
+	return &PriceRollupRepository{
+		points: points,
+		calls:  make(map[string]int),
+	}
+}
+
+type rollupKey struct {
+	bucket                               time.Time
+	category, subCategory, emirate, area string
+	currency, unit, period               string
+}
+
+// ListRollups implements repository.PriceRollupRepository
+func (m *PriceRollupRepository) ListRollups(ctx context.Context, filter repository.RollupFilter) ([]*models.PriceRollup, error) {
+	m.mu.Lock()
+	m.calls["ListRollups"]++
+	m.mu.Unlock()
+
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	interval := filter.Interval
+	if interval == "" {
+		interval = models.RollupDaily
+	}
+	width := 24 * time.Hour
+	if interval == models.RollupWeekly {
+		// The zero time is a Monday, matching time_bucket's week alignment
+		width = 7 * 24 * time.Hour
+	}
+
+	m.points.mu.RLock()
+	groups := make(map[rollupKey][]float64)
+	for _, cdp := range m.points.data {
+		// Soft-deleted points are excluded from the rollup views
+		if cdp.DeletedAt != nil {
+			continue
+		}
+		key := rollupKey{
+			bucket:      cdp.RecordedAt.UTC().Truncate(width),
+			category:    cdp.Category,
+			subCategory: cdp.SubCategory,
+			emirate:     cdp.Location.Emirate,
+			area:        cdp.Location.Area,
+			currency:    cdp.Currency,
+			unit:        cdp.Unit,
+			period:      cdp.Period,
+		}
+		if !matchesRollup(key, filter) {
+			continue
+		}
+		groups[key] = append(groups[key], cdp.Price)
+	}
+	m.points.mu.RUnlock()
+
+	results := make([]*models.PriceRollup, 0, len(groups))
+	for key, prices := range groups {
+		results = append(results, summarise(interval, key, prices))
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if !a.Bucket.Equal(b.Bucket) {
+			return a.Bucket.Before(b.Bucket)
+		}
+		return cmp.Or(
+			cmp.Compare(a.Category, b.Category),
+			cmp.Compare(a.SubCategory, b.SubCategory),
+			cmp.Compare(a.Emirate, b.Emirate),
+			cmp.Compare(a.Area, b.Area),
+			cmp.Compare(a.Currency, b.Currency),
+			cmp.Compare(a.Unit, b.Unit),
+			cmp.Compare(a.Period, b.Period),
+		) < 0
+	})
+
+	if filter.Limit > 0 && len(results) > filter.Limit {
+		results = results[:filter.Limit]
+	}
+	return results, nil
+}
+
+// GetCallCount returns the number of times a method was called
+func (m *PriceRollupRepository) GetCallCount(method string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls[method]
+}
+
+func matchesRollup(key rollupKey, filter repository.RollupFilter) bool {
+	if filter.Category != "" && key.category != filter.Category {
+		return false
+	}
+	if filter.SubCategory != "" && key.subCategory != filter.SubCategory {
+		return false
+	}
+	if filter.Emirate != "" && key.emirate != filter.Emirate {
+		return false
+	}
+	if filter.Area != "" && key.area != filter.Area {
+		return false
+	}
+	if filter.Currency != "" && key.currency != filter.Currency {
+		return false
+	}
+	if filter.Unit != "" && key.unit != filter.Unit {
+		return false
+	}
+	if filter.Period != "" && key.period != filter.Period {
+		return false
+	}
+	if filter.Start != nil && key.bucket.Before(*filter.Start) {
+		return false
+	}
+	if filter.End != nil && key.bucket.After(*filter.End) {
+		return false
+	}
+	return true
+}
+
+// summarise computes the statistics the continuous aggregates carry
+func summarise(interval models.RollupInterval, key rollupKey, prices []float64) *models.PriceRollup {
+	slices.Sort(prices)
+
+	var sum float64
+	for _, p := range prices {
+		sum += p
+	}
+	avg := sum / float64(len(prices))
+
+	var stddev float64
+	if len(prices) > 1 {
+		var squares float64
+		for _, p := range prices {
+			squares += (p - avg) * (p - avg)
+		}
+		stddev = math.Sqrt(squares / float64(len(prices)-1))
+	}
+
+	return &models.PriceRollup{
+		Interval:    interval,
+		Bucket:      key.bucket,
+		Category:    key.category,
+		SubCategory: key.subCategory,
+		Emirate:     key.emirate,
+		Area:        key.area,
+		Currency:    key.currency,
+		Unit:        key.unit,
+		Period:      key.period,
+		Count:       int64(len(prices)),
+		AvgPrice:    avg,
+		MinPrice:    prices[0],
+		MaxPrice:    prices[len(prices)-1],
+		P25Price:    percentileCont(prices, 0.25),
+		MedianPrice: percentileCont(prices, 0.5),
+		P75Price:    percentileCont(prices, 0.75),
+		StdDevPrice: stddev,
+	}
+}
+
+// percentileCont interpolates like PostgreSQL's percentile_cont over sorted
+// values
+func percentileCont(sorted []float64, p float64) float64 {
+	pos := p * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (pos-float64(lower))*(sorted[upper]-sorted[lower])
+}
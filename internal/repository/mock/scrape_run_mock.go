@@ -0,0 +1,151 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+)
+
+// ScrapeRunRepository is a mock implementation of repository.ScrapeRunRepository
+type ScrapeRunRepository struct {
+	mu    sync.RWMutex
+	data  map[string]*models.ScrapeRun
+	calls map[string]int
+}
+
+// NewScrapeRunRepository creates a new mock scrape run repository
+func NewScrapeRunRepository() *ScrapeRunRepository {
+	return &ScrapeRunRepository{
+		data:  make(map[string]*models.ScrapeRun),
+		calls: make(map[string]int),
+	}
+}
+
+// Create implements repository.ScrapeRunRepository
+func (m *ScrapeRunRepository) Create(ctx context.Context, run *models.ScrapeRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["Create"]++
+
+	if run.ID == "" {
+		run.ID = fmt.Sprintf("mock-run-%d", len(m.data)+1)
+	}
+	if run.StartedAt.IsZero() {
+		run.StartedAt = time.Now()
+	}
+	if run.Status == "" {
+		run.Status = models.ScrapeRunRunning
+	}
+
+	run.CreatedAt = time.Now()
+	run.UpdatedAt = time.Now()
+
+	stored := *run
+	m.data[run.ID] = &stored
+
+	return nil
+}
+
+// Update implements repository.ScrapeRunRepository
+func (m *ScrapeRunRepository) Update(ctx context.Context, run *models.ScrapeRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls["Update"]++
+
+	if _, exists := m.data[run.ID]; !exists {
+		return repository.NotFound("scrape run")
+	}
+
+	run.UpdatedAt = time.Now()
+	stored := *run
+	m.data[run.ID] = &stored
+
+	return nil
+}
+
+// GetByID implements repository.ScrapeRunRepository
+func (m *ScrapeRunRepository) GetByID(ctx context.Context, id string) (*models.ScrapeRun, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.calls["GetByID"]++
+
+	run, exists := m.data[id]
+	if !exists {
+		return nil, repository.NotFound("scrape run")
+	}
+
+	copied := *run
+	return &copied, nil
+}
+
+// List implements repository.ScrapeRunRepository
+func (m *ScrapeRunRepository) List(ctx context.Context, filter repository.ScrapeRunFilter) ([]*models.ScrapeRun, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.calls["List"]++
+
+	var results []*models.ScrapeRun
+	for _, run := range m.data {
+		if filter.ScraperName != "" && run.ScraperName != filter.ScraperName {
+			continue
+		}
+		if len(filter.Statuses) > 0 && !containsStatus(filter.Statuses, run.Status) {
+			continue
+		}
+		if filter.WorkflowID != "" && run.WorkflowID != filter.WorkflowID {
+			continue
+		}
+		if filter.WorkflowRunID != "" && run.WorkflowRunID != filter.WorkflowRunID {
+			continue
+		}
+		if filter.StartedAfter != nil && run.StartedAt.Before(*filter.StartedAfter) {
+			continue
+		}
+
+		copied := *run
+		results = append(results, &copied)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].StartedAt.After(results[j].StartedAt)
+	})
+
+	if filter.Limit > 0 && len(results) > filter.Limit {
+		results = results[:filter.Limit]
+	}
+
+	return results, nil
+}
+
+// GetCallCount returns the number of times a method was called
+func (m *ScrapeRunRepository) GetCallCount(method string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.calls[method]
+}
+
+// Reset clears all data and call counts
+func (m *ScrapeRunRepository) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = make(map[string]*models.ScrapeRun)
+	m.calls = make(map[string]int)
+}
+
+func containsStatus(statuses []models.ScrapeRunStatus, status models.ScrapeRunStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+)
+
+// APIKeyRepository implements the repository.APIKeyRepository interface
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates a new instance of APIKeyRepository
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+const apiKeyColumns = `id, name, prefix, key_hash, role, created_by, created_at, last_used_at, revoked_at`
+
+// Create stores a new key and assigns its ID
+func (r *APIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, role, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		key.Name,
+		key.Prefix,
+		key.Hash,
+		string(key.Role),
+		nullString(key.CreatedBy),
+	).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// GetByID retrieves a key by ID, including revoked keys
+func (r *APIKeyRepository) GetByID(ctx context.Context, id string) (*models.APIKey, error) {
+	return r.get(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id)
+}
+
+// GetByPrefix retrieves a key by its public prefix, including revoked keys
+func (r *APIKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*models.APIKey, error) {
+	return r.get(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix = $1`, prefix)
+}
+
+func (r *APIKeyRepository) get(ctx context.Context, query string, arg string) (*models.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRowContext(ctx, query, arg))
+	if err == sql.ErrNoRows {
+		return nil, repository.NotFound("api key")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get api key: %w", classifyError(err))
+	}
+	return key, nil
+}
+
+// List retrieves every key, newest first
+func (r *APIKeyRepository) List(ctx context.Context) ([]*models.APIKey, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var results []*models.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", classifyError(err))
+		}
+		results = append(results, key)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", classifyError(err))
+	}
+
+	return results, nil
+}
+
+// Revoke sets revoked_at on a key that is not already revoked
+func (r *APIKeyRepository) Revoke(ctx context.Context, id string, revokedAt time.Time) error {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`,
+		revokedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", classifyError(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", classifyError(err))
+	}
+	if rowsAffected == 0 {
+		return repository.NotFound("api key")
+	}
+
+	return nil
+}
+
+// Touch records when a key was last used
+func (r *APIKeyRepository) Touch(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`, usedAt, id)
+	if err != nil {
+		return fmt.Errorf("failed to touch api key: %w", classifyError(err))
+	}
+	return nil
+}
+
+// scanAPIKey scans a row selected with apiKeyColumns
+func scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	var role string
+	var createdBy sql.NullString
+	var lastUsedAt, revokedAt sql.NullTime
+
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.Hash,
+		&role,
+		&createdBy,
+		&key.CreatedAt,
+		&lastUsedAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Role = models.Role(role)
+	key.CreatedBy = createdBy.String
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+
+	return key, nil
+}
@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+)
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	defer func() {
+		_, _ = db.Exec("DELETE FROM api_keys WHERE name = 'test key'")
+	}()
+
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	key := &models.APIKey{
+		Name:      "test key",
+		Prefix:    "7e57a91c",
+		Hash:      "deadbeef",
+		Role:      models.RoleContributor,
+		CreatedBy: "apikey:a1b2c3d4",
+	}
+	if err := repo.Create(ctx, key); err != nil {
+		t.Fatalf("Failed to create api key: %v", err)
+	}
+	if key.ID == "" {
+		t.Fatal("Expected key ID to be generated")
+	}
+
+	duplicate := &models.APIKey{Name: "test key", Prefix: key.Prefix, Hash: "cafe", Role: models.RoleReader}
+	if err := repo.Create(ctx, duplicate); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("Expected conflict for duplicate prefix, got %v", err)
+	}
+
+	invalid := &models.APIKey{Name: "test key", Prefix: "7e57a91d", Hash: "cafe", Role: models.Role("owner")}
+	if err := repo.Create(ctx, invalid); !errors.Is(err, repository.ErrValidationFailed) {
+		t.Errorf("Expected unknown role to fail validation, got %v", err)
+	}
+
+	got, err := repo.GetByPrefix(ctx, key.Prefix)
+	if err != nil {
+		t.Fatalf("Failed to get api key: %v", err)
+	}
+	if got.ID != key.ID || got.Hash != "deadbeef" || got.Role != models.RoleContributor || got.CreatedBy != "apikey:a1b2c3d4" {
+		t.Errorf("Unexpected api key: %+v", got)
+	}
+	if got.LastUsedAt != nil || got.Revoked() {
+		t.Errorf("Expected a new key to be unused and active: %+v", got)
+	}
+
+	usedAt := time.Now().UTC().Truncate(time.Microsecond)
+	if err := repo.Touch(ctx, key.ID, usedAt); err != nil {
+		t.Fatalf("Failed to touch api key: %v", err)
+	}
+	if err := repo.Revoke(ctx, key.ID, usedAt); err != nil {
+		t.Fatalf("Failed to revoke api key: %v", err)
+	}
+	if err := repo.Revoke(ctx, key.ID, usedAt); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected revoking twice to be not found, got %v", err)
+	}
+
+	got, err = repo.GetByID(ctx, key.ID)
+	if err != nil {
+		t.Fatalf("Failed to get api key: %v", err)
+	}
+	if got.LastUsedAt == nil || !got.LastUsedAt.Equal(usedAt) {
+		t.Errorf("Expected last_used_at %v, got %v", usedAt, got.LastUsedAt)
+	}
+	if !got.Revoked() {
+		t.Error("Expected key to be revoked")
+	}
+
+	if _, err := repo.GetByID(ctx, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+}
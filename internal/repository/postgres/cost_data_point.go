@@ -4,11 +4,16 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/adonese/cost-of-living/internal/models"
 	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/google/uuid"
 	"github.com/lib/pq"
 )
 
@@ -22,61 +27,340 @@ func NewCostDataPointRepository(db *sql.DB) *CostDataPointRepository {
 	return &CostDataPointRepository{db: db}
 }
 
+// insertCostDataPointQuery inserts a single cost data point
+const insertCostDataPointQuery = `
+		INSERT INTO cost_data_points (
+			id, category, sub_category, item_name, price, min_price, max_price,
+			median_price, sample_size, location, recorded_at, valid_from, valid_to,
+			source, source_url, confidence, currency, unit, period, tags, attributes, run_id,
+			natural_key, first_seen_at, last_seen_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21,
+			$22, $23, $24, $25
+		)
+		RETURNING created_at, updated_at
+	`
+
 // Create inserts a new cost data point into the database
 func (r *CostDataPointRepository) Create(ctx context.Context, cdp *models.CostDataPoint) error {
-	// Generate UUID if not provided
-	if cdp.ID == "" {
-		var id string
-		err := r.db.QueryRowContext(ctx, "SELECT uuid_generate_v4()").Scan(&id)
+	row, err := prepareInsert(cdp)
+	if err != nil {
+		return err
+	}
+
+	err = r.db.QueryRowContext(ctx, insertCostDataPointQuery, row.args()...).
+		Scan(&cdp.CreatedAt, &cdp.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create cost data point: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// CreateBatch inserts many cost data points in a single transaction using
+// COPY. COPY is all-or-nothing, so when it fails the batch is retried row by
+// row behind savepoints and only the offending rows are reported.
+func (r *CostDataPointRepository) CreateBatch(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	result := &repository.BatchResult{}
+
+	rows := make([]insertRow, 0, len(cdps))
+	for i, cdp := range cdps {
+		row, err := prepareInsert(cdp)
 		if err != nil {
-			return fmt.Errorf("failed to generate UUID: %w", err)
+			result.Failed = append(result.Failed, repository.BatchError{Index: i, Err: err})
+			continue
 		}
-		cdp.ID = id
+		row.index = i
+		rows = append(rows, row)
 	}
 
-	// Set default values if not provided
-	if cdp.RecordedAt.IsZero() {
-		cdp.RecordedAt = time.Now()
+	if len(rows) == 0 {
+		return result, nil
 	}
-	if cdp.ValidFrom.IsZero() {
-		cdp.ValidFrom = time.Now()
+
+	copyErr := r.copyRows(ctx, rows)
+	if copyErr == nil {
+		result.Inserted = len(rows)
+		return result, nil
 	}
-	if cdp.SampleSize == 0 {
-		cdp.SampleSize = 1
+
+	written, err := r.writeRows(ctx, rows, func(tx *sql.Tx, row insertRow) (bool, error) {
+		return false, insertTx(ctx, tx, row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cost data points (copy: %v): %w", copyErr, classifyError(err))
 	}
-	if cdp.Confidence == 0 {
-		cdp.Confidence = 1.0
+
+	result.Inserted = written.Inserted
+	result.Failed = mergeBatchErrors(result.Failed, written.Failed)
+
+	return result, nil
+}
+
+// Upsert writes cost data points keyed on their natural key. When an active
+// row with the same key exists and its price is unchanged, that row is
+// refreshed in place (updated_at, valid_to, attributes); when the price has
+// changed the old row is closed at the new row's valid_from and a new row is
+// inserted, preserving price history. Writers are serialised per key with a
+// transaction-scoped advisory lock because the hypertable cannot carry a
+// unique constraint on the key alone.
+func (r *CostDataPointRepository) Upsert(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	result := &repository.BatchResult{}
+
+	rows := make([]insertRow, 0, len(cdps))
+	for i, cdp := range cdps {
+		row, err := prepareInsert(cdp)
+		if err != nil {
+			result.Failed = append(result.Failed, repository.BatchError{Index: i, Err: err})
+			continue
+		}
+		row.index = i
+		rows = append(rows, row)
 	}
-	if cdp.Unit == "" {
-		cdp.Unit = "AED"
+
+	if len(rows) == 0 {
+		return result, nil
 	}
 
-	// Marshal location to JSON
-	locationJSON, err := json.Marshal(cdp.Location)
+	written, err := r.writeRows(ctx, rows, func(tx *sql.Tx, row insertRow) (bool, error) {
+		return upsertTx(ctx, tx, row)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal location: %w", err)
+		return nil, fmt.Errorf("failed to upsert cost data points: %w", classifyError(err))
 	}
 
-	// Marshal attributes to JSON
-	attributesJSON, err := json.Marshal(cdp.Attributes)
+	result.Inserted = written.Inserted
+	result.Updated = written.Updated
+	result.Failed = mergeBatchErrors(result.Failed, written.Failed)
+
+	return result, nil
+}
+
+// upsertTx writes a single row by natural key within tx and reports whether
+// an existing row was refreshed in place
+func upsertTx(ctx context.Context, tx *sql.Tx, row insertRow) (bool, error) {
+	cdp := row.cdp
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", row.naturalKey); err != nil {
+		return false, fmt.Errorf("failed to lock natural key: %w", classifyError(err))
+	}
+
+	var existingID string
+	var existingRecordedAt, existingFirstSeen time.Time
+	var existingPrice float64
+	err := tx.QueryRowContext(ctx, `
+		SELECT id, recorded_at, price, first_seen_at
+		FROM cost_data_points
+		WHERE natural_key = $1 AND (valid_to IS NULL OR valid_to > NOW()) AND deleted_at IS NULL
+		ORDER BY recorded_at DESC
+		LIMIT 1
+		FOR UPDATE
+	`, row.naturalKey).Scan(&existingID, &existingRecordedAt, &existingPrice, &existingFirstSeen)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return false, insertTx(ctx, tx, row)
+
+	case err != nil:
+		return false, fmt.Errorf("failed to look up natural key: %w", classifyError(err))
+
+	case existingPrice != cdp.Price:
+		// Price moved: close the current row and start a new one. The listing
+		// itself is not new, so it keeps its original first-seen time. The
+		// closing run is recorded so compensating it reopens the row.
+		cdp.FirstSeenAt = existingFirstSeen
+		_, err := tx.ExecContext(ctx, `
+			UPDATE cost_data_points SET valid_to = $3, closed_by_run_id = $4
+			WHERE id = $1 AND recorded_at = $2
+		`, existingID, existingRecordedAt, cdp.ValidFrom, nullString(cdp.RunID))
+		if err != nil {
+			return false, fmt.Errorf("failed to close previous cost data point: %w", classifyError(err))
+		}
+		return false, insertTx(ctx, tx, row)
+	}
+
+	// Same listing at the same price: refresh the existing row and mark it seen. run_id is left
+	// untouched so compensating a later run never invalidates rows it did not create.
+	var runID sql.NullString
+	err = tx.QueryRowContext(ctx, `
+		UPDATE cost_data_points SET
+			min_price = $3,
+			max_price = $4,
+			median_price = $5,
+			sample_size = $6,
+			confidence = $7,
+			source_url = $8,
+			tags = $9,
+			attributes = $10,
+			valid_to = $11,
+			last_seen_at = $12
+		WHERE id = $1 AND recorded_at = $2
+		RETURNING valid_from, run_id, first_seen_at, created_at, updated_at
+	`,
+		existingID,
+		existingRecordedAt,
+		nullFloat64(cdp.MinPrice),
+		nullFloat64(cdp.MaxPrice),
+		nullFloat64(cdp.MedianPrice),
+		cdp.SampleSize,
+		cdp.Confidence,
+		nullString(cdp.SourceURL),
+		pq.Array(cdp.Tags),
+		row.attributes,
+		nullTime(cdp.ValidTo),
+		cdp.LastSeenAt,
+	).Scan(&cdp.ValidFrom, &runID, &cdp.FirstSeenAt, &cdp.CreatedAt, &cdp.UpdatedAt)
 	if err != nil {
-		return fmt.Errorf("failed to marshal attributes: %w", err)
+		return false, fmt.Errorf("failed to refresh cost data point: %w", classifyError(err))
 	}
 
-	query := `
-		INSERT INTO cost_data_points (
-			id, category, sub_category, item_name, price, min_price, max_price,
-			median_price, sample_size, location, recorded_at, valid_from, valid_to,
-			source, source_url, confidence, unit, tags, attributes
-		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19
-		)
-		RETURNING created_at, updated_at
-	`
+	cdp.ID = existingID
+	cdp.RecordedAt = existingRecordedAt
+	cdp.RunID = runID.String
 
-	err = r.db.QueryRowContext(
-		ctx,
-		query,
+	return true, nil
+}
+
+// mergeBatchErrors combines row failures and orders them by input position
+func mergeBatchErrors(a, b []repository.BatchError) []repository.BatchError {
+	merged := append(a, b...)
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Index < merged[j].Index
+	})
+	return merged
+}
+
+// copyColumns are the columns written by copyRows, in insertRow.copyArgs order
+var copyColumns = []string{
+	"id", "category", "sub_category", "item_name", "price", "min_price", "max_price",
+	"median_price", "sample_size", "location", "recorded_at", "valid_from", "valid_to",
+	"source", "source_url", "confidence", "currency", "unit", "period", "tags", "attributes",
+	"run_id", "natural_key", "first_seen_at", "last_seen_at", "created_at", "updated_at",
+}
+
+// copyRows streams rows into cost_data_points with COPY inside a transaction
+func (r *CostDataPointRepository) copyRows(ctx context.Context, rows []insertRow) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn("cost_data_points", copyColumns...))
+	if err != nil {
+		return fmt.Errorf("failed to prepare copy: %w", classifyError(err))
+	}
+
+	now := time.Now()
+	for _, row := range rows {
+		if _, err := stmt.ExecContext(ctx, row.copyArgs(now)...); err != nil {
+			stmt.Close()
+			return fmt.Errorf("failed to copy row %d: %w", row.index, classifyError(err))
+		}
+	}
+
+	if _, err := stmt.ExecContext(ctx); err != nil {
+		stmt.Close()
+		return fmt.Errorf("failed to flush copy: %w", classifyError(err))
+	}
+	if err := stmt.Close(); err != nil {
+		return fmt.Errorf("failed to close copy: %w", classifyError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit copy: %w", classifyError(err))
+	}
+
+	for _, row := range rows {
+		row.cdp.CreatedAt = now
+		row.cdp.UpdatedAt = now
+	}
+
+	return nil
+}
+
+// writeRows applies write to each row inside one transaction, rolling back to
+// a savepoint for each row that fails so the remaining rows are kept. write
+// reports whether the row updated an existing record rather than inserting.
+func (r *CostDataPointRepository) writeRows(
+	ctx context.Context,
+	rows []insertRow,
+	write func(tx *sql.Tx, row insertRow) (bool, error),
+) (*repository.BatchResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback()
+
+	result := &repository.BatchResult{}
+	for _, row := range rows {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_row"); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", classifyError(err))
+		}
+
+		updated, err := write(tx, row)
+		if err != nil {
+			if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_row"); rbErr != nil {
+				return nil, fmt.Errorf("failed to roll back savepoint: %w", classifyError(rbErr))
+			}
+			result.Failed = append(result.Failed, repository.BatchError{Index: row.index, Err: err})
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_row"); err != nil {
+			return nil, fmt.Errorf("failed to release savepoint: %w", classifyError(err))
+		}
+		if updated {
+			result.Updated++
+		} else {
+			result.Inserted++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+
+	return result, nil
+}
+
+// insertTx inserts a single prepared row within tx
+func insertTx(ctx context.Context, tx *sql.Tx, row insertRow) error {
+	err := tx.QueryRowContext(ctx, insertCostDataPointQuery, row.args()...).
+		Scan(&row.cdp.CreatedAt, &row.cdp.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create cost data point: %w", classifyError(err))
+	}
+	return nil
+}
+
+// insertRow holds a cost data point with its JSON columns already encoded
+type insertRow struct {
+	index      int
+	cdp        *models.CostDataPoint
+	location   []byte
+	attributes []byte
+	naturalKey string
+}
+
+// args returns the parameters for insertCostDataPointQuery, in order
+func (row insertRow) args() []interface{} {
+	return row.values(row.location, row.attributes)
+}
+
+// copyArgs returns the values of a COPY row: the insert parameters followed
+// by created_at and updated_at. COPY encodes []byte as bytea, so the JSONB
+// columns are sent as text.
+func (row insertRow) copyArgs(now time.Time) []interface{} {
+	return append(row.values(string(row.location), string(row.attributes)), now, now)
+}
+
+// values lists the inserted columns in insertCostDataPointQuery order, with
+// the given encodings of the location and attributes JSONB columns
+func (row insertRow) values(location, attributes interface{}) []interface{} {
+	cdp := row.cdp
+	return []interface{}{
 		cdp.ID,
 		cdp.Category,
 		nullString(cdp.SubCategory),
@@ -86,161 +370,235 @@ func (r *CostDataPointRepository) Create(ctx context.Context, cdp *models.CostDa
 		nullFloat64(cdp.MaxPrice),
 		nullFloat64(cdp.MedianPrice),
 		cdp.SampleSize,
-		locationJSON,
+		location,
 		cdp.RecordedAt,
 		cdp.ValidFrom,
 		nullTime(cdp.ValidTo),
 		cdp.Source,
 		nullString(cdp.SourceURL),
 		cdp.Confidence,
+		cdp.Currency,
 		cdp.Unit,
+		cdp.Period,
 		pq.Array(cdp.Tags),
-		attributesJSON,
-	).Scan(&cdp.CreatedAt, &cdp.UpdatedAt)
+		attributes,
+		nullString(cdp.RunID),
+		row.naturalKey,
+		cdp.FirstSeenAt,
+		cdp.LastSeenAt,
+	}
+}
+
+// prepareInsert assigns an ID and default values to cdp and encodes its JSON columns
+func prepareInsert(cdp *models.CostDataPoint) (insertRow, error) {
+	if cdp == nil {
+		return insertRow{}, repository.ValidationFailed(errors.New("cost data point is nil"))
+	}
+
+	// Generate UUID if not provided
+	if cdp.ID == "" {
+		cdp.ID = uuid.NewString()
+	}
+
+	// Set default values if not provided
+	if cdp.RecordedAt.IsZero() {
+		cdp.RecordedAt = time.Now()
+	}
+	if cdp.ValidFrom.IsZero() {
+		cdp.ValidFrom = time.Now()
+	}
+	if cdp.SampleSize == 0 {
+		cdp.SampleSize = 1
+	}
+	if cdp.Confidence == 0 {
+		cdp.Confidence = 1.0
+	}
+	cdp.NormalizeUnit()
+	if cdp.FirstSeenAt.IsZero() {
+		cdp.FirstSeenAt = cdp.RecordedAt
+	}
+	if cdp.LastSeenAt.IsZero() {
+		cdp.LastSeenAt = cdp.RecordedAt
+	}
 
+	// Marshal location to JSON
+	locationJSON, err := json.Marshal(cdp.Location)
 	if err != nil {
-		return fmt.Errorf("failed to create cost data point: %w", err)
+		return insertRow{}, fmt.Errorf("failed to marshal location: %w", err)
 	}
 
-	return nil
+	// Marshal attributes to JSON
+	attributesJSON, err := json.Marshal(cdp.Attributes)
+	if err != nil {
+		return insertRow{}, fmt.Errorf("failed to marshal attributes: %w", err)
+	}
+
+	return insertRow{
+		cdp:        cdp,
+		location:   locationJSON,
+		attributes: attributesJSON,
+		naturalKey: cdp.NaturalKey(),
+	}, nil
 }
 
 // GetByID retrieves a cost data point by ID and recorded_at timestamp
 func (r *CostDataPointRepository) GetByID(ctx context.Context, id string, recordedAt time.Time) (*models.CostDataPoint, error) {
-	query := `
-		SELECT
-			id, category, sub_category, item_name, price, min_price, max_price,
-			median_price, sample_size, location, recorded_at, valid_from, valid_to,
-			source, source_url, confidence, unit, tags, attributes, created_at, updated_at
+	query := `SELECT ` + costDataPointColumns + `
 		FROM cost_data_points
-		WHERE id = $1 AND recorded_at = $2
+		WHERE id = $1 AND recorded_at = $2 AND deleted_at IS NULL
 	`
 
-	cdp := &models.CostDataPoint{}
-	var locationJSON []byte
-	var attributesJSON []byte
-	var subCategory, sourceURL sql.NullString
-	var minPrice, maxPrice, medianPrice sql.NullFloat64
-	var validTo sql.NullTime
-
-	err := r.db.QueryRowContext(ctx, query, id, recordedAt).Scan(
-		&cdp.ID,
-		&cdp.Category,
-		&subCategory,
-		&cdp.ItemName,
-		&cdp.Price,
-		&minPrice,
-		&maxPrice,
-		&medianPrice,
-		&cdp.SampleSize,
-		&locationJSON,
-		&cdp.RecordedAt,
-		&cdp.ValidFrom,
-		&validTo,
-		&cdp.Source,
-		&sourceURL,
-		&cdp.Confidence,
-		&cdp.Unit,
-		pq.Array(&cdp.Tags),
-		&attributesJSON,
-		&cdp.CreatedAt,
-		&cdp.UpdatedAt,
-	)
-
+	cdp, err := scanCostDataPoint(r.db.QueryRowContext(ctx, query, id, recordedAt))
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("cost data point not found")
+		return nil, repository.NotFound("cost data point")
 	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cost data point: %w", err)
+		return nil, fmt.Errorf("failed to get cost data point: %w", classifyError(err))
 	}
 
-	// Unmarshal JSON fields
-	if err := json.Unmarshal(locationJSON, &cdp.Location); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal location: %w", err)
+	return cdp, nil
+}
+
+// List retrieves cost data points based on the provided filter
+func (r *CostDataPointRepository) List(ctx context.Context, filter repository.ListFilter) ([]*models.CostDataPoint, error) {
+	query, args, err := buildListQuery(filter)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(attributesJSON) > 0 {
-		if err := json.Unmarshal(attributesJSON, &cdp.Attributes); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal attributes: %w", err)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cost data points: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var results []*models.CostDataPoint
+
+	for rows.Next() {
+		cdp, err := scanCostDataPoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", classifyError(err))
 		}
+
+		results = append(results, cdp)
 	}
 
-	// Handle nullable fields
-	if subCategory.Valid {
-		cdp.SubCategory = subCategory.String
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", classifyError(err))
 	}
-	if sourceURL.Valid {
-		cdp.SourceURL = sourceURL.String
+
+	return results, nil
+}
+
+// streamFetchSize is the number of rows fetched per round trip by Stream
+const streamFetchSize = 1000
+
+// Stream walks the rows matching the filter through a server-side cursor,
+// fetching streamFetchSize rows at a time, so exports of any size run in
+// constant memory
+func (r *CostDataPointRepository) Stream(ctx context.Context, filter repository.ListFilter, fn func(*models.CostDataPoint) error) error {
+	query, args, err := buildListQuery(filter)
+	if err != nil {
+		return err
 	}
-	if minPrice.Valid {
-		cdp.MinPrice = minPrice.Float64
+
+	// Cursors only live for the duration of a transaction
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", classifyError(err))
 	}
-	if maxPrice.Valid {
-		cdp.MaxPrice = maxPrice.Float64
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DECLARE cost_data_points_stream NO SCROLL CURSOR FOR "+query, args...); err != nil {
+		return fmt.Errorf("failed to declare cursor: %w", classifyError(err))
 	}
-	if medianPrice.Valid {
-		cdp.MedianPrice = medianPrice.Float64
+
+	fetch := fmt.Sprintf("FETCH FORWARD %d FROM cost_data_points_stream", streamFetchSize)
+	for {
+		n, err := streamBatch(ctx, tx, fetch, fn)
+		if err != nil {
+			return err
+		}
+		if n < streamFetchSize {
+			break
+		}
 	}
-	if validTo.Valid {
-		cdp.ValidTo = &validTo.Time
+
+	if _, err := tx.ExecContext(ctx, "CLOSE cost_data_points_stream"); err != nil {
+		return fmt.Errorf("failed to close cursor: %w", classifyError(err))
 	}
 
-	return cdp, nil
+	return tx.Commit()
 }
 
-// List retrieves cost data points based on the provided filter
-func (r *CostDataPointRepository) List(ctx context.Context, filter repository.ListFilter) ([]*models.CostDataPoint, error) {
-	query := `
-		SELECT
-			id, category, sub_category, item_name, price, min_price, max_price,
-			median_price, sample_size, location, recorded_at, valid_from, valid_to,
-			source, source_url, confidence, unit, tags, attributes, created_at, updated_at
-		FROM cost_data_points
-		WHERE 1=1
-	`
+// streamBatch fetches one batch from the cursor and returns how many rows it
+// held
+func streamBatch(ctx context.Context, tx *sql.Tx, fetch string, fn func(*models.CostDataPoint) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch from cursor: %w", classifyError(err))
+	}
+	defer rows.Close()
 
-	args := []interface{}{}
-	argPos := 1
+	n := 0
+	for rows.Next() {
+		cdp, err := scanCostDataPoint(rows)
+		if err != nil {
+			return n, fmt.Errorf("failed to scan row: %w", classifyError(err))
+		}
+		n++
 
-	// Apply filters
-	if filter.ID != "" {
-		query += fmt.Sprintf(" AND id = $%d", argPos)
-		args = append(args, filter.ID)
-		argPos++
+		if err := fn(cdp); err != nil {
+			return n, err
+		}
 	}
 
-	if filter.Category != "" {
-		query += fmt.Sprintf(" AND category = $%d", argPos)
-		args = append(args, filter.Category)
-		argPos++
+	if err := rows.Err(); err != nil {
+		return n, fmt.Errorf("error iterating rows: %w", classifyError(err))
 	}
 
-	if filter.SubCategory != "" {
-		query += fmt.Sprintf(" AND sub_category = $%d", argPos)
-		args = append(args, filter.SubCategory)
-		argPos++
-	}
+	return n, nil
+}
 
-	if filter.Emirate != "" {
-		query += fmt.Sprintf(" AND location->>'emirate' = $%d", argPos)
-		args = append(args, filter.Emirate)
-		argPos++
+// buildListQuery renders the full SELECT for a filter, including cursor
+// position, ordering and pagination
+func buildListQuery(filter repository.ListFilter) (string, []interface{}, error) {
+	if err := filter.Validate(); err != nil {
+		return "", nil, fmt.Errorf("invalid filter: %w", classifyError(err))
 	}
 
-	if filter.StartDate != nil {
-		query += fmt.Sprintf(" AND recorded_at >= $%d", argPos)
-		args = append(args, *filter.StartDate)
-		argPos++
+	where, args, err := buildListWhere(filter)
+	if err != nil {
+		return "", nil, err
 	}
+	argPos := len(args) + 1
 
-	if filter.EndDate != nil {
-		query += fmt.Sprintf(" AND recorded_at <= $%d", argPos)
-		args = append(args, *filter.EndDate)
-		argPos++
+	query := `SELECT ` + costDataPointColumns + `
+		FROM cost_data_points
+		WHERE 1=1` + where
+
+	// Keyset pagination: continue strictly after the cursor row
+	if filter.After != nil {
+		op := "<"
+		if filter.OrderDirection == repository.SortAsc {
+			op = ">"
+		}
+		query += fmt.Sprintf(" AND (recorded_at, id) %s ($%d, $%d)", op, argPos, argPos+1)
+		args = append(args, filter.After.RecordedAt, filter.After.ID)
+		argPos += 2
 	}
 
-	// Order by recorded_at DESC (most recent first)
-	query += " ORDER BY recorded_at DESC"
+	// Order by the requested field, most recent first by default. The id
+	// tie-breaker keeps pagination stable when sort values repeat.
+	orderBy := "recorded_at"
+	if filter.OrderBy != "" {
+		orderBy = filter.OrderBy
+	}
+	direction := "DESC"
+	if filter.OrderDirection == repository.SortAsc {
+		direction = "ASC"
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", orderBy, direction, direction)
 
 	// Apply pagination
 	if filter.Limit > 0 {
@@ -254,93 +612,169 @@ func (r *CostDataPointRepository) List(ctx context.Context, filter repository.Li
 		args = append(args, filter.Offset)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
+	return query, args, nil
+}
+
+// Count returns the number of cost data points matching the filter
+func (r *CostDataPointRepository) Count(ctx context.Context, filter repository.ListFilter) (int64, error) {
+	if err := filter.Validate(); err != nil {
+		return 0, fmt.Errorf("invalid filter: %w", classifyError(err))
+	}
+
+	where, args, err := buildListWhere(filter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list cost data points: %w", err)
+		return 0, err
 	}
-	defer rows.Close()
 
-	var results []*models.CostDataPoint
+	var count int64
+	query := `SELECT COUNT(*) FROM cost_data_points WHERE 1=1` + where
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count cost data points: %w", classifyError(err))
+	}
 
-	for rows.Next() {
-		cdp := &models.CostDataPoint{}
-		var locationJSON []byte
-		var attributesJSON []byte
-		var subCategory, sourceURL sql.NullString
-		var minPrice, maxPrice, medianPrice sql.NullFloat64
-		var validTo sql.NullTime
-
-		err := rows.Scan(
-			&cdp.ID,
-			&cdp.Category,
-			&subCategory,
-			&cdp.ItemName,
-			&cdp.Price,
-			&minPrice,
-			&maxPrice,
-			&medianPrice,
-			&cdp.SampleSize,
-			&locationJSON,
-			&cdp.RecordedAt,
-			&cdp.ValidFrom,
-			&validTo,
-			&cdp.Source,
-			&sourceURL,
-			&cdp.Confidence,
-			&cdp.Unit,
-			pq.Array(&cdp.Tags),
-			&attributesJSON,
-			&cdp.CreatedAt,
-			&cdp.UpdatedAt,
-		)
+	return count, nil
+}
 
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan row: %w", err)
-		}
+// Coordinate expressions, written exactly as indexed by migration 010
+const (
+	coordinatesLat   = "(location->'coordinates'->>'lat')::DOUBLE PRECISION"
+	coordinatesLon   = "(location->'coordinates'->>'lon')::DOUBLE PRECISION"
+	coordinatesEarth = "ll_to_earth(" + coordinatesLat + ", " + coordinatesLon + ")"
+)
 
-		// Unmarshal JSON fields
-		if err := json.Unmarshal(locationJSON, &cdp.Location); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal location: %w", err)
-		}
+// buildListWhere renders the filter as " AND ..." predicates with positional
+// arguments starting at $1. Ordering and pagination are left to the caller.
+func buildListWhere(filter repository.ListFilter) (string, []interface{}, error) {
+	var where strings.Builder
+	args := []interface{}{}
+	argPos := 1
 
-		if len(attributesJSON) > 0 {
-			if err := json.Unmarshal(attributesJSON, &cdp.Attributes); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal attributes: %w", err)
-			}
-		}
+	add := func(predicate string, arg interface{}) {
+		fmt.Fprintf(&where, " AND "+predicate, argPos)
+		args = append(args, arg)
+		argPos++
+	}
 
-		// Handle nullable fields
-		if subCategory.Valid {
-			cdp.SubCategory = subCategory.String
-		}
-		if sourceURL.Valid {
-			cdp.SourceURL = sourceURL.String
-		}
-		if minPrice.Valid {
-			cdp.MinPrice = minPrice.Float64
-		}
-		if maxPrice.Valid {
-			cdp.MaxPrice = maxPrice.Float64
+	if filter.ID != "" {
+		add("id = $%d", filter.ID)
+	}
+	if filter.Category != "" {
+		add("category = $%d", filter.Category)
+	}
+	if filter.SubCategory != "" {
+		add("sub_category = $%d", filter.SubCategory)
+	}
+	if filter.Source != "" {
+		add("source = $%d", filter.Source)
+	}
+	if filter.Emirate != "" {
+		add("location->>'emirate' = $%d", filter.Emirate)
+	}
+	if filter.City != "" {
+		add("location->>'city' = $%d", filter.City)
+	}
+	if filter.Area != "" {
+		add("location->>'area' = $%d", filter.Area)
+	}
+	if len(filter.Tags) > 0 {
+		if filter.TagMatch == repository.TagMatchAll {
+			add("tags @> $%d", pq.Array(filter.Tags))
+		} else {
+			add("tags && $%d", pq.Array(filter.Tags))
 		}
-		if medianPrice.Valid {
-			cdp.MedianPrice = medianPrice.Float64
+	}
+	if filter.MinPrice != nil {
+		add("price >= $%d", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		add("price <= $%d", *filter.MaxPrice)
+	}
+
+	// Attribute equality uses JSONB containment so the GIN index on
+	// attributes applies. Scrapers store some values as strings and others as
+	// numbers, so numeric and boolean values match either representation.
+	keys := make([]string, 0, len(filter.Attributes))
+	for key := range filter.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		candidates, err := attributeCandidates(key, filter.Attributes[key])
+		if err != nil {
+			return "", nil, err
 		}
-		if validTo.Valid {
-			cdp.ValidTo = &validTo.Time
+		clauses := make([]string, len(candidates))
+		for i, candidate := range candidates {
+			clauses[i] = fmt.Sprintf("attributes @> $%d", argPos)
+			args = append(args, candidate)
+			argPos++
 		}
+		where.WriteString(" AND (" + strings.Join(clauses, " OR ") + ")")
+	}
 
-		results = append(results, cdp)
+	// Radius searches match the earthdistance GiST index from migration 010:
+	// earth_box narrows to the index, earth_distance drops the box corners
+	if filter.Near != nil {
+		center := fmt.Sprintf("ll_to_earth($%d, $%d)", argPos, argPos+1)
+		fmt.Fprintf(&where, " AND earth_box(%s, $%d) @> %s AND earth_distance(%s, %s) <= $%d",
+			center, argPos+2, coordinatesEarth, center, coordinatesEarth, argPos+2)
+		args = append(args, filter.Near.Center.Lat, filter.Near.Center.Lon, filter.Near.RadiusKm*1000)
+		argPos += 3
+	}
+	if filter.Within != nil {
+		fmt.Fprintf(&where, " AND %s BETWEEN $%d AND $%d AND %s BETWEEN $%d AND $%d",
+			coordinatesLat, argPos, argPos+1, coordinatesLon, argPos+2, argPos+3)
+		args = append(args, filter.Within.MinLat, filter.Within.MaxLat, filter.Within.MinLon, filter.Within.MaxLon)
+		argPos += 4
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating rows: %w", err)
+	if filter.RunID != "" {
+		add("run_id = $%d", filter.RunID)
+	}
+	if filter.ActiveOnly {
+		where.WriteString(" AND (valid_to IS NULL OR valid_to > NOW())")
+	}
+	if filter.StartDate != nil {
+		add("recorded_at >= $%d", *filter.StartDate)
+	}
+	if filter.EndDate != nil {
+		add("recorded_at <= $%d", *filter.EndDate)
+	}
+	if !filter.IncludeDeleted {
+		where.WriteString(" AND deleted_at IS NULL")
 	}
 
-	return results, nil
+	return where.String(), args, nil
+}
+
+// attributeCandidates returns the JSONB documents an attribute filter value
+// may be stored as
+func attributeCandidates(key, value string) ([]string, error) {
+	values := []interface{}{value}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		values = append(values, f)
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		values = append(values, b)
+	}
+
+	candidates := make([]string, 0, len(values))
+	for _, v := range values {
+		doc, err := json.Marshal(map[string]interface{}{key: v})
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal attribute filter: %w", err)
+		}
+		candidates = append(candidates, string(doc))
+	}
+	return candidates, nil
 }
 
-// Update updates an existing cost data point
+// Update updates an existing cost data point and records a revision of the
+// change in the same transaction. The natural key is recomputed, so a later
+// Upsert of the edited listing finds the row.
 func (r *CostDataPointRepository) Update(ctx context.Context, cdp *models.CostDataPoint) error {
+	cdp.NormalizeUnit()
+
 	// Marshal location to JSON
 	locationJSON, err := json.Marshal(cdp.Location)
 	if err != nil {
@@ -353,6 +787,25 @@ func (r *CostDataPointRepository) Update(ctx context.Context, cdp *models.CostDa
 		return fmt.Errorf("failed to marshal attributes: %w", err)
 	}
 
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback()
+
+	// Lock the current version so the revision reflects what was replaced
+	old, err := scanCostDataPoint(tx.QueryRowContext(ctx, `SELECT `+costDataPointColumns+`
+		FROM cost_data_points
+		WHERE id = $1 AND recorded_at = $2 AND deleted_at IS NULL
+		FOR UPDATE
+	`, cdp.ID, cdp.RecordedAt))
+	if err == sql.ErrNoRows {
+		return repository.NotFound("cost data point")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get cost data point: %w", classifyError(err))
+	}
+
 	query := `
 		UPDATE cost_data_points SET
 			category = $1,
@@ -369,13 +822,18 @@ func (r *CostDataPointRepository) Update(ctx context.Context, cdp *models.CostDa
 			source = $12,
 			source_url = $13,
 			confidence = $14,
-			unit = $15,
-			tags = $16,
-			attributes = $17
-		WHERE id = $18 AND recorded_at = $19
+			currency = $15,
+			unit = $16,
+			period = $17,
+			tags = $18,
+			attributes = $19,
+			run_id = $20,
+			natural_key = $21
+		WHERE id = $22 AND recorded_at = $23
+		RETURNING updated_at
 	`
 
-	result, err := r.db.ExecContext(
+	err = tx.QueryRowContext(
 		ctx,
 		query,
 		cdp.Category,
@@ -392,48 +850,344 @@ func (r *CostDataPointRepository) Update(ctx context.Context, cdp *models.CostDa
 		cdp.Source,
 		nullString(cdp.SourceURL),
 		cdp.Confidence,
+		cdp.Currency,
 		cdp.Unit,
+		cdp.Period,
 		pq.Array(cdp.Tags),
 		attributesJSON,
+		nullString(cdp.RunID),
+		cdp.NaturalKey(),
 		cdp.ID,
 		cdp.RecordedAt,
-	)
+	).Scan(&cdp.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return repository.NotFound("cost data point")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update cost data point: %w", classifyError(err))
+	}
+
+	audit := repository.AuditFromContext(ctx)
+	if err := insertRevision(ctx, tx, models.NewRevision(models.RevisionUpdate, old, cdp, audit.Actor, audit.Reason)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit update: %w", classifyError(err))
+	}
+
+	return nil
+}
 
+// Delete removes a cost data point by ID and recorded_at timestamp and
+// records a revision holding the removed values
+func (r *CostDataPointRepository) Delete(ctx context.Context, id string, recordedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("failed to update cost data point: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", classifyError(err))
 	}
+	defer tx.Rollback()
 
-	rowsAffected, err := result.RowsAffected()
+	query := `DELETE FROM cost_data_points WHERE id = $1 AND recorded_at = $2
+		RETURNING ` + costDataPointColumns
+
+	old, err := scanCostDataPoint(tx.QueryRowContext(ctx, query, id, recordedAt))
+	if err == sql.ErrNoRows {
+		return repository.NotFound("cost data point")
+	}
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return fmt.Errorf("failed to delete cost data point: %w", classifyError(err))
+	}
+
+	audit := repository.AuditFromContext(ctx)
+	if err := insertRevision(ctx, tx, models.NewRevision(models.RevisionDelete, old, nil, audit.Actor, audit.Reason)); err != nil {
+		return err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("cost data point not found")
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit delete: %w", classifyError(err))
 	}
 
 	return nil
 }
 
-// Delete removes a cost data point by ID and recorded_at timestamp
-func (r *CostDataPointRepository) Delete(ctx context.Context, id string, recordedAt time.Time) error {
-	query := `DELETE FROM cost_data_points WHERE id = $1 AND recorded_at = $2`
+// SoftDelete hides a cost data point from reads by setting deleted_at and
+// records a delete revision
+func (r *CostDataPointRepository) SoftDelete(ctx context.Context, id string, recordedAt time.Time) error {
+	_, err := r.setDeletedAt(ctx, id, recordedAt, models.RevisionDelete)
+	return err
+}
+
+// Restore clears deleted_at on a soft-deleted cost data point and records a
+// restore revision
+func (r *CostDataPointRepository) Restore(ctx context.Context, id string, recordedAt time.Time) (*models.CostDataPoint, error) {
+	return r.setDeletedAt(ctx, id, recordedAt, models.RevisionRestore)
+}
+
+// setDeletedAt soft deletes (RevisionDelete) or restores (RevisionRestore) a
+// cost data point in a transaction that also records the revision
+func (r *CostDataPointRepository) setDeletedAt(ctx context.Context, id string, recordedAt time.Time, action models.RevisionAction) (*models.CostDataPoint, error) {
+	current, deletedAt := "deleted_at IS NULL", "NOW()"
+	if action == models.RevisionRestore {
+		current, deletedAt = "deleted_at IS NOT NULL", "NULL"
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback()
+
+	old, err := scanCostDataPoint(tx.QueryRowContext(ctx, `SELECT `+costDataPointColumns+`
+		FROM cost_data_points
+		WHERE id = $1 AND recorded_at = $2 AND `+current+`
+		FOR UPDATE
+	`, id, recordedAt))
+	if err == sql.ErrNoRows {
+		return nil, repository.NotFound("cost data point")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost data point: %w", classifyError(err))
+	}
+
+	cdp, err := scanCostDataPoint(tx.QueryRowContext(ctx, `
+		UPDATE cost_data_points SET deleted_at = `+deletedAt+`
+		WHERE id = $1 AND recorded_at = $2
+		RETURNING `+costDataPointColumns, id, recordedAt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s cost data point: %w", action, classifyError(err))
+	}
+
+	audit := repository.AuditFromContext(ctx)
+	if err := insertRevision(ctx, tx, models.NewRevision(action, old, cdp, audit.Actor, audit.Reason)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit %s: %w", action, classifyError(err))
+	}
+
+	return cdp, nil
+}
+
+// InvalidateByRunID closes valid_to on every still-valid data point written
+// by the given run and reopens the rows the run superseded
+func (r *CostDataPointRepository) InvalidateByRunID(ctx context.Context, runID string, validTo time.Time) (int64, error) {
+	query := `
+		UPDATE cost_data_points SET valid_to = $2
+		WHERE run_id = $1 AND (valid_to IS NULL OR valid_to > $2)
+	`
+
+	rows, err := r.compensateRun(ctx, runID, query, runID, validTo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to invalidate run data: %w", err)
+	}
+	return rows, nil
+}
 
-	result, err := r.db.ExecContext(ctx, query, id, recordedAt)
+// DeleteByRunID removes every data point written by the given run and
+// reopens the rows the run superseded
+func (r *CostDataPointRepository) DeleteByRunID(ctx context.Context, runID string) (int64, error) {
+	rows, err := r.compensateRun(ctx, runID, `DELETE FROM cost_data_points WHERE run_id = $1`, runID)
 	if err != nil {
-		return fmt.Errorf("failed to delete cost data point: %w", err)
+		return 0, fmt.Errorf("failed to delete run data: %w", err)
 	}
+	return rows, nil
+}
+
+// reopenClosedByRunQuery reopens the rows closed by a run's upserts. A row is
+// left closed when its listing has another active row not written by the run,
+// such as one from a later run.
+const reopenClosedByRunQuery = `
+		UPDATE cost_data_points SET valid_to = NULL, closed_by_run_id = NULL
+		WHERE closed_by_run_id = $1
+			AND run_id IS DISTINCT FROM $1
+			AND deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM cost_data_points newer
+				WHERE newer.natural_key = cost_data_points.natural_key
+					AND (newer.id, newer.recorded_at) <> (cost_data_points.id, cost_data_points.recorded_at)
+					AND newer.run_id IS DISTINCT FROM $1
+					AND (newer.valid_to IS NULL OR newer.valid_to > NOW())
+					AND newer.deleted_at IS NULL
+			)
+	`
+
+// compensateRun applies query to the rows of a run and reopens the rows the
+// run closed in one transaction, returning the number of rows affected by
+// both
+func (r *CostDataPointRepository) compensateRun(ctx context.Context, runID, query string, args ...interface{}) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback()
 
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, classifyError(err)
+	}
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+		return 0, fmt.Errorf("failed to get rows affected: %w", classifyError(err))
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("cost data point not found")
+	result, err = tx.ExecContext(ctx, reopenClosedByRunQuery, runID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reopen superseded rows: %w", classifyError(err))
+	}
+	reopened, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", classifyError(err))
 	}
 
-	return nil
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+
+	return rowsAffected + reopened, nil
+}
+
+// CloseUnseenListings closes still-valid listings in scope that were last seen before seenBefore
+func (r *CostDataPointRepository) CloseUnseenListings(ctx context.Context, scope repository.ListingScope, seenBefore, validTo time.Time) (int64, error) {
+	query := `
+		UPDATE cost_data_points SET valid_to = $1
+		WHERE (valid_to IS NULL OR valid_to > $1)
+			AND last_seen_at < $2
+			AND natural_key LIKE source || '|listing|%'
+	`
+
+	args := []interface{}{validTo, seenBefore}
+	argPos := 3
+
+	if scope.Source != "" {
+		query += fmt.Sprintf(" AND source = $%d", argPos)
+		args = append(args, scope.Source)
+		argPos++
+	}
+
+	if scope.Category != "" {
+		query += fmt.Sprintf(" AND category = $%d", argPos)
+		args = append(args, scope.Category)
+		argPos++
+	}
+
+	if scope.SubCategory != "" {
+		query += fmt.Sprintf(" AND sub_category = $%d", argPos)
+		args = append(args, scope.SubCategory)
+		argPos++
+	}
+
+	if scope.Emirate != "" {
+		query += fmt.Sprintf(" AND location->>'emirate' = $%d", argPos)
+		args = append(args, scope.Emirate)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to close unseen listings: %w", classifyError(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", classifyError(err))
+	}
+
+	return rowsAffected, nil
+}
+
+// costDataPointColumns lists the columns read by scanCostDataPoint, in order
+const costDataPointColumns = `
+			id, category, sub_category, item_name, price, min_price, max_price,
+			median_price, sample_size, location, recorded_at, valid_from, valid_to,
+			source, source_url, confidence, currency, unit, period, tags, attributes,
+			run_id, first_seen_at, last_seen_at, created_at, updated_at, deleted_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanCostDataPoint scans a row selected with costDataPointColumns
+func scanCostDataPoint(row rowScanner) (*models.CostDataPoint, error) {
+	cdp := &models.CostDataPoint{}
+	var locationJSON []byte
+	var attributesJSON []byte
+	var subCategory, sourceURL, runID sql.NullString
+	var minPrice, maxPrice, medianPrice sql.NullFloat64
+	var validTo, deletedAt sql.NullTime
+
+	err := row.Scan(
+		&cdp.ID,
+		&cdp.Category,
+		&subCategory,
+		&cdp.ItemName,
+		&cdp.Price,
+		&minPrice,
+		&maxPrice,
+		&medianPrice,
+		&cdp.SampleSize,
+		&locationJSON,
+		&cdp.RecordedAt,
+		&cdp.ValidFrom,
+		&validTo,
+		&cdp.Source,
+		&sourceURL,
+		&cdp.Confidence,
+		&cdp.Currency,
+		&cdp.Unit,
+		&cdp.Period,
+		pq.Array(&cdp.Tags),
+		&attributesJSON,
+		&runID,
+		&cdp.FirstSeenAt,
+		&cdp.LastSeenAt,
+		&cdp.CreatedAt,
+		&cdp.UpdatedAt,
+		&deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal JSON fields
+	if err := json.Unmarshal(locationJSON, &cdp.Location); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal location: %w", err)
+	}
+
+	if len(attributesJSON) > 0 {
+		if err := json.Unmarshal(attributesJSON, &cdp.Attributes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal attributes: %w", err)
+		}
+	}
+
+	// Handle nullable fields
+	if subCategory.Valid {
+		cdp.SubCategory = subCategory.String
+	}
+	if sourceURL.Valid {
+		cdp.SourceURL = sourceURL.String
+	}
+	if runID.Valid {
+		cdp.RunID = runID.String
+	}
+	if minPrice.Valid {
+		cdp.MinPrice = minPrice.Float64
+	}
+	if maxPrice.Valid {
+		cdp.MaxPrice = maxPrice.Float64
+	}
+	if medianPrice.Valid {
+		cdp.MedianPrice = medianPrice.Float64
+	}
+	if validTo.Valid {
+		cdp.ValidTo = &validTo.Time
+	}
+	if deletedAt.Valid {
+		cdp.DeletedAt = &deletedAt.Time
+	}
+
+	return cdp, nil
 }
 
 // Helper functions to handle nullable fields
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/lib/pq"
+)
+
+// insertRevision records a revision within the transaction that made the change
+func insertRevision(ctx context.Context, tx *sql.Tx, rev *models.Revision) error {
+	oldJSON, err := revisionValuesJSON(rev.OldValues)
+	if err != nil {
+		return err
+	}
+	newJSON, err := revisionValuesJSON(rev.NewValues)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO cost_data_point_revisions (
+			data_point_id, recorded_at, action, actor, reason, changed_fields,
+			old_values, new_values
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		rev.DataPointID,
+		rev.RecordedAt,
+		string(rev.Action),
+		rev.Actor,
+		nullString(rev.Reason),
+		pq.Array(rev.ChangedFields),
+		oldJSON,
+		newJSON,
+	).Scan(&rev.ID, &rev.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record revision: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// revisionValuesJSON encodes one side of a revision, or NULL if it is absent
+func revisionValuesJSON(cdp *models.CostDataPoint) ([]byte, error) {
+	if cdp == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(cdp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal revision values: %w", err)
+	}
+	return data, nil
+}
+
+// History returns the revisions recorded for a cost data point, newest first
+func (r *CostDataPointRepository) History(ctx context.Context, id string) ([]*models.Revision, error) {
+	query := `
+		SELECT id, data_point_id, recorded_at, action, actor, reason, changed_fields,
+			old_values, new_values, created_at
+		FROM cost_data_point_revisions
+		WHERE data_point_id = $1
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var revisions []*models.Revision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", classifyError(err))
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating revisions: %w", classifyError(err))
+	}
+
+	return revisions, nil
+}
+
+// scanRevision scans a row of cost_data_point_revisions
+func scanRevision(row rowScanner) (*models.Revision, error) {
+	var (
+		rev       models.Revision
+		action    string
+		reason    sql.NullString
+		oldValues []byte
+		newValues []byte
+	)
+
+	err := row.Scan(
+		&rev.ID,
+		&rev.DataPointID,
+		&rev.RecordedAt,
+		&action,
+		&rev.Actor,
+		&reason,
+		pq.Array(&rev.ChangedFields),
+		&oldValues,
+		&newValues,
+		&rev.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rev.Action = models.RevisionAction(action)
+	rev.Reason = reason.String
+	if len(oldValues) > 0 {
+		rev.OldValues = &models.CostDataPoint{}
+		if err := json.Unmarshal(oldValues, rev.OldValues); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal old values: %w", err)
+		}
+	}
+	if len(newValues) > 0 {
+		rev.NewValues = &models.CostDataPoint{}
+		if err := json.Unmarshal(newValues, rev.NewValues); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal new values: %w", err)
+		}
+	}
+
+	return &rev, nil
+}
@@ -0,0 +1,65 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+)
+
+func TestHistory(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestData(t, db)
+
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	cdp := createTestCostDataPoint()
+	if err := repo.Create(ctx, cdp); err != nil {
+		t.Fatalf("Failed to create test record: %v", err)
+	}
+
+	if revisions, err := repo.History(ctx, cdp.ID); err != nil || len(revisions) != 0 {
+		t.Fatalf("Expected no revisions for a new record, got %d (err %v)", len(revisions), err)
+	}
+
+	updated := *cdp
+	updated.Price = 87500.00
+	auditCtx := repository.WithAudit(ctx, "analyst@example.com", "DEWA tariff corrected")
+	if err := repo.Update(auditCtx, &updated); err != nil {
+		t.Fatalf("Failed to update cost data point: %v", err)
+	}
+	if err := repo.Delete(repository.WithAudit(ctx, "analyst@example.com", "duplicate"), cdp.ID, cdp.RecordedAt); err != nil {
+		t.Fatalf("Failed to delete cost data point: %v", err)
+	}
+
+	revisions, err := repo.History(ctx, cdp.ID)
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("Expected 2 revisions, got %d", len(revisions))
+	}
+
+	deleted, update := revisions[0], revisions[1]
+	if deleted.Action != models.RevisionDelete || deleted.NewValues != nil || deleted.OldValues == nil {
+		t.Errorf("Unexpected delete revision: %+v", deleted)
+	}
+	if deleted.Reason != "duplicate" {
+		t.Errorf("Expected delete reason 'duplicate', got %q", deleted.Reason)
+	}
+
+	if update.Action != models.RevisionUpdate {
+		t.Errorf("Expected update revision, got %s", update.Action)
+	}
+	if update.Actor != "analyst@example.com" || update.Reason != "DEWA tariff corrected" {
+		t.Errorf("Unexpected audit details: actor %q reason %q", update.Actor, update.Reason)
+	}
+	if update.OldValues.Price != 85000.00 || update.NewValues.Price != 87500.00 {
+		t.Errorf("Expected price 85000 -> 87500, got %v -> %v", update.OldValues.Price, update.NewValues.Price)
+	}
+	if len(update.ChangedFields) != 1 || update.ChangedFields[0] != "price" {
+		t.Errorf("Expected only price to change, got %v", update.ChangedFields)
+	}
+}
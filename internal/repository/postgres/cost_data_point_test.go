@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -10,6 +11,7 @@ import (
 
 	"github.com/adonese/cost-of-living/internal/models"
 	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 )
 
@@ -57,6 +59,11 @@ func cleanupTestData(t *testing.T, db *sql.DB) {
 	if err != nil {
 		t.Logf("Warning: Failed to cleanup test data: %v", err)
 	}
+
+	_, err = db.Exec("DELETE FROM cost_data_point_revisions WHERE COALESCE(old_values, new_values)->>'source' = 'test'")
+	if err != nil {
+		t.Logf("Warning: Failed to cleanup test revisions: %v", err)
+	}
 }
 
 // createTestCostDataPoint creates a sample cost data point for testing
@@ -86,7 +93,8 @@ func createTestCostDataPoint() *models.CostDataPoint {
 		Source:     "test",
 		SourceURL:  "https://example.com/test",
 		Confidence: 1.0,
-		Unit:       "AED",
+		Currency:   "AED",
+		Period:     "year",
 		Tags:       []string{"rent", "apartment", "marina"},
 		Attributes: map[string]interface{}{
 			"bedrooms":  1,
@@ -184,8 +192,8 @@ func TestCreate(t *testing.T) {
 		if cdp.Confidence != 1.0 {
 			t.Errorf("Expected Confidence to be 1.0, got %f", cdp.Confidence)
 		}
-		if cdp.Unit != "AED" {
-			t.Errorf("Expected Unit to be AED, got %s", cdp.Unit)
+		if cdp.Currency != "AED" || cdp.Unit != "" {
+			t.Errorf("Expected an AED price for the whole item, got %q per %q", cdp.Currency, cdp.Unit)
 		}
 
 		// Clean up
@@ -193,6 +201,50 @@ func TestCreate(t *testing.T) {
 	})
 }
 
+func TestCreateKeepsSubFilsPrices(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestData(t, db)
+
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	// A water tariff of 2.09 AED per 1000 imperial gallons, stored per gallon
+	cdp := createTestCostDataPoint()
+	cdp.Category = "Utilities"
+	cdp.SubCategory = "Water"
+	cdp.ItemName = "Residential water slab 1"
+	cdp.Price = 0.00209
+	cdp.MinPrice = 0.00209
+	cdp.MaxPrice = 0.0044
+	cdp.MedianPrice = 0.00309
+	cdp.Unit = "IG"
+	cdp.Period = ""
+
+	if err := repo.Create(ctx, cdp); err != nil {
+		t.Fatalf("Failed to create cost data point: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, cdp.ID, cdp.RecordedAt)
+	if err != nil {
+		t.Fatalf("Failed to get cost data point: %v", err)
+	}
+
+	prices := []struct {
+		name      string
+		got, want float64
+	}{
+		{"price", got.Price, 0.00209},
+		{"min_price", got.MinPrice, 0.00209},
+		{"max_price", got.MaxPrice, 0.0044},
+		{"median_price", got.MedianPrice, 0.00309},
+	}
+	for _, p := range prices {
+		if p.got != p.want {
+			t.Errorf("Expected %s %v, got %v", p.name, p.want, p.got)
+		}
+	}
+}
+
 func TestGetByID(t *testing.T) {
 	db := setupTestDB(t)
 	defer cleanupTestData(t, db)
@@ -276,6 +328,7 @@ func TestList(t *testing.T) {
 	cdp3 := createTestCostDataPoint()
 	cdp3.Category = "Housing"
 	cdp3.Location.Emirate = "Sharjah"
+	cdp3.Location.Coordinates = &models.GeoPoint{Lat: 25.3463, Lon: 55.4209}
 	cdp3.RecordedAt = time.Now().UTC().Add(-2 * time.Hour).Truncate(time.Microsecond)
 	records = append(records, cdp3)
 
@@ -445,6 +498,487 @@ func TestList(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("filter by distance", func(t *testing.T) {
+		near := &repository.GeoRadius{Center: models.GeoPoint{Lat: 25.08, Lon: 55.14}, RadiusKm: 3}
+		results, err := repo.List(ctx, repository.ListFilter{Near: near, Limit: 100})
+		if err != nil {
+			t.Fatalf("Failed to list cost data points: %v", err)
+		}
+
+		found := map[string]bool{}
+		for _, r := range results {
+			found[r.ID] = true
+			if r.Location.Coordinates == nil || !near.Contains(*r.Location.Coordinates) {
+				t.Errorf("Result outside radius: %+v", r.Location.Coordinates)
+			}
+		}
+		if !found[records[0].ID] {
+			t.Error("Expected the Marina record within 3 km")
+		}
+		if found[cdp3.ID] {
+			t.Error("Expected the Sharjah record to be excluded")
+		}
+	})
+
+	t.Run("filter by bounding box", func(t *testing.T) {
+		box := &repository.BoundingBox{MinLat: 25.3, MinLon: 55.3, MaxLat: 25.4, MaxLon: 55.5}
+		results, err := repo.List(ctx, repository.ListFilter{Within: box, Limit: 100})
+		if err != nil {
+			t.Fatalf("Failed to list cost data points: %v", err)
+		}
+
+		found := false
+		for _, r := range results {
+			found = found || r.ID == cdp3.ID
+			if r.Location.Coordinates == nil || !box.Contains(*r.Location.Coordinates) {
+				t.Errorf("Result outside bounding box: %+v", r.Location.Coordinates)
+			}
+		}
+		if !found {
+			t.Error("Expected the Sharjah record within the bounding box")
+		}
+	})
+}
+
+func TestCreateBatch(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestData(t, db)
+
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	t.Run("copies all rows", func(t *testing.T) {
+		batch := []*models.CostDataPoint{
+			createTestCostDataPoint(),
+			createTestCostDataPoint(),
+			createTestCostDataPoint(),
+		}
+
+		result, err := repo.CreateBatch(ctx, batch)
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		if result.Inserted != len(batch) {
+			t.Errorf("Expected %d inserted rows, got %d", len(batch), result.Inserted)
+		}
+		if len(result.Failed) != 0 {
+			t.Errorf("Expected no failed rows, got %v", result.Failed)
+		}
+
+		for _, cdp := range batch {
+			if cdp.ID == "" {
+				t.Error("Expected ID to be generated")
+			}
+			if _, err := repo.GetByID(ctx, cdp.ID, cdp.RecordedAt); err != nil {
+				t.Errorf("Expected batch row %s to be stored: %v", cdp.ID, err)
+			}
+		}
+	})
+
+	t.Run("reports rows that violate constraints", func(t *testing.T) {
+		existing := createTestCostDataPoint()
+		if err := repo.Create(ctx, existing); err != nil {
+			t.Fatalf("Failed to create test record: %v", err)
+		}
+
+		duplicate := createTestCostDataPoint()
+		duplicate.ID = existing.ID
+		duplicate.RecordedAt = existing.RecordedAt
+
+		fresh := createTestCostDataPoint()
+
+		result, err := repo.CreateBatch(ctx, []*models.CostDataPoint{fresh, duplicate})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		if result.Inserted != 1 {
+			t.Errorf("Expected 1 inserted row, got %d", result.Inserted)
+		}
+		if len(result.Failed) != 1 || result.Failed[0].Index != 1 {
+			t.Fatalf("Expected row 1 to fail, got %v", result.Failed)
+		}
+
+		if _, err := repo.GetByID(ctx, fresh.ID, fresh.RecordedAt); err != nil {
+			t.Errorf("Expected valid row to be kept: %v", err)
+		}
+	})
+
+	t.Run("rejects nil rows", func(t *testing.T) {
+		fresh := createTestCostDataPoint()
+		result, err := repo.CreateBatch(ctx, []*models.CostDataPoint{nil, fresh})
+		if err != nil {
+			t.Fatalf("Failed to create batch: %v", err)
+		}
+		if result.Inserted != 1 {
+			t.Errorf("Expected 1 inserted row, got %d", result.Inserted)
+		}
+		if len(result.Failed) != 1 || !errors.Is(result.Failed[0], repository.ErrValidationFailed) {
+			t.Fatalf("Expected row 0 to fail validation, got %v", result.Failed)
+		}
+	})
+
+	t.Run("empty batch", func(t *testing.T) {
+		result, err := repo.CreateBatch(ctx, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Inserted != 0 || len(result.Failed) != 0 {
+			t.Errorf("Expected empty result, got %+v", result)
+		}
+	})
+}
+
+func TestCopyArgs(t *testing.T) {
+	cdp := createTestCostDataPoint()
+	row, err := prepareInsert(cdp)
+	if err != nil {
+		t.Fatalf("Failed to prepare row: %v", err)
+	}
+
+	now := time.Now()
+	args := row.copyArgs(now)
+	if len(args) != len(copyColumns) {
+		t.Fatalf("Expected %d values for %d columns, got %d", len(copyColumns), len(copyColumns), len(args))
+	}
+
+	values := make(map[string]interface{}, len(args))
+	for i, column := range copyColumns {
+		values[column] = args[i]
+	}
+	if values["location"] != string(row.location) {
+		t.Errorf("Expected location JSON as text, got %#v", values["location"])
+	}
+	if values["attributes"] != string(row.attributes) {
+		t.Errorf("Expected attributes JSON as text, got %#v", values["attributes"])
+	}
+	if values["natural_key"] != cdp.NaturalKey() {
+		t.Errorf("Expected natural key %q, got %#v", cdp.NaturalKey(), values["natural_key"])
+	}
+	if values["updated_at"] != now {
+		t.Errorf("Expected updated_at %v, got %#v", now, values["updated_at"])
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestData(t, db)
+
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	listing := func(price float64) *models.CostDataPoint {
+		cdp := createTestCostDataPoint()
+		cdp.Category = "Housing"
+		cdp.Price = price
+		cdp.SourceURL = "https://example.com/listing/upsert-test"
+		return cdp
+	}
+
+	first := listing(5000)
+	result, err := repo.Upsert(ctx, []*models.CostDataPoint{first})
+	if err != nil {
+		t.Fatalf("Failed to upsert: %v", err)
+	}
+	if result.Inserted != 1 || result.Updated != 0 {
+		t.Fatalf("Expected 1 insert, got %+v", result)
+	}
+
+	t.Run("same price refreshes existing row", func(t *testing.T) {
+		again := listing(5000)
+		result, err := repo.Upsert(ctx, []*models.CostDataPoint{again})
+		if err != nil {
+			t.Fatalf("Failed to upsert: %v", err)
+		}
+		if result.Updated != 1 || result.Inserted != 0 {
+			t.Fatalf("Expected 1 update, got %+v", result)
+		}
+		if again.ID != first.ID {
+			t.Errorf("Expected existing ID %s, got %s", first.ID, again.ID)
+		}
+		if !again.UpdatedAt.After(first.UpdatedAt) {
+			t.Error("Expected updated_at to advance")
+		}
+	})
+
+	t.Run("price change closes old row", func(t *testing.T) {
+		changed := listing(5500)
+		result, err := repo.Upsert(ctx, []*models.CostDataPoint{changed})
+		if err != nil {
+			t.Fatalf("Failed to upsert: %v", err)
+		}
+		if result.Inserted != 1 {
+			t.Fatalf("Expected 1 insert, got %+v", result)
+		}
+
+		previous, err := repo.GetByID(ctx, first.ID, first.RecordedAt)
+		if err != nil {
+			t.Fatalf("Failed to get previous row: %v", err)
+		}
+		if previous.ValidTo == nil {
+			t.Error("Expected previous row to be closed")
+		}
+	})
+}
+
+func TestCompensationReopensSupersededRows(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestData(t, db)
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	listing := func(price float64, runID string) *models.CostDataPoint {
+		cdp := createTestCostDataPoint()
+		cdp.Price = price
+		cdp.SourceURL = "https://example.com/listing/compensation-test"
+		cdp.RunID = runID
+		return cdp
+	}
+	upsert := func(cdp *models.CostDataPoint) {
+		t.Helper()
+		if _, err := repo.Upsert(ctx, []*models.CostDataPoint{cdp}); err != nil {
+			t.Fatalf("Failed to upsert: %v", err)
+		}
+	}
+	activePrice := func() float64 {
+		t.Helper()
+		active, err := repo.List(ctx, repository.ListFilter{Source: "test", ActiveOnly: true})
+		if err != nil {
+			t.Fatalf("Failed to list active rows: %v", err)
+		}
+		if len(active) != 1 {
+			t.Fatalf("Expected 1 active row for the listing, got %d", len(active))
+		}
+		return active[0].Price
+	}
+
+	upsert(listing(5000, uuid.NewString()))
+
+	t.Run("invalidating a run reopens the row it superseded", func(t *testing.T) {
+		runB := uuid.NewString()
+		upsert(listing(5500, runB))
+		if got := activePrice(); got != 5500 {
+			t.Fatalf("Expected the new price to be active, got %v", got)
+		}
+
+		rows, err := repo.InvalidateByRunID(ctx, runB, time.Now())
+		if err != nil {
+			t.Fatalf("Failed to invalidate run: %v", err)
+		}
+		if rows != 2 {
+			t.Errorf("Expected 1 invalidated and 1 reopened row, got %d", rows)
+		}
+		if got := activePrice(); got != 5000 {
+			t.Errorf("Expected the superseded price to be active again, got %v", got)
+		}
+	})
+
+	t.Run("deleting a run reopens the row it superseded", func(t *testing.T) {
+		runC := uuid.NewString()
+		upsert(listing(6000, runC))
+
+		rows, err := repo.DeleteByRunID(ctx, runC)
+		if err != nil {
+			t.Fatalf("Failed to delete run: %v", err)
+		}
+		if rows != 2 {
+			t.Errorf("Expected 1 deleted and 1 reopened row, got %d", rows)
+		}
+		if got := activePrice(); got != 5000 {
+			t.Errorf("Expected the superseded price to be active again, got %v", got)
+		}
+	})
+
+	t.Run("a later run's row is not superseded twice", func(t *testing.T) {
+		runD, runE := uuid.NewString(), uuid.NewString()
+		upsert(listing(6500, runD))
+		upsert(listing(7000, runE))
+
+		if _, err := repo.InvalidateByRunID(ctx, runD, time.Now()); err != nil {
+			t.Fatalf("Failed to invalidate run: %v", err)
+		}
+		if got := activePrice(); got != 7000 {
+			t.Errorf("Expected the later run's price to stay the only active one, got %v", got)
+		}
+	})
+}
+
+func TestCloseUnseenListings(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestData(t, db)
+
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	seenAt := time.Now()
+
+	stale := createTestCostDataPoint()
+	stale.Category = "Housing"
+	stale.SourceURL = "https://example.com/listing/stale"
+	stale.LastSeenAt = seenAt.Add(-24 * time.Hour)
+
+	fresh := createTestCostDataPoint()
+	fresh.Category = "Housing"
+	fresh.SourceURL = "https://example.com/listing/fresh"
+	fresh.LastSeenAt = seenAt
+
+	if _, err := repo.CreateBatch(ctx, []*models.CostDataPoint{stale, fresh}); err != nil {
+		t.Fatalf("Failed to create listings: %v", err)
+	}
+
+	closed, err := repo.CloseUnseenListings(ctx, repository.ListingScope{Source: "test", Category: "Housing"}, seenAt, seenAt)
+	if err != nil {
+		t.Fatalf("Failed to close unseen listings: %v", err)
+	}
+	if closed != 1 {
+		t.Errorf("Expected 1 closed listing, got %d", closed)
+	}
+
+	got, err := repo.GetByID(ctx, stale.ID, stale.RecordedAt)
+	if err != nil {
+		t.Fatalf("Failed to get stale listing: %v", err)
+	}
+	if got.ValidTo == nil {
+		t.Error("Expected stale listing to be closed")
+	}
+}
+
+func TestStream(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestData(t, db)
+
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	// Span more than one fetch from the cursor
+	total := streamFetchSize + 5
+	batch := make([]*models.CostDataPoint, total)
+	base := time.Now().UTC().Truncate(time.Microsecond)
+	for i := range batch {
+		cdp := createTestCostDataPoint()
+		cdp.RecordedAt = base.Add(-time.Duration(i) * time.Second)
+		batch[i] = cdp
+	}
+	if _, err := repo.CreateBatch(ctx, batch); err != nil {
+		t.Fatalf("Failed to create batch: %v", err)
+	}
+
+	var seen int
+	var previous time.Time
+	err := repo.Stream(ctx, repository.ListFilter{Source: "test"}, func(cdp *models.CostDataPoint) error {
+		if seen > 0 && cdp.RecordedAt.After(previous) {
+			t.Errorf("Stream out of order at row %d", seen)
+		}
+		previous = cdp.RecordedAt
+		seen++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to stream: %v", err)
+	}
+	if seen != total {
+		t.Errorf("Expected %d streamed rows, got %d", total, seen)
+	}
+
+	t.Run("callback error stops the stream", func(t *testing.T) {
+		stop := errors.New("stop")
+		calls := 0
+		err := repo.Stream(ctx, repository.ListFilter{Source: "test"}, func(*models.CostDataPoint) error {
+			calls++
+			return stop
+		})
+		if !errors.Is(err, stop) {
+			t.Errorf("Expected callback error, got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("Expected 1 callback, got %d", calls)
+		}
+	})
+}
+
+func TestBuildListWhere(t *testing.T) {
+	minPrice := 5000.0
+	filter := repository.ListFilter{
+		Source:     "bayut",
+		Area:       "Dubai Marina",
+		Tags:       []string{"rent", "apartment"},
+		TagMatch:   repository.TagMatchAll,
+		MinPrice:   &minPrice,
+		Attributes: map[string]string{"bedrooms": "2", "furnished": "true"},
+		ActiveOnly: true,
+	}
+
+	where, args, err := buildListWhere(filter)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := " AND source = $1" +
+		" AND location->>'area' = $2" +
+		" AND tags @> $3" +
+		" AND price >= $4" +
+		" AND (attributes @> $5 OR attributes @> $6)" +
+		" AND (attributes @> $7 OR attributes @> $8)" +
+		" AND (valid_to IS NULL OR valid_to > NOW())" +
+		" AND deleted_at IS NULL"
+	if where != expected {
+		t.Errorf("Unexpected where clause:\n got: %s\nwant: %s", where, expected)
+	}
+	if len(args) != 8 {
+		t.Fatalf("Expected 8 args, got %d", len(args))
+	}
+	if args[4] != `{"bedrooms":"2"}` || args[5] != `{"bedrooms":2}` {
+		t.Errorf("Unexpected bedrooms candidates: %v, %v", args[4], args[5])
+	}
+	if args[6] != `{"furnished":"true"}` || args[7] != `{"furnished":true}` {
+		t.Errorf("Unexpected furnished candidates: %v, %v", args[6], args[7])
+	}
+}
+
+func TestBuildListWhereGeo(t *testing.T) {
+	where, args, err := buildListWhere(repository.ListFilter{
+		Category:       "Housing",
+		Near:           &repository.GeoRadius{Center: models.GeoPoint{Lat: 25.08, Lon: 55.14}, RadiusKm: 3},
+		Within:         &repository.BoundingBox{MinLat: 25, MinLon: 55, MaxLat: 25.5, MaxLon: 55.5},
+		IncludeDeleted: true,
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := " AND category = $1" +
+		" AND earth_box(ll_to_earth($2, $3), $4) @> " + coordinatesEarth +
+		" AND earth_distance(ll_to_earth($2, $3), " + coordinatesEarth + ") <= $4" +
+		" AND " + coordinatesLat + " BETWEEN $5 AND $6" +
+		" AND " + coordinatesLon + " BETWEEN $7 AND $8"
+	if where != expected {
+		t.Errorf("Unexpected where clause:\n got: %s\nwant: %s", where, expected)
+	}
+	if len(args) != 8 {
+		t.Fatalf("Expected 8 args, got %d", len(args))
+	}
+	if args[3] != 3000.0 {
+		t.Errorf("Expected radius in meters, got %v", args[3])
+	}
+}
+
+func TestBuildListWhereIncludeDeleted(t *testing.T) {
+	where, _, err := buildListWhere(repository.ListFilter{Category: "Housing", IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if where != " AND category = $1" {
+		t.Errorf("Unexpected where clause: %s", where)
+	}
+}
+
+func TestListRejectsUnknownOrderField(t *testing.T) {
+	repo := NewCostDataPointRepository(nil)
+
+	_, err := repo.List(context.Background(), repository.ListFilter{OrderBy: "price; DROP TABLE cost_data_points"})
+	if err == nil {
+		t.Fatal("Expected error for unsupported order field")
+	}
 }
 
 func TestUpdate(t *testing.T) {
@@ -494,6 +1028,30 @@ func TestUpdate(t *testing.T) {
 		}
 	})
 
+	t.Run("edited listing is found by a later upsert", func(t *testing.T) {
+		cdp := createTestCostDataPoint()
+		cdp.SourceURL = "https://example.com/listing/before-edit"
+		if err := repo.Create(ctx, cdp); err != nil {
+			t.Fatalf("Failed to create test record: %v", err)
+		}
+
+		cdp.SourceURL = "https://example.com/listing/after-edit"
+		if err := repo.Update(ctx, cdp); err != nil {
+			t.Fatalf("Failed to update cost data point: %v", err)
+		}
+
+		again := createTestCostDataPoint()
+		again.SourceURL = cdp.SourceURL
+		again.Price = cdp.Price
+		result, err := repo.Upsert(ctx, []*models.CostDataPoint{again})
+		if err != nil {
+			t.Fatalf("Failed to upsert: %v", err)
+		}
+		if result.Updated != 1 || again.ID != cdp.ID {
+			t.Errorf("Expected the edited row %s to be refreshed, got %+v with ID %s", cdp.ID, result, again.ID)
+		}
+	})
+
 	t.Run("update non-existent record", func(t *testing.T) {
 		cdp := createTestCostDataPoint()
 		cdp.ID = "00000000-0000-0000-0000-000000000000"
@@ -547,6 +1105,67 @@ func TestDelete(t *testing.T) {
 	})
 }
 
+func TestSoftDeleteAndRestore(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestData(t, db)
+
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	cdp := createTestCostDataPoint()
+	if err := repo.Create(ctx, cdp); err != nil {
+		t.Fatalf("Failed to create test record: %v", err)
+	}
+
+	if err := repo.SoftDelete(ctx, cdp.ID, cdp.RecordedAt); err != nil {
+		t.Fatalf("Failed to soft delete cost data point: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, cdp.ID, cdp.RecordedAt); err == nil {
+		t.Error("Expected soft-deleted record to be hidden from GetByID")
+	}
+	if err := repo.SoftDelete(ctx, cdp.ID, cdp.RecordedAt); err == nil {
+		t.Error("Expected error when soft deleting an already deleted record")
+	}
+
+	hidden, err := repo.List(ctx, repository.ListFilter{ID: cdp.ID})
+	if err != nil {
+		t.Fatalf("Failed to list: %v", err)
+	}
+	if len(hidden) != 0 {
+		t.Errorf("Expected soft-deleted record to be excluded from List, got %d", len(hidden))
+	}
+
+	included, err := repo.List(ctx, repository.ListFilter{ID: cdp.ID, IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("Failed to list: %v", err)
+	}
+	if len(included) != 1 || included[0].DeletedAt == nil {
+		t.Fatalf("Expected one record with deleted_at set, got %+v", included)
+	}
+
+	restored, err := repo.Restore(ctx, cdp.ID, cdp.RecordedAt)
+	if err != nil {
+		t.Fatalf("Failed to restore cost data point: %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Errorf("Expected deleted_at to be cleared, got %v", restored.DeletedAt)
+	}
+	if _, err := repo.Restore(ctx, cdp.ID, cdp.RecordedAt); err == nil {
+		t.Error("Expected error when restoring a record that is not deleted")
+	}
+	if _, err := repo.GetByID(ctx, cdp.ID, cdp.RecordedAt); err != nil {
+		t.Errorf("Expected restored record to be found: %v", err)
+	}
+
+	revisions, err := repo.History(ctx, cdp.ID)
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Action != models.RevisionRestore || revisions[1].Action != models.RevisionDelete {
+		t.Errorf("Expected restore and delete revisions, got %d", len(revisions))
+	}
+}
+
 // getEnv gets an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/lib/pq"
+)
+
+// classifyError wraps driver errors in the matching repository error kind
+// so callers can tell a duplicate key or a lost connection from a bug.
+// Errors that are already classified or not recognised are returned as is.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == "23505" || pqErr.Code == "23P01":
+			// unique_violation, exclusion_violation
+			return repository.DriverError(repository.ErrConflict, err)
+		case pqErr.Code.Class() == "23" || pqErr.Code.Class() == "22":
+			// other integrity constraint violations and data exceptions
+			return repository.DriverError(repository.ErrValidationFailed, err)
+		case pqErr.Code.Class() == "08" || pqErr.Code.Class() == "53" || pqErr.Code.Class() == "57":
+			// connection exceptions, insufficient resources, operator intervention
+			return repository.DriverError(repository.ErrUnavailable, err)
+		}
+		return err
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return repository.DriverError(repository.ErrUnavailable, err)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return repository.DriverError(repository.ErrUnavailable, err)
+	}
+
+	return err
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/lib/pq"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"unique violation", &pq.Error{Code: "23505"}, repository.ErrConflict},
+		{"check violation", &pq.Error{Code: "23514"}, repository.ErrValidationFailed},
+		{"numeric overflow", &pq.Error{Code: "22003"}, repository.ErrValidationFailed},
+		{"connection failure", &pq.Error{Code: "08006"}, repository.ErrUnavailable},
+		{"admin shutdown", &pq.Error{Code: "57P01"}, repository.ErrUnavailable},
+		{"bad connection", fmt.Errorf("query: %w", driver.ErrBadConn), repository.ErrUnavailable},
+		{"dial failure", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, repository.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := classifyError(tt.err); !errors.Is(err, tt.kind) {
+				t.Errorf("classifyError(%v) = %v, want kind %v", tt.err, err, tt.kind)
+			}
+		})
+	}
+
+	syntax := &pq.Error{Code: "42601"}
+	if err := classifyError(syntax); err != error(syntax) {
+		t.Errorf("classifyError() should leave unrecognised errors unchanged, got %v", err)
+	}
+	if classifyError(nil) != nil {
+		t.Error("classifyError(nil) should be nil")
+	}
+}
@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/lib/pq"
+)
+
+// MaintenanceRepository implements the repository.MaintenanceRepository
+// interface with TimescaleDB policies
+type MaintenanceRepository struct {
+	db *sql.DB
+}
+
+// NewMaintenanceRepository creates a new instance of MaintenanceRepository
+func NewMaintenanceRepository(db *sql.DB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+// SetCompressionPolicy replaces the compression policy of the hypertable
+func (r *MaintenanceRepository) SetCompressionPolicy(ctx context.Context, after time.Duration) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT remove_compression_policy('cost_data_points', if_exists => TRUE)`); err != nil {
+		return fmt.Errorf("failed to remove compression policy: %w", classifyError(err))
+	}
+
+	if after > 0 {
+		interval := fmt.Sprintf("%d seconds", int64(after/time.Second))
+		if _, err := tx.ExecContext(ctx, `SELECT add_compression_policy('cost_data_points', compress_after => $1::interval)`, interval); err != nil {
+			return fmt.Errorf("failed to add compression policy: %w", classifyError(err))
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CountExpired returns the number of rows the retention filter selects
+func (r *MaintenanceRepository) CountExpired(ctx context.Context, filter repository.RetentionFilter) (int64, error) {
+	where, args := buildRetentionWhere(filter)
+
+	var count int64
+	query := `SELECT COUNT(*) FROM cost_data_points WHERE ` + where
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count expired cost data points: %w", classifyError(err))
+	}
+
+	return count, nil
+}
+
+// DeleteExpired deletes one batch of expired rows, handing them to archive
+// inside the deleting transaction
+func (r *MaintenanceRepository) DeleteExpired(
+	ctx context.Context,
+	filter repository.RetentionFilter,
+	limit int,
+	archive func([]*models.CostDataPoint) error,
+) (int, error) {
+	if limit <= 0 {
+		return 0, repository.ValidationFailed(fmt.Errorf("limit must be positive"))
+	}
+
+	where, args := buildRetentionWhere(filter)
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+		DELETE FROM cost_data_points
+		WHERE (id, recorded_at) IN (
+			SELECT id, recorded_at FROM cost_data_points
+			WHERE %s
+			ORDER BY recorded_at
+			LIMIT $%d
+		)
+		RETURNING `+costDataPointColumns, where, len(args))
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired cost data points: %w", classifyError(err))
+	}
+
+	var deleted []*models.CostDataPoint
+	for rows.Next() {
+		cdp, err := scanCostDataPoint(rows)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan row: %w", classifyError(err))
+		}
+		deleted = append(deleted, cdp)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating rows: %w", classifyError(err))
+	}
+
+	if len(deleted) == 0 {
+		return 0, nil
+	}
+
+	if err := archive(deleted); err != nil {
+		return 0, fmt.Errorf("failed to archive expired cost data points: %w", classifyError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+
+	return len(deleted), nil
+}
+
+// buildRetentionWhere renders the retention predicate with positional
+// arguments starting at $1
+func buildRetentionWhere(filter repository.RetentionFilter) (string, []interface{}) {
+	clauses := []string{
+		"recorded_at < $1",
+		"COALESCE(valid_to, last_seen_at) < $1",
+	}
+	args := []interface{}{filter.Before}
+
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		clauses = append(clauses, fmt.Sprintf("category = $%d", len(args)))
+	}
+	if len(filter.ExcludeCategories) > 0 {
+		args = append(args, pq.Array(filter.ExcludeCategories))
+		clauses = append(clauses, fmt.Sprintf("NOT (category = ANY($%d))", len(args)))
+	}
+
+	return strings.Join(clauses, " AND "), args
+}
@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+)
+
+func TestDeleteExpired(t *testing.T) {
+	db := setupTestDB(t)
+	defer cleanupTestData(t, db)
+
+	repo := NewCostDataPointRepository(db)
+	maintenance := NewMaintenanceRepository(db)
+	ctx := context.Background()
+
+	old := time.Now().UTC().Add(-400 * 24 * time.Hour).Truncate(time.Microsecond)
+
+	expired := createTestCostDataPoint()
+	expired.Category = "Retention Test"
+	expired.RecordedAt = old
+	expired.LastSeenAt = old
+
+	stillListed := createTestCostDataPoint()
+	stillListed.Category = "Retention Test"
+	stillListed.RecordedAt = old
+	stillListed.LastSeenAt = time.Now()
+
+	if _, err := repo.CreateBatch(ctx, []*models.CostDataPoint{expired, stillListed}); err != nil {
+		t.Fatalf("Failed to create data points: %v", err)
+	}
+
+	filter := repository.RetentionFilter{Category: "Retention Test", Before: time.Now().Add(-365 * 24 * time.Hour)}
+
+	count, err := maintenance.CountExpired(ctx, filter)
+	if err != nil {
+		t.Fatalf("Failed to count expired rows: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("Expected 1 expired row, got %d", count)
+	}
+
+	t.Run("archive failure rolls back", func(t *testing.T) {
+		_, err := maintenance.DeleteExpired(ctx, filter, 10, func([]*models.CostDataPoint) error {
+			return fmt.Errorf("disk full")
+		})
+		if err == nil || !strings.Contains(err.Error(), "disk full") {
+			t.Fatalf("Expected archive error, got %v", err)
+		}
+		if _, err := repo.GetByID(ctx, expired.ID, expired.RecordedAt); err != nil {
+			t.Errorf("Expected row to survive a failed archive: %v", err)
+		}
+	})
+
+	var archived []*models.CostDataPoint
+	deleted, err := maintenance.DeleteExpired(ctx, filter, 10, func(batch []*models.CostDataPoint) error {
+		archived = append(archived, batch...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to delete expired rows: %v", err)
+	}
+	if deleted != 1 || len(archived) != 1 || archived[0].ID != expired.ID {
+		t.Errorf("Expected only the expired row to be archived, got %d rows", len(archived))
+	}
+	if _, err := repo.GetByID(ctx, stillListed.ID, stillListed.RecordedAt); err != nil {
+		t.Errorf("Expected still listed row to be kept: %v", err)
+	}
+}
+
+func TestBuildRetentionWhere(t *testing.T) {
+	where, args := buildRetentionWhere(repository.RetentionFilter{
+		ExcludeCategories: []string{"Housing"},
+		Before:            time.Now(),
+	})
+
+	expected := "recorded_at < $1 AND COALESCE(valid_to, last_seen_at) < $1 AND NOT (category = ANY($2))"
+	if where != expected {
+		t.Errorf("Unexpected where clause:\n got: %s\nwant: %s", where, expected)
+	}
+	if len(args) != 2 {
+		t.Errorf("Expected 2 args, got %d", len(args))
+	}
+}
@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+)
+
+// PriceRollupRepository implements the repository.PriceRollupRepository
+// interface over the continuous aggregates, last rebuilt by migration 016
+type PriceRollupRepository struct {
+	db *sql.DB
+}
+
+// NewPriceRollupRepository creates a new instance of PriceRollupRepository
+func NewPriceRollupRepository(db *sql.DB) *PriceRollupRepository {
+	return &PriceRollupRepository{db: db}
+}
+
+// rollupViews maps each interval to its continuous aggregate
+var rollupViews = map[models.RollupInterval]string{
+	models.RollupDaily:  "cost_data_points_daily",
+	models.RollupWeekly: "cost_data_points_weekly",
+}
+
+// ListRollups returns the rollups matching the filter, oldest bucket first
+func (r *PriceRollupRepository) ListRollups(ctx context.Context, filter repository.RollupFilter) ([]*models.PriceRollup, error) {
+	query, args, err := buildRollupQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list price rollups: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	interval := rollupInterval(filter)
+	var results []*models.PriceRollup
+	for rows.Next() {
+		var rollup models.PriceRollup
+		var subCategory, emirate, area, currency, unit, period sql.NullString
+		var stddev sql.NullFloat64
+
+		if err := rows.Scan(
+			&rollup.Bucket,
+			&rollup.Category,
+			&subCategory,
+			&emirate,
+			&area,
+			&currency,
+			&unit,
+			&period,
+			&rollup.Count,
+			&rollup.AvgPrice,
+			&rollup.MinPrice,
+			&rollup.MaxPrice,
+			&rollup.P25Price,
+			&rollup.MedianPrice,
+			&rollup.P75Price,
+			&stddev,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan price rollup: %w", classifyError(err))
+		}
+
+		rollup.Interval = interval
+		rollup.SubCategory = subCategory.String
+		rollup.Emirate = emirate.String
+		rollup.Area = area.String
+		rollup.Currency = currency.String
+		rollup.Unit = unit.String
+		rollup.Period = period.String
+		rollup.StdDevPrice = stddev.Float64
+		results = append(results, &rollup)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", classifyError(err))
+	}
+
+	return results, nil
+}
+
+// buildRollupQuery renders the SELECT for a rollup filter
+func buildRollupQuery(filter repository.RollupFilter) (string, []interface{}, error) {
+	if err := filter.Validate(); err != nil {
+		return "", nil, fmt.Errorf("invalid filter: %w", classifyError(err))
+	}
+
+	var where strings.Builder
+	args := []interface{}{}
+	argPos := 1
+
+	add := func(predicate string, arg interface{}) {
+		fmt.Fprintf(&where, " AND "+predicate, argPos)
+		args = append(args, arg)
+		argPos++
+	}
+
+	if filter.Category != "" {
+		add("category = $%d", filter.Category)
+	}
+	if filter.SubCategory != "" {
+		add("sub_category = $%d", filter.SubCategory)
+	}
+	if filter.Emirate != "" {
+		add("emirate = $%d", filter.Emirate)
+	}
+	if filter.Area != "" {
+		add("area = $%d", filter.Area)
+	}
+	if filter.Currency != "" {
+		add("currency = $%d", filter.Currency)
+	}
+	if filter.Unit != "" {
+		add("unit = $%d", filter.Unit)
+	}
+	if filter.Period != "" {
+		add("period = $%d", filter.Period)
+	}
+	if filter.Start != nil {
+		add("bucket >= $%d", *filter.Start)
+	}
+	if filter.End != nil {
+		add("bucket <= $%d", *filter.End)
+	}
+
+	query := `SELECT bucket, category, sub_category, emirate, area, currency, unit, period,
+			sample_count, avg_price, min_price, max_price, p25_price, median_price, p75_price, stddev_price
+		FROM ` + rollupViews[rollupInterval(filter)] + `
+		WHERE 1=1` + where.String() + `
+		ORDER BY bucket ASC, category, sub_category, emirate, area, currency, unit, period`
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argPos)
+		args = append(args, filter.Limit)
+	}
+
+	return query, args, nil
+}
+
+func rollupInterval(filter repository.RollupFilter) models.RollupInterval {
+	if filter.Interval == "" {
+		return models.RollupDaily
+	}
+	return filter.Interval
+}
@@ -0,0 +1,279 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/lib/pq"
+)
+
+// ScrapeRunRepository implements the repository.ScrapeRunRepository interface
+type ScrapeRunRepository struct {
+	db *sql.DB
+}
+
+// NewScrapeRunRepository creates a new instance of ScrapeRunRepository
+func NewScrapeRunRepository(db *sql.DB) *ScrapeRunRepository {
+	return &ScrapeRunRepository{db: db}
+}
+
+const scrapeRunColumns = `
+			id, scraper_name, status, started_at, finished_at, fetched, validated,
+			saved, save_failures, validation, errors, workflow_id, workflow_run_id,
+			compensated_at, compensation_action, compensated_rows, compensation_error,
+			created_at, updated_at`
+
+// Create records the start of a scrape run and assigns its ID
+func (r *ScrapeRunRepository) Create(ctx context.Context, run *models.ScrapeRun) error {
+	if run.StartedAt.IsZero() {
+		run.StartedAt = time.Now()
+	}
+	if run.Status == "" {
+		run.Status = models.ScrapeRunRunning
+	}
+
+	validationJSON, err := json.Marshal(run.Validation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal validation summary: %w", err)
+	}
+
+	query := `
+		INSERT INTO scrape_runs (
+			scraper_name, status, started_at, finished_at, fetched, validated,
+			saved, save_failures, validation, errors, workflow_id, workflow_run_id
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		)
+		RETURNING id, created_at, updated_at
+	`
+
+	err = r.db.QueryRowContext(
+		ctx,
+		query,
+		run.ScraperName,
+		string(run.Status),
+		run.StartedAt,
+		nullTime(run.FinishedAt),
+		run.Fetched,
+		run.Validated,
+		run.Saved,
+		run.SaveFailures,
+		validationJSON,
+		pq.Array(run.Errors),
+		nullString(run.WorkflowID),
+		nullString(run.WorkflowRunID),
+	).Scan(&run.ID, &run.CreatedAt, &run.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to create scrape run: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// Update persists the counts, status and errors of an existing run
+func (r *ScrapeRunRepository) Update(ctx context.Context, run *models.ScrapeRun) error {
+	validationJSON, err := json.Marshal(run.Validation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal validation summary: %w", err)
+	}
+
+	query := `
+		UPDATE scrape_runs SET
+			status = $1,
+			finished_at = $2,
+			fetched = $3,
+			validated = $4,
+			saved = $5,
+			save_failures = $6,
+			validation = $7,
+			errors = $8,
+			compensated_at = $9,
+			compensation_action = $10,
+			compensated_rows = $11,
+			compensation_error = $12
+		WHERE id = $13
+		RETURNING updated_at
+	`
+
+	err = r.db.QueryRowContext(
+		ctx,
+		query,
+		string(run.Status),
+		nullTime(run.FinishedAt),
+		run.Fetched,
+		run.Validated,
+		run.Saved,
+		run.SaveFailures,
+		validationJSON,
+		pq.Array(run.Errors),
+		nullTime(run.CompensatedAt),
+		nullString(string(run.CompensationAction)),
+		run.CompensatedRows,
+		nullString(run.CompensationError),
+		run.ID,
+	).Scan(&run.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return repository.NotFound("scrape run")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update scrape run: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// GetByID retrieves a scrape run by ID
+func (r *ScrapeRunRepository) GetByID(ctx context.Context, id string) (*models.ScrapeRun, error) {
+	query := `SELECT ` + scrapeRunColumns + ` FROM scrape_runs WHERE id = $1`
+
+	run, err := scanScrapeRun(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, repository.NotFound("scrape run")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scrape run: %w", classifyError(err))
+	}
+
+	return run, nil
+}
+
+// List retrieves scrape runs based on the provided filter, newest first
+func (r *ScrapeRunRepository) List(ctx context.Context, filter repository.ScrapeRunFilter) ([]*models.ScrapeRun, error) {
+	query := `SELECT ` + scrapeRunColumns + ` FROM scrape_runs WHERE 1=1`
+
+	args := []interface{}{}
+	argPos := 1
+
+	if filter.ScraperName != "" {
+		query += fmt.Sprintf(" AND scraper_name = $%d", argPos)
+		args = append(args, filter.ScraperName)
+		argPos++
+	}
+
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		query += fmt.Sprintf(" AND status = ANY($%d)", argPos)
+		args = append(args, pq.Array(statuses))
+		argPos++
+	}
+
+	if filter.WorkflowID != "" {
+		query += fmt.Sprintf(" AND workflow_id = $%d", argPos)
+		args = append(args, filter.WorkflowID)
+		argPos++
+	}
+
+	if filter.WorkflowRunID != "" {
+		query += fmt.Sprintf(" AND workflow_run_id = $%d", argPos)
+		args = append(args, filter.WorkflowRunID)
+		argPos++
+	}
+
+	if filter.StartedAfter != nil {
+		query += fmt.Sprintf(" AND started_at >= $%d", argPos)
+		args = append(args, *filter.StartedAfter)
+		argPos++
+	}
+
+	query += " ORDER BY started_at DESC"
+
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT $%d", argPos)
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scrape runs: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var results []*models.ScrapeRun
+	for rows.Next() {
+		run, err := scanScrapeRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", classifyError(err))
+		}
+		results = append(results, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", classifyError(err))
+	}
+
+	return results, nil
+}
+
+// scanScrapeRun scans a row selected with scrapeRunColumns
+func scanScrapeRun(row rowScanner) (*models.ScrapeRun, error) {
+	run := &models.ScrapeRun{}
+	var status string
+	var finishedAt sql.NullTime
+	var validationJSON []byte
+	var workflowID, workflowRunID sql.NullString
+	var compensatedAt sql.NullTime
+	var compensationAction, compensationError sql.NullString
+
+	err := row.Scan(
+		&run.ID,
+		&run.ScraperName,
+		&status,
+		&run.StartedAt,
+		&finishedAt,
+		&run.Fetched,
+		&run.Validated,
+		&run.Saved,
+		&run.SaveFailures,
+		&validationJSON,
+		pq.Array(&run.Errors),
+		&workflowID,
+		&workflowRunID,
+		&compensatedAt,
+		&compensationAction,
+		&run.CompensatedRows,
+		&compensationError,
+		&run.CreatedAt,
+		&run.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	run.Status = models.ScrapeRunStatus(status)
+	if finishedAt.Valid {
+		run.FinishedAt = &finishedAt.Time
+	}
+	if workflowID.Valid {
+		run.WorkflowID = workflowID.String
+	}
+	if workflowRunID.Valid {
+		run.WorkflowRunID = workflowRunID.String
+	}
+	if compensatedAt.Valid {
+		run.CompensatedAt = &compensatedAt.Time
+	}
+	if compensationAction.Valid {
+		run.CompensationAction = models.CompensationAction(compensationAction.String)
+	}
+	if compensationError.Valid {
+		run.CompensationError = compensationError.String
+	}
+
+	if len(validationJSON) > 0 {
+		if err := json.Unmarshal(validationJSON, &run.Validation); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal validation summary: %w", err)
+		}
+	}
+
+	return run, nil
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+)
+
+// PriceRollupRepository reads pre-aggregated price statistics
+type PriceRollupRepository interface {
+	// ListRollups returns one rollup per bucket and group matching the
+	// filter, oldest bucket first
+	ListRollups(ctx context.Context, filter RollupFilter) ([]*models.PriceRollup, error)
+}
+
+// RollupFilter defines filtering options for listing price rollups. Rollups
+// are grouped by category, sub category, emirate, area, currency, unit and
+// period; dimensions left empty are not constrained, so each of their groups
+// is returned separately.
+type RollupFilter struct {
+	// Interval selects daily or weekly buckets; defaults to daily
+	Interval models.RollupInterval
+
+	// Category filters by category (exact match)
+	Category string
+
+	// SubCategory filters by sub category (exact match)
+	SubCategory string
+
+	// Emirate filters by location emirate (exact match)
+	Emirate string
+
+	// Area filters by location area (exact match)
+	Area string
+
+	// Currency filters by price currency (exact match)
+	Currency string
+
+	// Unit filters by price unit (exact match)
+	Unit string
+
+	// Period filters by the period a recurring price covers (exact match)
+	Period string
+
+	// Start filters buckets starting at or after Start
+	Start *time.Time
+
+	// End filters buckets starting at or before End
+	End *time.Time
+
+	// Limit specifies the maximum number of rollups to return
+	Limit int
+}
+
+// Validate checks the enumerated options of the filter
+func (f RollupFilter) Validate() error {
+	switch f.Interval {
+	case "", models.RollupDaily, models.RollupWeekly:
+	default:
+		return ValidationFailed(fmt.Errorf("unsupported rollup interval %q", f.Interval))
+	}
+	if f.Start != nil && f.End != nil && f.Start.After(*f.End) {
+		return ValidationFailed(fmt.Errorf("rollup start %s is after end %s", f.Start.Format(time.RFC3339), f.End.Format(time.RFC3339)))
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+)
+
+// ScrapeRunRepository defines the interface for persisting scrape runs
+type ScrapeRunRepository interface {
+	// Create records the start of a scrape run and assigns its ID
+	Create(ctx context.Context, run *models.ScrapeRun) error
+
+	// Update persists the counts, status and errors of an existing run
+	Update(ctx context.Context, run *models.ScrapeRun) error
+
+	// GetByID retrieves a scrape run by ID
+	GetByID(ctx context.Context, id string) (*models.ScrapeRun, error)
+
+	// List retrieves scrape runs based on the provided filter, newest first
+	List(ctx context.Context, filter ScrapeRunFilter) ([]*models.ScrapeRun, error)
+}
+
+// ScrapeRunFilter defines filtering options for listing scrape runs
+type ScrapeRunFilter struct {
+	// ScraperName filters by scraper name (exact match)
+	ScraperName string
+
+	// Statuses filters runs whose status is one of the given values
+	Statuses []models.ScrapeRunStatus
+
+	// WorkflowID filters by Temporal workflow ID
+	WorkflowID string
+
+	// WorkflowRunID filters by Temporal workflow run ID
+	WorkflowRunID string
+
+	// StartedAfter filters runs where started_at >= StartedAfter
+	StartedAfter *time.Time
+
+	// Limit specifies the maximum number of runs to return
+	Limit int
+}
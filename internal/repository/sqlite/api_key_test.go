@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+)
+
+func TestAPIKeyLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewAPIKeyRepository(db)
+	ctx := context.Background()
+
+	key := &models.APIKey{
+		Name:      "ingest bot",
+		Prefix:    "3f9a1c2b",
+		Hash:      "deadbeef",
+		Role:      models.RoleContributor,
+		CreatedBy: "apikey:a1b2c3d4",
+	}
+	if err := repo.Create(ctx, key); err != nil {
+		t.Fatalf("Failed to create api key: %v", err)
+	}
+	if key.ID == "" {
+		t.Fatal("Expected key ID to be generated")
+	}
+
+	duplicate := &models.APIKey{Name: "other", Prefix: key.Prefix, Hash: "cafe", Role: models.RoleReader}
+	if err := repo.Create(ctx, duplicate); !errors.Is(err, repository.ErrConflict) {
+		t.Errorf("Expected conflict for duplicate prefix, got %v", err)
+	}
+
+	invalid := &models.APIKey{Name: "other", Prefix: "00000000", Hash: "cafe", Role: models.Role("owner")}
+	if err := repo.Create(ctx, invalid); err == nil {
+		t.Error("Expected unknown role to be rejected")
+	}
+
+	got, err := repo.GetByPrefix(ctx, key.Prefix)
+	if err != nil {
+		t.Fatalf("Failed to get api key: %v", err)
+	}
+	if got.ID != key.ID || got.Hash != "deadbeef" || got.Role != models.RoleContributor || got.CreatedBy != "apikey:a1b2c3d4" {
+		t.Errorf("Unexpected api key: %+v", got)
+	}
+	if got.LastUsedAt != nil || got.Revoked() {
+		t.Errorf("Expected a new key to be unused and active: %+v", got)
+	}
+
+	usedAt := time.Now().UTC().Truncate(time.Millisecond)
+	if err := repo.Touch(ctx, key.ID, usedAt); err != nil {
+		t.Fatalf("Failed to touch api key: %v", err)
+	}
+	if err := repo.Revoke(ctx, key.ID, usedAt); err != nil {
+		t.Fatalf("Failed to revoke api key: %v", err)
+	}
+	if err := repo.Revoke(ctx, key.ID, usedAt); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected revoking twice to be not found, got %v", err)
+	}
+
+	got, err = repo.GetByID(ctx, key.ID)
+	if err != nil {
+		t.Fatalf("Failed to get api key: %v", err)
+	}
+	if got.LastUsedAt == nil || !got.LastUsedAt.Equal(usedAt) {
+		t.Errorf("Expected last_used_at %v, got %v", usedAt, got.LastUsedAt)
+	}
+	if !got.Revoked() {
+		t.Error("Expected key to be revoked")
+	}
+
+	keys, err := repo.List(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list api keys: %v", err)
+	}
+	if len(keys) != 1 {
+		t.Errorf("Expected 1 key, got %d", len(keys))
+	}
+
+	if _, err := repo.GetByID(ctx, "missing"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,1106 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/google/uuid"
+)
+
+// CostDataPointRepository implements the repository.CostDataPointRepository interface
+type CostDataPointRepository struct {
+	db *sql.DB
+}
+
+// NewCostDataPointRepository creates a new instance of CostDataPointRepository
+func NewCostDataPointRepository(db *sql.DB) *CostDataPointRepository {
+	return &CostDataPointRepository{db: db}
+}
+
+// insertCostDataPointQuery inserts a single cost data point
+const insertCostDataPointQuery = `
+		INSERT INTO cost_data_points (
+			id, category, sub_category, item_name, price, min_price, max_price,
+			median_price, sample_size, location, recorded_at, valid_from, valid_to,
+			source, source_url, confidence, currency, unit, period, tags, attributes, run_id,
+			natural_key, first_seen_at, last_seen_at, created_at, updated_at
+		) VALUES (
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		)
+	`
+
+// Create inserts a new cost data point into the database
+func (r *CostDataPointRepository) Create(ctx context.Context, cdp *models.CostDataPoint) error {
+	row, err := prepareInsert(cdp)
+	if err != nil {
+		return err
+	}
+
+	return insertTx(ctx, r.db, row)
+}
+
+// CreateBatch inserts many cost data points in a single transaction. Each
+// row is written behind a savepoint so only the offending rows are rolled
+// back and reported.
+func (r *CostDataPointRepository) CreateBatch(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	result, rows := prepareBatch(cdps)
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	written, err := r.writeRows(ctx, rows, func(tx *sql.Tx, row insertRow) (bool, error) {
+		return false, insertTx(ctx, tx, row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cost data points: %w", classifyError(err))
+	}
+
+	result.Inserted = written.Inserted
+	result.Failed = mergeBatchErrors(result.Failed, written.Failed)
+
+	return result, nil
+}
+
+// Upsert writes cost data points keyed on their natural key. When an active
+// row with the same key exists and its price is unchanged, that row is
+// refreshed in place; when the price has changed the old row is closed at the
+// new row's valid_from and a new row is inserted, preserving price history.
+// SQLite has a single writer and write transactions take the lock up front,
+// so no per-key locking is needed.
+func (r *CostDataPointRepository) Upsert(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	result, rows := prepareBatch(cdps)
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	written, err := r.writeRows(ctx, rows, func(tx *sql.Tx, row insertRow) (bool, error) {
+		return upsertTx(ctx, tx, row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to upsert cost data points: %w", classifyError(err))
+	}
+
+	result.Inserted = written.Inserted
+	result.Updated = written.Updated
+	result.Failed = mergeBatchErrors(result.Failed, written.Failed)
+
+	return result, nil
+}
+
+// prepareBatch prepares every cost data point for insertion, reporting the
+// ones that cannot be encoded as failed
+func prepareBatch(cdps []*models.CostDataPoint) (*repository.BatchResult, []insertRow) {
+	result := &repository.BatchResult{}
+
+	rows := make([]insertRow, 0, len(cdps))
+	for i, cdp := range cdps {
+		row, err := prepareInsert(cdp)
+		if err != nil {
+			result.Failed = append(result.Failed, repository.BatchError{Index: i, Err: err})
+			continue
+		}
+		row.index = i
+		rows = append(rows, row)
+	}
+
+	return result, rows
+}
+
+// upsertTx writes a single row by natural key within tx and reports whether
+// an existing row was refreshed in place
+func upsertTx(ctx context.Context, tx *sql.Tx, row insertRow) (bool, error) {
+	cdp := row.cdp
+
+	existing, err := scanCostDataPoint(tx.QueryRowContext(ctx, `SELECT `+costDataPointColumns+`
+		FROM cost_data_points
+		WHERE natural_key = ? AND (valid_to IS NULL OR valid_to > ?) AND deleted_at IS NULL
+		ORDER BY recorded_at DESC
+		LIMIT 1
+	`, row.naturalKey, timestamp(time.Now())))
+
+	switch {
+	case err == sql.ErrNoRows:
+		return false, insertTx(ctx, tx, row)
+
+	case err != nil:
+		return false, fmt.Errorf("failed to look up natural key: %w", classifyError(err))
+
+	case existing.Price != cdp.Price:
+		// Price moved: close the current row and start a new one. The listing
+		// itself is not new, so it keeps its original first-seen time. The
+		// closing run is recorded so compensating it reopens the row.
+		cdp.FirstSeenAt = existing.FirstSeenAt
+		_, err := tx.ExecContext(ctx, `
+			UPDATE cost_data_points SET valid_to = ?, closed_by_run_id = ?, updated_at = ?
+			WHERE id = ? AND recorded_at = ?
+		`, timestamp(cdp.ValidFrom), nullString(cdp.RunID), timestamp(now()), existing.ID, timestamp(existing.RecordedAt))
+		if err != nil {
+			return false, fmt.Errorf("failed to close previous cost data point: %w", classifyError(err))
+		}
+		return false, insertTx(ctx, tx, row)
+	}
+
+	// Same listing at the same price: refresh the existing row and mark it seen. run_id is left
+	// untouched so compensating a later run never invalidates rows it did not create.
+	updatedAt := now()
+	_, err = tx.ExecContext(ctx, `
+		UPDATE cost_data_points SET
+			min_price = ?,
+			max_price = ?,
+			median_price = ?,
+			sample_size = ?,
+			confidence = ?,
+			source_url = ?,
+			tags = ?,
+			attributes = ?,
+			valid_to = ?,
+			last_seen_at = ?,
+			updated_at = ?
+		WHERE id = ? AND recorded_at = ?
+	`,
+		nullFloat64(cdp.MinPrice),
+		nullFloat64(cdp.MaxPrice),
+		nullFloat64(cdp.MedianPrice),
+		cdp.SampleSize,
+		cdp.Confidence,
+		nullString(cdp.SourceURL),
+		row.tags,
+		row.attributes,
+		nullTimestamp(cdp.ValidTo),
+		timestamp(cdp.LastSeenAt),
+		timestamp(updatedAt),
+		existing.ID,
+		timestamp(existing.RecordedAt),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to refresh cost data point: %w", classifyError(err))
+	}
+
+	cdp.ID = existing.ID
+	cdp.RecordedAt = existing.RecordedAt
+	cdp.ValidFrom = existing.ValidFrom
+	cdp.RunID = existing.RunID
+	cdp.FirstSeenAt = existing.FirstSeenAt
+	cdp.CreatedAt = existing.CreatedAt
+	cdp.UpdatedAt = updatedAt
+
+	return true, nil
+}
+
+// mergeBatchErrors combines row failures and orders them by input position
+func mergeBatchErrors(a, b []repository.BatchError) []repository.BatchError {
+	merged := append(a, b...)
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].Index < merged[j].Index
+	})
+	return merged
+}
+
+// writeRows applies write to each row inside one transaction, rolling back to
+// a savepoint for each row that fails so the remaining rows are kept. write
+// reports whether the row updated an existing record rather than inserting.
+func (r *CostDataPointRepository) writeRows(
+	ctx context.Context,
+	rows []insertRow,
+	write func(tx *sql.Tx, row insertRow) (bool, error),
+) (*repository.BatchResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback()
+
+	result := &repository.BatchResult{}
+	for _, row := range rows {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT batch_row"); err != nil {
+			return nil, fmt.Errorf("failed to create savepoint: %w", classifyError(err))
+		}
+
+		updated, err := write(tx, row)
+		if err != nil {
+			if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT batch_row"); rbErr != nil {
+				return nil, fmt.Errorf("failed to roll back savepoint: %w", classifyError(rbErr))
+			}
+			result.Failed = append(result.Failed, repository.BatchError{Index: row.index, Err: err})
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT batch_row"); err != nil {
+			return nil, fmt.Errorf("failed to release savepoint: %w", classifyError(err))
+		}
+		if updated {
+			result.Updated++
+		} else {
+			result.Inserted++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+
+	return result, nil
+}
+
+// insertTx inserts a single prepared row and sets its created and updated
+// times
+func insertTx(ctx context.Context, q queryer, row insertRow) error {
+	createdAt := now()
+	if _, err := q.ExecContext(ctx, insertCostDataPointQuery, row.args(createdAt)...); err != nil {
+		return fmt.Errorf("failed to create cost data point: %w", classifyError(err))
+	}
+	row.cdp.CreatedAt = createdAt
+	row.cdp.UpdatedAt = createdAt
+	return nil
+}
+
+// insertRow holds a cost data point with its JSON columns already encoded
+type insertRow struct {
+	index      int
+	cdp        *models.CostDataPoint
+	location   string
+	tags       interface{}
+	attributes string
+	naturalKey string
+}
+
+// args returns the parameters for insertCostDataPointQuery, in order
+func (row insertRow) args(createdAt time.Time) []interface{} {
+	cdp := row.cdp
+	return []interface{}{
+		cdp.ID,
+		cdp.Category,
+		nullString(cdp.SubCategory),
+		cdp.ItemName,
+		cdp.Price,
+		nullFloat64(cdp.MinPrice),
+		nullFloat64(cdp.MaxPrice),
+		nullFloat64(cdp.MedianPrice),
+		cdp.SampleSize,
+		row.location,
+		timestamp(cdp.RecordedAt),
+		timestamp(cdp.ValidFrom),
+		nullTimestamp(cdp.ValidTo),
+		cdp.Source,
+		nullString(cdp.SourceURL),
+		cdp.Confidence,
+		cdp.Currency,
+		cdp.Unit,
+		cdp.Period,
+		row.tags,
+		row.attributes,
+		nullString(cdp.RunID),
+		row.naturalKey,
+		timestamp(cdp.FirstSeenAt),
+		timestamp(cdp.LastSeenAt),
+		timestamp(createdAt),
+		timestamp(createdAt),
+	}
+}
+
+// prepareInsert assigns an ID and default values to cdp and encodes its JSON columns
+func prepareInsert(cdp *models.CostDataPoint) (insertRow, error) {
+	if cdp == nil {
+		return insertRow{}, repository.ValidationFailed(errors.New("cost data point is nil"))
+	}
+
+	// Generate UUID if not provided
+	if cdp.ID == "" {
+		cdp.ID = uuid.NewString()
+	}
+
+	// Set default values if not provided
+	if cdp.RecordedAt.IsZero() {
+		cdp.RecordedAt = time.Now()
+	}
+	if cdp.ValidFrom.IsZero() {
+		cdp.ValidFrom = time.Now()
+	}
+	if cdp.SampleSize == 0 {
+		cdp.SampleSize = 1
+	}
+	if cdp.Confidence == 0 {
+		cdp.Confidence = 1.0
+	}
+	cdp.NormalizeUnit()
+	if cdp.FirstSeenAt.IsZero() {
+		cdp.FirstSeenAt = cdp.RecordedAt
+	}
+	if cdp.LastSeenAt.IsZero() {
+		cdp.LastSeenAt = cdp.RecordedAt
+	}
+
+	location, tags, attributes, err := encodeJSONColumns(cdp)
+	if err != nil {
+		return insertRow{}, err
+	}
+
+	return insertRow{
+		cdp:        cdp,
+		location:   location,
+		tags:       tags,
+		attributes: attributes,
+		naturalKey: cdp.NaturalKey(),
+	}, nil
+}
+
+// encodeJSONColumns marshals the location, tags and attributes of cdp. Tags
+// are NULL when there are none.
+func encodeJSONColumns(cdp *models.CostDataPoint) (location string, tags interface{}, attributes string, err error) {
+	locationJSON, err := json.Marshal(cdp.Location)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("failed to marshal location: %w", err)
+	}
+
+	if len(cdp.Tags) > 0 {
+		tagsJSON, err := json.Marshal(cdp.Tags)
+		if err != nil {
+			return "", nil, "", fmt.Errorf("failed to marshal tags: %w", err)
+		}
+		tags = string(tagsJSON)
+	}
+
+	attributesJSON, err := json.Marshal(cdp.Attributes)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("failed to marshal attributes: %w", err)
+	}
+
+	return string(locationJSON), tags, string(attributesJSON), nil
+}
+
+// GetByID retrieves a cost data point by ID and recorded_at timestamp
+func (r *CostDataPointRepository) GetByID(ctx context.Context, id string, recordedAt time.Time) (*models.CostDataPoint, error) {
+	query := `SELECT ` + costDataPointColumns + `
+		FROM cost_data_points
+		WHERE id = ? AND recorded_at = ? AND deleted_at IS NULL
+	`
+
+	cdp, err := scanCostDataPoint(r.db.QueryRowContext(ctx, query, id, timestamp(recordedAt)))
+	if err == sql.ErrNoRows {
+		return nil, repository.NotFound("cost data point")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost data point: %w", classifyError(err))
+	}
+
+	return cdp, nil
+}
+
+// List retrieves cost data points based on the provided filter
+func (r *CostDataPointRepository) List(ctx context.Context, filter repository.ListFilter) ([]*models.CostDataPoint, error) {
+	query, args, err := buildListQuery(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cost data points: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var results []*models.CostDataPoint
+	for rows.Next() {
+		cdp, err := scanCostDataPoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", classifyError(err))
+		}
+		results = append(results, cdp)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", classifyError(err))
+	}
+
+	return results, nil
+}
+
+// streamPageSize is the number of rows Stream reads per query
+var streamPageSize = 500
+
+// Stream reads the rows matching the filter a page at a time and calls fn
+// once each page is read. The database has a single connection, so holding
+// it while a slow consumer such as an export download runs would block every
+// other request, health checks included. Pages follow the (recorded_at, id)
+// cursor when ordering by recorded_at and the offset otherwise, where rows
+// written during iteration can shift between pages.
+func (r *CostDataPointRepository) Stream(ctx context.Context, filter repository.ListFilter, fn func(*models.CostDataPoint) error) error {
+	if err := filter.Validate(); err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+
+	keyset := filter.OrderBy == "" || filter.OrderBy == "recorded_at"
+	remaining := filter.Limit
+	page := filter
+	for {
+		page.Limit = streamPageSize
+		if remaining > 0 && remaining < streamPageSize {
+			page.Limit = remaining
+		}
+
+		points, err := r.List(ctx, page)
+		if err != nil {
+			return err
+		}
+		for _, cdp := range points {
+			if err := fn(cdp); err != nil {
+				return err
+			}
+		}
+
+		if len(points) < page.Limit {
+			return nil
+		}
+		if remaining > 0 {
+			if remaining -= len(points); remaining == 0 {
+				return nil
+			}
+		}
+
+		if keyset {
+			last := points[len(points)-1]
+			page.After = &repository.Cursor{RecordedAt: last.RecordedAt, ID: last.ID}
+			page.Offset = 0
+		} else {
+			page.Offset += len(points)
+		}
+	}
+}
+
+// buildListQuery renders the full SELECT for a filter, including cursor
+// position, ordering and pagination
+func buildListQuery(filter repository.ListFilter) (string, []interface{}, error) {
+	if err := filter.Validate(); err != nil {
+		return "", nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	where, args := buildListWhere(filter)
+
+	query := `SELECT ` + costDataPointColumns + `
+		FROM cost_data_points
+		WHERE 1=1` + where
+
+	// Keyset pagination: continue strictly after the cursor row
+	if filter.After != nil {
+		op := "<"
+		if filter.OrderDirection == repository.SortAsc {
+			op = ">"
+		}
+		query += fmt.Sprintf(" AND (recorded_at, id) %s (?, ?)", op)
+		args = append(args, timestamp(filter.After.RecordedAt), filter.After.ID)
+	}
+
+	// Order by the requested field, most recent first by default. The id
+	// tie-breaker keeps pagination stable when sort values repeat.
+	orderBy := "recorded_at"
+	if filter.OrderBy != "" {
+		orderBy = filter.OrderBy
+	}
+	direction := "DESC"
+	if filter.OrderDirection == repository.SortAsc {
+		direction = "ASC"
+	}
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", orderBy, direction, direction)
+
+	// Apply pagination; SQLite only accepts OFFSET after a LIMIT, where -1
+	// means no limit
+	if filter.Limit > 0 || filter.Offset > 0 {
+		limit := -1
+		if filter.Limit > 0 {
+			limit = filter.Limit
+		}
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	if filter.Offset > 0 {
+		query += " OFFSET ?"
+		args = append(args, filter.Offset)
+	}
+
+	return query, args, nil
+}
+
+// Count returns the number of cost data points matching the filter
+func (r *CostDataPointRepository) Count(ctx context.Context, filter repository.ListFilter) (int64, error) {
+	if err := filter.Validate(); err != nil {
+		return 0, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	where, args := buildListWhere(filter)
+
+	var count int64
+	query := `SELECT COUNT(*) FROM cost_data_points WHERE 1=1` + where
+	if err := r.db.QueryRowContext(ctx, query, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count cost data points: %w", classifyError(err))
+	}
+
+	return count, nil
+}
+
+// buildListWhere renders the filter as " AND ..." predicates with positional
+// arguments. Ordering and pagination are left to the caller.
+func buildListWhere(filter repository.ListFilter) (string, []interface{}) {
+	var where strings.Builder
+	args := []interface{}{}
+
+	add := func(predicate string, arg interface{}) {
+		where.WriteString(" AND " + predicate)
+		args = append(args, arg)
+	}
+
+	if filter.ID != "" {
+		add("id = ?", filter.ID)
+	}
+	if filter.Category != "" {
+		add("category = ?", filter.Category)
+	}
+	if filter.SubCategory != "" {
+		add("sub_category = ?", filter.SubCategory)
+	}
+	if filter.Source != "" {
+		add("source = ?", filter.Source)
+	}
+	if filter.Emirate != "" {
+		add("json_extract(location, '$.emirate') = ?", filter.Emirate)
+	}
+	if filter.City != "" {
+		add("json_extract(location, '$.city') = ?", filter.City)
+	}
+	if filter.Area != "" {
+		add("json_extract(location, '$.area') = ?", filter.Area)
+	}
+	if len(filter.Tags) > 0 {
+		if filter.TagMatch == repository.TagMatchAll {
+			for _, tag := range filter.Tags {
+				add("EXISTS (SELECT 1 FROM json_each(tags) WHERE value = ?)", tag)
+			}
+		} else {
+			where.WriteString(" AND EXISTS (SELECT 1 FROM json_each(tags) WHERE value IN (" + placeholders(len(filter.Tags)) + "))")
+			for _, tag := range filter.Tags {
+				args = append(args, tag)
+			}
+		}
+	}
+	if filter.MinPrice != nil {
+		add("price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		add("price <= ?", *filter.MaxPrice)
+	}
+
+	// json_extract returns strings as text, numbers as integers or reals and
+	// booleans as 0 or 1. Scrapers store some values as strings and others as
+	// numbers, so numeric and boolean values match either representation.
+	keys := make([]string, 0, len(filter.Attributes))
+	for key := range filter.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		candidates := attributeCandidates(filter.Attributes[key])
+		where.WriteString(" AND json_extract(attributes, ?) IN (" + placeholders(len(candidates)) + ")")
+		args = append(args, attributePath(key))
+		args = append(args, candidates...)
+	}
+
+	// SQLite has no spatial functions, so a radius search narrows to its
+	// bounding box, which uses the coordinates index, and then compares an
+	// equirectangular distance. The approximation is well under 1% for
+	// city-scale radii.
+	if filter.Near != nil {
+		box := filter.Near.BoundingBox()
+		addBoundingBox(&where, &args, box)
+
+		center := filter.Near.Center
+		kmPerLat := models.EarthRadiusKm * math.Pi / 180
+		kmPerLon := kmPerLat * math.Cos(center.Lat*math.Pi/180)
+		where.WriteString(" AND ((" + coordinatesLat + " - ?) * ?) * ((" + coordinatesLat + " - ?) * ?)" +
+			" + ((" + coordinatesLon + " - ?) * ?) * ((" + coordinatesLon + " - ?) * ?) <= ?")
+		args = append(args,
+			center.Lat, kmPerLat, center.Lat, kmPerLat,
+			center.Lon, kmPerLon, center.Lon, kmPerLon,
+			filter.Near.RadiusKm*filter.Near.RadiusKm)
+	}
+	if filter.Within != nil {
+		addBoundingBox(&where, &args, *filter.Within)
+	}
+
+	if filter.RunID != "" {
+		add("run_id = ?", filter.RunID)
+	}
+	if filter.ActiveOnly {
+		add("(valid_to IS NULL OR valid_to > ?)", timestamp(time.Now()))
+	}
+	if filter.StartDate != nil {
+		add("recorded_at >= ?", timestamp(*filter.StartDate))
+	}
+	if filter.EndDate != nil {
+		add("recorded_at <= ?", timestamp(*filter.EndDate))
+	}
+	if !filter.IncludeDeleted {
+		where.WriteString(" AND deleted_at IS NULL")
+	}
+
+	return where.String(), args
+}
+
+// Coordinate expressions, written exactly as indexed by migration 010
+const (
+	coordinatesLat = "json_extract(location, '$.coordinates.lat')"
+	coordinatesLon = "json_extract(location, '$.coordinates.lon')"
+)
+
+// addBoundingBox appends the predicates selecting coordinates within box
+func addBoundingBox(where *strings.Builder, args *[]interface{}, box repository.BoundingBox) {
+	where.WriteString(" AND " + coordinatesLat + " BETWEEN ? AND ? AND " + coordinatesLon + " BETWEEN ? AND ?")
+	*args = append(*args, box.MinLat, box.MaxLat, box.MinLon, box.MaxLon)
+}
+
+// placeholders returns n comma-separated parameter placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// attributePath returns the JSON path of a top-level attribute key
+func attributePath(key string) string {
+	return `$."` + strings.ReplaceAll(key, `"`, `\"`) + `"`
+}
+
+// attributeCandidates returns the SQL values an attribute filter value may
+// be extracted as
+func attributeCandidates(value string) []interface{} {
+	candidates := []interface{}{value}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		candidates = append(candidates, f)
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		candidates = append(candidates, b)
+	}
+	return candidates
+}
+
+// Update updates an existing cost data point and records a revision of the
+// change in the same transaction. The natural key is recomputed, so a later
+// Upsert of the edited listing finds the row.
+func (r *CostDataPointRepository) Update(ctx context.Context, cdp *models.CostDataPoint) error {
+	cdp.NormalizeUnit()
+
+	location, tags, attributes, err := encodeJSONColumns(cdp)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback()
+
+	// The write transaction already holds the database lock, so the revision
+	// reflects what was replaced
+	old, err := scanCostDataPoint(tx.QueryRowContext(ctx, `SELECT `+costDataPointColumns+`
+		FROM cost_data_points
+		WHERE id = ? AND recorded_at = ? AND deleted_at IS NULL
+	`, cdp.ID, timestamp(cdp.RecordedAt)))
+	if err == sql.ErrNoRows {
+		return repository.NotFound("cost data point")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get cost data point: %w", classifyError(err))
+	}
+
+	query := `
+		UPDATE cost_data_points SET
+			category = ?,
+			sub_category = ?,
+			item_name = ?,
+			price = ?,
+			min_price = ?,
+			max_price = ?,
+			median_price = ?,
+			sample_size = ?,
+			location = ?,
+			valid_from = ?,
+			valid_to = ?,
+			source = ?,
+			source_url = ?,
+			confidence = ?,
+			currency = ?,
+			unit = ?,
+			period = ?,
+			tags = ?,
+			attributes = ?,
+			run_id = ?,
+			natural_key = ?,
+			updated_at = ?
+		WHERE id = ? AND recorded_at = ?
+	`
+
+	updatedAt := now()
+	_, err = tx.ExecContext(
+		ctx,
+		query,
+		cdp.Category,
+		nullString(cdp.SubCategory),
+		cdp.ItemName,
+		cdp.Price,
+		nullFloat64(cdp.MinPrice),
+		nullFloat64(cdp.MaxPrice),
+		nullFloat64(cdp.MedianPrice),
+		cdp.SampleSize,
+		location,
+		timestamp(cdp.ValidFrom),
+		nullTimestamp(cdp.ValidTo),
+		cdp.Source,
+		nullString(cdp.SourceURL),
+		cdp.Confidence,
+		cdp.Currency,
+		cdp.Unit,
+		cdp.Period,
+		tags,
+		attributes,
+		nullString(cdp.RunID),
+		cdp.NaturalKey(),
+		timestamp(updatedAt),
+		cdp.ID,
+		timestamp(cdp.RecordedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update cost data point: %w", classifyError(err))
+	}
+	cdp.UpdatedAt = updatedAt
+
+	audit := repository.AuditFromContext(ctx)
+	if err := insertRevision(ctx, tx, models.NewRevision(models.RevisionUpdate, old, cdp, audit.Actor, audit.Reason)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit update: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// Delete removes a cost data point by ID and recorded_at timestamp and
+// records a revision holding the removed values
+func (r *CostDataPointRepository) Delete(ctx context.Context, id string, recordedAt time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM cost_data_points WHERE id = ? AND recorded_at = ?
+		RETURNING ` + costDataPointColumns
+
+	old, err := scanCostDataPoint(tx.QueryRowContext(ctx, query, id, timestamp(recordedAt)))
+	if err == sql.ErrNoRows {
+		return repository.NotFound("cost data point")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete cost data point: %w", classifyError(err))
+	}
+
+	audit := repository.AuditFromContext(ctx)
+	if err := insertRevision(ctx, tx, models.NewRevision(models.RevisionDelete, old, nil, audit.Actor, audit.Reason)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit delete: %w", classifyError(err))
+	}
+
+	return nil
+}
+
+// SoftDelete hides a cost data point from reads by setting deleted_at and
+// records a delete revision
+func (r *CostDataPointRepository) SoftDelete(ctx context.Context, id string, recordedAt time.Time) error {
+	_, err := r.setDeletedAt(ctx, id, recordedAt, models.RevisionDelete)
+	return err
+}
+
+// Restore clears deleted_at on a soft-deleted cost data point and records a
+// restore revision
+func (r *CostDataPointRepository) Restore(ctx context.Context, id string, recordedAt time.Time) (*models.CostDataPoint, error) {
+	return r.setDeletedAt(ctx, id, recordedAt, models.RevisionRestore)
+}
+
+// setDeletedAt soft deletes (RevisionDelete) or restores (RevisionRestore) a
+// cost data point in a transaction that also records the revision
+func (r *CostDataPointRepository) setDeletedAt(ctx context.Context, id string, recordedAt time.Time, action models.RevisionAction) (*models.CostDataPoint, error) {
+	changedAt := now()
+	current, deletedAt := "deleted_at IS NULL", interface{}(timestamp(changedAt))
+	if action == models.RevisionRestore {
+		current, deletedAt = "deleted_at IS NOT NULL", nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback()
+
+	old, err := scanCostDataPoint(tx.QueryRowContext(ctx, `SELECT `+costDataPointColumns+`
+		FROM cost_data_points
+		WHERE id = ? AND recorded_at = ? AND `+current,
+		id, timestamp(recordedAt)))
+	if err == sql.ErrNoRows {
+		return nil, repository.NotFound("cost data point")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cost data point: %w", classifyError(err))
+	}
+
+	cdp, err := scanCostDataPoint(tx.QueryRowContext(ctx, `
+		UPDATE cost_data_points SET deleted_at = ?, updated_at = ?
+		WHERE id = ? AND recorded_at = ?
+		RETURNING `+costDataPointColumns,
+		deletedAt, timestamp(changedAt), id, timestamp(recordedAt)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s cost data point: %w", action, classifyError(err))
+	}
+
+	audit := repository.AuditFromContext(ctx)
+	if err := insertRevision(ctx, tx, models.NewRevision(action, old, cdp, audit.Actor, audit.Reason)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit %s: %w", action, classifyError(err))
+	}
+
+	return cdp, nil
+}
+
+// InvalidateByRunID closes valid_to on every still-valid data point written
+// by the given run and reopens the rows the run superseded
+func (r *CostDataPointRepository) InvalidateByRunID(ctx context.Context, runID string, validTo time.Time) (int64, error) {
+	query := `
+		UPDATE cost_data_points SET valid_to = ?2, updated_at = ?3
+		WHERE run_id = ?1 AND (valid_to IS NULL OR valid_to > ?2)
+	`
+
+	rows, err := r.compensateRun(ctx, runID, query, runID, timestamp(validTo), timestamp(now()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to invalidate run data: %w", err)
+	}
+	return rows, nil
+}
+
+// DeleteByRunID removes every data point written by the given run and
+// reopens the rows the run superseded
+func (r *CostDataPointRepository) DeleteByRunID(ctx context.Context, runID string) (int64, error) {
+	rows, err := r.compensateRun(ctx, runID, `DELETE FROM cost_data_points WHERE run_id = ?`, runID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete run data: %w", err)
+	}
+	return rows, nil
+}
+
+// reopenClosedByRunQuery reopens the rows closed by a run's upserts. A row is
+// left closed when its listing has another active row not written by the run,
+// such as one from a later run.
+const reopenClosedByRunQuery = `
+		UPDATE cost_data_points SET valid_to = NULL, closed_by_run_id = NULL, updated_at = ?2
+		WHERE closed_by_run_id = ?1
+			AND run_id IS NOT ?1
+			AND deleted_at IS NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM cost_data_points newer
+				WHERE newer.natural_key = cost_data_points.natural_key
+					AND (newer.id, newer.recorded_at) <> (cost_data_points.id, cost_data_points.recorded_at)
+					AND newer.run_id IS NOT ?1
+					AND (newer.valid_to IS NULL OR newer.valid_to > ?2)
+					AND newer.deleted_at IS NULL
+			)
+	`
+
+// compensateRun applies query to the rows of a run and reopens the rows the
+// run closed in one transaction, returning the number of rows affected by
+// both
+func (r *CostDataPointRepository) compensateRun(ctx context.Context, runID, query string, args ...interface{}) (int64, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, classifyError(err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", classifyError(err))
+	}
+
+	result, err = tx.ExecContext(ctx, reopenClosedByRunQuery, runID, timestamp(now()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to reopen superseded rows: %w", classifyError(err))
+	}
+	reopened, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", classifyError(err))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+
+	return rowsAffected + reopened, nil
+}
+
+// CloseUnseenListings closes still-valid listings in scope that were last seen before seenBefore
+func (r *CostDataPointRepository) CloseUnseenListings(ctx context.Context, scope repository.ListingScope, seenBefore, validTo time.Time) (int64, error) {
+	query := `
+		UPDATE cost_data_points SET valid_to = ?1, updated_at = ?3
+		WHERE (valid_to IS NULL OR valid_to > ?1)
+			AND last_seen_at < ?2
+			AND substr(natural_key, 1, length(source) + 9) = source || '|listing|'
+	`
+
+	args := []interface{}{timestamp(validTo), timestamp(seenBefore), timestamp(now())}
+
+	if scope.Source != "" {
+		query += " AND source = ?"
+		args = append(args, scope.Source)
+	}
+
+	if scope.Category != "" {
+		query += " AND category = ?"
+		args = append(args, scope.Category)
+	}
+
+	if scope.SubCategory != "" {
+		query += " AND sub_category = ?"
+		args = append(args, scope.SubCategory)
+	}
+
+	if scope.Emirate != "" {
+		query += " AND json_extract(location, '$.emirate') = ?"
+		args = append(args, scope.Emirate)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to close unseen listings: %w", classifyError(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", classifyError(err))
+	}
+
+	return rowsAffected, nil
+}
+
+// costDataPointColumns lists the columns read by scanCostDataPoint, in order
+const costDataPointColumns = `
+			id, category, sub_category, item_name, price, min_price, max_price,
+			median_price, sample_size, location, recorded_at, valid_from, valid_to,
+			source, source_url, confidence, currency, unit, period, tags, attributes,
+			run_id, first_seen_at, last_seen_at, created_at, updated_at, deleted_at`
+
+// scanCostDataPoint scans a row selected with costDataPointColumns
+func scanCostDataPoint(row rowScanner) (*models.CostDataPoint, error) {
+	cdp := &models.CostDataPoint{}
+	var locationJSON, tagsJSON, attributesJSON []byte
+	var subCategory, sourceURL, runID sql.NullString
+	var minPrice, maxPrice, medianPrice sql.NullFloat64
+	var recordedAt, validFrom, validTo, firstSeenAt, lastSeenAt nullTime
+	var createdAt, updatedAt, deletedAt nullTime
+
+	err := row.Scan(
+		&cdp.ID,
+		&cdp.Category,
+		&subCategory,
+		&cdp.ItemName,
+		&cdp.Price,
+		&minPrice,
+		&maxPrice,
+		&medianPrice,
+		&cdp.SampleSize,
+		&locationJSON,
+		&recordedAt,
+		&validFrom,
+		&validTo,
+		&cdp.Source,
+		&sourceURL,
+		&cdp.Confidence,
+		&cdp.Currency,
+		&cdp.Unit,
+		&cdp.Period,
+		&tagsJSON,
+		&attributesJSON,
+		&runID,
+		&firstSeenAt,
+		&lastSeenAt,
+		&createdAt,
+		&updatedAt,
+		&deletedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal JSON fields
+	if err := json.Unmarshal(locationJSON, &cdp.Location); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal location: %w", err)
+	}
+
+	if len(tagsJSON) > 0 {
+		if err := json.Unmarshal(tagsJSON, &cdp.Tags); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal tags: %w", err)
+		}
+	}
+
+	if len(attributesJSON) > 0 {
+		if err := json.Unmarshal(attributesJSON, &cdp.Attributes); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal attributes: %w", err)
+		}
+	}
+
+	cdp.RecordedAt = recordedAt.Time
+	cdp.ValidFrom = validFrom.Time
+	cdp.ValidTo = validTo.ptr()
+	cdp.FirstSeenAt = firstSeenAt.Time
+	cdp.LastSeenAt = lastSeenAt.Time
+	cdp.CreatedAt = createdAt.Time
+	cdp.UpdatedAt = updatedAt.Time
+	cdp.DeletedAt = deletedAt.ptr()
+
+	// Handle nullable fields
+	if subCategory.Valid {
+		cdp.SubCategory = subCategory.String
+	}
+	if sourceURL.Valid {
+		cdp.SourceURL = sourceURL.String
+	}
+	if runID.Valid {
+		cdp.RunID = runID.String
+	}
+	if minPrice.Valid {
+		cdp.MinPrice = minPrice.Float64
+	}
+	if maxPrice.Valid {
+		cdp.MaxPrice = maxPrice.Float64
+	}
+	if medianPrice.Valid {
+		cdp.MedianPrice = medianPrice.Float64
+	}
+
+	return cdp, nil
+}
@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/adonese/cost-of-living/internal/models"
+)
+
+// insertRevision records a revision within the transaction that made the change
+func insertRevision(ctx context.Context, tx *sql.Tx, rev *models.Revision) error {
+	oldJSON, err := revisionValuesJSON(rev.OldValues)
+	if err != nil {
+		return err
+	}
+	newJSON, err := revisionValuesJSON(rev.NewValues)
+	if err != nil {
+		return err
+	}
+	changedJSON, err := json.Marshal(rev.ChangedFields)
+	if err != nil {
+		return fmt.Errorf("failed to marshal changed fields: %w", err)
+	}
+
+	query := `
+		INSERT INTO cost_data_point_revisions (
+			data_point_id, recorded_at, action, actor, reason, changed_fields,
+			old_values, new_values, created_at
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	createdAt := now()
+	result, err := tx.ExecContext(
+		ctx,
+		query,
+		rev.DataPointID,
+		timestamp(rev.RecordedAt),
+		string(rev.Action),
+		rev.Actor,
+		nullString(rev.Reason),
+		string(changedJSON),
+		oldJSON,
+		newJSON,
+		timestamp(createdAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record revision: %w", classifyError(err))
+	}
+
+	if rev.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("failed to get revision id: %w", classifyError(err))
+	}
+	rev.CreatedAt = createdAt
+
+	return nil
+}
+
+// revisionValuesJSON encodes one side of a revision, or NULL if it is absent
+func revisionValuesJSON(cdp *models.CostDataPoint) (interface{}, error) {
+	if cdp == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(cdp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal revision values: %w", err)
+	}
+	return string(data), nil
+}
+
+// History returns the revisions recorded for a cost data point, newest first
+func (r *CostDataPointRepository) History(ctx context.Context, id string) ([]*models.Revision, error) {
+	query := `
+		SELECT id, data_point_id, recorded_at, action, actor, reason, changed_fields,
+			old_values, new_values, created_at
+		FROM cost_data_point_revisions
+		WHERE data_point_id = ?
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list revisions: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var revisions []*models.Revision
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan revision: %w", classifyError(err))
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating revisions: %w", classifyError(err))
+	}
+
+	return revisions, nil
+}
+
+// scanRevision scans a row of cost_data_point_revisions
+func scanRevision(row rowScanner) (*models.Revision, error) {
+	var (
+		rev           models.Revision
+		action        string
+		reason        sql.NullString
+		changedFields []byte
+		oldValues     []byte
+		newValues     []byte
+		recordedAt    nullTime
+		createdAt     nullTime
+	)
+
+	err := row.Scan(
+		&rev.ID,
+		&rev.DataPointID,
+		&recordedAt,
+		&action,
+		&rev.Actor,
+		&reason,
+		&changedFields,
+		&oldValues,
+		&newValues,
+		&createdAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rev.RecordedAt = recordedAt.Time
+	rev.CreatedAt = createdAt.Time
+	rev.Action = models.RevisionAction(action)
+	rev.Reason = reason.String
+	if len(changedFields) > 0 {
+		if err := json.Unmarshal(changedFields, &rev.ChangedFields); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal changed fields: %w", err)
+		}
+	}
+	if len(oldValues) > 0 {
+		rev.OldValues = &models.CostDataPoint{}
+		if err := json.Unmarshal(oldValues, rev.OldValues); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal old values: %w", err)
+		}
+	}
+	if len(newValues) > 0 {
+		rev.NewValues = &models.CostDataPoint{}
+		if err := json.Unmarshal(newValues, rev.NewValues); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal new values: %w", err)
+		}
+	}
+
+	return &rev, nil
+}
@@ -0,0 +1,820 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/pkg/database"
+	"github.com/golang-migrate/migrate/v4"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
+)
+
+// setupTestDB creates a fresh SQLite database in a temporary directory and
+// applies migrations/sqlite to it
+func setupTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := database.Connect(&database.Config{
+		Driver: database.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "test.db"),
+	})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m := newTestMigrate(t, db.GetConn())
+	if err := m.Up(); err != nil {
+		t.Fatalf("Failed to apply migrations: %v", err)
+	}
+
+	return db.GetConn()
+}
+
+func newTestMigrate(t *testing.T, db *sql.DB) *migrate.Migrate {
+	t.Helper()
+
+	driver, err := migratesqlite.WithInstance(db, &migratesqlite.Config{})
+	if err != nil {
+		t.Fatalf("Failed to create migration driver: %v", err)
+	}
+	m, err := migrate.NewWithDatabaseInstance("file://../../../migrations/sqlite", "sqlite3", driver)
+	if err != nil {
+		t.Fatalf("Failed to create migrate instance: %v", err)
+	}
+	return m
+}
+
+// createTestCostDataPoint creates a sample cost data point for testing. Each
+// call gets its own listing URL, so points created within the same
+// microsecond do not collide on the natural key index.
+func createTestCostDataPoint() *models.CostDataPoint {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	return &models.CostDataPoint{
+		Category:    "Housing",
+		SubCategory: "Rent",
+		ItemName:    "1BR Apartment in Marina",
+		Price:       85000.00,
+		MinPrice:    80000.00,
+		MaxPrice:    90000.00,
+		MedianPrice: 85000.00,
+		SampleSize:  5,
+		Location: models.Location{
+			Emirate: "Dubai",
+			City:    "Dubai",
+			Area:    "Marina",
+			Coordinates: &models.GeoPoint{
+				Lat: 25.0803,
+				Lon: 55.1396,
+			},
+		},
+		RecordedAt: now,
+		ValidFrom:  now,
+		Source:     "test",
+		SourceURL:  "https://example.com/test/" + uuid.NewString(),
+		Confidence: 1.0,
+		Currency:   "AED",
+		Period:     "year",
+		Tags:       []string{"rent", "apartment", "marina"},
+		Attributes: map[string]interface{}{
+			"bedrooms":  1,
+			"bathrooms": 1,
+			"furnished": true,
+		},
+	}
+}
+
+func TestMigrationsRoundTrip(t *testing.T) {
+	db := setupTestDB(t)
+
+	if err := newTestMigrate(t, db).Down(); err != nil {
+		t.Fatalf("Failed to roll back migrations: %v", err)
+	}
+
+	var tables int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name LIKE 'cost_data_point%'`).Scan(&tables)
+	if err != nil {
+		t.Fatalf("Failed to inspect schema: %v", err)
+	}
+	if tables != 0 {
+		t.Errorf("Expected no cost data point tables after down, got %d", tables)
+	}
+}
+
+func TestMigrationSplitsUnit(t *testing.T) {
+	db := setupTestDB(t)
+	m := newTestMigrate(t, db)
+	if err := m.Migrate(10); err != nil {
+		t.Fatalf("Failed to roll back to 010: %v", err)
+	}
+
+	rows := []struct {
+		id, category, subCategory, source, unit, attributes string
+		price                                               float64
+	}{
+		{"rent", "Housing", "Rent", "bayut", "AED", `{}`, 85000},
+		{"bedspace", "Housing", "Shared Accommodation", "dubizzle", "AED", `{}`, 900},
+		{"aadc-water", "Utilities", "Water", "aadc_official", "AED per 1000 IG", `{}`, 8.55},
+		{"dewa-power", "Utilities", "Electricity", "dewa_official", "AED", `{"rate_type":"slab","unit":"fils_per_kwh"}`, 0.23},
+		{"dewa-water", "Utilities", "Water", "dewa_official", "AED", `{"rate_type":"slab","unit":"fils_per_ig"}`, 0.0357},
+		{"careem-km", "Transportation", "Ride Sharing", "careem_rates", "AED", `{"rate_type":"per_km"}`, 1.97},
+		{"rta-taxi", "Transportation", "Taxi", "rta_official", "AED/km", `{}`, 1.96},
+		{"sewerage", "Utilities", "Sewerage", "sewa_official", "percentage of water charge", `{}`, 0.5},
+	}
+	for _, r := range rows {
+		_, err := db.Exec(`INSERT INTO cost_data_points (id, category, sub_category, item_name, price, location, source, unit, attributes)
+			VALUES (?, ?, ?, ?, ?, '{"emirate":"Dubai"}', ?, ?, ?)`,
+			r.id, r.category, r.subCategory, r.id, r.price, r.source, r.unit, r.attributes)
+		if err != nil {
+			t.Fatalf("Failed to insert %s: %v", r.id, err)
+		}
+	}
+
+	if err := m.Up(); err != nil {
+		t.Fatalf("Failed to apply 011: %v", err)
+	}
+
+	want := map[string]struct {
+		currency, unit, period string
+		price                  float64
+	}{
+		"rent":       {"AED", "", "year", 85000},
+		"bedspace":   {"AED", "", "month", 900},
+		"aadc-water": {"AED", "IG", "", 0.00855},
+		"dewa-power": {"AED", "kWh", "", 0.23},
+		"dewa-water": {"AED", "IG", "", 0.0357},
+		"careem-km":  {"AED", "km", "", 1.97},
+		"rta-taxi":   {"AED", "km", "", 1.96},
+		"sewerage":   {"", "fraction", "", 0.5},
+	}
+	for id, w := range want {
+		var currency, unit, period string
+		var price float64
+		err := db.QueryRow(`SELECT currency, unit, period, price FROM cost_data_points WHERE id = ?`, id).
+			Scan(&currency, &unit, &period, &price)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", id, err)
+		}
+		if currency != w.currency || unit != w.unit || period != w.period || math.Abs(price-w.price) > 1e-9 {
+			t.Errorf("%s: got %q %q %q %v, want %+v", id, currency, unit, period, price, w)
+		}
+	}
+}
+
+func TestCreateAndGetByID(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	cdp := createTestCostDataPoint()
+	if err := repo.Create(ctx, cdp); err != nil {
+		t.Fatalf("Failed to create cost data point: %v", err)
+	}
+	if cdp.ID == "" {
+		t.Error("Expected ID to be generated")
+	}
+	if cdp.CreatedAt.IsZero() || cdp.UpdatedAt.IsZero() {
+		t.Error("Expected timestamps to be set")
+	}
+
+	got, err := repo.GetByID(ctx, cdp.ID, cdp.RecordedAt)
+	if err != nil {
+		t.Fatalf("Failed to get cost data point: %v", err)
+	}
+	if got.ItemName != cdp.ItemName || got.Price != cdp.Price || got.MinPrice != cdp.MinPrice {
+		t.Errorf("Unexpected cost data point: %+v", got)
+	}
+	if !got.RecordedAt.Equal(cdp.RecordedAt) {
+		t.Errorf("Expected recorded_at %v, got %v", cdp.RecordedAt, got.RecordedAt)
+	}
+	if got.Location.Area != "Marina" || got.Location.Coordinates == nil {
+		t.Errorf("Expected location to round trip, got %+v", got.Location)
+	}
+	if len(got.Tags) != 3 || got.Attributes["furnished"] != true {
+		t.Errorf("Expected tags and attributes to round trip, got %v %v", got.Tags, got.Attributes)
+	}
+	if got.SourceURL != cdp.SourceURL {
+		t.Errorf("Expected source URL %q, got %q", cdp.SourceURL, got.SourceURL)
+	}
+	if got.Currency != "AED" || got.Unit != "" || got.Period != "year" {
+		t.Errorf("Expected AED per year, got %q/%q/%q", got.Currency, got.Unit, got.Period)
+	}
+
+	t.Run("duplicate key conflicts", func(t *testing.T) {
+		dup := createTestCostDataPoint()
+		dup.ID = cdp.ID
+		dup.RecordedAt = cdp.RecordedAt
+		if err := repo.Create(ctx, dup); !errors.Is(err, repository.ErrConflict) {
+			t.Errorf("Expected conflict, got %v", err)
+		}
+	})
+
+	t.Run("missing point is not found", func(t *testing.T) {
+		_, err := repo.GetByID(ctx, "missing", cdp.RecordedAt)
+		if !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Expected not found, got %v", err)
+		}
+	})
+}
+
+func TestList(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	base := time.Now().UTC().Truncate(time.Second)
+	expired := base.Add(-time.Hour)
+
+	marina := createTestCostDataPoint()
+	marina.RecordedAt = base.Add(-3 * time.Minute)
+
+	downtown := createTestCostDataPoint()
+	downtown.ItemName = "2BR Apartment in Downtown"
+	downtown.Price = 120000
+	downtown.RecordedAt = base.Add(-2 * time.Minute)
+	downtown.Location.Area = "Downtown"
+	downtown.Location.Coordinates = &models.GeoPoint{Lat: 25.1972, Lon: 55.2744}
+	downtown.Tags = []string{"rent", "apartment"}
+	downtown.Attributes = map[string]interface{}{"bedrooms": "2", "furnished": false}
+
+	sharjah := createTestCostDataPoint()
+	sharjah.Category = "Food"
+	sharjah.ItemName = "Bread"
+	sharjah.Price = 5
+	sharjah.RecordedAt = base.Add(-1 * time.Minute)
+	sharjah.Location = models.Location{Emirate: "Sharjah", City: "Sharjah"}
+	sharjah.Tags = nil
+	sharjah.Attributes = nil
+	sharjah.ValidTo = &expired
+
+	if _, err := repo.CreateBatch(ctx, []*models.CostDataPoint{marina, downtown, sharjah}); err != nil {
+		t.Fatalf("Failed to create cost data points: %v", err)
+	}
+
+	minPrice := 100000.0
+	tests := []struct {
+		name   string
+		filter repository.ListFilter
+		want   []string
+	}{
+		{"newest first", repository.ListFilter{}, []string{sharjah.ID, downtown.ID, marina.ID}},
+		{"ascending price", repository.ListFilter{OrderBy: "price", OrderDirection: repository.SortAsc}, []string{sharjah.ID, marina.ID, downtown.ID}},
+		{"category", repository.ListFilter{Category: "Housing"}, []string{downtown.ID, marina.ID}},
+		{"emirate", repository.ListFilter{Emirate: "Sharjah"}, []string{sharjah.ID}},
+		{"area", repository.ListFilter{Area: "Downtown"}, []string{downtown.ID}},
+		{"any tag", repository.ListFilter{Tags: []string{"marina", "missing"}}, []string{marina.ID}},
+		{"all tags", repository.ListFilter{Tags: []string{"rent", "marina"}, TagMatch: repository.TagMatchAll}, []string{marina.ID}},
+		{"min price", repository.ListFilter{MinPrice: &minPrice}, []string{downtown.ID}},
+		{"numeric attribute stored as number", repository.ListFilter{Attributes: map[string]string{"bedrooms": "1"}}, []string{marina.ID}},
+		{"numeric attribute stored as string", repository.ListFilter{Attributes: map[string]string{"bedrooms": "2"}}, []string{downtown.ID}},
+		{"boolean attribute", repository.ListFilter{Attributes: map[string]string{"furnished": "false"}}, []string{downtown.ID}},
+		{"active only", repository.ListFilter{ActiveOnly: true}, []string{downtown.ID, marina.ID}},
+		{"start date", repository.ListFilter{StartDate: &downtown.RecordedAt}, []string{sharjah.ID, downtown.ID}},
+		{"near", repository.ListFilter{Near: &repository.GeoRadius{Center: models.GeoPoint{Lat: 25.08, Lon: 55.14}, RadiusKm: 3}}, []string{marina.ID}},
+		{"near wide radius", repository.ListFilter{Near: &repository.GeoRadius{Center: models.GeoPoint{Lat: 25.08, Lon: 55.14}, RadiusKm: 25}}, []string{downtown.ID, marina.ID}},
+		{"within bounding box", repository.ListFilter{Within: &repository.BoundingBox{MinLat: 25.1, MinLon: 55.2, MaxLat: 25.3, MaxLon: 55.3}}, []string{downtown.ID}},
+		{"limit and offset", repository.ListFilter{Limit: 1, Offset: 1}, []string{downtown.ID}},
+		{"offset without limit", repository.ListFilter{Offset: 2}, []string{marina.ID}},
+		{"after cursor", repository.ListFilter{After: &repository.Cursor{RecordedAt: downtown.RecordedAt, ID: downtown.ID}}, []string{marina.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := repo.List(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("Failed to list: %v", err)
+			}
+			got := make([]string, len(results))
+			for i, cdp := range results {
+				got[i] = cdp.ID
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Expected %v, got %v", tt.want, got)
+				}
+			}
+
+			count, err := repo.Count(ctx, tt.filter)
+			if err != nil {
+				t.Fatalf("Failed to count: %v", err)
+			}
+			if tt.filter.Limit == 0 && tt.filter.Offset == 0 && tt.filter.After == nil && count != int64(len(tt.want)) {
+				t.Errorf("Expected count %d, got %d", len(tt.want), count)
+			}
+		})
+	}
+
+	t.Run("rejects unknown order field", func(t *testing.T) {
+		_, err := repo.List(ctx, repository.ListFilter{OrderBy: "price; DROP TABLE cost_data_points"})
+		if !errors.Is(err, repository.ErrValidationFailed) {
+			t.Errorf("Expected validation error, got %v", err)
+		}
+	})
+}
+
+func TestCreateBatch(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	first := createTestCostDataPoint()
+	if err := repo.Create(ctx, first); err != nil {
+		t.Fatalf("Failed to create cost data point: %v", err)
+	}
+
+	dup := createTestCostDataPoint()
+	dup.ID = first.ID
+	dup.RecordedAt = first.RecordedAt
+
+	result, err := repo.CreateBatch(ctx, []*models.CostDataPoint{createTestCostDataPoint(), dup, createTestCostDataPoint()})
+	if err != nil {
+		t.Fatalf("Failed to create batch: %v", err)
+	}
+	if result.Inserted != 2 {
+		t.Errorf("Expected 2 inserted, got %d", result.Inserted)
+	}
+	if len(result.Failed) != 1 || result.Failed[0].Index != 1 {
+		t.Fatalf("Expected row 1 to fail, got %+v", result.Failed)
+	}
+	if !errors.Is(result.Failed[0], repository.ErrConflict) {
+		t.Errorf("Expected conflict, got %v", result.Failed[0].Err)
+	}
+}
+
+func TestBatchRejectsNilRows(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	for name, write := range map[string]func(context.Context, []*models.CostDataPoint) (*repository.BatchResult, error){
+		"CreateBatch": repo.CreateBatch,
+		"Upsert":      repo.Upsert,
+	} {
+		result, err := write(ctx, []*models.CostDataPoint{nil, createTestCostDataPoint()})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if result.Inserted != 1 {
+			t.Errorf("%s: expected 1 inserted, got %d", name, result.Inserted)
+		}
+		if len(result.Failed) != 1 || result.Failed[0].Index != 0 || !errors.Is(result.Failed[0], repository.ErrValidationFailed) {
+			t.Errorf("%s: expected row 0 to fail validation, got %+v", name, result.Failed)
+		}
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	listing := func(price float64) *models.CostDataPoint {
+		cdp := createTestCostDataPoint()
+		cdp.Category = "Housing"
+		cdp.Price = price
+		cdp.SourceURL = "https://example.com/listing/upsert-test"
+		return cdp
+	}
+
+	first := listing(5000)
+	result, err := repo.Upsert(ctx, []*models.CostDataPoint{first})
+	if err != nil {
+		t.Fatalf("Failed to upsert: %v", err)
+	}
+	if result.Inserted != 1 || result.Updated != 0 {
+		t.Fatalf("Expected 1 insert, got %+v", result)
+	}
+
+	t.Run("same price refreshes existing row", func(t *testing.T) {
+		again := listing(5000)
+		result, err := repo.Upsert(ctx, []*models.CostDataPoint{again})
+		if err != nil {
+			t.Fatalf("Failed to upsert: %v", err)
+		}
+		if result.Updated != 1 || result.Inserted != 0 {
+			t.Fatalf("Expected 1 update, got %+v", result)
+		}
+		if again.ID != first.ID {
+			t.Errorf("Expected existing ID %s, got %s", first.ID, again.ID)
+		}
+		if !again.UpdatedAt.After(first.UpdatedAt) {
+			t.Error("Expected updated_at to advance")
+		}
+	})
+
+	t.Run("price change closes old row", func(t *testing.T) {
+		changed := listing(5500)
+		result, err := repo.Upsert(ctx, []*models.CostDataPoint{changed})
+		if err != nil {
+			t.Fatalf("Failed to upsert: %v", err)
+		}
+		if result.Inserted != 1 {
+			t.Fatalf("Expected 1 insert, got %+v", result)
+		}
+		if !changed.FirstSeenAt.Equal(first.FirstSeenAt) {
+			t.Errorf("Expected first_seen_at %v to carry over, got %v", first.FirstSeenAt, changed.FirstSeenAt)
+		}
+
+		previous, err := repo.GetByID(ctx, first.ID, first.RecordedAt)
+		if err != nil {
+			t.Fatalf("Failed to get previous row: %v", err)
+		}
+		if previous.ValidTo == nil {
+			t.Error("Expected previous row to be closed")
+		}
+	})
+}
+
+func TestCompensationReopensSupersededRows(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	listing := func(price float64, runID string) *models.CostDataPoint {
+		cdp := createTestCostDataPoint()
+		cdp.Price = price
+		cdp.SourceURL = "https://example.com/listing/compensation-test"
+		cdp.RunID = runID
+		return cdp
+	}
+	upsert := func(cdp *models.CostDataPoint) {
+		t.Helper()
+		if _, err := repo.Upsert(ctx, []*models.CostDataPoint{cdp}); err != nil {
+			t.Fatalf("Failed to upsert: %v", err)
+		}
+	}
+	activePrice := func() float64 {
+		t.Helper()
+		active, err := repo.List(ctx, repository.ListFilter{Source: "test", ActiveOnly: true})
+		if err != nil {
+			t.Fatalf("Failed to list active rows: %v", err)
+		}
+		if len(active) != 1 {
+			t.Fatalf("Expected 1 active row for the listing, got %d", len(active))
+		}
+		return active[0].Price
+	}
+
+	upsert(listing(5000, "run-a"))
+
+	t.Run("invalidating a run reopens the row it superseded", func(t *testing.T) {
+		runB := "run-b"
+		upsert(listing(5500, runB))
+		if got := activePrice(); got != 5500 {
+			t.Fatalf("Expected the new price to be active, got %v", got)
+		}
+
+		rows, err := repo.InvalidateByRunID(ctx, runB, time.Now())
+		if err != nil {
+			t.Fatalf("Failed to invalidate run: %v", err)
+		}
+		if rows != 2 {
+			t.Errorf("Expected 1 invalidated and 1 reopened row, got %d", rows)
+		}
+		if got := activePrice(); got != 5000 {
+			t.Errorf("Expected the superseded price to be active again, got %v", got)
+		}
+	})
+
+	t.Run("deleting a run reopens the row it superseded", func(t *testing.T) {
+		runC := "run-c"
+		upsert(listing(6000, runC))
+
+		rows, err := repo.DeleteByRunID(ctx, runC)
+		if err != nil {
+			t.Fatalf("Failed to delete run: %v", err)
+		}
+		if rows != 2 {
+			t.Errorf("Expected 1 deleted and 1 reopened row, got %d", rows)
+		}
+		if got := activePrice(); got != 5000 {
+			t.Errorf("Expected the superseded price to be active again, got %v", got)
+		}
+	})
+
+	t.Run("a later run's row is not superseded twice", func(t *testing.T) {
+		runD, runE := "run-d", "run-e"
+		upsert(listing(6500, runD))
+		upsert(listing(7000, runE))
+
+		if _, err := repo.InvalidateByRunID(ctx, runD, time.Now()); err != nil {
+			t.Fatalf("Failed to invalidate run: %v", err)
+		}
+		if got := activePrice(); got != 7000 {
+			t.Errorf("Expected the later run's price to stay the only active one, got %v", got)
+		}
+	})
+}
+
+func TestCloseUnseenListings(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	seenAt := time.Now()
+
+	stale := createTestCostDataPoint()
+	stale.SourceURL = "https://example.com/listing/stale"
+	stale.LastSeenAt = seenAt.Add(-24 * time.Hour)
+
+	fresh := createTestCostDataPoint()
+	fresh.SourceURL = "https://example.com/listing/fresh"
+	fresh.LastSeenAt = seenAt
+
+	item := createTestCostDataPoint()
+	item.Category = "Food"
+	item.LastSeenAt = seenAt.Add(-24 * time.Hour)
+
+	if _, err := repo.CreateBatch(ctx, []*models.CostDataPoint{stale, fresh, item}); err != nil {
+		t.Fatalf("Failed to create listings: %v", err)
+	}
+
+	closed, err := repo.CloseUnseenListings(ctx, repository.ListingScope{Source: "test", Emirate: "Dubai"}, seenAt, seenAt)
+	if err != nil {
+		t.Fatalf("Failed to close unseen listings: %v", err)
+	}
+	if closed != 1 {
+		t.Errorf("Expected 1 closed listing, got %d", closed)
+	}
+
+	got, err := repo.GetByID(ctx, stale.ID, stale.RecordedAt)
+	if err != nil {
+		t.Fatalf("Failed to get stale listing: %v", err)
+	}
+	if got.ValidTo == nil {
+		t.Error("Expected stale listing to be closed")
+	}
+}
+
+func TestRunIDOperations(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	inRun := createTestCostDataPoint()
+	inRun.RunID = "run-1"
+	other := createTestCostDataPoint()
+	other.RunID = "run-2"
+	if _, err := repo.CreateBatch(ctx, []*models.CostDataPoint{inRun, other}); err != nil {
+		t.Fatalf("Failed to create cost data points: %v", err)
+	}
+
+	invalidated, err := repo.InvalidateByRunID(ctx, "run-1", time.Now())
+	if err != nil {
+		t.Fatalf("Failed to invalidate run: %v", err)
+	}
+	if invalidated != 1 {
+		t.Errorf("Expected 1 invalidated row, got %d", invalidated)
+	}
+
+	active, err := repo.Count(ctx, repository.ListFilter{ActiveOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to count: %v", err)
+	}
+	if active != 1 {
+		t.Errorf("Expected 1 active row, got %d", active)
+	}
+
+	deleted, err := repo.DeleteByRunID(ctx, "run-2")
+	if err != nil {
+		t.Fatalf("Failed to delete run: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected 1 deleted row, got %d", deleted)
+	}
+}
+
+func TestStream(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	batch := make([]*models.CostDataPoint, 5)
+	for i := range batch {
+		batch[i] = createTestCostDataPoint()
+	}
+	if _, err := repo.CreateBatch(ctx, batch); err != nil {
+		t.Fatalf("Failed to create cost data points: %v", err)
+	}
+
+	seen := 0
+	stop := errors.New("stop")
+	err := repo.Stream(ctx, repository.ListFilter{}, func(*models.CostDataPoint) error {
+		seen++
+		if seen == 3 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected fn error to be returned, got %v", err)
+	}
+	if seen != 3 {
+		t.Errorf("Expected iteration to stop after 3 rows, got %d", seen)
+	}
+}
+
+func TestStreamReleasesConnectionBetweenPages(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	defer func(size int) { streamPageSize = size }(streamPageSize)
+	streamPageSize = 2
+
+	base := time.Now().UTC().Truncate(time.Second)
+	batch := make([]*models.CostDataPoint, 5)
+	for i := range batch {
+		batch[i] = createTestCostDataPoint()
+		batch[i].RecordedAt = base.Add(-time.Duration(i) * time.Minute)
+	}
+	if _, err := repo.CreateBatch(ctx, batch); err != nil {
+		t.Fatalf("Failed to create cost data points: %v", err)
+	}
+
+	for _, filter := range []repository.ListFilter{{}, {OrderBy: "price"}, {Limit: 3}} {
+		want, err := repo.List(ctx, filter)
+		if err != nil {
+			t.Fatalf("Failed to list: %v", err)
+		}
+
+		// The single connection is free while fn runs, so fn can query
+		var got []string
+		err = repo.Stream(ctx, filter, func(cdp *models.CostDataPoint) error {
+			queryCtx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+			if _, err := repo.Count(queryCtx, repository.ListFilter{}); err != nil {
+				return err
+			}
+			got = append(got, cdp.ID)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Stream(%+v) failed: %v", filter, err)
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("Stream(%+v) returned %d rows, want %d", filter, len(got), len(want))
+		}
+		for i := range want {
+			if got[i] != want[i].ID {
+				t.Errorf("Stream(%+v) row %d = %s, want %s", filter, i, got[i], want[i].ID)
+			}
+		}
+	}
+}
+
+func TestUpdateAndHistory(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCostDataPointRepository(db)
+	ctx := repository.WithAudit(context.Background(), "alice", "price correction")
+
+	cdp := createTestCostDataPoint()
+	if err := repo.Create(ctx, cdp); err != nil {
+		t.Fatalf("Failed to create cost data point: %v", err)
+	}
+
+	cdp.Price = 86000
+	cdp.Tags = []string{"rent"}
+	if err := repo.Update(ctx, cdp); err != nil {
+		t.Fatalf("Failed to update cost data point: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, cdp.ID, cdp.RecordedAt)
+	if err != nil {
+		t.Fatalf("Failed to get cost data point: %v", err)
+	}
+	if got.Price != 86000 || len(got.Tags) != 1 {
+		t.Errorf("Expected update to persist, got price %v tags %v", got.Price, got.Tags)
+	}
+
+	if err := repo.Delete(ctx, cdp.ID, cdp.RecordedAt); err != nil {
+		t.Fatalf("Failed to delete cost data point: %v", err)
+	}
+	if err := repo.Delete(ctx, cdp.ID, cdp.RecordedAt); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected second delete to be not found, got %v", err)
+	}
+
+	revisions, err := repo.History(ctx, cdp.ID)
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(revisions) != 2 {
+		t.Fatalf("Expected 2 revisions, got %d", len(revisions))
+	}
+	if revisions[0].Action != models.RevisionDelete || revisions[1].Action != models.RevisionUpdate {
+		t.Errorf("Expected delete then update, got %s then %s", revisions[0].Action, revisions[1].Action)
+	}
+	update := revisions[1]
+	if update.Actor != "alice" || update.Reason != "price correction" {
+		t.Errorf("Expected audit to be recorded, got %q %q", update.Actor, update.Reason)
+	}
+	if update.OldValues == nil || update.OldValues.Price != 85000 || update.NewValues.Price != 86000 {
+		t.Errorf("Expected old and new prices, got %+v", update)
+	}
+	if len(update.ChangedFields) == 0 {
+		t.Error("Expected changed fields to be recorded")
+	}
+
+	t.Run("update of missing point is not found", func(t *testing.T) {
+		if err := repo.Update(ctx, cdp); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("Expected not found, got %v", err)
+		}
+	})
+}
+
+func TestUpdateRecomputesNaturalKey(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	cdp := createTestCostDataPoint()
+	if err := repo.Create(ctx, cdp); err != nil {
+		t.Fatalf("Failed to create cost data point: %v", err)
+	}
+
+	cdp.SourceURL = "https://example.com/listing/after-edit"
+	if err := repo.Update(ctx, cdp); err != nil {
+		t.Fatalf("Failed to update cost data point: %v", err)
+	}
+
+	again := createTestCostDataPoint()
+	again.SourceURL = cdp.SourceURL
+	again.Price = cdp.Price
+	result, err := repo.Upsert(ctx, []*models.CostDataPoint{again})
+	if err != nil {
+		t.Fatalf("Failed to upsert: %v", err)
+	}
+	if result.Updated != 1 || again.ID != cdp.ID {
+		t.Errorf("Expected the edited row %s to be refreshed, got %+v with ID %s", cdp.ID, result, again.ID)
+	}
+
+	active, err := repo.Count(ctx, repository.ListFilter{ActiveOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to count: %v", err)
+	}
+	if active != 1 {
+		t.Errorf("Expected 1 active listing, got %d", active)
+	}
+}
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCostDataPointRepository(db)
+	ctx := context.Background()
+
+	cdp := createTestCostDataPoint()
+	if err := repo.Create(ctx, cdp); err != nil {
+		t.Fatalf("Failed to create cost data point: %v", err)
+	}
+
+	if err := repo.SoftDelete(ctx, cdp.ID, cdp.RecordedAt); err != nil {
+		t.Fatalf("Failed to soft delete: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, cdp.ID, cdp.RecordedAt); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected soft-deleted point to be hidden, got %v", err)
+	}
+	if err := repo.SoftDelete(ctx, cdp.ID, cdp.RecordedAt); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected second soft delete to be not found, got %v", err)
+	}
+
+	count, err := repo.Count(ctx, repository.ListFilter{IncludeDeleted: true})
+	if err != nil {
+		t.Fatalf("Failed to count: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected deleted point to be counted with IncludeDeleted, got %d", count)
+	}
+
+	restored, err := repo.Restore(ctx, cdp.ID, cdp.RecordedAt)
+	if err != nil {
+		t.Fatalf("Failed to restore: %v", err)
+	}
+	if restored.DeletedAt != nil {
+		t.Error("Expected deleted_at to be cleared")
+	}
+	if _, err := repo.Restore(ctx, cdp.ID, cdp.RecordedAt); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected restore of live point to be not found, got %v", err)
+	}
+
+	revisions, err := repo.History(ctx, cdp.ID)
+	if err != nil {
+		t.Fatalf("Failed to get history: %v", err)
+	}
+	if len(revisions) != 2 || revisions[0].Action != models.RevisionRestore {
+		t.Errorf("Expected restore and delete revisions, got %d", len(revisions))
+	}
+}
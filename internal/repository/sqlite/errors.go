@@ -0,0 +1,28 @@
+package sqlite
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+
+	"github.com/adonese/cost-of-living/internal/repository"
+)
+
+// classifyError wraps driver errors in the matching repository error kind
+// so callers can tell a duplicate key or a locked database from a bug.
+// Errors that are already classified or not recognised are returned as is.
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if classified, ok := classifySQLiteError(err); ok {
+		return classified
+	}
+
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) {
+		return repository.DriverError(repository.ErrUnavailable, err)
+	}
+
+	return err
+}
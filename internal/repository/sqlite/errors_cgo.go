@@ -0,0 +1,35 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"errors"
+
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/mattn/go-sqlite3"
+)
+
+// classifySQLiteError maps SQLite result codes to repository error kinds.
+// ok is false when err is not a recognised SQLite error.
+func classifySQLiteError(err error) (error, bool) {
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return nil, false
+	}
+
+	switch sqliteErr.Code {
+	case sqlite3.ErrConstraint:
+		if sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey {
+			return repository.DriverError(repository.ErrConflict, err), true
+		}
+		// CHECK, NOT NULL and foreign key violations
+		return repository.DriverError(repository.ErrValidationFailed, err), true
+	case sqlite3.ErrMismatch, sqlite3.ErrTooBig, sqlite3.ErrRange:
+		return repository.DriverError(repository.ErrValidationFailed, err), true
+	case sqlite3.ErrBusy, sqlite3.ErrLocked, sqlite3.ErrCantOpen, sqlite3.ErrFull, sqlite3.ErrIoErr:
+		// another writer holds the lock, or the file cannot be used
+		return repository.DriverError(repository.ErrUnavailable, err), true
+	}
+	return err, true
+}
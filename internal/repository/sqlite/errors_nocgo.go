@@ -0,0 +1,9 @@
+//go:build !cgo
+
+package sqlite
+
+// classifySQLiteError is a no-op without cgo: the SQLite driver is not
+// compiled in, so opening a database already fails
+func classifySQLiteError(err error) (error, bool) {
+	return nil, false
+}
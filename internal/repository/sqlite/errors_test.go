@@ -0,0 +1,43 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{"unique violation", sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}, repository.ErrConflict},
+		{"primary key violation", sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintPrimaryKey}, repository.ErrConflict},
+		{"check violation", sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintCheck}, repository.ErrValidationFailed},
+		{"busy", sqlite3.Error{Code: sqlite3.ErrBusy}, repository.ErrUnavailable},
+		{"bad connection", fmt.Errorf("query: %w", driver.ErrBadConn), repository.ErrUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := classifyError(tt.err); !errors.Is(err, tt.kind) {
+				t.Errorf("classifyError(%v) = %v, want kind %v", tt.err, err, tt.kind)
+			}
+		})
+	}
+
+	syntax := sqlite3.Error{Code: sqlite3.ErrError}
+	if err := classifyError(syntax); err != error(syntax) {
+		t.Errorf("classifyError() should leave unrecognised errors unchanged, got %v", err)
+	}
+	if classifyError(nil) != nil {
+		t.Error("classifyError(nil) should be nil")
+	}
+}
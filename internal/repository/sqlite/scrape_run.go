@@ -0,0 +1,309 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/google/uuid"
+)
+
+// ScrapeRunRepository implements the repository.ScrapeRunRepository interface
+type ScrapeRunRepository struct {
+	db *sql.DB
+}
+
+// NewScrapeRunRepository creates a new instance of ScrapeRunRepository
+func NewScrapeRunRepository(db *sql.DB) *ScrapeRunRepository {
+	return &ScrapeRunRepository{db: db}
+}
+
+const scrapeRunColumns = `
+			id, scraper_name, status, started_at, finished_at, fetched, validated,
+			saved, save_failures, validation, errors, workflow_id, workflow_run_id,
+			compensated_at, compensation_action, compensated_rows, compensation_error,
+			created_at, updated_at`
+
+// Create records the start of a scrape run and assigns its ID
+func (r *ScrapeRunRepository) Create(ctx context.Context, run *models.ScrapeRun) error {
+	if run.StartedAt.IsZero() {
+		run.StartedAt = time.Now()
+	}
+	if run.Status == "" {
+		run.Status = models.ScrapeRunRunning
+	}
+
+	validationJSON, err := json.Marshal(run.Validation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal validation summary: %w", err)
+	}
+	errorsJSON, err := errorsJSON(run.Errors)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO scrape_runs (
+			id, scraper_name, status, started_at, finished_at, fetched, validated,
+			saved, save_failures, validation, errors, workflow_id, workflow_run_id,
+			created_at, updated_at
+		) VALUES (
+			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
+		)
+	`
+
+	id := uuid.NewString()
+	createdAt := now()
+	_, err = r.db.ExecContext(
+		ctx,
+		query,
+		id,
+		run.ScraperName,
+		string(run.Status),
+		timestamp(run.StartedAt),
+		nullTimestamp(run.FinishedAt),
+		run.Fetched,
+		run.Validated,
+		run.Saved,
+		run.SaveFailures,
+		string(validationJSON),
+		errorsJSON,
+		nullString(run.WorkflowID),
+		nullString(run.WorkflowRunID),
+		timestamp(createdAt),
+		timestamp(createdAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create scrape run: %w", classifyError(err))
+	}
+
+	run.ID = id
+	run.CreatedAt = createdAt
+	run.UpdatedAt = createdAt
+
+	return nil
+}
+
+// Update persists the counts, status and errors of an existing run
+func (r *ScrapeRunRepository) Update(ctx context.Context, run *models.ScrapeRun) error {
+	validationJSON, err := json.Marshal(run.Validation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal validation summary: %w", err)
+	}
+	errorsJSON, err := errorsJSON(run.Errors)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE scrape_runs SET
+			status = ?,
+			finished_at = ?,
+			fetched = ?,
+			validated = ?,
+			saved = ?,
+			save_failures = ?,
+			validation = ?,
+			errors = ?,
+			compensated_at = ?,
+			compensation_action = ?,
+			compensated_rows = ?,
+			compensation_error = ?,
+			updated_at = ?
+		WHERE id = ?
+	`
+
+	updatedAt := now()
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		string(run.Status),
+		nullTimestamp(run.FinishedAt),
+		run.Fetched,
+		run.Validated,
+		run.Saved,
+		run.SaveFailures,
+		string(validationJSON),
+		errorsJSON,
+		nullTimestamp(run.CompensatedAt),
+		nullString(string(run.CompensationAction)),
+		run.CompensatedRows,
+		nullString(run.CompensationError),
+		timestamp(updatedAt),
+		run.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update scrape run: %w", classifyError(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", classifyError(err))
+	}
+	if rowsAffected == 0 {
+		return repository.NotFound("scrape run")
+	}
+	run.UpdatedAt = updatedAt
+
+	return nil
+}
+
+// GetByID retrieves a scrape run by ID
+func (r *ScrapeRunRepository) GetByID(ctx context.Context, id string) (*models.ScrapeRun, error) {
+	query := `SELECT ` + scrapeRunColumns + ` FROM scrape_runs WHERE id = ?`
+
+	run, err := scanScrapeRun(r.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, repository.NotFound("scrape run")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scrape run: %w", classifyError(err))
+	}
+
+	return run, nil
+}
+
+// List retrieves scrape runs based on the provided filter, newest first
+func (r *ScrapeRunRepository) List(ctx context.Context, filter repository.ScrapeRunFilter) ([]*models.ScrapeRun, error) {
+	query := `SELECT ` + scrapeRunColumns + ` FROM scrape_runs WHERE 1=1`
+
+	args := []interface{}{}
+
+	if filter.ScraperName != "" {
+		query += " AND scraper_name = ?"
+		args = append(args, filter.ScraperName)
+	}
+
+	if len(filter.Statuses) > 0 {
+		query += " AND status IN (" + placeholders(len(filter.Statuses)) + ")"
+		for _, status := range filter.Statuses {
+			args = append(args, string(status))
+		}
+	}
+
+	if filter.WorkflowID != "" {
+		query += " AND workflow_id = ?"
+		args = append(args, filter.WorkflowID)
+	}
+
+	if filter.WorkflowRunID != "" {
+		query += " AND workflow_run_id = ?"
+		args = append(args, filter.WorkflowRunID)
+	}
+
+	if filter.StartedAfter != nil {
+		query += " AND started_at >= ?"
+		args = append(args, timestamp(*filter.StartedAfter))
+	}
+
+	query += " ORDER BY started_at DESC"
+
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scrape runs: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var results []*models.ScrapeRun
+	for rows.Next() {
+		run, err := scanScrapeRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", classifyError(err))
+		}
+		results = append(results, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", classifyError(err))
+	}
+
+	return results, nil
+}
+
+// errorsJSON encodes run errors as a JSON array, or NULL if there are none
+func errorsJSON(errs []string) (interface{}, error) {
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(errs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal run errors: %w", err)
+	}
+	return string(data), nil
+}
+
+// scanScrapeRun scans a row selected with scrapeRunColumns
+func scanScrapeRun(row rowScanner) (*models.ScrapeRun, error) {
+	run := &models.ScrapeRun{}
+	var status string
+	var startedAt, finishedAt, compensatedAt, createdAt, updatedAt nullTime
+	var validationJSON, errorsJSON []byte
+	var workflowID, workflowRunID sql.NullString
+	var compensationAction, compensationError sql.NullString
+
+	err := row.Scan(
+		&run.ID,
+		&run.ScraperName,
+		&status,
+		&startedAt,
+		&finishedAt,
+		&run.Fetched,
+		&run.Validated,
+		&run.Saved,
+		&run.SaveFailures,
+		&validationJSON,
+		&errorsJSON,
+		&workflowID,
+		&workflowRunID,
+		&compensatedAt,
+		&compensationAction,
+		&run.CompensatedRows,
+		&compensationError,
+		&createdAt,
+		&updatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	run.Status = models.ScrapeRunStatus(status)
+	run.StartedAt = startedAt.Time
+	run.FinishedAt = finishedAt.ptr()
+	run.CompensatedAt = compensatedAt.ptr()
+	run.CreatedAt = createdAt.Time
+	run.UpdatedAt = updatedAt.Time
+	if workflowID.Valid {
+		run.WorkflowID = workflowID.String
+	}
+	if workflowRunID.Valid {
+		run.WorkflowRunID = workflowRunID.String
+	}
+	if compensationAction.Valid {
+		run.CompensationAction = models.CompensationAction(compensationAction.String)
+	}
+	if compensationError.Valid {
+		run.CompensationError = compensationError.String
+	}
+
+	if len(validationJSON) > 0 {
+		if err := json.Unmarshal(validationJSON, &run.Validation); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal validation summary: %w", err)
+		}
+	}
+	if len(errorsJSON) > 0 {
+		if err := json.Unmarshal(errorsJSON, &run.Errors); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal run errors: %w", err)
+		}
+	}
+
+	return run, nil
+}
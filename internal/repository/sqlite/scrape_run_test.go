@@ -0,0 +1,75 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+)
+
+func TestScrapeRunLifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewScrapeRunRepository(db)
+	ctx := context.Background()
+
+	run := &models.ScrapeRun{
+		ScraperName: "test",
+		WorkflowID:  "wf-test",
+	}
+	if err := repo.Create(ctx, run); err != nil {
+		t.Fatalf("Failed to create scrape run: %v", err)
+	}
+	if run.ID == "" {
+		t.Fatal("Expected run ID to be generated")
+	}
+	if run.Status != models.ScrapeRunRunning {
+		t.Errorf("Expected status %q, got %q", models.ScrapeRunRunning, run.Status)
+	}
+
+	finishedAt := time.Now()
+	run.Status = models.ScrapeRunSucceeded
+	run.FinishedAt = &finishedAt
+	run.Fetched = 10
+	run.Validated = 9
+	run.Saved = 8
+	run.SaveFailures = 1
+	run.Validation = models.ScrapeRunValidation{Total: 10, Valid: 9, Invalid: 1}
+	run.Errors = []string{"duplicate listing"}
+	if err := repo.Update(ctx, run); err != nil {
+		t.Fatalf("Failed to update scrape run: %v", err)
+	}
+
+	got, err := repo.GetByID(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("Failed to get scrape run: %v", err)
+	}
+	if got.Status != models.ScrapeRunSucceeded || got.Saved != 8 || got.Validation.Invalid != 1 {
+		t.Errorf("Unexpected scrape run: %+v", got)
+	}
+	if len(got.Errors) != 1 {
+		t.Errorf("Expected 1 error, got %d", len(got.Errors))
+	}
+	if got.FinishedAt == nil {
+		t.Error("Expected finished_at to be set")
+	}
+
+	runs, err := repo.List(ctx, repository.ScrapeRunFilter{
+		ScraperName: "test",
+		WorkflowID:  "wf-test",
+		Statuses:    []models.ScrapeRunStatus{models.ScrapeRunSucceeded, models.ScrapeRunFailed},
+	})
+	if err != nil {
+		t.Fatalf("Failed to list scrape runs: %v", err)
+	}
+	if len(runs) != 1 {
+		t.Errorf("Expected 1 run, got %d", len(runs))
+	}
+
+	missing := &models.ScrapeRun{ID: "missing"}
+	if err := repo.Update(ctx, missing); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+// Package sqlite implements the repository interfaces on SQLite, for
+// development and small self-hosted deployments that do not run PostgreSQL.
+// The schema lives in migrations/sqlite and mirrors the PostgreSQL one,
+// minus the TimescaleDB rollups and compression.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// timeLayout is the fixed-width UTC format timestamps are stored in, so
+// that text comparison and ordering match time order
+const timeLayout = "2006-01-02 15:04:05.000000"
+
+// timestamp formats t for storage. Like PostgreSQL, precision is
+// microseconds, so a time read back compares equal to the one written.
+func timestamp(t time.Time) string {
+	return t.UTC().Round(time.Microsecond).Format(timeLayout)
+}
+
+// nullTimestamp formats t for storage, or NULL if it is nil
+func nullTimestamp(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return timestamp(*t)
+}
+
+// now returns the current time at stored precision
+func now() time.Time {
+	return time.Now().UTC().Round(time.Microsecond)
+}
+
+// nullTime scans a timestamp column. The driver returns time.Time for
+// columns declared TIMESTAMP but text for expressions and RETURNING clauses,
+// so both are accepted.
+type nullTime struct {
+	Time  time.Time
+	Valid bool
+}
+
+// Scan implements sql.Scanner
+func (nt *nullTime) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		nt.Time, nt.Valid = time.Time{}, false
+		return nil
+	case time.Time:
+		nt.Time, nt.Valid = v.UTC(), true
+		return nil
+	case string:
+		return nt.parse(v)
+	case []byte:
+		return nt.parse(string(v))
+	}
+	return fmt.Errorf("cannot scan %T into timestamp", value)
+}
+
+func (nt *nullTime) parse(s string) error {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999", time.RFC3339Nano} {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			nt.Time, nt.Valid = t.UTC(), true
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid timestamp %q", s)
+}
+
+// ptr returns the scanned time, or nil if the column was NULL
+func (nt nullTime) ptr() *time.Time {
+	if !nt.Valid {
+		return nil
+	}
+	t := nt.Time
+	return &t
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// Helper functions to handle nullable fields
+
+func nullString(s string) sql.NullString {
+	if s == "" {
+		return sql.NullString{Valid: false}
+	}
+	return sql.NullString{String: s, Valid: true}
+}
+
+func nullFloat64(f float64) sql.NullFloat64 {
+	if f == 0 {
+		return sql.NullFloat64{Valid: false}
+	}
+	return sql.NullFloat64{Float64: f, Valid: true}
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/units"
 )
 
 // ElectricityRate represents a single electricity rate tier
@@ -231,7 +232,7 @@ func convertElectricityToDataPoints(rates []ElectricityRate, sourceURL string) [
 		itemName := fmt.Sprintf("AADC Electricity %s - %s", tierName, strings.Title(rate.CustomerType))
 
 		// Convert fils to AED (100 fils = 1 AED)
-		priceAED := rate.RateFils / 100.0
+		priceAED := units.FilsToAED(rate.RateFils)
 
 		// Create attributes map
 		attributes := map[string]interface{}{
@@ -263,7 +264,8 @@ func convertElectricityToDataPoints(rates []ElectricityRate, sourceURL string) [
 			Source:      "aadc_official",
 			SourceURL:   sourceURL,
 			Confidence:  0.98, // Official source
-			Unit:        "AED per kWh",
+			Currency:    "AED",
+			Unit:        units.KWh,
 			RecordedAt:  now,
 			ValidFrom:   now,
 			SampleSize:  1,
@@ -300,7 +302,7 @@ func convertWaterToDataPoints(rates []WaterRate, sourceURL string) []*models.Cos
 			Category:    "Utilities",
 			SubCategory: "Water",
 			ItemName:    itemName,
-			Price:       rate.RateAED,
+			Price:       rate.RateAED / 1000, // tariff is per 1000 IG
 			Location: models.Location{
 				Emirate: "Abu Dhabi",
 				City:    "Abu Dhabi",
@@ -308,7 +310,8 @@ func convertWaterToDataPoints(rates []WaterRate, sourceURL string) []*models.Cos
 			Source:      "aadc_official",
 			SourceURL:   sourceURL,
 			Confidence:  0.98, // Official source
-			Unit:        "AED per 1000 IG",
+			Currency:    "AED",
+			Unit:        units.ImperialGallon,
 			RecordedAt:  now,
 			ValidFrom:   now,
 			SampleSize:  1,
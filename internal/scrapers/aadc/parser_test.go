@@ -295,7 +295,8 @@ func TestConvertElectricityToDataPoints(t *testing.T) {
 	assert.Equal(t, "aadc_official", dp1.Source)
 	assert.Equal(t, sourceURL, dp1.SourceURL)
 	assert.Equal(t, float32(0.98), dp1.Confidence)
-	assert.Equal(t, "AED per kWh", dp1.Unit)
+	assert.Equal(t, "AED", dp1.Currency)
+	assert.Equal(t, "kWh", dp1.Unit)
 	assert.Contains(t, dp1.Tags, "electricity")
 	assert.Contains(t, dp1.Tags, "expatriate")
 	assert.Equal(t, "expatriate", dp1.Attributes["customer_type"])
@@ -335,11 +336,12 @@ func TestConvertWaterToDataPoints(t *testing.T) {
 	assert.Equal(t, "Water", dp1.SubCategory)
 	assert.Contains(t, dp1.ItemName, "AADC Water")
 	assert.Contains(t, dp1.ItemName, "National")
-	assert.Equal(t, 2.09, dp1.Price)
+	assert.InDelta(t, 0.00209, dp1.Price, 1e-9) // AED 2.09 per 1000 IG
 	assert.Equal(t, "Abu Dhabi", dp1.Location.Emirate)
 	assert.Equal(t, "aadc_official", dp1.Source)
 	assert.Equal(t, float32(0.98), dp1.Confidence)
-	assert.Equal(t, "AED per 1000 IG", dp1.Unit)
+	assert.Equal(t, "AED", dp1.Currency)
+	assert.Equal(t, "IG", dp1.Unit)
 	assert.Contains(t, dp1.Tags, "water")
 	assert.Contains(t, dp1.Tags, "national")
 	assert.Equal(t, "national", dp1.Attributes["customer_type"])
@@ -347,7 +349,7 @@ func TestConvertWaterToDataPoints(t *testing.T) {
 
 	// Check expatriate rate
 	dp2 := dataPoints[1]
-	assert.Equal(t, 8.55, dp2.Price)
+	assert.InDelta(t, 0.00855, dp2.Price, 1e-9)
 	assert.Contains(t, dp2.ItemName, "Expatriate")
 	assert.Equal(t, "expatriate", dp2.Attributes["customer_type"])
 }
@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"golang.org/x/time/rate"
 
+	"github.com/adonese/cost-of-living/internal/gazetteer"
 	"github.com/adonese/cost-of-living/internal/models"
 	"github.com/adonese/cost-of-living/internal/scrapers"
+	"github.com/adonese/cost-of-living/internal/units"
 	"github.com/adonese/cost-of-living/pkg/logger"
 	"github.com/adonese/cost-of-living/pkg/metrics"
 )
@@ -23,6 +26,7 @@ type BayutScraper struct {
 	rateLimiter *rate.Limiter
 	emirate     string // Dubai, Sharjah, Ajman, Abu Dhabi, etc.
 	baseURL     string
+	complete    atomic.Bool // whether the last Scrape reached the last page
 }
 
 // NewBayutScraper creates a new Bayut scraper for Dubai (default)
@@ -71,25 +75,79 @@ func (s *BayutScraper) CanScrape() bool {
 	return s.rateLimiter.Allow()
 }
 
-// Scrape fetches housing data from Bayut
+// Scrape fetches housing data from Bayut, following the result pages until
+// the last one or the configured page cap
 func (s *BayutScraper) Scrape(ctx context.Context) ([]*models.CostDataPoint, error) {
 	logger.Info("Starting Bayut scrape", "emirate", s.emirate)
+	s.complete.Store(false)
+
+	dataPoints := []*models.CostDataPoint{}
+	maxPages := s.config.EffectiveMaxPages()
+	visited := make(map[string]bool)
+	complete := false
 
-	// Build URL for the specific emirate
 	url := s.buildURL()
-	logger.Info("Scraping URL", "url", url)
+	for page := 1; ; page++ {
+		logger.Info("Scraping URL", "url", url, "page", page)
+		visited[url] = true
+
+		// Wait for rate limit
+		if err := s.rateLimiter.Wait(ctx); err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("rate limit wait: %w", err)
+			}
+			logger.Warn("Stopping Bayut crawl early", "emirate", s.emirate, "page", page, "error", err)
+			break
+		}
 
-	// Wait for rate limit
-	if err := s.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limit wait: %w", err)
-	}
+		doc, err := s.fetchDocument(ctx, url)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			// Keep what the earlier pages returned, but the crawl is
+			// incomplete so none of the missing listings are closed
+			logger.Warn("Stopping Bayut crawl early", "emirate", s.emirate, "page", page, "error", err)
+			break
+		}
+
+		dataPoints = append(dataPoints, s.extractPage(doc, url)...)
 
-	doc, err := s.fetchDocument(ctx, url)
-	if err != nil {
-		return nil, err
+		next, err := scrapers.NextPageURL(doc, url)
+		if err != nil {
+			logger.Warn("Stopping Bayut crawl early", "emirate", s.emirate, "page", page, "error", err)
+			break
+		}
+		if next == "" {
+			complete = true
+			break
+		}
+		if visited[next] {
+			logger.Warn("Stopping Bayut crawl at repeated page", "emirate", s.emirate, "url", next)
+			break
+		}
+		if page >= maxPages {
+			logger.Info("Stopping Bayut crawl at page limit", "emirate", s.emirate, "pages", maxPages)
+			break
+		}
+		url = next
 	}
+	s.complete.Store(complete)
+
+	logger.Info("Completed Bayut scrape", "count", len(dataPoints), "complete", complete)
+	metrics.ScraperItemsScraped.WithLabelValues(s.Name()).Add(float64(len(dataPoints)))
+
+	return dataPoints, nil
+}
+
+// LastCrawlComplete reports whether the most recent Scrape reached the last
+// result page without errors
+func (s *BayutScraper) LastCrawlComplete() bool {
+	return s.complete.Load()
+}
 
-	// Extract listings
+// extractPage extracts every listing on a result page
+func (s *BayutScraper) extractPage(doc *goquery.Document, url string) []*models.CostDataPoint {
 	dataPoints := []*models.CostDataPoint{}
 
 	// Try different selectors for property cards
@@ -104,10 +162,6 @@ func (s *BayutScraper) Scrape(ctx context.Context) ([]*models.CostDataPoint, err
 	for _, selector := range selectors {
 		count := 0
 		doc.Find(selector).Each(func(i int, selection *goquery.Selection) {
-			if i >= 10 { // Limit to first 10 for initial implementation
-				return
-			}
-
 			cdp := s.extractListing(selection, url)
 			if cdp != nil {
 				dataPoints = append(dataPoints, cdp)
@@ -128,10 +182,7 @@ func (s *BayutScraper) Scrape(ctx context.Context) ([]*models.CostDataPoint, err
 		dataPoints = s.extractWithGeneralApproach(doc)
 	}
 
-	logger.Info("Completed Bayut scrape", "count", len(dataPoints))
-	metrics.ScraperItemsScraped.WithLabelValues(s.Name()).Add(float64(len(dataPoints)))
-
-	return dataPoints, nil
+	return dataPoints
 }
 
 func (s *BayutScraper) fetchDocument(ctx context.Context, url string) (*goquery.Document, error) {
@@ -255,6 +306,9 @@ func (scraper *BayutScraper) extractListing(s *goquery.Selection, baseURL string
 	if location.City == "" {
 		location.City = scraper.emirate
 	}
+	// Canonicalise the area so aliases such as "JLT" group together
+	location, _ = gazetteer.Default().Resolve(location, locationText)
+	location.Coordinates = scrapers.ExtractCoordinates(s)
 
 	// Extract property details
 	bedrooms := ""
@@ -292,7 +346,8 @@ func (scraper *BayutScraper) extractListing(s *goquery.Selection, baseURL string
 		Source:      "bayut",
 		SourceURL:   propertyURL,
 		Confidence:  0.8,
-		Unit:        "AED",
+		Currency:    "AED",
+		Period:      units.Year,
 		RecordedAt:  now,
 		ValidFrom:   now,
 		SampleSize:  1,
@@ -309,10 +364,6 @@ func (s *BayutScraper) extractWithGeneralApproach(doc *goquery.Document) []*mode
 
 	// Look for any links that seem to be property listings
 	doc.Find("a[href*='/property/']").Each(func(i int, link *goquery.Selection) {
-		if i >= 10 {
-			return
-		}
-
 		// Get the parent container (likely the property card)
 		card := link.Parent().Parent()
 
@@ -356,7 +407,8 @@ func (s *BayutScraper) extractWithGeneralApproach(doc *goquery.Document) []*mode
 			Source:     "bayut",
 			SourceURL:  propertyURL,
 			Confidence: 0.6, // Lower confidence for general approach
-			Unit:       "AED",
+			Currency:   "AED",
+			Period:     units.Year,
 			RecordedAt: now,
 			ValidFrom:  now,
 			SampleSize: 1,
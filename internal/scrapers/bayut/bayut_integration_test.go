@@ -2,6 +2,9 @@ package bayut
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
@@ -11,6 +14,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/adonese/cost-of-living/internal/models"
 	"github.com/adonese/cost-of-living/internal/scrapers"
 	"github.com/adonese/cost-of-living/pkg/logger"
 	"github.com/adonese/cost-of-living/test/helpers"
@@ -54,7 +58,8 @@ func TestBayutScraperWithDubaiFixture(t *testing.T) {
 		assert.Equal(t, "Housing", cdp.Category)
 		assert.Equal(t, "Rent", cdp.SubCategory)
 		assert.Equal(t, "bayut", cdp.Source)
-		assert.Equal(t, "AED", cdp.Unit)
+		assert.Equal(t, "AED", cdp.Currency)
+		assert.Equal(t, "year", cdp.Period)
 
 		// Dubai-specific validations
 		assert.Contains(t, cdp.ItemName, "Dubai", "Item name should contain Dubai")
@@ -65,6 +70,49 @@ func TestBayutScraperWithDubaiFixture(t *testing.T) {
 	}
 }
 
+func TestBayutScraperExtractsCoordinates(t *testing.T) {
+	html := helpers.MustLoadFixture("bayut", "dubai_listings.html")
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+
+	scraper := NewBayutScraper(scrapers.Config{Timeout: 30, RateLimit: 1.0, UserAgent: "Test Agent"})
+	cards := doc.Find("article[data-testid='property-card']")
+	require.GreaterOrEqual(t, cards.Length(), 3)
+
+	// Data attributes on the card
+	cdp := scraper.extractListing(cards.Eq(0), "https://www.bayut.com/test")
+	require.NotNil(t, cdp)
+	require.NotNil(t, cdp.Location.Coordinates)
+	assert.Equal(t, models.GeoPoint{Lat: 25.0805, Lon: 55.1403}, *cdp.Location.Coordinates)
+
+	// JSON-LD embedded in the card
+	cdp = scraper.extractListing(cards.Eq(1), "https://www.bayut.com/test")
+	require.NotNil(t, cdp)
+	require.NotNil(t, cdp.Location.Coordinates)
+	assert.Equal(t, models.GeoPoint{Lat: 25.1972, Lon: 55.2744}, *cdp.Location.Coordinates)
+
+	// No coordinates in the markup
+	cdp = scraper.extractListing(cards.Eq(2), "https://www.bayut.com/test")
+	require.NotNil(t, cdp)
+	assert.Nil(t, cdp.Location.Coordinates)
+}
+
+func TestBayutScraperCanonicalisesArea(t *testing.T) {
+	html := `<article data-testid="property-card">
+		<a href="/property/details-1" title="1BR in JLT"><h2>1BR in JLT</h2></a>
+		<span aria-label="Price">AED 75,000/year</span>
+		<div aria-label="Location">Cluster D, Jumeirah Lakes Towers (JLT), Dubai</div>
+	</article>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+
+	scraper := NewBayutScraper(scrapers.Config{Timeout: 30, RateLimit: 1.0, UserAgent: "Test Agent"})
+	cdp := scraper.extractListing(doc.Find("article").First(), "https://www.bayut.com/test")
+	require.NotNil(t, cdp)
+	assert.Equal(t, "Jumeirah Lake Towers", cdp.Location.Area)
+	assert.Equal(t, "Dubai", cdp.Location.Emirate)
+}
+
 func TestBayutScraperWithMultipleEmirates(t *testing.T) {
 	testCases := []struct {
 		name          string
@@ -257,6 +305,66 @@ func TestBayutScraperScrapeWithMockServer(t *testing.T) {
 	}
 }
 
+func TestBayutScraperFollowsPagination(t *testing.T) {
+	logger.Init()
+
+	card := func(id int) string {
+		return fmt.Sprintf(`<article data-testid="property-card">
+			<a href="/property/details-%d" title="1BR in JLT %d"><h2>1BR in JLT %d</h2></a>
+			<span aria-label="Price">AED 75,000/year</span>
+			<div aria-label="Location">Jumeirah Lakes Towers (JLT), Dubai</div>
+		</article>`, id, id, id)
+	}
+
+	testCases := []struct {
+		name         string
+		maxPages     int
+		failPage2    bool
+		wantListings int
+		wantComplete bool
+	}{
+		{name: "reaches the last page", wantListings: 3, wantComplete: true},
+		{name: "page fails", failPage2: true, wantListings: 1, wantComplete: false},
+		{name: "stops at page cap", maxPages: 2, wantListings: 2, wantComplete: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/to-rent/apartments/dubai/":
+					fmt.Fprintf(w, `<html><head><link rel="next" href="/to-rent/apartments/dubai/page-2/"></head><body>%s</body></html>`, card(1))
+				case "/to-rent/apartments/dubai/page-2/":
+					if tc.failPage2 {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					fmt.Fprintf(w, `<html><body>%s<a rel="next" href="page-3/">Next</a></body></html>`, card(2))
+				case "/to-rent/apartments/dubai/page-2/page-3/":
+					fmt.Fprintf(w, `<html><body>%s</body></html>`, card(3))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			scraper := NewBayutScraper(scrapers.Config{
+				Timeout:    5,
+				RateLimit:  100,
+				MaxRetries: 1,
+				MaxPages:   tc.maxPages,
+				UserAgent:  "Test Agent",
+				BaseURL:    server.URL,
+			})
+
+			dataPoints, err := scraper.Scrape(context.Background())
+			require.NoError(t, err)
+			assert.Len(t, dataPoints, tc.wantListings)
+			assert.Equal(t, tc.wantComplete, scrapers.CrawlReporter(scraper).LastCrawlComplete())
+		})
+	}
+}
+
 func TestBayutScraperLocationParsing(t *testing.T) {
 	html := helpers.MustLoadFixture("bayut", "dubai_listings.html")
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
@@ -144,8 +144,8 @@ func TestCareemScraper_Scrape(t *testing.T) {
 		if dp.SubCategory != "Ride Sharing" {
 			t.Errorf("SubCategory = %v, want Ride Sharing", dp.SubCategory)
 		}
-		if dp.Unit != "AED" {
-			t.Errorf("Unit = %v, want AED", dp.Unit)
+		if dp.Currency != "AED" {
+			t.Errorf("Currency = %v, want AED", dp.Currency)
 		}
 		if dp.Price <= 0 {
 			t.Errorf("Price = %v, want > 0", dp.Price)
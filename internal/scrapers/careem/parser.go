@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/units"
 )
 
 // RateComponent represents a type of rate component
@@ -213,6 +214,19 @@ func parseServiceRate(serviceRate ServiceRate, parentRates *CareemRates, locatio
 	return dataPoints
 }
 
+// Unit returns what the component is charged per: distance, waiting time
+// or, for fixed charges, the trip
+func (c RateComponent) Unit() string {
+	switch c {
+	case PerKmComponent:
+		return units.Kilometre
+	case PerMinuteComponent:
+		return units.Minute
+	default:
+		return units.Trip
+	}
+}
+
 // createRateDataPoint creates a cost data point for a rate component
 func createRateDataPoint(
 	itemName string,
@@ -231,7 +245,8 @@ func createRateDataPoint(
 		Source:      "careem_rates",
 		SourceURL:   "aggregated_from_multiple_sources",
 		Confidence:  rates.Confidence,
-		Unit:        "AED",
+		Currency:    "AED",
+		Unit:        component.Unit(),
 		RecordedAt:  now,
 		ValidFrom:   effectiveDate,
 		SampleSize:  1,
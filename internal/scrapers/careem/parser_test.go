@@ -103,8 +103,11 @@ func TestParseRatesToDataPoints(t *testing.T) {
 					if dp.SubCategory != "Ride Sharing" {
 						t.Errorf("SubCategory = %v, want Ride Sharing", dp.SubCategory)
 					}
-					if dp.Unit != "AED" {
-						t.Errorf("Unit = %v, want AED", dp.Unit)
+					if dp.Currency != "AED" {
+						t.Errorf("Currency = %v, want AED", dp.Currency)
+					}
+					if dp.Unit != "trip" {
+						t.Errorf("Unit = %v, want trip for the base fare", dp.Unit)
 					}
 					if dp.Location.Emirate != tt.rates.Emirate {
 						t.Errorf("Location.Emirate = %v, want %v", dp.Location.Emirate, tt.rates.Emirate)
@@ -192,6 +195,22 @@ func TestCreateRateDataPoint(t *testing.T) {
 	}
 }
 
+func TestRateComponentUnit(t *testing.T) {
+	tests := map[RateComponent]string{
+		BaseFareComponent:      "trip",
+		PerKmComponent:         "km",
+		PerMinuteComponent:     "minute",
+		MinimumFareComponent:   "trip",
+		SalikTollComponent:     "trip",
+		PeakSurchargeComponent: "trip",
+	}
+	for component, want := range tests {
+		if got := component.Unit(); got != want {
+			t.Errorf("%s.Unit() = %v, want %v", component, got, want)
+		}
+	}
+}
+
 func TestValidateRates(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -71,7 +71,8 @@ func TestDEWAScraper_Integration_WithFixture(t *testing.T) {
 	assert.Equal(t, "Dubai", firstElectric.Location.Emirate)
 	assert.Equal(t, "dewa_official", firstElectric.Source)
 	assert.Equal(t, float32(0.98), firstElectric.Confidence)
-	assert.Equal(t, "AED", firstElectric.Unit)
+	assert.Equal(t, "AED", firstElectric.Currency)
+	assert.Equal(t, "kWh", firstElectric.Unit)
 	assert.Contains(t, firstElectric.Tags, "electricity")
 	assert.Equal(t, "fils_per_kwh", firstElectric.Attributes["unit"])
 
@@ -106,6 +107,7 @@ func TestDEWAScraper_Integration_WithFixture(t *testing.T) {
 	assert.Equal(t, "Utilities", firstWater.Category)
 	assert.Equal(t, "Water", firstWater.SubCategory)
 	assert.InDelta(t, 0.0357, firstWater.Price, 0.0001, "3.57 fils should convert to 0.0357 AED")
+	assert.Equal(t, "IG", firstWater.Unit)
 	assert.Contains(t, firstWater.Tags, "water")
 	assert.Equal(t, "fils_per_ig", firstWater.Attributes["unit"])
 
@@ -134,7 +136,7 @@ func TestDEWAScraper_Integration_WithFixture(t *testing.T) {
 		assert.Equal(t, "dewa_official", dp.Source)
 		assert.NotEmpty(t, dp.SourceURL)
 		assert.Greater(t, dp.Confidence, float32(0.9), "Confidence should be high for official source")
-		assert.Equal(t, "AED", dp.Unit)
+		assert.Equal(t, "AED", dp.Currency)
 		assert.NotEmpty(t, dp.Tags)
 	}
 
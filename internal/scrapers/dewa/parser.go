@@ -9,6 +9,7 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/units"
 )
 
 // RateSlab represents a consumption slab with its rate
@@ -198,7 +199,12 @@ func slabToDataPoint(slab RateSlab, utilityType string, sourceURL string) *model
 	now := time.Now()
 
 	// Convert fils to AED (100 fils = 1 AED)
-	priceInAED := slab.Rate / 100.0
+	priceInAED := units.FilsToAED(slab.Rate)
+
+	unit := units.KWh
+	if slab.Unit == "fils_per_ig" {
+		unit = units.ImperialGallon
+	}
 
 	category := "Utilities"
 	subCategory := ""
@@ -239,7 +245,8 @@ func slabToDataPoint(slab RateSlab, utilityType string, sourceURL string) *model
 		Source:      "dewa_official",
 		SourceURL:   sourceURL,
 		Confidence:  0.98, // Official source
-		Unit:        "AED",
+		Currency:    "AED",
+		Unit:        unit,
 		RecordedAt:  now,
 		ValidFrom:   now,
 		SampleSize:  1,
@@ -358,7 +358,8 @@ func TestSlabToDataPoint(t *testing.T) {
 	assert.Equal(t, "Dubai", dp.Location.Emirate)
 	assert.Equal(t, "dewa_official", dp.Source)
 	assert.Equal(t, float32(0.98), dp.Confidence)
-	assert.Equal(t, "AED", dp.Unit)
+	assert.Equal(t, "AED", dp.Currency)
+	assert.Equal(t, "kWh", dp.Unit)
 	assert.Contains(t, dp.Tags, "utility")
 	assert.Contains(t, dp.Tags, "electricity")
 	assert.Equal(t, "fils_per_kwh", dp.Attributes["unit"])
@@ -381,6 +382,7 @@ func TestSlabToDataPoint_WaterSlab(t *testing.T) {
 	assert.Equal(t, "Water", dp.SubCategory)
 	assert.Equal(t, "DEWA Water Slab 0-5000 IG", dp.ItemName)
 	assert.InDelta(t, 0.0357, dp.Price, 0.0001) // 3.57 fils = 0.0357 AED
+	assert.Equal(t, "IG", dp.Unit)
 	assert.Contains(t, dp.Tags, "water")
 }
 
@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"golang.org/x/time/rate"
 
+	"github.com/adonese/cost-of-living/internal/gazetteer"
 	"github.com/adonese/cost-of-living/internal/models"
 	"github.com/adonese/cost-of-living/internal/scrapers"
+	"github.com/adonese/cost-of-living/internal/units"
 	"github.com/adonese/cost-of-living/pkg/logger"
 	"github.com/adonese/cost-of-living/pkg/metrics"
 )
@@ -24,6 +27,7 @@ type DubizzleScraper struct {
 	emirate     string // Dubai, Sharjah, Ajman, Abu Dhabi, etc.
 	category    string // apartmentflat, bedspace, roomspace
 	baseURL     string
+	complete    atomic.Bool // whether the last Scrape reached the last page
 }
 
 // NewDubizzleScraper creates a new Dubizzle scraper for Dubai apartments (default)
@@ -79,33 +83,76 @@ func (s *DubizzleScraper) CanScrape() bool {
 	return s.rateLimiter.Allow()
 }
 
-// Scrape fetches housing data from Dubizzle
+// Scrape fetches housing data from Dubizzle, following the result pages
+// until the last one or the configured page cap
 func (s *DubizzleScraper) Scrape(ctx context.Context) ([]*models.CostDataPoint, error) {
 	logger.Info("Starting Dubizzle scrape", "emirate", s.emirate, "category", s.category)
+	s.complete.Store(false)
+
+	dataPoints := []*models.CostDataPoint{}
+	maxPages := s.config.EffectiveMaxPages()
+	visited := make(map[string]bool)
+	complete := false
 
 	// Build URL for the specific emirate and category
 	url := s.buildURL()
-	logger.Info("Scraping URL", "url", url)
+	for page := 1; ; page++ {
+		logger.Info("Scraping URL", "url", url, "page", page)
+		visited[url] = true
+
+		// Wait for rate limit
+		if err := s.rateLimiter.Wait(ctx); err != nil {
+			if page == 1 {
+				return nil, fmt.Errorf("rate limit wait: %w", err)
+			}
+			logger.Warn("Stopping Dubizzle crawl early", "emirate", s.emirate, "category", s.category, "page", page, "error", err)
+			break
+		}
 
-	// Wait for rate limit
-	if err := s.rateLimiter.Wait(ctx); err != nil {
-		return nil, fmt.Errorf("rate limit wait: %w", err)
-	}
+		// Fetch the page with retry logic
+		pagePoints, next, err := s.fetchWithRetry(ctx, url)
+		if err != nil {
+			if page == 1 {
+				return nil, err
+			}
+			// Keep what the earlier pages returned, but the crawl is
+			// incomplete so none of the missing listings are closed
+			logger.Warn("Stopping Dubizzle crawl early", "emirate", s.emirate, "category", s.category, "page", page, "error", err)
+			break
+		}
+		dataPoints = append(dataPoints, pagePoints...)
 
-	// Fetch the page with retry logic
-	dataPoints, err := s.fetchWithRetry(ctx, url)
-	if err != nil {
-		return nil, err
+		if next == "" {
+			complete = true
+			break
+		}
+		if visited[next] {
+			logger.Warn("Stopping Dubizzle crawl at repeated page", "emirate", s.emirate, "category", s.category, "url", next)
+			break
+		}
+		if page >= maxPages {
+			logger.Info("Stopping Dubizzle crawl at page limit", "emirate", s.emirate, "category", s.category, "pages", maxPages)
+			break
+		}
+		url = next
 	}
+	s.complete.Store(complete)
 
-	logger.Info("Completed Dubizzle scrape", "count", len(dataPoints))
+	logger.Info("Completed Dubizzle scrape", "count", len(dataPoints), "complete", complete)
 	metrics.ScraperItemsScraped.WithLabelValues(s.Name()).Add(float64(len(dataPoints)))
 
 	return dataPoints, nil
 }
 
-// fetchWithRetry attempts to fetch and parse the page with retries
-func (s *DubizzleScraper) fetchWithRetry(ctx context.Context, url string) ([]*models.CostDataPoint, error) {
+// LastCrawlComplete reports whether the most recent Scrape reached the last
+// result page without errors
+func (s *DubizzleScraper) LastCrawlComplete() bool {
+	return s.complete.Load()
+}
+
+// fetchWithRetry attempts to fetch and parse the page with retries. It
+// returns the listings on the page and the URL of the next page, if any.
+func (s *DubizzleScraper) fetchWithRetry(ctx context.Context, url string) ([]*models.CostDataPoint, string, error) {
 	var lastErr error
 
 	maxRetries := s.config.EffectiveMaxRetries()
@@ -114,18 +161,18 @@ func (s *DubizzleScraper) fetchWithRetry(ctx context.Context, url string) ([]*mo
 		if attempt > 0 {
 			logger.Info("Retrying fetch", "attempt", attempt+1)
 			if err := scrapers.WaitRetry(ctx, s.config, attempt-1); err != nil {
-				return nil, err
+				return nil, "", err
 			}
 		}
 
 		if err := scrapers.DelayBetweenRequests(ctx, s.config); err != nil {
-			return nil, err
+			return nil, "", err
 		}
 
 		// Fetch the page with rotated headers.
 		req, err := scrapers.PrepareRequest(ctx, http.MethodGet, url, nil, s.config)
 		if err != nil {
-			return nil, fmt.Errorf("create request: %w", err)
+			return nil, "", fmt.Errorf("create request: %w", err)
 		}
 
 		resp, err := s.client.Do(req)
@@ -171,7 +218,11 @@ func (s *DubizzleScraper) fetchWithRetry(ctx context.Context, url string) ([]*mo
 
 		// If we got results, return them
 		if len(dataPoints) > 0 {
-			return dataPoints, nil
+			next, err := scrapers.NextPageURL(doc, url)
+			if err != nil {
+				return nil, "", err
+			}
+			return dataPoints, next, nil
 		}
 
 		// If no results but no error, might be legitimate empty page
@@ -180,9 +231,9 @@ func (s *DubizzleScraper) fetchWithRetry(ctx context.Context, url string) ([]*mo
 
 	// All retries exhausted
 	if lastErr != nil {
-		return nil, lastErr
+		return nil, "", lastErr
 	}
-	return nil, fmt.Errorf("failed after %d attempts", maxRetries)
+	return nil, "", fmt.Errorf("failed after %d attempts", maxRetries)
 }
 
 // isErrorPage checks if the document is an error/block page
@@ -226,10 +277,6 @@ func (s *DubizzleScraper) extractListings(doc *goquery.Document, baseURL string)
 	for _, selector := range selectors {
 		count := 0
 		doc.Find(selector).Each(func(i int, selection *goquery.Selection) {
-			if i >= 10 { // Limit to first 10 for initial implementation
-				return
-			}
-
 			cdp := s.extractListing(selection, baseURL)
 			if cdp != nil {
 				dataPoints = append(dataPoints, cdp)
@@ -319,6 +366,9 @@ func (scraper *DubizzleScraper) extractListing(sel *goquery.Selection, baseURL s
 	if location.City == "" {
 		location.City = scraper.emirate
 	}
+	// Canonicalise the area so aliases such as "JLT" group together
+	location, _ = gazetteer.Default().Resolve(location, locationText)
+	location.Coordinates = scrapers.ExtractCoordinates(sel)
 
 	// Extract property details
 	bedrooms := ""
@@ -379,7 +429,8 @@ func (scraper *DubizzleScraper) extractListing(sel *goquery.Selection, baseURL s
 		Source:      "dubizzle",
 		SourceURL:   propertyURL,
 		Confidence:  0.75, // Slightly lower than Bayut due to potential bot detection issues
-		Unit:        "AED",
+		Currency:    "AED",
+		Period:      scraper.getPeriodFromCategory(),
 		RecordedAt:  now,
 		ValidFrom:   now,
 		SampleSize:  1,
@@ -397,10 +448,6 @@ func (s *DubizzleScraper) extractWithGeneralApproach(doc *goquery.Document, base
 
 	// Look for any links that seem to be property listings
 	doc.Find("a[href*='/property-for-rent/']").Each(func(i int, link *goquery.Selection) {
-		if i >= 10 {
-			return
-		}
-
 		// Get the parent container (likely the property card)
 		card := link.Parent().Parent()
 
@@ -454,7 +501,8 @@ func (s *DubizzleScraper) extractWithGeneralApproach(doc *goquery.Document, base
 			Source:     "dubizzle",
 			SourceURL:  propertyURL,
 			Confidence: 0.5, // Lower confidence for general approach
-			Unit:       "AED",
+			Currency:   "AED",
+			Period:     s.getPeriodFromCategory(),
 			RecordedAt: now,
 			ValidFrom:  now,
 			SampleSize: 1,
@@ -517,6 +565,17 @@ func (s *DubizzleScraper) getSubCategoryFromCategory() string {
 	}
 }
 
+// getPeriodFromCategory returns the period listing prices cover: shared
+// rooms are let by the month, apartments by the year
+func (s *DubizzleScraper) getPeriodFromCategory() string {
+	switch s.category {
+	case "bedspace", "roomspace":
+		return units.Month
+	default:
+		return units.Year
+	}
+}
+
 func (s *DubizzleScraper) resolveURL(href string) string {
 	if href == "" {
 		return ""
@@ -2,6 +2,9 @@ package dubizzle
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -10,6 +13,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/adonese/cost-of-living/internal/models"
 	"github.com/adonese/cost-of-living/internal/scrapers"
 	"github.com/adonese/cost-of-living/pkg/logger"
 	"github.com/adonese/cost-of-living/test/helpers"
@@ -44,7 +48,8 @@ func TestDubizzleScraperWithApartmentsFixture(t *testing.T) {
 		assert.Equal(t, "Housing", cdp.Category)
 		assert.Equal(t, "Rent", cdp.SubCategory)
 		assert.Equal(t, "dubizzle", cdp.Source)
-		assert.Equal(t, "AED", cdp.Unit)
+		assert.Equal(t, "AED", cdp.Currency)
+		assert.Equal(t, "year", cdp.Period)
 
 		helpers.AssertPriceInRange(t, cdp.Price, "yearly_rent")
 		helpers.AssertTagsContain(t, cdp.Tags, "dubizzle")
@@ -54,6 +59,26 @@ func TestDubizzleScraperWithApartmentsFixture(t *testing.T) {
 	}
 }
 
+func TestDubizzleScraperExtractsCoordinates(t *testing.T) {
+	html := helpers.MustLoadFixture("dubizzle", "apartments.html")
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+
+	scraper := NewDubizzleScraper(scrapers.Config{Timeout: 30, RateLimit: 1.0, UserAgent: "Test Agent"})
+	items := doc.Find("li[data-testid='listing-item']")
+	require.GreaterOrEqual(t, items.Length(), 2)
+
+	// schema.org microdata
+	cdp := scraper.extractListing(items.Eq(0), "https://dubai.dubizzle.com/test")
+	require.NotNil(t, cdp)
+	require.NotNil(t, cdp.Location.Coordinates)
+	assert.Equal(t, models.GeoPoint{Lat: 25.0781, Lon: 55.1336}, *cdp.Location.Coordinates)
+
+	cdp = scraper.extractListing(items.Eq(1), "https://dubai.dubizzle.com/test")
+	require.NotNil(t, cdp)
+	assert.Nil(t, cdp.Location.Coordinates)
+}
+
 func TestDubizzleScraperWithSharedAccommodation(t *testing.T) {
 	testCases := []struct {
 		name         string
@@ -61,6 +86,7 @@ func TestDubizzleScraperWithSharedAccommodation(t *testing.T) {
 		category     string
 		subCategory  string
 		priceType    string
+		period       string
 		minListings  int
 		expectedTags []string
 	}{
@@ -70,6 +96,7 @@ func TestDubizzleScraperWithSharedAccommodation(t *testing.T) {
 			category:     "apartmentflat",
 			subCategory:  "Rent",
 			priceType:    "yearly_rent",
+			period:       "year",
 			minListings:  6,
 			expectedTags: []string{"dubizzle", "rent", "apartment"},
 		},
@@ -79,6 +106,7 @@ func TestDubizzleScraperWithSharedAccommodation(t *testing.T) {
 			category:     "bedspace",
 			subCategory:  "Shared Accommodation",
 			priceType:    "bedspace",
+			period:       "month",
 			minListings:  4,
 			expectedTags: []string{"dubizzle", "shared", "bedspace"},
 		},
@@ -88,6 +116,7 @@ func TestDubizzleScraperWithSharedAccommodation(t *testing.T) {
 			category:     "roomspace",
 			subCategory:  "Shared Accommodation",
 			priceType:    "roomspace",
+			period:       "month",
 			minListings:  6,
 			expectedTags: []string{"dubizzle", "shared", "roomspace"},
 		},
@@ -124,6 +153,7 @@ func TestDubizzleScraperWithSharedAccommodation(t *testing.T) {
 					helpers.AssertHousingDataPoint(t, cdp)
 					assert.Equal(t, tc.subCategory, cdp.SubCategory,
 						"SubCategory should match: %s", tc.subCategory)
+					assert.Equal(t, tc.period, cdp.Period)
 
 					// Validate tags
 					helpers.AssertTagsContain(t, cdp.Tags, tc.expectedTags...)
@@ -291,6 +321,70 @@ func TestDubizzleScraperWithMockServer(t *testing.T) {
 	}
 }
 
+func TestDubizzleScraperFollowsPagination(t *testing.T) {
+	logger.Init()
+
+	card := func(id int) string {
+		return fmt.Sprintf(`<li data-testid="listing-item">
+			<a href="/property-for-rent/apartments/dubai/jlt/%d" title="1BR in JLT %d"><h2>1BR in JLT %d</h2></a>
+			<span data-testid="listing-price">AED 75,000/year</span>
+			<span data-testid="listing-location">Jumeirah Lakes Towers, Dubai</span>
+		</li>`, id, id, id)
+	}
+
+	testCases := []struct {
+		name         string
+		maxPages     int
+		failPage2    bool
+		wantListings int
+		wantComplete bool
+	}{
+		{name: "reaches the last page", wantListings: 3, wantComplete: true},
+		{name: "page fails", failPage2: true, wantListings: 1, wantComplete: false},
+		{name: "stops at page cap", maxPages: 2, wantListings: 2, wantComplete: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/property-for-rent/residential/apartmentflat/" {
+					http.NotFound(w, r)
+					return
+				}
+				switch r.URL.Query().Get("page") {
+				case "":
+					fmt.Fprintf(w, `<html><body><ul>%s</ul><a data-testid="page-next" href="?page=2">Next</a></body></html>`, card(1))
+				case "2":
+					if tc.failPage2 {
+						w.WriteHeader(http.StatusInternalServerError)
+						return
+					}
+					fmt.Fprintf(w, `<html><body><ul>%s</ul><a aria-label="Next page" href="?page=3">Next</a></body></html>`, card(2))
+				case "3":
+					fmt.Fprintf(w, `<html><body><ul>%s</ul></body></html>`, card(3))
+				default:
+					http.NotFound(w, r)
+				}
+			}))
+			defer server.Close()
+
+			scraper := NewDubizzleScraperFor(scrapers.Config{
+				Timeout:    5,
+				RateLimit:  100,
+				MaxRetries: 1,
+				MaxPages:   tc.maxPages,
+				UserAgent:  "Test Agent",
+				BaseURL:    server.URL,
+			}, "Dubai", "apartmentflat")
+
+			dataPoints, err := scraper.Scrape(context.Background())
+			require.NoError(t, err)
+			assert.Len(t, dataPoints, tc.wantListings)
+			assert.Equal(t, tc.wantComplete, scrapers.CrawlReporter(scraper).LastCrawlComplete())
+		})
+	}
+}
+
 func TestDubizzleScraperNaming(t *testing.T) {
 	testCases := []struct {
 		emirate      string
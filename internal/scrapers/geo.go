@@ -0,0 +1,149 @@
+package scrapers
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/adonese/cost-of-living/internal/models"
+)
+
+// ExtractCoordinates returns the map position of a listing card, or nil if
+// the markup does not expose one. Listing sites publish coordinates in one
+// of three ways, tried in order:
+//
+//   - data attributes such as data-lat/data-lng on the card or a child
+//   - schema.org microdata: itemprop="latitude" and itemprop="longitude"
+//   - schema.org JSON-LD with a "geo" GeoCoordinates object
+func ExtractCoordinates(sel *goquery.Selection) *models.GeoPoint {
+	for _, extract := range []func(*goquery.Selection) (models.GeoPoint, bool){
+		coordinatesFromDataAttributes,
+		coordinatesFromMicrodata,
+		coordinatesFromJSONLD,
+	} {
+		if point, ok := extract(sel); ok && point.Valid() {
+			return &point
+		}
+	}
+	return nil
+}
+
+var (
+	latAttributes = []string{"data-lat", "data-latitude"}
+	lonAttributes = []string{"data-lng", "data-lon", "data-longitude"}
+)
+
+func coordinatesFromDataAttributes(sel *goquery.Selection) (models.GeoPoint, bool) {
+	nodes := sel.AddSelection(sel.Find("[data-lat], [data-latitude]"))
+	for i := range nodes.Nodes {
+		node := nodes.Eq(i)
+		lat, ok := firstFloatAttr(node, latAttributes)
+		if !ok {
+			continue
+		}
+		if lon, ok := firstFloatAttr(node, lonAttributes); ok {
+			return models.GeoPoint{Lat: lat, Lon: lon}, true
+		}
+	}
+	return models.GeoPoint{}, false
+}
+
+func coordinatesFromMicrodata(sel *goquery.Selection) (models.GeoPoint, bool) {
+	lat, ok := microdataFloat(sel.Find("[itemprop='latitude']").First())
+	if !ok {
+		return models.GeoPoint{}, false
+	}
+	lon, ok := microdataFloat(sel.Find("[itemprop='longitude']").First())
+	if !ok {
+		return models.GeoPoint{}, false
+	}
+	return models.GeoPoint{Lat: lat, Lon: lon}, true
+}
+
+func coordinatesFromJSONLD(sel *goquery.Selection) (models.GeoPoint, bool) {
+	var point models.GeoPoint
+	found := false
+	sel.Find("script[type='application/ld+json']").EachWithBreak(func(_ int, script *goquery.Selection) bool {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(script.Text()), &doc); err != nil {
+			return true
+		}
+		point, found = findGeo(doc)
+		return !found
+	})
+	return point, found
+}
+
+// findGeo walks a JSON-LD document for the first object carrying latitude
+// and longitude
+func findGeo(doc interface{}) (models.GeoPoint, bool) {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		lat, latOK := jsonFloat(v["latitude"])
+		lon, lonOK := jsonFloat(v["longitude"])
+		if latOK && lonOK {
+			return models.GeoPoint{Lat: lat, Lon: lon}, true
+		}
+		// Visit "geo" first so a listing's own position wins over nested
+		// places such as the agency address
+		if point, ok := findGeo(v["geo"]); ok {
+			return point, true
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			if key != "geo" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if point, ok := findGeo(v[key]); ok {
+				return point, true
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			if point, ok := findGeo(child); ok {
+				return point, true
+			}
+		}
+	}
+	return models.GeoPoint{}, false
+}
+
+func firstFloatAttr(sel *goquery.Selection, names []string) (float64, bool) {
+	for _, name := range names {
+		if value, ok := sel.Attr(name); ok {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				return f, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func microdataFloat(sel *goquery.Selection) (float64, bool) {
+	if sel.Length() == 0 {
+		return 0, false
+	}
+	value, ok := sel.Attr("content")
+	if !ok {
+		value = sel.Text()
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	return f, err == nil
+}
+
+func jsonFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -40,6 +40,14 @@ func (c Config) EffectiveMaxRetries() int {
 	return 3
 }
 
+// EffectiveMaxPages returns the configured page cap or DefaultMaxPages.
+func (c Config) EffectiveMaxPages() int {
+	if c.MaxPages > 0 {
+		return c.MaxPages
+	}
+	return DefaultMaxPages
+}
+
 // EffectiveUserAgent picks a random User-Agent string for the request.
 func (c Config) EffectiveUserAgent() string {
 	if len(c.UserAgents) > 0 {
@@ -0,0 +1,49 @@
+package scrapers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultMaxPages is the number of result pages a paginated scraper fetches
+// when Config.MaxPages is unset
+const DefaultMaxPages = 50
+
+// nextPageSelectors match the link to the following result page, most
+// specific first
+var nextPageSelectors = []string{
+	"link[rel='next']",
+	"a[rel='next']",
+	"a[data-testid='page-next']",
+	"[data-testid='page-next'] a",
+	"a[aria-label='Next page']",
+	"a[aria-label='Next']",
+	"a[title='Next']",
+}
+
+// NextPageURL returns the absolute URL of the result page after doc, which
+// was fetched from pageURL, or "" when doc is the last page. A next link
+// that cannot be resolved is an error rather than the end of the results.
+func NextPageURL(doc *goquery.Document, pageURL string) (string, error) {
+	for _, selector := range nextPageSelectors {
+		href, ok := doc.Find(selector).First().Attr("href")
+		href = strings.TrimSpace(href)
+		if !ok || href == "" || href == "#" {
+			continue
+		}
+
+		base, err := url.Parse(pageURL)
+		if err != nil {
+			return "", fmt.Errorf("parse page url: %w", err)
+		}
+		next, err := base.Parse(href)
+		if err != nil {
+			return "", fmt.Errorf("parse next page link %q: %w", href, err)
+		}
+		return next.String(), nil
+	}
+	return "", nil
+}
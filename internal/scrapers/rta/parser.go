@@ -9,6 +9,7 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/units"
 )
 
 // ParseFares extracts all fare information from RTA HTML document
@@ -261,13 +262,13 @@ func parseTaxiFares(doc *goquery.Document, sourceURL string, timestamp time.Time
 		itemName := fmt.Sprintf("Dubai Taxi - %s", fareType)
 
 		// Determine unit based on fare type
-		unit := "AED"
+		unit := units.Trip
 		if strings.Contains(strings.ToLower(fareType), "kilometer") ||
 		   strings.Contains(strings.ToLower(fareType), "km") {
-			unit = "AED/km"
+			unit = units.Kilometre
 		} else if strings.Contains(strings.ToLower(fareType), "minute") ||
 		          strings.Contains(strings.ToLower(fareType), "waiting") {
-			unit = "AED/minute"
+			unit = units.Minute
 		}
 
 		dataPoint := &models.CostDataPoint{
@@ -282,6 +283,7 @@ func parseTaxiFares(doc *goquery.Document, sourceURL string, timestamp time.Time
 			Source:     "rta_official",
 			SourceURL:  sourceURL,
 			Confidence: 0.95,
+			Currency:   "AED",
 			Unit:       unit,
 			RecordedAt: timestamp,
 			ValidFrom:  timestamp,
@@ -334,6 +336,12 @@ func createFareDataPoint(mode TransportMode, cardType CardType, fareType FareTyp
 		subCategory = "Taxi"
 	}
 
+	// A day pass covers any number of journeys
+	unit := units.Trip
+	if fareType == FareDayPass {
+		unit = ""
+	}
+
 	return &models.CostDataPoint{
 		Category:    "Transportation",
 		SubCategory: subCategory,
@@ -346,7 +354,8 @@ func createFareDataPoint(mode TransportMode, cardType CardType, fareType FareTyp
 		Source:     "rta_official",
 		SourceURL:  sourceURL,
 		Confidence: 0.95,
-		Unit:       "AED",
+		Currency:   "AED",
+		Unit:       unit,
 		RecordedAt: timestamp,
 		ValidFrom:  timestamp,
 		SampleSize: 1,
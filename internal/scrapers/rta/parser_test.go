@@ -364,8 +364,8 @@ func TestParseTaxiFares(t *testing.T) {
 		// Check for per kilometer fare with correct unit
 		if strings.Contains(dp.ItemName, "Kilometer") || strings.Contains(dp.ItemName, "kilometer") {
 			hasPerKm = true
-			if dp.Unit != "AED/km" {
-				t.Errorf("Expected unit AED/km for per kilometer fare, got %s", dp.Unit)
+			if dp.Unit != "km" {
+				t.Errorf("Expected unit km for per kilometer fare, got %s", dp.Unit)
 			}
 		}
 	}
@@ -573,8 +573,12 @@ func TestCreateFareDataPoint(t *testing.T) {
 		t.Errorf("Expected price 5.0, got %f", dp.Price)
 	}
 
-	if dp.Unit != "AED" {
-		t.Errorf("Expected unit AED, got %s", dp.Unit)
+	if dp.Currency != "AED" {
+		t.Errorf("Expected currency AED, got %s", dp.Currency)
+	}
+
+	if dp.Unit != "trip" {
+		t.Errorf("Expected unit trip, got %s", dp.Unit)
 	}
 
 	if dp.Source != "rta_official" {
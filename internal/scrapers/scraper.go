@@ -20,6 +20,16 @@ type Scraper interface {
 	CanScrape() bool
 }
 
+// CrawlReporter is implemented by scrapers that can tell whether their most
+// recent Scrape returned every live listing of the scopes it covered, rather
+// than stopping at a page or item limit. Listings missing from a scrape are
+// only closed as delisted when its scraper reports a complete crawl.
+type CrawlReporter interface {
+	// LastCrawlComplete reports whether the most recent Scrape fetched every
+	// page of its results
+	LastCrawlComplete() bool
+}
+
 // Config holds common scraper configuration
 type Config struct {
 	UserAgent string
@@ -31,6 +41,11 @@ type Config struct {
 	Timeout    int // seconds
 	MaxRetries int
 	ProxyURL   string // optional
+
+	// MaxPages caps how many result pages a paginated scraper fetches per
+	// scrape. When zero, DefaultMaxPages is used. A crawl that stops at the
+	// cap is reported as incomplete.
+	MaxPages int
 	// ExtraHeaders are added to every outbound request. They can be used to
 	// inject cookies or other negotiated headers required by a target site.
 	ExtraHeaders map[string]string
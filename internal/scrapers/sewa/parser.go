@@ -9,6 +9,7 @@ import (
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/units"
 )
 
 // TariffType represents the type of customer
@@ -166,7 +167,7 @@ func createElectricityDataPoint(rate ElectricityRate, tariffType TariffType, sou
 	itemName = fmt.Sprintf("SEWA Electricity (%s) - %s", tierDesc, customerType)
 
 	// Convert fils to AED (100 fils = 1 AED)
-	priceInAED := rate.Rate / 100.0
+	priceInAED := units.FilsToAED(rate.Rate)
 
 	attributes := map[string]interface{}{
 		"consumption_range_min": rate.MinConsumption,
@@ -192,7 +193,8 @@ func createElectricityDataPoint(rate ElectricityRate, tariffType TariffType, sou
 		Source:      "sewa_official",
 		SourceURL:   sourceURL,
 		Confidence:  0.98, // Official source
-		Unit:        "AED per kWh",
+		Currency:    "AED",
+		Unit:        units.KWh,
 		RecordedAt:  recordedAt,
 		ValidFrom:   recordedAt,
 		SampleSize:  1,
@@ -239,7 +241,7 @@ func parseWaterTariff(doc *goquery.Document) []*models.CostDataPoint {
 			Category:    "Utilities",
 			SubCategory: "Water",
 			ItemName:    itemName,
-			Price:       rate,
+			Price:       rate / 1000, // tariff is per 1000 gallons
 			Location: models.Location{
 				Emirate: "Sharjah",
 				City:    "Sharjah",
@@ -247,7 +249,8 @@ func parseWaterTariff(doc *goquery.Document) []*models.CostDataPoint {
 			Source:      "sewa_official",
 			SourceURL:   "", // Will be set by caller
 			Confidence:  0.98,
-			Unit:        "AED per 1000 gallons",
+			Currency:    "AED",
+			Unit:        units.ImperialGallon,
 			RecordedAt:  now,
 			ValidFrom:   now,
 			SampleSize:  1,
@@ -317,7 +320,7 @@ func parseSewerageInfo(doc *goquery.Document, sourceURL string, recordedAt time.
 		Source:      "sewa_official",
 		SourceURL:   sourceURL,
 		Confidence:  0.98,
-		Unit:        "percentage of water charge",
+		Unit:        units.Fraction,
 		RecordedAt:  recordedAt,
 		ValidFrom:   recordedAt,
 		SampleSize:  1,
@@ -223,7 +223,8 @@ func TestParseWaterTariff(t *testing.T) {
 	assert.Equal(t, "Utilities", emiratiRate.Category)
 	assert.Equal(t, "Water", emiratiRate.SubCategory)
 	assert.Contains(t, emiratiRate.ItemName, "Emirati")
-	assert.Equal(t, 8.0, emiratiRate.Price)
+	assert.InDelta(t, 0.008, emiratiRate.Price, 1e-9) // AED 8 per 1000 gallons
+	assert.Equal(t, "IG", emiratiRate.Unit)
 	assert.Equal(t, "Sharjah", emiratiRate.Location.Emirate)
 	assert.Equal(t, float32(0.98), emiratiRate.Confidence)
 
@@ -231,7 +232,7 @@ func TestParseWaterTariff(t *testing.T) {
 	assert.Equal(t, "Utilities", expatRate.Category)
 	assert.Equal(t, "Water", expatRate.SubCategory)
 	assert.Contains(t, expatRate.ItemName, "Expatriate")
-	assert.Equal(t, 15.0, expatRate.Price)
+	assert.InDelta(t, 0.015, expatRate.Price, 1e-9)
 	assert.Equal(t, "Sharjah", expatRate.Location.Emirate)
 }
 
@@ -317,7 +318,7 @@ func TestCreateElectricityDataPoint(t *testing.T) {
 	assert.Equal(t, "Sharjah", dp.Location.Emirate)
 	assert.Equal(t, "sewa_official", dp.Source)
 	assert.Equal(t, float32(0.98), dp.Confidence)
-	assert.Equal(t, "AED per kWh", dp.Unit)
+	assert.Equal(t, "kWh", dp.Unit)
 
 	// Check attributes
 	assert.Equal(t, 1, dp.Attributes["consumption_range_min"])
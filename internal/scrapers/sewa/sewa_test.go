@@ -213,7 +213,8 @@ func TestSEWAScraper_DataPointValidation(t *testing.T) {
 					assert.Contains(t, dp.ItemName, "1-3000 kWh")
 					assert.Contains(t, dp.ItemName, "Emirati")
 					assert.Equal(t, 0.14, dp.Price, "14 fils = 0.14 AED")
-					assert.Equal(t, "AED per kWh", dp.Unit)
+					assert.Equal(t, "AED", dp.Currency)
+					assert.Equal(t, "kWh", dp.Unit)
 					assert.Equal(t, 3000, dp.Attributes["consumption_range_max"])
 					assert.Equal(t, 14.0, dp.Attributes["rate_fils"])
 					found = true
@@ -232,8 +233,8 @@ func TestSEWAScraper_DataPointValidation(t *testing.T) {
 				if ok && customerType == "expatriate" {
 					assert.Equal(t, "Utilities", dp.Category)
 					assert.Contains(t, dp.ItemName, "Expatriate")
-					assert.Equal(t, 15.0, dp.Price)
-					assert.Equal(t, "AED per 1000 gallons", dp.Unit)
+					assert.InDelta(t, 0.015, dp.Price, 1e-9, "AED 15 per 1000 gallons")
+					assert.Equal(t, "IG", dp.Unit)
 					found = true
 					break
 				}
@@ -249,7 +250,8 @@ func TestSEWAScraper_DataPointValidation(t *testing.T) {
 				assert.Equal(t, "Utilities", dp.Category)
 				assert.Equal(t, "SEWA Sewerage Charge", dp.ItemName)
 				assert.Equal(t, 0.50, dp.Price, "50% as decimal")
-				assert.Equal(t, "percentage of water charge", dp.Unit)
+				assert.Equal(t, "fraction", dp.Unit)
+				assert.Empty(t, dp.Currency)
 				assert.Contains(t, dp.Attributes, "calculation_method")
 				found = true
 				break
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/adonese/cost-of-living/internal/gazetteer"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+// AreaBackfillActor is recorded as the actor of revisions written by the
+// area backfill
+const AreaBackfillActor = "area-backfill"
+
+// AreaBackfill rewrites the area of stored listings to the canonical
+// gazetteer community, as scrapers now do when they collect them. Only
+// listings are touched: the natural key of other data points includes the
+// area, so renaming it would split their history.
+type AreaBackfill struct {
+	repo      repository.CostDataPointRepository
+	gazetteer *gazetteer.Gazetteer
+}
+
+// NewAreaBackfill creates a backfill resolving areas against g
+func NewAreaBackfill(repo repository.CostDataPointRepository, g *gazetteer.Gazetteer) *AreaBackfill {
+	return &AreaBackfill{repo: repo, gazetteer: g}
+}
+
+// AreaBackfillResult summarises a backfill run
+type AreaBackfillResult struct {
+	DryRun bool
+
+	// Scanned is the number of listings examined
+	Scanned int
+
+	// Updated is the number of listings whose area was, or in a dry run
+	// would be, rewritten
+	Updated int
+
+	// Failed is the number of listings that could not be updated
+	Failed int
+
+	// Renames counts rewrites by "old -> new" area
+	Renames map[string]int
+
+	// Unresolved counts listings by area for areas the gazetteer does not
+	// know, to guide additions to the dataset
+	Unresolved map[string]int
+}
+
+// UnresolvedAreas returns the unresolved areas, most frequent first
+func (r *AreaBackfillResult) UnresolvedAreas() []string {
+	areas := make([]string, 0, len(r.Unresolved))
+	for area := range r.Unresolved {
+		areas = append(areas, area)
+	}
+	sort.Slice(areas, func(i, j int) bool {
+		if r.Unresolved[areas[i]] != r.Unresolved[areas[j]] {
+			return r.Unresolved[areas[i]] > r.Unresolved[areas[j]]
+		}
+		return areas[i] < areas[j]
+	})
+	return areas
+}
+
+// Run resolves the area of every listing matching filter and updates those
+// that change, recording a revision for each. Rows are collected before any
+// update so the scan does not hold a connection the updates need.
+func (b *AreaBackfill) Run(ctx context.Context, filter repository.ListFilter, dryRun bool) (*AreaBackfillResult, error) {
+	result := &AreaBackfillResult{
+		DryRun:     dryRun,
+		Renames:    make(map[string]int),
+		Unresolved: make(map[string]int),
+	}
+
+	var changed []*models.CostDataPoint
+	err := b.repo.Stream(ctx, filter, func(cdp *models.CostDataPoint) error {
+		if !cdp.IsListing() || cdp.Location.Area == "" {
+			return nil
+		}
+		result.Scanned++
+
+		loc, ok := b.gazetteer.Resolve(cdp.Location)
+		if !ok {
+			result.Unresolved[cdp.Location.Area]++
+			return nil
+		}
+		if loc.Area == cdp.Location.Area && loc.Emirate == cdp.Location.Emirate {
+			return nil
+		}
+
+		result.Renames[cdp.Location.Area+" -> "+loc.Area]++
+		updated := *cdp
+		updated.Location = loc
+		changed = append(changed, &updated)
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("scan cost data points: %w", err)
+	}
+
+	if dryRun {
+		result.Updated = len(changed)
+		return result, nil
+	}
+
+	ctx = repository.WithAudit(ctx, AreaBackfillActor, "canonicalise area")
+	var errs []error
+	for _, cdp := range changed {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if err := b.repo.Update(ctx, cdp); err != nil {
+			result.Failed++
+			errs = append(errs, fmt.Errorf("update %s: %w", cdp.ID, err))
+			continue
+		}
+		result.Updated++
+	}
+
+	logger.Info("Area backfill completed",
+		"scanned", result.Scanned,
+		"updated", result.Updated,
+		"failed", result.Failed,
+		"unresolved_areas", len(result.Unresolved))
+
+	return result, errors.Join(errs...)
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adonese/cost-of-living/internal/gazetteer"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+func seedAreaBackfill(t *testing.T) (*mock.CostDataPointRepository, map[string]*models.CostDataPoint) {
+	t.Helper()
+
+	repo := mock.NewCostDataPointRepository()
+	points := map[string]*models.CostDataPoint{}
+	for i, area := range []string{"JLT", "Jumeirah Lakes Towers", "Jumeirah Lake Towers", "Atlantis Underwater Village"} {
+		dp := newTestListing(i+1, "Dubai")
+		dp.Location.Area = area
+		require.NoError(t, repo.Create(context.Background(), dp))
+		points[area] = dp
+	}
+
+	// Tariffs keep their area: it is part of their natural key
+	tariff := newTestPoint("Electricity Slab 1")
+	tariff.Location = models.Location{Emirate: "Dubai", Area: "JLT"}
+	require.NoError(t, repo.Create(context.Background(), tariff))
+	points["tariff"] = tariff
+
+	return repo, points
+}
+
+func TestAreaBackfillDryRun(t *testing.T) {
+	logger.Init()
+	repo, points := seedAreaBackfill(t)
+
+	result, err := NewAreaBackfill(repo, gazetteer.Default()).Run(context.Background(), repository.ListFilter{}, true)
+	require.NoError(t, err)
+
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 4, result.Scanned)
+	assert.Equal(t, 2, result.Updated)
+	assert.Equal(t, map[string]int{
+		"JLT -> Jumeirah Lake Towers":                   1,
+		"Jumeirah Lakes Towers -> Jumeirah Lake Towers": 1,
+	}, result.Renames)
+	assert.Equal(t, []string{"Atlantis Underwater Village"}, result.UnresolvedAreas())
+	assert.Equal(t, 0, repo.GetCallCount("Update"))
+
+	stored, err := repo.GetByID(context.Background(), points["JLT"].ID, points["JLT"].RecordedAt)
+	require.NoError(t, err)
+	assert.Equal(t, "JLT", stored.Location.Area)
+}
+
+func TestAreaBackfillUpdatesListings(t *testing.T) {
+	logger.Init()
+	repo, points := seedAreaBackfill(t)
+	ctx := context.Background()
+
+	result, err := NewAreaBackfill(repo, gazetteer.Default()).Run(ctx, repository.ListFilter{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Updated)
+	assert.Equal(t, 0, result.Failed)
+
+	listings, err := repo.List(ctx, repository.ListFilter{Area: "Jumeirah Lake Towers", Source: "bayut"})
+	require.NoError(t, err)
+	assert.Len(t, listings, 3)
+
+	history, err := repo.History(ctx, points["JLT"].ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, AreaBackfillActor, history[0].Actor)
+
+	tariff, err := repo.GetByID(ctx, points["tariff"].ID, points["tariff"].RecordedAt)
+	require.NoError(t, err)
+	assert.Equal(t, "JLT", tariff.Location.Area)
+
+	// A second run has nothing left to do
+	again, err := NewAreaBackfill(repo, gazetteer.Default()).Run(ctx, repository.ListFilter{}, false)
+	require.NoError(t, err)
+	assert.Equal(t, 0, again.Updated)
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/notify"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+// CompensationService rolls back the data written by failed scrape runs
+type CompensationService struct {
+	repo     repository.CostDataPointRepository
+	runs     repository.ScrapeRunRepository
+	notifier notify.Notifier
+	action   models.CompensationAction
+}
+
+// CompensationResult summarises a compensation pass
+type CompensationResult struct {
+	Runs         []string
+	RowsAffected int64
+	Action       models.CompensationAction
+	Errors       []error
+}
+
+// NewCompensationService creates a compensation service. When notifier is nil
+// notifications are only logged; when action is empty rows are invalidated.
+func NewCompensationService(
+	repo repository.CostDataPointRepository,
+	runs repository.ScrapeRunRepository,
+	notifier notify.Notifier,
+	action models.CompensationAction,
+) *CompensationService {
+	if notifier == nil {
+		notifier = notify.NewLogNotifier()
+	}
+	if action == "" {
+		action = models.CompensationInvalidate
+	}
+	return &CompensationService{
+		repo:     repo,
+		runs:     runs,
+		notifier: notifier,
+		action:   action,
+	}
+}
+
+// ParseCompensationAction converts a configuration string into an action,
+// defaulting to invalidation.
+func ParseCompensationAction(s string) (models.CompensationAction, error) {
+	switch models.CompensationAction(s) {
+	case "", models.CompensationInvalidate:
+		return models.CompensationInvalidate, nil
+	case models.CompensationDelete:
+		return models.CompensationDelete, nil
+	default:
+		return "", fmt.Errorf("unsupported compensation action %q", s)
+	}
+}
+
+// CompensateRuns rolls back every failed or unfinished run matching filter.
+// Runs that already succeeded or were compensated are never touched.
+func (c *CompensationService) CompensateRuns(ctx context.Context, filter repository.ScrapeRunFilter) (*CompensationResult, error) {
+	filter.Statuses = []models.ScrapeRunStatus{models.ScrapeRunFailed, models.ScrapeRunRunning}
+
+	runs, err := c.runs.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("list runs to compensate: %w", err)
+	}
+
+	result := &CompensationResult{Action: c.action}
+	for _, run := range runs {
+		rows, err := c.compensateRun(ctx, run)
+		result.Runs = append(result.Runs, run.ID)
+		result.RowsAffected += rows
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Errorf("run %s: %w", run.ID, err))
+		}
+	}
+
+	if len(runs) > 0 {
+		c.notify(ctx, filter.ScraperName, result)
+	}
+
+	if len(result.Errors) > 0 {
+		return result, errors.Join(result.Errors...)
+	}
+	return result, nil
+}
+
+// compensateRun rolls back a single run and records the outcome on it
+func (c *CompensationService) compensateRun(ctx context.Context, run *models.ScrapeRun) (int64, error) {
+	now := time.Now()
+
+	var rows int64
+	var err error
+	switch c.action {
+	case models.CompensationDelete:
+		rows, err = c.repo.DeleteByRunID(ctx, run.ID)
+	default:
+		rows, err = c.repo.InvalidateByRunID(ctx, run.ID, now)
+	}
+
+	run.CompensatedAt = &now
+	run.CompensationAction = c.action
+	run.CompensatedRows = rows
+	if err != nil {
+		run.CompensationError = err.Error()
+	} else {
+		run.Status = models.ScrapeRunCompensated
+		run.CompensationError = ""
+	}
+	if run.FinishedAt == nil {
+		run.FinishedAt = &now
+	}
+
+	if updateErr := c.runs.Update(ctx, run); updateErr != nil {
+		logger.Error("Failed to record compensation outcome", "run_id", run.ID, "error", updateErr)
+		if err == nil {
+			err = updateErr
+		}
+	}
+
+	logger.Info("Compensated scrape run",
+		"scraper", run.ScraperName,
+		"run_id", run.ID,
+		"action", c.action,
+		"rows", rows,
+		"error", err)
+
+	return rows, err
+}
+
+func (c *CompensationService) notify(ctx context.Context, scraperName string, result *CompensationResult) {
+	severity := notify.SeverityWarning
+	message := fmt.Sprintf("Rolled back %d rows from %d failed run(s)", result.RowsAffected, len(result.Runs))
+	if len(result.Errors) > 0 {
+		severity = notify.SeverityCritical
+		message = fmt.Sprintf("%s; %d run(s) could not be compensated", message, len(result.Errors))
+	}
+
+	n := notify.Notification{
+		Title:    fmt.Sprintf("Scrape compensation: %s", scraperName),
+		Message:  message,
+		Severity: severity,
+		Fields: map[string]string{
+			"scraper": scraperName,
+			"action":  string(result.Action),
+			"runs":    strconv.Itoa(len(result.Runs)),
+			"rows":    strconv.FormatInt(result.RowsAffected, 10),
+		},
+		SentAt: time.Now(),
+	}
+
+	if err := c.notifier.Notify(ctx, n); err != nil {
+		logger.Error("Failed to send compensation notification", "scraper", scraperName, "error", err)
+	}
+}
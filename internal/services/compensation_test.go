@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/notify"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+type recordingNotifier struct {
+	notifications []notify.Notification
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, n notify.Notification) error {
+	r.notifications = append(r.notifications, n)
+	return nil
+}
+
+func seedRun(t *testing.T, repo *mock.CostDataPointRepository, runs *mock.ScrapeRunRepository, status models.ScrapeRunStatus, points int) *models.ScrapeRun {
+	t.Helper()
+
+	run := &models.ScrapeRun{ScraperName: "bayut", Status: status, WorkflowID: "wf-1"}
+	require.NoError(t, runs.Create(context.Background(), run))
+
+	for i := 0; i < points; i++ {
+		dp := newTestPoint("listing")
+		dp.RunID = run.ID
+		require.NoError(t, repo.Create(context.Background(), dp))
+	}
+	return run
+}
+
+func TestCompensationServiceInvalidatesFailedRuns(t *testing.T) {
+	logger.Init()
+
+	repo := mock.NewCostDataPointRepository()
+	runs := mock.NewScrapeRunRepository()
+	notifier := &recordingNotifier{}
+
+	failed := seedRun(t, repo, runs, models.ScrapeRunFailed, 2)
+	succeeded := seedRun(t, repo, runs, models.ScrapeRunSucceeded, 1)
+
+	service := NewCompensationService(repo, runs, notifier, models.CompensationInvalidate)
+	result, err := service.CompensateRuns(context.Background(), repository.ScrapeRunFilter{
+		ScraperName: "bayut",
+		WorkflowID:  "wf-1",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{failed.ID}, result.Runs)
+	assert.EqualValues(t, 2, result.RowsAffected)
+
+	active, err := repo.List(context.Background(), repository.ListFilter{ActiveOnly: true})
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, succeeded.ID, active[0].RunID)
+
+	stored, err := runs.GetByID(context.Background(), failed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ScrapeRunCompensated, stored.Status)
+	assert.Equal(t, models.CompensationInvalidate, stored.CompensationAction)
+	assert.EqualValues(t, 2, stored.CompensatedRows)
+	require.NotNil(t, stored.CompensatedAt)
+
+	require.Len(t, notifier.notifications, 1)
+	assert.Equal(t, notify.SeverityWarning, notifier.notifications[0].Severity)
+}
+
+func TestCompensationServiceDeletesRunData(t *testing.T) {
+	logger.Init()
+
+	repo := mock.NewCostDataPointRepository()
+	runs := mock.NewScrapeRunRepository()
+
+	seedRun(t, repo, runs, models.ScrapeRunRunning, 3)
+
+	service := NewCompensationService(repo, runs, &recordingNotifier{}, models.CompensationDelete)
+	result, err := service.CompensateRuns(context.Background(), repository.ScrapeRunFilter{ScraperName: "bayut"})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, result.RowsAffected)
+
+	remaining, err := repo.List(context.Background(), repository.ListFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+}
+
+func TestCompensationServiceNoRuns(t *testing.T) {
+	logger.Init()
+
+	notifier := &recordingNotifier{}
+	service := NewCompensationService(mock.NewCostDataPointRepository(), mock.NewScrapeRunRepository(), notifier, "")
+
+	result, err := service.CompensateRuns(context.Background(), repository.ScrapeRunFilter{ScraperName: "bayut"})
+	require.NoError(t, err)
+	assert.Empty(t, result.Runs)
+	assert.Empty(t, notifier.notifications)
+}
+
+func TestParseCompensationAction(t *testing.T) {
+	action, err := ParseCompensationAction("")
+	require.NoError(t, err)
+	assert.Equal(t, models.CompensationInvalidate, action)
+
+	action, err = ParseCompensationAction("delete")
+	require.NoError(t, err)
+	assert.Equal(t, models.CompensationDelete, action)
+
+	_, err = ParseCompensationAction("truncate")
+	assert.Error(t, err)
+}
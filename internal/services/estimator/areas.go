@@ -0,0 +1,333 @@
+package estimator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/units"
+)
+
+const (
+	// roadDistanceFactor converts the straight-line distance between
+	// community centroids into an approximate driving distance.
+	roadDistanceFactor = 1.3
+
+	// areaFullConfidenceSamples is the sample size from which an area's
+	// confidence is no longer discounted for having few listings.
+	areaFullConfidenceSamples = 10
+)
+
+// AreaQuery describes the home an area comparison looks for. Emirate is
+// required.
+type AreaQuery struct {
+	Emirate string
+
+	// Bedrooms matches the bedrooms attribute of listings when set.
+	Bedrooms *int
+
+	// HousingType defaults to apartment. Shared selects shared
+	// accommodation and villa only listings tagged villa.
+	HousingType HousingType
+
+	// Budget is the monthly AED the rent and commute must fit in; zero
+	// means no budget.
+	Budget float64
+
+	// Target is the community commuted to, such as the place of work.
+	// Without it commute costs are left out.
+	Target string
+
+	// TransportMode defaults to mixed; WorkDaysPerWeek to 5.
+	TransportMode   TransportMode
+	WorkDaysPerWeek int
+}
+
+// AreaScore ranks one community for an AreaQuery, after the AreaScore
+// sketched in data_models.md. Amounts are monthly AED.
+type AreaScore struct {
+	Rank     int              `json:"rank"`
+	Area     string           `json:"area"`
+	Emirate  string           `json:"emirate"`
+	Centroid *models.GeoPoint `json:"centroid,omitempty"`
+
+	MedianRent float64 `json:"median_rent"`
+	RentLow    float64 `json:"rent_low"`
+	RentHigh   float64 `json:"rent_high"`
+	SampleSize int     `json:"sample_size"`
+	// Confidence is the mean confidence of the listings, discounted for
+	// areas with few of them.
+	Confidence float32 `json:"confidence"`
+
+	// CommuteKm is the approximate road distance to the target; nil when
+	// there is no target or the area's location is unknown.
+	CommuteKm   *float64 `json:"commute_km,omitempty"`
+	CommuteCost float64  `json:"commute_cost"`
+
+	MonthlyTotal float64 `json:"monthly_total"`
+	WithinBudget bool    `json:"within_budget"`
+
+	// AffordabilityScore is 100 for the cheapest area and falls in
+	// proportion as MonthlyTotal rises above it; Score weighs it by
+	// Confidence and decides the ranking.
+	AffordabilityScore float32 `json:"affordability_score"`
+	Score              float32 `json:"score"`
+
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// AreaComparison is the response of CompareAreas.
+type AreaComparison struct {
+	Emirate       string        `json:"emirate"`
+	Bedrooms      *int          `json:"bedrooms,omitempty"`
+	HousingType   HousingType   `json:"housing_type"`
+	Budget        float64       `json:"budget,omitempty"`
+	Target        string        `json:"target,omitempty"`
+	TransportMode TransportMode `json:"transport_mode"`
+	Currency      string        `json:"currency"`
+	Areas         []AreaScore   `json:"areas"`
+	SampleSize    int           `json:"sample_size"`
+	Warnings      []string      `json:"warnings,omitempty"`
+	GeneratedAt   time.Time     `json:"generated_at"`
+}
+
+// CompareAreas ranks the communities of an emirate by the median rent of
+// recent matching listings plus the commute to the target, weighted by how
+// much data backs each area. Areas within budget rank before the rest.
+// Invalid queries return repository.ErrValidationFailed errors.
+func (s *Service) CompareAreas(ctx context.Context, query AreaQuery) (*AreaComparison, error) {
+	query, err := query.normalize()
+	if err != nil {
+		return nil, repository.ValidationFailed(err)
+	}
+
+	var target *models.GeoPoint
+	if query.Target != "" {
+		community, ok := s.config.Gazetteer.Lookup(query.Emirate, query.Target)
+		if !ok {
+			return nil, repository.ValidationFailed(fmt.Errorf("unknown target area %q in %s", query.Target, query.Emirate))
+		}
+		query.Target = community.Name
+		target = &community.Centroid
+	}
+
+	since := s.lookbackStart()
+	filter := repository.ListFilter{
+		Category:    "Housing",
+		SubCategory: housingSubCategory(query.HousingType),
+		Emirate:     query.Emirate,
+		ActiveOnly:  true,
+		StartDate:   &since,
+	}
+	if query.HousingType == HousingVilla {
+		filter.Tags = []string{string(HousingVilla)}
+	}
+	if query.Bedrooms != nil {
+		filter.Attributes = map[string]string{"bedrooms": strconv.Itoa(*query.Bedrooms)}
+	}
+
+	type areaData struct {
+		centroid *models.GeoPoint
+		points   []*models.CostDataPoint
+	}
+	areas := map[string]*areaData{}
+	unplaced := 0
+	err = s.repo.Stream(ctx, filter, func(dp *models.CostDataPoint) error {
+		if monthlyPrice(dp) <= 0 {
+			return nil
+		}
+		name := strings.TrimSpace(dp.Location.Area)
+		var centroid *models.GeoPoint
+		if community, ok := s.config.Gazetteer.Lookup(query.Emirate, name); ok {
+			name, centroid = community.Name, &community.Centroid
+		}
+		if name == "" {
+			unplaced++
+			return nil
+		}
+		if areas[name] == nil {
+			areas[name] = &areaData{centroid: centroid}
+		}
+		areas[name].points = append(areas[name].points, dp)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("stream housing data: %w", err)
+	}
+
+	res := &AreaComparison{
+		Emirate:       query.Emirate,
+		Bedrooms:      query.Bedrooms,
+		HousingType:   query.HousingType,
+		Budget:        query.Budget,
+		Target:        query.Target,
+		TransportMode: query.TransportMode,
+		Currency:      models.DefaultCurrency,
+		Areas:         make([]AreaScore, 0, len(areas)),
+		GeneratedAt:   time.Now(),
+	}
+	if unplaced > 0 {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("%d listings without an area were left out.", unplaced))
+	}
+
+	var fares commuteFares
+	if target != nil {
+		if fares, err = s.commuteFares(ctx, query.Emirate, since); err != nil {
+			return nil, err
+		}
+	}
+	trips := monthlyCommuteTrips(query.WorkDaysPerWeek)
+
+	var unlocated []string
+	for name, data := range areas {
+		stats := computeStats(data.points, monthlyPrice)
+		score := AreaScore{
+			Area:        name,
+			Emirate:     query.Emirate,
+			Centroid:    data.centroid,
+			MedianRent:  roundCurrency(stats.Median),
+			RentLow:     roundCurrency(stats.P25),
+			RentHigh:    roundCurrency(stats.P75),
+			SampleSize:  stats.SampleSize,
+			Confidence:  float32(math.Round(stats.Confidence*math.Min(1, float64(stats.SampleSize)/areaFullConfidenceSamples)*100) / 100),
+			LastUpdated: stats.LastUpdated,
+		}
+
+		if target != nil {
+			if data.centroid == nil {
+				unlocated = append(unlocated, name)
+			} else {
+				km := math.Round(data.centroid.DistanceKm(*target)*roadDistanceFactor*10) / 10
+				score.CommuteKm = &km
+				score.CommuteCost = roundCurrency(monthlyCommute(query.TransportMode, fares.public, fares.rideShare(km), trips))
+			}
+		}
+
+		score.MonthlyTotal = roundCurrency(score.MedianRent + score.CommuteCost)
+		score.WithinBudget = query.Budget == 0 || score.MonthlyTotal <= query.Budget
+		res.Areas = append(res.Areas, score)
+		res.SampleSize += stats.SampleSize
+	}
+
+	if len(unlocated) > 0 {
+		sort.Strings(unlocated)
+		res.Warnings = append(res.Warnings, fmt.Sprintf("No commute cost for areas missing from the gazetteer: %s.", strings.Join(unlocated, ", ")))
+	}
+
+	rankAreas(res.Areas)
+	return res, nil
+}
+
+// rankAreas scores areas against the cheapest one and sorts them, those
+// within budget first, by descending score.
+func rankAreas(areas []AreaScore) {
+	cheapest := math.Inf(1)
+	for _, a := range areas {
+		if a.MonthlyTotal > 0 {
+			cheapest = math.Min(cheapest, a.MonthlyTotal)
+		}
+	}
+	for i := range areas {
+		if areas[i].MonthlyTotal <= 0 {
+			continue
+		}
+		affordability := 100 * cheapest / areas[i].MonthlyTotal
+		areas[i].AffordabilityScore = float32(math.Round(affordability*10) / 10)
+		// An area backed by no confident data keeps 60% of its affordability
+		areas[i].Score = float32(math.Round(affordability*(0.6+0.4*float64(areas[i].Confidence))*10) / 10)
+	}
+
+	sort.SliceStable(areas, func(i, j int) bool {
+		a, b := areas[i], areas[j]
+		if a.WithinBudget != b.WithinBudget {
+			return a.WithinBudget
+		}
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Area < b.Area
+	})
+	for i := range areas {
+		areas[i].Rank = i + 1
+	}
+}
+
+// commuteFares are the scraped fares commute costs are built from.
+type commuteFares struct {
+	public        float64
+	rideShareData []*models.CostDataPoint
+}
+
+// rideShare returns the fare of a ride share trip of km. Trips within an
+// area are priced as 1 km, which the minimum fare covers.
+func (f commuteFares) rideShare(km float64) float64 {
+	return estimateRideShareTrip(f.rideShareData, math.Max(km, 1))
+}
+
+func (s *Service) commuteFares(ctx context.Context, emirate string, since time.Time) (commuteFares, error) {
+	publicData, err := s.fetchData(ctx, "Transportation", "Public Transport", emirate, s.config.TransportSampleLimit, since)
+	if err != nil {
+		return commuteFares{}, err
+	}
+	rideShareData, err := s.fetchData(ctx, "Transportation", "Ride Sharing", emirate, s.config.TransportSampleLimit, since)
+	if err != nil {
+		return commuteFares{}, err
+	}
+
+	fares := commuteFares{
+		public:        computeStats(publicData, pricePer(units.Trip)).Median,
+		rideShareData: rideShareData,
+	}
+	if fares.public == 0 {
+		fares.public = defaultPublicFare
+	}
+	return fares, nil
+}
+
+// normalize applies the query defaults and checks its options.
+func (q AreaQuery) normalize() (AreaQuery, error) {
+	q.Emirate = strings.TrimSpace(q.Emirate)
+	q.Target = strings.TrimSpace(q.Target)
+	q.HousingType = HousingType(strings.ToLower(string(q.HousingType)))
+	q.TransportMode = TransportMode(strings.ToLower(string(q.TransportMode)))
+	if q.HousingType == "" {
+		q.HousingType = HousingApartment
+	}
+	if q.TransportMode == "" {
+		q.TransportMode = TransportMixed
+	}
+	if q.WorkDaysPerWeek <= 0 {
+		q.WorkDaysPerWeek = 5
+	}
+
+	var problems []string
+	if q.Emirate == "" {
+		problems = append(problems, "emirate is required")
+	}
+	if !isValidHousingType(q.HousingType) {
+		problems = append(problems, fmt.Sprintf("unsupported housing_type %q", q.HousingType))
+	}
+	if !isValidTransportMode(q.TransportMode) {
+		problems = append(problems, fmt.Sprintf("unsupported transport_mode %q", q.TransportMode))
+	}
+	if q.Bedrooms != nil && *q.Bedrooms < 0 {
+		problems = append(problems, "bedrooms cannot be negative")
+	}
+	if q.Budget < 0 {
+		problems = append(problems, "budget cannot be negative")
+	}
+	if q.WorkDaysPerWeek > 7 {
+		problems = append(problems, "work_days_per_week cannot exceed 7")
+	}
+	if len(problems) > 0 {
+		return q, errors.New(strings.Join(problems, "; "))
+	}
+	return q, nil
+}
@@ -0,0 +1,129 @@
+package estimator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adonese/cost-of-living/internal/repository"
+	mockrepo "github.com/adonese/cost-of-living/internal/repository/mock"
+)
+
+func TestServiceCompareAreas(t *testing.T) {
+	repo := mockrepo.NewCostDataPointRepository()
+	now := time.Now()
+
+	// Yearly 2BR rents: the Marina is dearer but close to the target, JVC is
+	// cheap with plenty of listings, and International City is cheapest on
+	// a single listing
+	add := func(area string, yearly ...float64) {
+		for i, price := range yearly {
+			require.NoError(t, repo.Create(context.Background(), newRentPoint(fmt.Sprintf("%s-%d", area, i), area, "2", price, now)))
+		}
+	}
+	add("Marina", 144000, 150000, 156000, 150000, 150000, 150000, 150000, 150000, 150000, 150000)
+	add("JVC", 96000, 102000, 108000, 102000, 102000, 102000, 102000, 102000, 102000, 102000)
+	add("International City", 60000)
+	add("Mystery Towers", 90000)
+	add("", 70000)
+	require.NoError(t, repo.Create(context.Background(), newRentPoint("jvc-1br", "JVC", "1", 50000, now)))
+	require.NoError(t, repo.Create(context.Background(), newRentPoint("jvc-stale", "JVC", "2", 10000, now.AddDate(-1, 0, 0))))
+
+	svc := NewService(repo, nil)
+	bedrooms := 2
+	res, err := svc.CompareAreas(context.Background(), AreaQuery{
+		Emirate:       "Dubai",
+		Bedrooms:      &bedrooms,
+		Budget:        10000,
+		Target:        "JLT",
+		TransportMode: TransportPublic,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Jumeirah Lake Towers", res.Target, "the target is resolved through the gazetteer")
+	assert.Equal(t, HousingApartment, res.HousingType)
+	assert.Equal(t, "AED", res.Currency)
+	assert.Equal(t, 22, res.SampleSize)
+	require.Len(t, res.Areas, 4)
+
+	byArea := map[string]AreaScore{}
+	for _, a := range res.Areas {
+		byArea[a.Area] = a
+	}
+
+	marina := byArea["Dubai Marina"]
+	assert.Equal(t, 12500.0, marina.MedianRent, "aliases are grouped under the community name")
+	assert.Equal(t, 10, marina.SampleSize)
+	assert.Equal(t, float32(0.9), marina.Confidence)
+	require.NotNil(t, marina.CommuteKm)
+	assert.InDelta(t, 1.6, *marina.CommuteKm, 0.2)
+	assert.Equal(t, 4.0*43, marina.CommuteCost, "flat public fares over 43 trips a month")
+	assert.False(t, marina.WithinBudget)
+
+	jvc := byArea["Jumeirah Village Circle"]
+	assert.Equal(t, 8500.0, jvc.MedianRent)
+	assert.Equal(t, 8672.0, jvc.MonthlyTotal)
+	assert.True(t, jvc.WithinBudget)
+
+	intl := byArea["International City"]
+	assert.Equal(t, float32(0.09), intl.Confidence, "one listing is a tenth of full confidence")
+	assert.Equal(t, float32(100), intl.AffordabilityScore)
+	assert.Equal(t, float32(63.6), intl.Score)
+	assert.Equal(t, float32(59.6), jvc.AffordabilityScore)
+	assert.Less(t, jvc.Score, jvc.AffordabilityScore)
+
+	mystery := byArea["Mystery Towers"]
+	assert.Nil(t, mystery.CommuteKm)
+	assert.Nil(t, mystery.Centroid)
+	assert.Zero(t, mystery.CommuteCost)
+
+	// A single listing costs International City part of its lead over JVC;
+	// the Marina is over budget and ranks last
+	names := make([]string, len(res.Areas))
+	for i, a := range res.Areas {
+		names[i] = a.Area
+		assert.Equal(t, i+1, a.Rank)
+	}
+	assert.Equal(t, []string{"International City", "Jumeirah Village Circle", "Mystery Towers", "Dubai Marina"}, names)
+	assert.Equal(t, []string{
+		"1 listings without an area were left out.",
+		"No commute cost for areas missing from the gazetteer: Mystery Towers.",
+	}, res.Warnings)
+}
+
+func TestServiceCompareAreasWithoutTarget(t *testing.T) {
+	repo := mockrepo.NewCostDataPointRepository()
+	require.NoError(t, repo.Create(context.Background(), newRentPoint("jvc", "JVC", "1", 72000, time.Now())))
+
+	res, err := NewService(repo, nil).CompareAreas(context.Background(), AreaQuery{Emirate: "Dubai"})
+	require.NoError(t, err)
+	require.Len(t, res.Areas, 1)
+	assert.Nil(t, res.Areas[0].CommuteKm)
+	assert.Equal(t, 6000.0, res.Areas[0].MonthlyTotal)
+	assert.True(t, res.Areas[0].WithinBudget, "no budget means every area fits")
+
+	res, err = NewService(repo, nil).CompareAreas(context.Background(), AreaQuery{Emirate: "Dubai", HousingType: HousingVilla})
+	require.NoError(t, err)
+	assert.Empty(t, res.Areas, "villas need listings tagged villa")
+}
+
+func TestServiceCompareAreasValidation(t *testing.T) {
+	svc := NewService(mockrepo.NewCostDataPointRepository(), nil)
+	negative := -1
+
+	for _, query := range []AreaQuery{
+		{},
+		{Emirate: "Dubai", HousingType: "castle"},
+		{Emirate: "Dubai", TransportMode: "boat"},
+		{Emirate: "Dubai", Bedrooms: &negative},
+		{Emirate: "Dubai", Budget: -5},
+		{Emirate: "Dubai", Target: "Atlantis Underwater"},
+	} {
+		_, err := svc.CompareAreas(context.Background(), query)
+		assert.ErrorIs(t, err, repository.ErrValidationFailed, "%+v", query)
+	}
+}
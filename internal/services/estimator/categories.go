@@ -4,10 +4,10 @@ import (
 	"context"
 	"math"
 	"sort"
-	"strings"
 	"time"
 
 	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/units"
 )
 
 func (s *Service) buildHousingEstimate(ctx context.Context, persona PersonaInput, since time.Time, tracker *dataTracker) (CategoryEstimate, error) {
@@ -17,13 +17,7 @@ func (s *Service) buildHousingEstimate(ctx context.Context, persona PersonaInput
 		return CategoryEstimate{}, err
 	}
 
-	stats := computeStats(data, func(dp *models.CostDataPoint) float64 {
-		price := dp.Price
-		if strings.EqualFold(dp.SubCategory, "Rent") {
-			price = price / 12
-		}
-		return price
-	})
+	stats := computeStats(data, monthlyPrice)
 
 	tracker.Track("Housing", stats)
 
@@ -93,9 +87,9 @@ func (s *Service) buildUtilitiesEstimate(ctx context.Context, persona PersonaInp
 		return CategoryEstimate{}, err
 	}
 
-	elecStats := computeStats(electricityData, func(dp *models.CostDataPoint) float64 { return dp.Price })
-	waterStats := computeStats(waterData, func(dp *models.CostDataPoint) float64 { return dp.Price })
-	surchargeStats := computeStats(surchargeData, func(dp *models.CostDataPoint) float64 { return dp.Price })
+	elecStats := computeStats(electricityData, pricePer(units.KWh))
+	waterStats := computeStats(waterData, pricePer(units.CubicMetre))
+	surchargeStats := computeStats(surchargeData, pricePer(units.KWh))
 
 	tracker.Track("Utilities", elecStats)
 	tracker.Track("Utilities", waterStats)
@@ -169,22 +163,19 @@ func (s *Service) buildTransportEstimate(ctx context.Context, persona PersonaInp
 		return CategoryEstimate{}, err
 	}
 
-	publicStats := computeStats(publicData, func(dp *models.CostDataPoint) float64 { return dp.Price })
-	taxiStats := computeStats(taxiData, func(dp *models.CostDataPoint) float64 { return dp.Price })
+	publicStats := computeStats(publicData, pricePer(units.Trip))
+	taxiStats := computeStats(taxiData, pricePer(units.Kilometre))
 	rideStats := computeStats(rideShareData, func(dp *models.CostDataPoint) float64 { return dp.Price })
 
 	tracker.Track("Transportation", publicStats)
 	tracker.Track("Transportation", taxiStats)
 	tracker.Track("Transportation", rideStats)
 
-	commuteTrips := float64(persona.WorkDaysPerWeek*2) * 4.3
-	if commuteTrips < 30 {
-		commuteTrips = 30
-	}
+	commuteTrips := monthlyCommuteTrips(persona.WorkDaysPerWeek)
 
 	publicFare := publicStats.Median
 	if publicFare == 0 {
-		publicFare = 4.0
+		publicFare = defaultPublicFare
 	}
 
 	taxiPerKm := taxiStats.Median
@@ -197,16 +188,7 @@ func (s *Service) buildTransportEstimate(ctx context.Context, persona PersonaInp
 		rideFare = taxiPerKm*persona.CommuteDistanceKM + 8
 	}
 
-	monthly := 0.0
-	switch persona.TransportMode {
-	case TransportPublic:
-		monthly = publicFare * commuteTrips
-	case TransportRideshare:
-		monthly = rideFare*commuteTrips + 4*rideFare // errands
-	default:
-		monthly = publicFare*commuteTrips*0.65 + rideFare*commuteTrips*0.35
-	}
-
+	monthly := monthlyCommute(persona.TransportMode, publicFare, rideFare, commuteTrips)
 	monthly *= s.config.LifestyleMultipliers[persona.Lifestyle]
 
 	estimate := CategoryEstimate{
@@ -349,6 +331,36 @@ func (s *Service) fallbackHousing(persona PersonaInput) float64 {
 	return base * lifestyleMult * housingMult * bedroomMult
 }
 
+// monthlyPrice returns the monthly AED cost of a data point, or 0 to skip
+// data points in another currency or with an unknown period
+func monthlyPrice(dp *models.CostDataPoint) float64 {
+	if dp.Currency != models.DefaultCurrency {
+		return 0
+	}
+	price, err := units.Monthly(dp.Price, dp.Period)
+	if err != nil {
+		return 0
+	}
+	return price
+}
+
+// pricePer returns a transform giving the AED price of a data point per
+// unit, converting volumes as needed. Data points quoted in another currency
+// or per an incompatible unit, such as a taxi flag fall among per-km rates,
+// are skipped.
+func pricePer(unit string) func(*models.CostDataPoint) float64 {
+	return func(dp *models.CostDataPoint) float64 {
+		if dp.Currency != models.DefaultCurrency {
+			return 0
+		}
+		price, err := units.PricePer(dp.Price, dp.Unit, unit)
+		if err != nil {
+			return 0
+		}
+		return price
+	}
+}
+
 func mergeSources(groups ...[]string) []string {
 	uniq := map[string]struct{}{}
 	for _, g := range groups {
@@ -367,6 +379,33 @@ func mergeSources(groups ...[]string) []string {
 	return out
 }
 
+// defaultPublicFare is the AED fare of a public transport trip when no
+// fares have been scraped.
+const defaultPublicFare = 4.0
+
+// monthlyCommuteTrips returns the one-way trips of a month of commuting,
+// at least 30.
+func monthlyCommuteTrips(workDaysPerWeek int) float64 {
+	trips := float64(workDaysPerWeek*2) * 4.3
+	if trips < 30 {
+		trips = 30
+	}
+	return trips
+}
+
+// monthlyCommute returns the monthly cost of trips commuting by mode, given
+// the fare of one public transport trip and one ride share trip.
+func monthlyCommute(mode TransportMode, publicFare, rideFare, trips float64) float64 {
+	switch mode {
+	case TransportPublic:
+		return publicFare * trips
+	case TransportRideshare:
+		return rideFare*trips + 4*rideFare // errands
+	default:
+		return publicFare*trips*0.65 + rideFare*trips*0.35
+	}
+}
+
 func estimateRideShareTrip(data []*models.CostDataPoint, distance float64) float64 {
 	if distance <= 0 {
 		distance = 15
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/adonese/cost-of-living/internal/fx"
 	"github.com/adonese/cost-of-living/internal/models"
 	"github.com/adonese/cost-of-living/internal/repository"
 )
@@ -49,19 +50,47 @@ func NewService(repo repository.CostDataPointRepository, cfg *Config) *Service {
 		if cfg.BedroomStepPercent > 0 {
 			finalCfg.BedroomStepPercent = cfg.BedroomStepPercent
 		}
+		if cfg.TrendStablePercent > 0 {
+			finalCfg.TrendStablePercent = cfg.TrendStablePercent
+		}
+		if cfg.Rates != nil {
+			finalCfg.Rates = cfg.Rates
+		}
+		if cfg.Gazetteer != nil {
+			finalCfg.Gazetteer = cfg.Gazetteer
+		}
+		if cfg.Rollups != nil {
+			finalCfg.Rollups = cfg.Rollups
+		}
 	}
 
 	return &Service{repo: repo, config: finalCfg}
 }
 
-// Estimate returns a monthly budget breakdown for the supplied persona.
+// Estimate returns a monthly budget breakdown for the supplied persona in
+// the configured currency.
 func (s *Service) Estimate(ctx context.Context, persona PersonaInput) (*EstimateResult, error) {
+	return s.EstimateIn(ctx, persona, s.config.Currency)
+}
+
+// EstimateIn returns a monthly budget breakdown converted to currency. An
+// empty currency uses the configured one. Unknown currencies return an error
+// wrapping fx.ErrUnsupportedCurrency.
+func (s *Service) EstimateIn(ctx context.Context, persona PersonaInput, currency string) (*EstimateResult, error) {
 	persona = persona.Normalize()
 	if errs := persona.Validate(); len(errs) > 0 {
 		return nil, combineErrors(errs)
 	}
 
-	since := time.Now().AddDate(0, 0, -s.config.LookbackDays)
+	if currency == "" {
+		currency = s.config.Currency
+	}
+	rate, err := s.rate(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	since := s.lookbackStart()
 	tracker := newDataTracker()
 
 	housing, err := s.buildHousingEstimate(ctx, persona, since, tracker)
@@ -87,26 +116,46 @@ func (s *Service) Estimate(ctx context.Context, persona PersonaInput) (*Estimate
 		return breakdown[i].MonthlyAED > breakdown[j].MonthlyAED
 	})
 
-	total := 0.0
+	total, converted := 0.0, 0.0
 	for i := range breakdown {
 		breakdown[i].MonthlyAED = roundCurrency(breakdown[i].MonthlyAED)
 		breakdown[i].RangeLowAED = roundCurrency(breakdown[i].RangeLowAED)
 		breakdown[i].RangeHighAED = roundCurrency(breakdown[i].RangeHighAED)
+		breakdown[i].Monthly = roundCurrency(rate.Convert(breakdown[i].MonthlyAED))
+		breakdown[i].RangeLow = roundCurrency(rate.Convert(breakdown[i].RangeLowAED))
+		breakdown[i].RangeHigh = roundCurrency(rate.Convert(breakdown[i].RangeHighAED))
 		total += breakdown[i].MonthlyAED
+		converted += breakdown[i].Monthly
 	}
 
 	res := &EstimateResult{
 		Persona:         persona,
-		Currency:        s.config.Currency,
+		Currency:        rate.To,
 		MonthlyTotalAED: roundCurrency(total),
+		MonthlyTotal:    roundCurrency(converted),
 		Breakdown:       breakdown,
 		Recommendations: s.buildRecommendations(breakdown, persona),
 		Dataset:         tracker.Snapshot(),
 		GeneratedAt:     time.Now(),
 	}
+	if rate.To != fx.BaseCurrency {
+		res.FXRate = &rate
+	}
 	return res, nil
 }
 
+// rate returns the conversion from AED to currency
+func (s *Service) rate(ctx context.Context, currency string) (fx.Rate, error) {
+	code := fx.NormalizeCode(currency)
+	if code == fx.BaseCurrency {
+		return fx.Rate{From: fx.BaseCurrency, To: fx.BaseCurrency, Rate: 1}, nil
+	}
+	if code == "" || s.config.Rates == nil {
+		return fx.Rate{}, fmt.Errorf("%w %q", fx.ErrUnsupportedCurrency, currency)
+	}
+	return s.config.Rates.Rate(ctx, fx.BaseCurrency, code)
+}
+
 // Summary returns dataset coverage info for UI/monitoring cards.
 func (s *Service) Summary(ctx context.Context, emirate string) (DatasetSnapshot, error) {
 	emirate = strings.TrimSpace(emirate)
@@ -125,7 +174,7 @@ func (s *Service) Summary(ctx context.Context, emirate string) (DatasetSnapshot,
 	}
 	persona = persona.Normalize()
 
-	since := time.Now().AddDate(0, 0, -s.config.LookbackDays)
+	since := s.lookbackStart()
 	tracker := newDataTracker()
 
 	if _, err := s.buildHousingEstimate(ctx, persona, since, tracker); err != nil {
@@ -142,10 +191,19 @@ func (s *Service) Summary(ctx context.Context, emirate string) (DatasetSnapshot,
 	return tracker.Snapshot(), nil
 }
 
+// lookbackStart returns the start of the window estimates draw data from.
+// It is truncated to the day so that repeated estimates build identical
+// filters and share cached list queries.
+func (s *Service) lookbackStart() time.Time {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return today.AddDate(0, 0, -s.config.LookbackDays)
+}
+
 func (s *Service) fetchData(ctx context.Context, category string, subCategory string, emirate string, limit int, since time.Time) ([]*models.CostDataPoint, error) {
 	filter := repository.ListFilter{
-		Category: category,
-		Limit:    limit,
+		Category:   category,
+		Limit:      limit,
+		ActiveOnly: true,
 	}
 	if subCategory != "" {
 		filter.SubCategory = subCategory
@@ -8,7 +8,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/adonese/cost-of-living/internal/fx"
 	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository/cache"
 	mockrepo "github.com/adonese/cost-of-living/internal/repository/mock"
 )
 
@@ -26,18 +28,19 @@ func TestServiceEstimateWithLiveData(t *testing.T) {
 		RecordedAt:  now,
 		ValidFrom:   now,
 		Source:      "test_housing",
-		Unit:        "AED",
+		Currency:    "AED",
+		Period:      "year",
 		Confidence:  0.9,
 	}))
 
 	// Utilities
-	require.NoError(t, repo.Create(context.Background(), newUtilityPoint("Dubai", "Electricity", 0.38, now)))
-	require.NoError(t, repo.Create(context.Background(), newUtilityPoint("Dubai", "Water", 3.1, now)))
-	require.NoError(t, repo.Create(context.Background(), newUtilityPoint("Dubai", "Fuel Surcharge", 0.05, now)))
+	require.NoError(t, repo.Create(context.Background(), newUtilityPoint("Dubai", "Electricity", 0.38, "kWh", now)))
+	require.NoError(t, repo.Create(context.Background(), newUtilityPoint("Dubai", "Water", 3.1, "m3", now)))
+	require.NoError(t, repo.Create(context.Background(), newUtilityPoint("Dubai", "Fuel Surcharge", 0.05, "kWh", now)))
 
 	// Transport - public, taxi, ride sharing components
-	require.NoError(t, repo.Create(context.Background(), newTransportPoint("Public Transport", 4.0, now)))
-	require.NoError(t, repo.Create(context.Background(), newTransportPoint("Taxi", 2.6, now)))
+	require.NoError(t, repo.Create(context.Background(), newTransportPoint("Public Transport", 4.0, "trip", now)))
+	require.NoError(t, repo.Create(context.Background(), newTransportPoint("Taxi", 2.6, "km", now)))
 	require.NoError(t, repo.Create(context.Background(), &models.CostDataPoint{
 		ID:          "ride-base",
 		Category:    "Transportation",
@@ -46,7 +49,8 @@ func TestServiceEstimateWithLiveData(t *testing.T) {
 		RecordedAt:  now,
 		ValidFrom:   now,
 		Source:      "careem",
-		Unit:        "AED",
+		Currency:    "AED",
+		Unit:        "trip",
 		Confidence:  0.9,
 		Location:    models.Location{Emirate: "Dubai"},
 		Attributes: map[string]interface{}{
@@ -61,7 +65,8 @@ func TestServiceEstimateWithLiveData(t *testing.T) {
 		RecordedAt:  now,
 		ValidFrom:   now,
 		Source:      "careem",
-		Unit:        "AED",
+		Currency:    "AED",
+		Unit:        "km",
 		Confidence:  0.9,
 		Location:    models.Location{Emirate: "Dubai"},
 		Attributes: map[string]interface{}{
@@ -76,7 +81,8 @@ func TestServiceEstimateWithLiveData(t *testing.T) {
 		RecordedAt:  now,
 		ValidFrom:   now,
 		Source:      "careem",
-		Unit:        "AED",
+		Currency:    "AED",
+		Unit:        "trip",
 		Confidence:  0.9,
 		Location:    models.Location{Emirate: "Dubai"},
 		Attributes: map[string]interface{}{
@@ -116,6 +122,72 @@ func TestServiceEstimateWithLiveData(t *testing.T) {
 	assert.Contains(t, res.Dataset.Coverage, "Transportation")
 }
 
+func TestServiceEstimateIgnoresDelistedListings(t *testing.T) {
+	repo := mockrepo.NewCostDataPointRepository()
+	now := time.Now()
+	delistedAt := now.Add(-time.Hour)
+
+	listing := func(id string, price float64, validTo *time.Time) *models.CostDataPoint {
+		return &models.CostDataPoint{
+			ID:          id,
+			Category:    "Housing",
+			SubCategory: "Rent",
+			Price:       price,
+			Location:    models.Location{Emirate: "Dubai"},
+			RecordedAt:  now,
+			ValidFrom:   now.AddDate(0, 0, -30),
+			ValidTo:     validTo,
+			Source:      "bayut",
+			SourceURL:   "https://www.bayut.com/property/" + id,
+			Currency:    "AED",
+			Period:      "year",
+			Confidence:  0.9,
+		}
+	}
+
+	require.NoError(t, repo.Create(context.Background(), listing("live", 120000, nil)))
+	require.NoError(t, repo.Create(context.Background(), listing("gone", 600000, &delistedAt)))
+
+	svc := NewService(repo, nil)
+	res, err := svc.Estimate(context.Background(), PersonaInput{
+		Adults:      1,
+		Bedrooms:    2,
+		HousingType: HousingApartment,
+		Lifestyle:   LifestyleModerate,
+		Emirate:     "Dubai",
+	})
+	require.NoError(t, err)
+
+	housing := findCategory(res.Breakdown, "Housing")
+	require.NotNil(t, housing)
+	assert.Equal(t, 1, housing.SampleSize)
+	assert.InDelta(t, 10000, housing.MonthlyAED, 3000)
+}
+
+func TestServiceEstimateSharesCachedQueries(t *testing.T) {
+	repo := mockrepo.NewCostDataPointRepository()
+	svc := NewService(cache.NewCostDataPointRepository(repo, cache.Config{Size: 100, TTL: time.Minute}), nil)
+
+	persona := PersonaInput{
+		Adults:      1,
+		Bedrooms:    1,
+		HousingType: HousingApartment,
+		Lifestyle:   LifestyleModerate,
+		Emirate:     "Dubai",
+	}
+
+	_, err := svc.Estimate(context.Background(), persona)
+	require.NoError(t, err)
+	lists := repo.GetCallCount("List")
+	require.NotZero(t, lists)
+
+	// The same estimate moments later builds the same filters, so every
+	// query is served from the cache
+	_, err = svc.Estimate(context.Background(), persona)
+	require.NoError(t, err)
+	assert.Equal(t, lists, repo.GetCallCount("List"))
+}
+
 func TestServiceEstimateFallsBackWhenNoData(t *testing.T) {
 	repo := mockrepo.NewCostDataPointRepository()
 	svc := NewService(repo, nil)
@@ -139,6 +211,49 @@ func TestServiceEstimateFallsBackWhenNoData(t *testing.T) {
 	assert.NotEmpty(t, res.Dataset.Warnings)
 }
 
+func TestServiceEstimateInCurrency(t *testing.T) {
+	repo := mockrepo.NewCostDataPointRepository()
+	rates := fx.NewStore(fx.NewFileProvider(""), 0)
+	svc := NewService(repo, &Config{Rates: rates})
+
+	persona := PersonaInput{
+		Adults:        2,
+		Bedrooms:      2,
+		HousingType:   HousingApartment,
+		Lifestyle:     LifestyleModerate,
+		Emirate:       "Dubai",
+		TransportMode: TransportMixed,
+	}
+
+	aed, err := svc.Estimate(context.Background(), persona)
+	require.NoError(t, err)
+	assert.Equal(t, "AED", aed.Currency)
+	assert.Nil(t, aed.FXRate)
+	assert.Equal(t, aed.MonthlyTotalAED, aed.MonthlyTotal)
+
+	usd, err := svc.EstimateIn(context.Background(), persona, "usd")
+	require.NoError(t, err)
+	assert.Equal(t, "USD", usd.Currency)
+	require.NotNil(t, usd.FXRate)
+	assert.Equal(t, "AED", usd.FXRate.From)
+	assert.Equal(t, "USD", usd.FXRate.To)
+	assert.NotEmpty(t, usd.FXRate.Date)
+
+	assert.Equal(t, aed.MonthlyTotalAED, usd.MonthlyTotalAED)
+	assert.InDelta(t, usd.FXRate.Convert(usd.MonthlyTotalAED), usd.MonthlyTotal, 1)
+	for _, cat := range usd.Breakdown {
+		assert.InDelta(t, usd.FXRate.Convert(cat.MonthlyAED), cat.Monthly, 0.01, cat.Category)
+		assert.InDelta(t, usd.FXRate.Convert(cat.RangeHighAED), cat.RangeHigh, 0.01, cat.Category)
+	}
+
+	_, err = svc.EstimateIn(context.Background(), persona, "XYZ")
+	assert.ErrorIs(t, err, fx.ErrUnsupportedCurrency)
+
+	// Without a rate store only AED is offered
+	_, err = NewService(repo, nil).EstimateIn(context.Background(), persona, "USD")
+	assert.ErrorIs(t, err, fx.ErrUnsupportedCurrency)
+}
+
 func TestServiceSummary(t *testing.T) {
 	repo := mockrepo.NewCostDataPointRepository()
 	now := time.Now()
@@ -161,7 +276,39 @@ func TestServiceSummary(t *testing.T) {
 	assert.Contains(t, snap.Coverage, "Housing")
 }
 
-func newUtilityPoint(emirate, sub string, price float64, ts time.Time) *models.CostDataPoint {
+func TestServiceEstimateConvertsUnits(t *testing.T) {
+	now := time.Now()
+	persona := PersonaInput{
+		Adults:    2,
+		Bedrooms:  2,
+		Lifestyle: LifestyleModerate,
+		Emirate:   "Dubai",
+	}
+
+	utilities := func(water *models.CostDataPoint) float64 {
+		repo := mockrepo.NewCostDataPointRepository()
+		require.NoError(t, repo.Create(context.Background(), newUtilityPoint("Dubai", "Electricity", 0.38, "kWh", now)))
+		require.NoError(t, repo.Create(context.Background(), water))
+
+		res, err := NewService(repo, nil).Estimate(context.Background(), persona)
+		require.NoError(t, err)
+		estimate := findCategory(res.Breakdown, "Utilities")
+		require.NotNil(t, estimate)
+		return estimate.MonthlyAED
+	}
+
+	// A tariff per imperial gallon costs the same as its per-m3 equivalent
+	perM3 := utilities(newUtilityPoint("Dubai", "Water", 7.92, "m3", now))
+	perIG := utilities(newUtilityPoint("Dubai", "Water", 7.92*0.00454609, "IG", now))
+	assert.InDelta(t, perM3, perIG, 0.01)
+
+	// Legacy per-1000-gallon prices are normalised on write
+	legacy := newUtilityPoint("Dubai", "Water", 7.92*4.54609, "", now)
+	legacy.Currency, legacy.Unit = "", "AED per 1000 IG"
+	assert.InDelta(t, perM3, utilities(legacy), 0.01)
+}
+
+func newUtilityPoint(emirate, sub string, price float64, unit string, ts time.Time) *models.CostDataPoint {
 	return &models.CostDataPoint{
 		ID:          "util-" + sub,
 		Category:    "Utilities",
@@ -171,12 +318,13 @@ func newUtilityPoint(emirate, sub string, price float64, ts time.Time) *models.C
 		RecordedAt:  ts,
 		ValidFrom:   ts,
 		Source:      "utility",
-		Unit:        "AED",
+		Currency:    "AED",
+		Unit:        unit,
 		Confidence:  0.9,
 	}
 }
 
-func newTransportPoint(sub string, price float64, ts time.Time) *models.CostDataPoint {
+func newTransportPoint(sub string, price float64, unit string, ts time.Time) *models.CostDataPoint {
 	return &models.CostDataPoint{
 		ID:          "trans-" + sub,
 		Category:    "Transportation",
@@ -186,7 +334,8 @@ func newTransportPoint(sub string, price float64, ts time.Time) *models.CostData
 		RecordedAt:  ts,
 		ValidFrom:   ts,
 		Source:      "transport",
-		Unit:        "AED",
+		Currency:    "AED",
+		Unit:        unit,
 		Confidence:  0.85,
 	}
 }
@@ -0,0 +1,522 @@
+package estimator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/fx"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/units"
+)
+
+// TrendBucket is the width of the time buckets a trend is split into.
+type TrendBucket string
+
+const (
+	BucketDay   TrendBucket = "day"
+	BucketWeek  TrendBucket = "week"
+	BucketMonth TrendBucket = "month"
+)
+
+// TrendAggregate is the statistic each bucket's prices are reduced to.
+type TrendAggregate string
+
+const (
+	AggregateMedian TrendAggregate = "median"
+	AggregateMean   TrendAggregate = "mean"
+	AggregateP25    TrendAggregate = "p25"
+	AggregateP75    TrendAggregate = "p75"
+)
+
+// TrendDirection summarises how prices moved across a trend.
+type TrendDirection string
+
+const (
+	TrendUp     TrendDirection = "up"
+	TrendDown   TrendDirection = "down"
+	TrendStable TrendDirection = "stable"
+)
+
+// TrendQuery selects the prices a trend is built from. Category is
+// required; empty location fields are not constrained.
+type TrendQuery struct {
+	Category    string
+	SubCategory string
+	Emirate     string
+	Area        string
+
+	// Unit is what the prices are quoted per (see package units). It
+	// defaults by sub category and category (see TrendUnits), and prices
+	// in any other unit are left out so a series never mixes, say, per-kWh
+	// and per-gallon tariffs.
+	Unit string
+
+	// Bedrooms matches the bedrooms attribute of listings when set.
+	Bedrooms *int
+
+	// Bucket defaults to month and Aggregate to median.
+	Bucket    TrendBucket
+	Aggregate TrendAggregate
+
+	// Start and End bound recorded_at. End defaults to now and Start to
+	// the trend lookback of the bucket before End (see TrendLookback).
+	Start *time.Time
+	End   *time.Time
+}
+
+// TrendUnits is the unit a trend defaults to, looked up by sub category and
+// then by category. Anything else defaults to whole-item prices.
+var TrendUnits = map[string]string{
+	"Electricity":    units.KWh,
+	"Water":          units.ImperialGallon,
+	"Utilities":      units.KWh,
+	"Transportation": units.Trip,
+}
+
+// TrendLookback is the number of buckets a trend covers when the query
+// has no start.
+var TrendLookback = map[TrendBucket]int{
+	BucketDay:   30,
+	BucketWeek:  26,
+	BucketMonth: 12,
+}
+
+// TrendPoint is one bucket of a trend. Value is in AED per unit, and per
+// month for prices quoted per month or year; prices in other currencies are
+// converted with the configured rates.
+type TrendPoint struct {
+	Bucket     time.Time `json:"bucket"`
+	Value      float64   `json:"value"`
+	SampleSize int       `json:"sample_size"`
+}
+
+// TrendResult is a price series with a summary of its movement, after the
+// TrendSnapshot sketched in data_models.md. Buckets without prices are left
+// out of Series.
+type TrendResult struct {
+	Category    string         `json:"category"`
+	SubCategory string         `json:"sub_category,omitempty"`
+	Emirate     string         `json:"emirate,omitempty"`
+	Area        string         `json:"area,omitempty"`
+	Bedrooms    *int           `json:"bedrooms,omitempty"`
+	Unit        string         `json:"unit"`
+	Bucket      TrendBucket    `json:"bucket"`
+	Aggregate   TrendAggregate `json:"aggregate"`
+	Currency    string         `json:"currency"`
+	Start       time.Time      `json:"start"`
+	End         time.Time      `json:"end"`
+	Series      []TrendPoint   `json:"series"`
+	SampleSize  int            `json:"sample_size"`
+
+	// Excluded counts prices left out of Series because they could not be
+	// converted to AED per month, such as a currency without a rate.
+	Excluded int `json:"excluded,omitempty"`
+
+	// Approximate is set when percentile values were combined from several
+	// rollups, as the sample-weighted mean of their percentiles rather than
+	// the percentile of the prices themselves.
+	Approximate bool `json:"approximate"`
+
+	// PercentChange is the change from the first to the last bucket.
+	PercentChange float64 `json:"percent_change"`
+	// Direction is stable when PercentChange is within the configured
+	// TrendStablePercent either way, or there are fewer than two buckets.
+	Direction TrendDirection `json:"direction"`
+	// Strength is how closely the series follows a straight line (R²),
+	// from 0 for noise to 1; it is 0 for fewer than three buckets.
+	Strength float32 `json:"strength"`
+
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+// Trend buckets the prices matching the query and summarises their
+// movement. Invalid queries return repository.ErrValidationFailed errors.
+//
+// With Config.Rollups set, trends read the price rollups instead of every
+// raw data point, except for bedrooms queries: the rollups are not grouped
+// by attribute.
+func (s *Service) Trend(ctx context.Context, query TrendQuery) (*TrendResult, error) {
+	query, err := query.normalize(time.Now())
+	if err != nil {
+		return nil, repository.ValidationFailed(err)
+	}
+
+	var data *trendData
+	if s.config.Rollups != nil && query.Bedrooms == nil {
+		data, err = s.trendFromRollups(ctx, query)
+	} else {
+		data, err = s.trendFromPoints(ctx, query)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &TrendResult{
+		Category:    query.Category,
+		SubCategory: query.SubCategory,
+		Emirate:     query.Emirate,
+		Area:        query.Area,
+		Bedrooms:    query.Bedrooms,
+		Unit:        query.Unit,
+		Bucket:      query.Bucket,
+		Aggregate:   query.Aggregate,
+		Currency:    fx.BaseCurrency,
+		Start:       *query.Start,
+		End:         *query.End,
+		Series:      data.series,
+		Excluded:    data.excluded,
+		Approximate: data.approximate,
+		Direction:   TrendStable,
+		GeneratedAt: time.Now(),
+	}
+	for _, point := range res.Series {
+		res.SampleSize += point.SampleSize
+	}
+	sort.Slice(res.Series, func(i, j int) bool {
+		return res.Series[i].Bucket.Before(res.Series[j].Bucket)
+	})
+
+	if n := len(res.Series); n >= 2 && res.Series[0].Value > 0 {
+		first, last := res.Series[0].Value, res.Series[n-1].Value
+		res.PercentChange = math.Round((last-first)/first*10000) / 100
+		switch {
+		case res.PercentChange >= s.config.TrendStablePercent:
+			res.Direction = TrendUp
+		case res.PercentChange <= -s.config.TrendStablePercent:
+			res.Direction = TrendDown
+		}
+	}
+	res.Strength = float32(math.Round(trendStrength(res.Series)*100) / 100)
+
+	return res, nil
+}
+
+// trendData is a series before it is summarised, with how many prices were
+// left out of it and whether its percentiles are approximate.
+type trendData struct {
+	series      []TrendPoint
+	excluded    int
+	approximate bool
+}
+
+// trendFromPoints builds the series from the raw data points, reducing each
+// bucket's prices to the aggregate exactly.
+func (s *Service) trendFromPoints(ctx context.Context, query TrendQuery) (*trendData, error) {
+	filter := repository.ListFilter{
+		Category:       query.Category,
+		SubCategory:    query.SubCategory,
+		Emirate:        query.Emirate,
+		Area:           query.Area,
+		StartDate:      query.Start,
+		EndDate:        query.End,
+		OrderBy:        "recorded_at",
+		OrderDirection: repository.SortAsc,
+	}
+	if query.Bedrooms != nil {
+		filter.Attributes = map[string]string{"bedrooms": strconv.Itoa(*query.Bedrooms)}
+	}
+
+	convert := s.monthlyAED(ctx)
+	buckets := map[time.Time][]float64{}
+	excluded := 0
+	err := s.repo.Stream(ctx, filter, func(dp *models.CostDataPoint) error {
+		if dp.Unit != query.Unit {
+			return nil
+		}
+		v, ok := convert(dp.Price, dp.Currency, dp.Period)
+		if !ok {
+			excluded++
+			return nil
+		}
+		bucket := query.Bucket.truncate(dp.RecordedAt)
+		buckets[bucket] = append(buckets[bucket], v)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("stream %s data: %w", query.Category, err)
+	}
+
+	data := &trendData{series: make([]TrendPoint, 0, len(buckets)), excluded: excluded}
+	for bucket, values := range buckets {
+		sort.Float64s(values)
+		data.series = append(data.series, TrendPoint{
+			Bucket:     bucket,
+			Value:      roundTrendValue(query.Aggregate.apply(values), query.Unit),
+			SampleSize: len(values),
+		})
+	}
+	return data, nil
+}
+
+// trendFromRollups builds the series from the price rollups. Day and week
+// buckets read the rollup of the same width and months combine daily ones.
+// A bucket made of several rollups, for different days or groups, takes
+// their sample-weighted mean: exact for the mean aggregate and an
+// approximation for the percentiles, which marks the series approximate.
+func (s *Service) trendFromRollups(ctx context.Context, query TrendQuery) (*trendData, error) {
+	interval, width := models.RollupDaily, BucketDay
+	if query.Bucket == BucketWeek {
+		interval, width = models.RollupWeekly, BucketWeek
+	}
+	start := width.truncate(*query.Start)
+
+	rollups, err := s.config.Rollups.ListRollups(ctx, repository.RollupFilter{
+		Interval:    interval,
+		Category:    query.Category,
+		SubCategory: query.SubCategory,
+		Emirate:     query.Emirate,
+		Area:        query.Area,
+		Unit:        query.Unit,
+		Start:       &start,
+		End:         query.End,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list %s rollups: %w", query.Category, err)
+	}
+
+	type weighted struct {
+		sum     float64
+		count   int64
+		rollups int
+	}
+	convert := s.monthlyAED(ctx)
+	buckets := map[time.Time]*weighted{}
+	excluded := 0
+	for _, rollup := range rollups {
+		// An empty unit filter matches every unit
+		if rollup.Unit != query.Unit {
+			continue
+		}
+		v, ok := convert(query.Aggregate.fromRollup(rollup), rollup.Currency, rollup.Period)
+		if !ok {
+			excluded += int(rollup.Count)
+			continue
+		}
+		bucket := query.Bucket.truncate(rollup.Bucket)
+		b, ok := buckets[bucket]
+		if !ok {
+			b = &weighted{}
+			buckets[bucket] = b
+		}
+		b.sum += v * float64(rollup.Count)
+		b.count += rollup.Count
+		b.rollups++
+	}
+
+	data := &trendData{series: make([]TrendPoint, 0, len(buckets)), excluded: excluded}
+	for bucket, b := range buckets {
+		if b.rollups > 1 && query.Aggregate != AggregateMean {
+			data.approximate = true
+		}
+		data.series = append(data.series, TrendPoint{
+			Bucket:     bucket,
+			Value:      roundTrendValue(b.sum/float64(b.count), query.Unit),
+			SampleSize: int(b.count),
+		})
+	}
+	return data, nil
+}
+
+// roundTrendValue rounds whole-item prices to the fils. Prices per unit,
+// such as 0.0021 AED per gallon of water, keep six decimal places.
+func roundTrendValue(v float64, unit string) float64 {
+	if unit == "" {
+		return roundCurrency(v)
+	}
+	return math.Round(v*1e6) / 1e6
+}
+
+// monthlyAED returns a function converting a price in currency, charged
+// every period, to AED per month with the configured rates. It reports false
+// for prices that are not positive, not money, or in a currency or period it
+// cannot convert. Rates are looked up once per currency.
+func (s *Service) monthlyAED(ctx context.Context) func(price float64, currency, period string) (float64, bool) {
+	rates := map[string]*fx.Rate{}
+	return func(price float64, currency, period string) (float64, bool) {
+		if price <= 0 {
+			return 0, false
+		}
+		monthly, err := units.Monthly(price, period)
+		if err != nil {
+			return 0, false
+		}
+
+		code := fx.NormalizeCode(currency)
+		if code == fx.BaseCurrency {
+			return monthly, true
+		}
+		rate, seen := rates[code]
+		if !seen {
+			if code != "" && s.config.Rates != nil {
+				if r, err := s.config.Rates.Rate(ctx, code, fx.BaseCurrency); err == nil {
+					rate = &r
+				}
+			}
+			rates[code] = rate
+		}
+		if rate == nil {
+			return 0, false
+		}
+		return rate.Convert(monthly), true
+	}
+}
+
+// normalize applies the query defaults and checks its options.
+func (q TrendQuery) normalize(now time.Time) (TrendQuery, error) {
+	q.Category = strings.TrimSpace(q.Category)
+	q.SubCategory = strings.TrimSpace(q.SubCategory)
+	q.Emirate = strings.TrimSpace(q.Emirate)
+	q.Area = strings.TrimSpace(q.Area)
+	q.Unit = strings.TrimSpace(q.Unit)
+	if q.Unit == "" {
+		q.Unit = defaultTrendUnit(q.Category, q.SubCategory)
+	}
+	q.Bucket = TrendBucket(strings.ToLower(string(q.Bucket)))
+	q.Aggregate = TrendAggregate(strings.ToLower(string(q.Aggregate)))
+	if q.Bucket == "" {
+		q.Bucket = BucketMonth
+	}
+	if q.Aggregate == "" {
+		q.Aggregate = AggregateMedian
+	}
+
+	var problems []string
+	if q.Category == "" {
+		problems = append(problems, "category is required")
+	}
+	if _, ok := TrendLookback[q.Bucket]; !ok {
+		problems = append(problems, fmt.Sprintf("unsupported bucket %q", q.Bucket))
+	}
+	switch q.Aggregate {
+	case AggregateMedian, AggregateMean, AggregateP25, AggregateP75:
+	default:
+		problems = append(problems, fmt.Sprintf("unsupported aggregate %q", q.Aggregate))
+	}
+	if !units.ValidUnit(q.Unit) {
+		problems = append(problems, fmt.Sprintf("unsupported unit %q", q.Unit))
+	}
+	if q.Bedrooms != nil && *q.Bedrooms < 0 {
+		problems = append(problems, "bedrooms cannot be negative")
+	}
+	if len(problems) > 0 {
+		return q, errors.New(strings.Join(problems, "; "))
+	}
+
+	if q.End == nil {
+		q.End = &now
+	}
+	if q.Start == nil {
+		start := q.Bucket.add(q.Bucket.truncate(*q.End), 1-TrendLookback[q.Bucket])
+		q.Start = &start
+	}
+	if q.Start.After(*q.End) {
+		return q, fmt.Errorf("start %s is after end %s", q.Start.Format(time.RFC3339), q.End.Format(time.RFC3339))
+	}
+	return q, nil
+}
+
+// defaultTrendUnit returns the unit a trend of the category and sub
+// category is quoted in when the query does not say.
+func defaultTrendUnit(category, subCategory string) string {
+	if unit, ok := TrendUnits[subCategory]; ok {
+		return unit
+	}
+	return TrendUnits[category]
+}
+
+// truncate returns the start of the bucket holding t, in UTC. Weeks start
+// on Monday, as TimescaleDB's time_bucket aligns them.
+func (b TrendBucket) truncate(t time.Time) time.Time {
+	t = t.UTC()
+	year, month, day := t.Date()
+	switch b {
+	case BucketMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	case BucketWeek:
+		sinceMonday := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-sinceMonday, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+// add moves a bucket start n buckets forward, or back when n is negative.
+func (b TrendBucket) add(start time.Time, n int) time.Time {
+	switch b {
+	case BucketMonth:
+		return start.AddDate(0, n, 0)
+	case BucketWeek:
+		return start.AddDate(0, 0, 7*n)
+	default:
+		return start.AddDate(0, 0, n)
+	}
+}
+
+// fromRollup returns the aggregate as carried by a rollup.
+func (a TrendAggregate) fromRollup(r *models.PriceRollup) float64 {
+	switch a {
+	case AggregateMean:
+		return r.AvgPrice
+	case AggregateP25:
+		return r.P25Price
+	case AggregateP75:
+		return r.P75Price
+	default:
+		return r.MedianPrice
+	}
+}
+
+// apply reduces sorted values to the aggregate.
+func (a TrendAggregate) apply(sorted []float64) float64 {
+	switch a {
+	case AggregateMean:
+		var sum float64
+		for _, v := range sorted {
+			sum += v
+		}
+		return sum / float64(len(sorted))
+	case AggregateP25:
+		return percentile(sorted, 25)
+	case AggregateP75:
+		return percentile(sorted, 75)
+	default:
+		return percentile(sorted, 50)
+	}
+}
+
+// trendStrength returns the coefficient of determination of a least squares
+// line through the series, with time as x so gaps between buckets count.
+func trendStrength(series []TrendPoint) float64 {
+	n := len(series)
+	if n < 3 {
+		return 0
+	}
+
+	origin := series[0].Bucket
+	var sumX, sumY float64
+	for _, p := range series {
+		sumX += p.Bucket.Sub(origin).Hours()
+		sumY += p.Value
+	}
+	meanX, meanY := sumX/float64(n), sumY/float64(n)
+
+	var sxx, sxy, syy float64
+	for _, p := range series {
+		dx := p.Bucket.Sub(origin).Hours() - meanX
+		dy := p.Value - meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0
+	}
+	return sxy * sxy / (sxx * syy)
+}
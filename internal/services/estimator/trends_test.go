@@ -0,0 +1,273 @@
+package estimator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adonese/cost-of-living/internal/fx"
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	mockrepo "github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/internal/units"
+)
+
+func TestServiceTrend(t *testing.T) {
+	repo := mockrepo.NewCostDataPointRepository()
+	end := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+
+	// Yearly 2BR rents in Dubai Marina rising 1,200 AED a year each month,
+	// two listings per month, plus listings the query must ignore
+	for month := 0; month < 6; month++ {
+		recorded := time.Date(2025, time.January+time.Month(month), 10, 0, 0, 0, 0, time.UTC)
+		for i, price := range []float64{120000, 132000} {
+			require.NoError(t, repo.Create(context.Background(), newRentPoint(
+				fmt.Sprintf("marina-%d-%d", month, i), "Dubai Marina", "2", price+float64(month)*1200, recorded)))
+		}
+	}
+	require.NoError(t, repo.Create(context.Background(), newRentPoint("marina-studio", "Dubai Marina", "0", 40000, end.AddDate(0, -1, 0))))
+	require.NoError(t, repo.Create(context.Background(), newRentPoint("jvc", "JVC", "2", 80000, end.AddDate(0, -1, 0))))
+	require.NoError(t, repo.Create(context.Background(), newRentPoint("old", "Dubai Marina", "2", 1, end.AddDate(-2, 0, 0))))
+
+	svc := NewService(repo, nil)
+	bedrooms := 2
+	res, err := svc.Trend(context.Background(), TrendQuery{
+		Category:    "Housing",
+		SubCategory: "Rent",
+		Emirate:     "Dubai",
+		Area:        "Dubai Marina",
+		Bedrooms:    &bedrooms,
+		End:         &end,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, BucketMonth, res.Bucket)
+	assert.Equal(t, AggregateMedian, res.Aggregate)
+	assert.Equal(t, "AED", res.Currency)
+	assert.Equal(t, time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC), res.Start, "12 monthly buckets up to end")
+
+	require.Len(t, res.Series, 6)
+	assert.Equal(t, 12, res.SampleSize)
+	assert.Equal(t, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), res.Series[0].Bucket)
+	assert.Equal(t, 10500.0, res.Series[0].Value, "median of 10,000 and 11,000 a month")
+	assert.Equal(t, 2, res.Series[0].SampleSize)
+	assert.Equal(t, 11000.0, res.Series[5].Value)
+
+	assert.Equal(t, 4.76, res.PercentChange)
+	assert.Equal(t, TrendUp, res.Direction)
+	assert.Equal(t, float32(1), res.Strength, "a steady rise fits a line")
+
+	p25, err := svc.Trend(context.Background(), TrendQuery{
+		Category: "Housing", Area: "Dubai Marina", Bedrooms: &bedrooms, End: &end, Aggregate: "P25",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 10250.0, p25.Series[0].Value)
+}
+
+func TestServiceTrendBuckets(t *testing.T) {
+	repo := mockrepo.NewCostDataPointRepository()
+	// Wednesday and the following Sunday share a week; Monday starts the next
+	wednesday := time.Date(2025, 6, 4, 9, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.Create(context.Background(), newRentPoint("a", "JVC", "1", 60000, wednesday)))
+	require.NoError(t, repo.Create(context.Background(), newRentPoint("b", "JVC", "1", 72000, wednesday.AddDate(0, 0, 4))))
+	require.NoError(t, repo.Create(context.Background(), newRentPoint("c", "JVC", "1", 60600, wednesday.AddDate(0, 0, 5))))
+
+	end := wednesday.AddDate(0, 0, 10)
+	res, err := NewService(repo, nil).Trend(context.Background(), TrendQuery{
+		Category: "Housing", Bucket: BucketWeek, Aggregate: AggregateMean, End: &end,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, res.Series, 2)
+	assert.Equal(t, time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), res.Series[0].Bucket)
+	assert.Equal(t, 5500.0, res.Series[0].Value)
+	assert.Equal(t, time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC), res.Series[1].Bucket)
+	assert.Equal(t, 5050.0, res.Series[1].Value)
+	assert.Equal(t, -8.18, res.PercentChange)
+	assert.Equal(t, TrendDown, res.Direction)
+	assert.Zero(t, res.Strength, "two buckets are too few to judge strength")
+
+	res, err = NewService(repo, &Config{TrendStablePercent: 10}).Trend(context.Background(), TrendQuery{
+		Category: "Housing", Bucket: BucketWeek, Aggregate: AggregateMean, End: &end,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, TrendStable, res.Direction)
+
+	res, err = NewService(repo, nil).Trend(context.Background(), TrendQuery{Category: "Groceries"})
+	require.NoError(t, err)
+	assert.Empty(t, res.Series)
+	assert.Equal(t, TrendStable, res.Direction)
+}
+
+func TestServiceTrendFromRollups(t *testing.T) {
+	repo := mockrepo.NewCostDataPointRepository()
+	rollups := mockrepo.NewPriceRollupRepository(repo)
+	end := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+
+	// Two days in May, one with two listings, and one day in June
+	may := time.Date(2025, 5, 5, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, repo.Create(context.Background(), newRentPoint("a", "JVC", "1", 60000, may)))
+	require.NoError(t, repo.Create(context.Background(), newRentPoint("b", "JVC", "2", 72000, may)))
+	require.NoError(t, repo.Create(context.Background(), newRentPoint("c", "JVC", "1", 66000, may.AddDate(0, 0, 1))))
+	require.NoError(t, repo.Create(context.Background(), newRentPoint("d", "JVC", "1", 66000, may.AddDate(0, 1, 0))))
+
+	svc := NewService(repo, &Config{Rollups: rollups})
+	res, err := svc.Trend(context.Background(), TrendQuery{Category: "Housing", Area: "JVC", Aggregate: AggregateMean, End: &end})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, rollups.GetCallCount("ListRollups"))
+	assert.Zero(t, repo.GetCallCount("Stream"), "raw points are not read")
+	require.Len(t, res.Series, 2)
+	assert.Equal(t, time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC), res.Series[0].Bucket)
+	assert.Equal(t, 5500.0, res.Series[0].Value, "mean of 5,000, 6,000 and 5,500 a month")
+	assert.Equal(t, 3, res.Series[0].SampleSize)
+	assert.Equal(t, 5500.0, res.Series[1].Value)
+	assert.Equal(t, 4, res.SampleSize)
+	assert.False(t, res.Approximate, "the weighted mean of means is exact")
+
+	// May's median combines two days of medians
+	res, err = svc.Trend(context.Background(), TrendQuery{Category: "Housing", Area: "JVC", End: &end})
+	require.NoError(t, err)
+	assert.True(t, res.Approximate)
+	assert.Equal(t, 5500.0, res.Series[0].Value, "5,500 and 5,500 weighted 2:1")
+
+	// Bedrooms is an attribute the rollups do not group by
+	bedrooms := 1
+	res, err = svc.Trend(context.Background(), TrendQuery{Category: "Housing", Area: "JVC", Bedrooms: &bedrooms, Aggregate: AggregateMean, End: &end})
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.GetCallCount("Stream"))
+	assert.Equal(t, 5250.0, res.Series[0].Value)
+	assert.False(t, res.Approximate)
+}
+
+func TestServiceTrendUnits(t *testing.T) {
+	repo := mockrepo.NewCostDataPointRepository()
+	end := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	recorded := end.AddDate(0, 0, -5)
+
+	tariff := func(id, subCategory, unit string, price float64) *models.CostDataPoint {
+		return &models.CostDataPoint{
+			ID:          id,
+			Category:    "Utilities",
+			SubCategory: subCategory,
+			Price:       price,
+			Unit:        unit,
+			Location:    models.Location{Emirate: "Dubai"},
+			RecordedAt:  recorded,
+			ValidFrom:   recorded,
+			Source:      "dewa",
+			Currency:    "AED",
+			Confidence:  1,
+		}
+	}
+	require.NoError(t, repo.Create(context.Background(), tariff("kwh-1", "Electricity", units.KWh, 0.23)))
+	require.NoError(t, repo.Create(context.Background(), tariff("kwh-2", "Electricity", units.KWh, 0.28)))
+	require.NoError(t, repo.Create(context.Background(), tariff("ig", "Water", units.ImperialGallon, 0.0357)))
+	require.NoError(t, repo.Create(context.Background(), tariff("fee", "Water", "", 30)))
+
+	for name, cfg := range map[string]*Config{
+		"points":  nil,
+		"rollups": {Rollups: mockrepo.NewPriceRollupRepository(repo)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			svc := NewService(repo, cfg)
+
+			res, err := svc.Trend(context.Background(), TrendQuery{Category: "Utilities", Aggregate: AggregateMean, End: &end})
+			require.NoError(t, err)
+			assert.Equal(t, units.KWh, res.Unit, "utilities default to electricity tariffs")
+			assert.Equal(t, 2, res.SampleSize)
+			assert.Zero(t, res.Excluded, "prices in other units are not counted as excluded")
+			assert.Equal(t, 0.255, res.Series[0].Value)
+
+			res, err = svc.Trend(context.Background(), TrendQuery{Category: "Utilities", SubCategory: "Water", End: &end})
+			require.NoError(t, err)
+			assert.Equal(t, units.ImperialGallon, res.Unit)
+			assert.Equal(t, 1, res.SampleSize)
+			assert.Equal(t, 0.0357, res.Series[0].Value, "per-unit prices keep their fils fractions")
+
+			res, err = svc.Trend(context.Background(), TrendQuery{Category: "Utilities", Unit: units.ImperialGallon, End: &end})
+			require.NoError(t, err)
+			assert.Equal(t, 1, res.SampleSize)
+			assert.Equal(t, 0.0357, res.Series[0].Value)
+		})
+	}
+}
+
+func TestServiceTrendCurrencies(t *testing.T) {
+	repo := mockrepo.NewCostDataPointRepository()
+	end := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	recorded := end.AddDate(0, 0, -5)
+
+	aed := newRentPoint("aed", "JVC", "1", 60000, recorded)
+	usd := newRentPoint("usd", "JVC", "1", 12000, recorded)
+	usd.Currency = "USD"
+	require.NoError(t, repo.Create(context.Background(), aed))
+	require.NoError(t, repo.Create(context.Background(), usd))
+
+	query := TrendQuery{Category: "Housing", Aggregate: AggregateMean, End: &end}
+
+	// Without rates the USD listing cannot be compared, and says so
+	res, err := NewService(repo, nil).Trend(context.Background(), query)
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.SampleSize)
+	assert.Equal(t, 1, res.Excluded)
+	assert.Equal(t, 5000.0, res.Series[0].Value)
+
+	rates := fx.NewStore(fx.NewFileProvider(""), 0)
+	rate, err := rates.Rate(context.Background(), "USD", "AED")
+	require.NoError(t, err)
+
+	for name, cfg := range map[string]*Config{
+		"points":  {Rates: rates},
+		"rollups": {Rates: rates, Rollups: mockrepo.NewPriceRollupRepository(repo)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			res, err := NewService(repo, cfg).Trend(context.Background(), query)
+			require.NoError(t, err)
+			assert.Equal(t, "AED", res.Currency)
+			assert.Equal(t, 2, res.SampleSize)
+			assert.Zero(t, res.Excluded)
+			assert.InDelta(t, (5000+rate.Convert(1000))/2, res.Series[0].Value, 0.01)
+		})
+	}
+}
+
+func TestServiceTrendValidation(t *testing.T) {
+	svc := NewService(mockrepo.NewCostDataPointRepository(), nil)
+	negative := -1
+	start := time.Now()
+	end := start.Add(-time.Hour)
+
+	for _, query := range []TrendQuery{
+		{},
+		{Category: "Housing", Bucket: "year"},
+		{Category: "Housing", Aggregate: "max"},
+		{Category: "Housing", Bedrooms: &negative},
+		{Category: "Housing", Unit: "litre"},
+		{Category: "Housing", Start: &start, End: &end},
+	} {
+		_, err := svc.Trend(context.Background(), query)
+		assert.ErrorIs(t, err, repository.ErrValidationFailed, "%+v", query)
+	}
+}
+
+func newRentPoint(id, area, bedrooms string, yearly float64, ts time.Time) *models.CostDataPoint {
+	return &models.CostDataPoint{
+		ID:          id,
+		Category:    "Housing",
+		SubCategory: "Rent",
+		Price:       yearly,
+		Location:    models.Location{Emirate: "Dubai", Area: area},
+		RecordedAt:  ts,
+		ValidFrom:   ts,
+		Source:      "bayut",
+		Currency:    "AED",
+		Period:      "year",
+		Confidence:  0.9,
+		Attributes:  map[string]interface{}{"bedrooms": bedrooms},
+	}
+}
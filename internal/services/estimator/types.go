@@ -4,6 +4,10 @@ import (
 	"fmt"
 	"strings"
 	"time"
+
+	"github.com/adonese/cost-of-living/internal/fx"
+	"github.com/adonese/cost-of-living/internal/gazetteer"
+	"github.com/adonese/cost-of-living/internal/repository"
 )
 
 // Lifestyle represents the qualitative spending style supplied by the user.
@@ -128,11 +132,15 @@ func isValidTransportMode(tm TransportMode) bool {
 }
 
 // CategoryEstimate represents one budget slice returned to clients.
+// Monthly, RangeLow and RangeHigh are in the result currency.
 type CategoryEstimate struct {
 	Category     string    `json:"category"`
 	MonthlyAED   float64   `json:"monthly_aed"`
 	RangeLowAED  float64   `json:"range_low_aed"`
 	RangeHighAED float64   `json:"range_high_aed"`
+	Monthly      float64   `json:"monthly"`
+	RangeLow     float64   `json:"range_low"`
+	RangeHigh    float64   `json:"range_high"`
 	SampleSize   int       `json:"sample_size"`
 	Sources      []string  `json:"sources"`
 	Confidence   float32   `json:"confidence"`
@@ -151,10 +159,14 @@ type DatasetSnapshot struct {
 }
 
 // EstimateResult is the response returned by the estimator service/API.
+// MonthlyTotal is in Currency; FXRate records the conversion from AED when
+// Currency is not AED.
 type EstimateResult struct {
 	Persona         PersonaInput       `json:"persona"`
 	Currency        string             `json:"currency"`
 	MonthlyTotalAED float64            `json:"monthly_total_aed"`
+	MonthlyTotal    float64            `json:"monthly_total"`
+	FXRate          *fx.Rate           `json:"fx_rate,omitempty"`
 	Breakdown       []CategoryEstimate `json:"breakdown"`
 	Recommendations []string           `json:"recommendations"`
 	Dataset         DatasetSnapshot    `json:"dataset"`
@@ -171,6 +183,20 @@ type Config struct {
 	LifestyleMultipliers   map[Lifestyle]float64
 	HousingTypeMultipliers map[HousingType]float64
 	BedroomStepPercent     float64
+
+	// TrendStablePercent is the change a trend must exceed, either way, to
+	// be reported as up or down rather than stable.
+	TrendStablePercent float64
+
+	// Rates converts estimates out of AED. Without it only AED is offered.
+	Rates *fx.Store
+
+	// Rollups serves trends from the pre-aggregated price statistics.
+	// Without it trends read every raw data point in range.
+	Rollups repository.PriceRollupRepository
+
+	// Gazetteer places areas for comparisons; defaults to the embedded one.
+	Gazetteer *gazetteer.Gazetteer
 }
 
 // DefaultConfig wires pragmatic defaults.
@@ -192,5 +218,7 @@ func DefaultConfig() Config {
 			HousingShared:    0.45,
 		},
 		BedroomStepPercent: 0.12, // each bedroom beyond 1 adds 12%
+		TrendStablePercent: 2,
+		Gazetteer:          gazetteer.Default(),
 	}
 }
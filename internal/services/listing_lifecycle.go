@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/pkg/logger"
+	"github.com/adonese/cost-of-living/pkg/metrics"
+)
+
+// DefaultMinListingsPerScope is the smallest number of listings a scrape must
+// return for a scope before listings missing from it are treated as delisted.
+// It stops a blocked or truncated scrape from closing an entire market.
+const DefaultMinListingsPerScope = 5
+
+// ListingLifecycle closes listings that disappear from the scrape of their
+// scope (source, category, sub-category and emirate). A listing's first and
+// last seen times are maintained by the repository's Upsert; this only decides
+// which listings are no longer live.
+type ListingLifecycle struct {
+	repo        repository.CostDataPointRepository
+	minPerScope int
+	now         func() time.Time
+}
+
+// NewListingLifecycle creates a lifecycle tracker. minPerScope <= 0 uses
+// DefaultMinListingsPerScope.
+func NewListingLifecycle(repo repository.CostDataPointRepository, minPerScope int) *ListingLifecycle {
+	if minPerScope <= 0 {
+		minPerScope = DefaultMinListingsPerScope
+	}
+	return &ListingLifecycle{
+		repo:        repo,
+		minPerScope: minPerScope,
+		now:         time.Now,
+	}
+}
+
+// CloseUnseen closes every active listing in the scopes covered by seen whose
+// last_seen_at is before seenAt, the time the seen listings were stamped with,
+// and returns how many were closed. Scopes with fewer than the configured
+// minimum listings are skipped.
+func (l *ListingLifecycle) CloseUnseen(ctx context.Context, seen []*models.CostDataPoint, seenAt time.Time) (int64, error) {
+	counts := make(map[repository.ListingScope]int)
+	for _, dp := range seen {
+		if dp == nil || !dp.IsListing() {
+			continue
+		}
+		counts[listingScopeOf(dp)]++
+	}
+
+	var closed int64
+	var errs []error
+	now := l.now()
+
+	for scope, count := range counts {
+		if count < l.minPerScope {
+			logger.Warn("Skipping delisting for sparse scrape",
+				"source", scope.Source,
+				"category", scope.Category,
+				"sub_category", scope.SubCategory,
+				"emirate", scope.Emirate,
+				"listings", count,
+				"minimum", l.minPerScope)
+			continue
+		}
+
+		n, err := l.repo.CloseUnseenListings(ctx, scope, seenAt, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("close unseen %s/%s listings in %s: %w", scope.Source, scope.SubCategory, scope.Emirate, err))
+			continue
+		}
+
+		closed += n
+		metrics.ListingsDelistedTotal.WithLabelValues(scope.Source).Add(float64(n))
+		logger.Info("Closed unseen listings",
+			"source", scope.Source,
+			"category", scope.Category,
+			"sub_category", scope.SubCategory,
+			"emirate", scope.Emirate,
+			"seen", count,
+			"closed", n)
+	}
+
+	return closed, errors.Join(errs...)
+}
+
+// listingScopeOf returns the scope a listing was scraped in
+func listingScopeOf(dp *models.CostDataPoint) repository.ListingScope {
+	return repository.ListingScope{
+		Source:      dp.Source,
+		Category:    dp.Category,
+		SubCategory: dp.SubCategory,
+		Emirate:     dp.Location.Emirate,
+	}
+}
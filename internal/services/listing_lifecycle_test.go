@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/internal/scrapers"
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+func newTestListing(n int, emirate string) *models.CostDataPoint {
+	dp := newTestPoint(fmt.Sprintf("listing-%d", n))
+	dp.Category = "Housing"
+	dp.SubCategory = "Rent"
+	dp.Source = "bayut"
+	dp.SourceURL = fmt.Sprintf("https://www.bayut.com/property/details-%d.html", n)
+	dp.Location = models.Location{Emirate: emirate}
+	return dp
+}
+
+func TestScraperServiceClosesUnseenListings(t *testing.T) {
+	logger.Init()
+
+	repo := mock.NewCostDataPointRepository()
+	config := &ScraperServiceConfig{TrackListings: true, MinListingsToClose: 2}
+	service := NewScraperServiceWithConfig(repo, config)
+
+	scraper := &stubScraper{name: "bayut", canScrape: true}
+	service.RegisterScraper(scraper)
+
+	scraper.points = []*models.CostDataPoint{newTestListing(1, "Dubai"), newTestListing(2, "Dubai"), newTestListing(3, "Dubai")}
+	first, err := service.RunScraper(context.Background(), "bayut")
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, first.Delisted)
+
+	// Listing 3 has been taken down; a tariff in another category is untouched
+	tariff := newTestPoint("Electricity Slab 1")
+	tariff.RecordedAt = time.Now().Add(-time.Hour)
+	require.NoError(t, repo.Create(context.Background(), tariff))
+
+	scraper.points = []*models.CostDataPoint{newTestListing(1, "Dubai"), newTestListing(2, "Dubai")}
+	second, err := service.RunScraper(context.Background(), "bayut")
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, second.Delisted)
+	assert.Equal(t, 2, second.Refreshed)
+
+	active, err := repo.List(context.Background(), repository.ListFilter{ActiveOnly: true})
+	require.NoError(t, err)
+	assert.Len(t, active, 3)
+	for _, dp := range active {
+		assert.NotEqual(t, "listing-3", dp.ItemName)
+	}
+}
+
+func TestScraperServiceKeepsListingsAfterIncompleteCrawl(t *testing.T) {
+	logger.Init()
+
+	listings := func(n int) []*models.CostDataPoint {
+		points := make([]*models.CostDataPoint, n)
+		for i := range points {
+			points[i] = newTestListing(i+1, "Dubai")
+		}
+		return points
+	}
+
+	for name, scraper := range map[string]func(*stubScraper) scrapers.Scraper{
+		"truncated crawl": func(s *stubScraper) scrapers.Scraper {
+			s.incomplete = true
+			return s
+		},
+		// Hides LastCrawlComplete, as scrapers that cannot tell do
+		"unreported crawl": func(s *stubScraper) scrapers.Scraper {
+			return struct{ scrapers.Scraper }{s}
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			repo := mock.NewCostDataPointRepository()
+			service := NewScraperServiceWithConfig(repo, &ScraperServiceConfig{TrackListings: true, MinListingsToClose: 2})
+
+			stub := &stubScraper{name: "bayut", canScrape: true, points: listings(4)}
+			service.RegisterScraper(scraper(stub))
+			_, err := service.RunScraper(context.Background(), "bayut")
+			require.NoError(t, err)
+
+			// The next crawl stops after the first page of two listings
+			stub.points = listings(2)
+			result, err := service.RunScraper(context.Background(), "bayut")
+			require.NoError(t, err)
+			assert.Zero(t, result.Delisted)
+
+			active, err := repo.Count(context.Background(), repository.ListFilter{ActiveOnly: true})
+			require.NoError(t, err)
+			assert.EqualValues(t, 4, active)
+		})
+	}
+}
+
+func TestListingLifecycleSkipsSparseScopes(t *testing.T) {
+	logger.Init()
+
+	repo := mock.NewCostDataPointRepository()
+	old := newTestListing(1, "Sharjah")
+	old.RecordedAt = time.Now().Add(-24 * time.Hour)
+	require.NoError(t, repo.Create(context.Background(), old))
+
+	lifecycle := NewListingLifecycle(repo, 3)
+	closed, err := lifecycle.CloseUnseen(context.Background(), []*models.CostDataPoint{newTestListing(2, "Sharjah")}, time.Now())
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, closed)
+	assert.Equal(t, 0, repo.GetCallCount("CloseUnseenListings"))
+}
+
+func TestListingLifecycleScopesByEmirate(t *testing.T) {
+	logger.Init()
+
+	repo := mock.NewCostDataPointRepository()
+	start := time.Now()
+
+	stale := newTestListing(1, "Dubai")
+	stale.RecordedAt = start.Add(-24 * time.Hour)
+	require.NoError(t, repo.Create(context.Background(), stale))
+
+	otherEmirate := newTestListing(2, "Ajman")
+	otherEmirate.RecordedAt = start.Add(-24 * time.Hour)
+	require.NoError(t, repo.Create(context.Background(), otherEmirate))
+
+	seen := newTestListing(3, "Dubai")
+	require.NoError(t, repo.Create(context.Background(), seen))
+
+	lifecycle := NewListingLifecycle(repo, 1)
+	closed, err := lifecycle.CloseUnseen(context.Background(), []*models.CostDataPoint{seen}, start)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, closed)
+
+	stored, err := repo.GetByID(context.Background(), otherEmirate.ID, otherEmirate.RecordedAt)
+	require.NoError(t, err)
+	assert.Nil(t, stored.ValidTo)
+}
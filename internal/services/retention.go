@@ -0,0 +1,310 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/pkg/logger"
+	"github.com/adonese/cost-of-living/pkg/metrics"
+)
+
+// DefaultRetentionBatchSize is the number of rows archived and deleted per
+// transaction
+const DefaultRetentionBatchSize = 1000
+
+// defaultRetentionScope labels the retention pass for categories without a
+// period of their own
+const defaultRetentionScope = "default"
+
+// RetentionPolicy configures compression and retention of cost data points.
+// Zero durations disable the corresponding step.
+type RetentionPolicy struct {
+	// CompressAfter compresses chunks older than this
+	CompressAfter time.Duration
+
+	// DefaultRetention expires rows of categories not listed in
+	// CategoryRetention
+	DefaultRetention time.Duration
+
+	// CategoryRetention overrides the retention period per category
+	CategoryRetention map[string]time.Duration
+
+	// ArchiveDir receives one NDJSON file per category and run holding the
+	// expired rows
+	ArchiveDir string
+
+	// BatchSize is the number of rows deleted per transaction
+	BatchSize int
+
+	// DryRun only counts the rows that would expire
+	DryRun bool
+}
+
+// RetentionPolicyFromEnv reads the policy from COMPRESS_AFTER_DAYS,
+// RETENTION_DAYS, RETENTION_CATEGORY_DAYS (e.g. "Housing=365,Food=730") and
+// ARCHIVE_DIR
+func RetentionPolicyFromEnv() (RetentionPolicy, error) {
+	policy := RetentionPolicy{
+		ArchiveDir: "archive",
+		BatchSize:  DefaultRetentionBatchSize,
+	}
+	if dir := os.Getenv("ARCHIVE_DIR"); dir != "" {
+		policy.ArchiveDir = dir
+	}
+
+	var err error
+	if policy.CompressAfter, err = parseDays(os.Getenv("COMPRESS_AFTER_DAYS")); err != nil {
+		return policy, fmt.Errorf("COMPRESS_AFTER_DAYS: %w", err)
+	}
+	if policy.DefaultRetention, err = parseDays(os.Getenv("RETENTION_DAYS")); err != nil {
+		return policy, fmt.Errorf("RETENTION_DAYS: %w", err)
+	}
+	if policy.CategoryRetention, err = ParseCategoryRetention(os.Getenv("RETENTION_CATEGORY_DAYS")); err != nil {
+		return policy, fmt.Errorf("RETENTION_CATEGORY_DAYS: %w", err)
+	}
+
+	return policy, nil
+}
+
+// ParseCategoryRetention parses "Category=days" pairs separated by commas
+func ParseCategoryRetention(s string) (map[string]time.Duration, error) {
+	retention := make(map[string]time.Duration)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		category, days, ok := strings.Cut(pair, "=")
+		category = strings.TrimSpace(category)
+		if !ok || category == "" {
+			return nil, fmt.Errorf("invalid category retention %q, use Category=days", pair)
+		}
+		d, err := parseDays(days)
+		if err != nil {
+			return nil, fmt.Errorf("category %s: %w", category, err)
+		}
+		retention[category] = d
+	}
+	return retention, nil
+}
+
+func parseDays(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	days, err := strconv.Atoi(s)
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("invalid number of days %q", s)
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// RetentionService compresses, archives and expires old cost data points
+type RetentionService struct {
+	repo   repository.MaintenanceRepository
+	policy RetentionPolicy
+	now    func() time.Time
+}
+
+// RetentionResult summarises a retention pass
+type RetentionResult struct {
+	DryRun        bool
+	CompressAfter time.Duration
+	Scopes        []RetentionScopeResult
+	Expired       int64
+}
+
+// RetentionScopeResult reports the rows expired for one category, or for
+// every other category under the default period
+type RetentionScopeResult struct {
+	Category    string
+	Cutoff      time.Time
+	Expired     int64
+	ArchiveFile string
+}
+
+// NewRetentionService creates a retention service
+func NewRetentionService(repo repository.MaintenanceRepository, policy RetentionPolicy) *RetentionService {
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = DefaultRetentionBatchSize
+	}
+	if policy.ArchiveDir == "" {
+		policy.ArchiveDir = "archive"
+	}
+	return &RetentionService{
+		repo:   repo,
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Run applies the compression policy, then archives and deletes expired rows
+// category by category. Rows are archived before the transaction deleting
+// them commits, so a crash can at worst archive a batch twice.
+func (s *RetentionService) Run(ctx context.Context) (*RetentionResult, error) {
+	result := &RetentionResult{DryRun: s.policy.DryRun, CompressAfter: s.policy.CompressAfter}
+
+	if !s.policy.DryRun {
+		if err := s.repo.SetCompressionPolicy(ctx, s.policy.CompressAfter); err != nil {
+			return result, fmt.Errorf("set compression policy: %w", err)
+		}
+	}
+
+	now := s.now()
+	categories := make([]string, 0, len(s.policy.CategoryRetention))
+	for category := range s.policy.CategoryRetention {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		retention := s.policy.CategoryRetention[category]
+		if retention <= 0 {
+			continue
+		}
+		filter := repository.RetentionFilter{Category: category, Before: now.Add(-retention)}
+		if err := s.expire(ctx, category, filter, now, result); err != nil {
+			return result, err
+		}
+	}
+
+	if s.policy.DefaultRetention > 0 {
+		// Categories with their own period, including "keep forever", are
+		// left alone by the default pass
+		filter := repository.RetentionFilter{
+			ExcludeCategories: categories,
+			Before:            now.Add(-s.policy.DefaultRetention),
+		}
+		if err := s.expire(ctx, defaultRetentionScope, filter, now, result); err != nil {
+			return result, err
+		}
+	}
+
+	logger.Info("Retention pass finished",
+		"dry_run", result.DryRun,
+		"compress_after", result.CompressAfter,
+		"expired", result.Expired)
+
+	return result, nil
+}
+
+// expire archives and deletes the rows of one scope
+func (s *RetentionService) expire(ctx context.Context, scope string, filter repository.RetentionFilter, now time.Time, result *RetentionResult) error {
+	scopeResult := RetentionScopeResult{Category: scope, Cutoff: filter.Before}
+
+	if s.policy.DryRun {
+		count, err := s.repo.CountExpired(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("count expired %s rows: %w", scope, err)
+		}
+		scopeResult.Expired = count
+		result.Scopes = append(result.Scopes, scopeResult)
+		result.Expired += count
+		return nil
+	}
+
+	archive := &archiveFile{path: archivePath(s.policy.ArchiveDir, scope, now)}
+	defer archive.Close()
+
+	for {
+		n, err := s.repo.DeleteExpired(ctx, filter, s.policy.BatchSize, archive.Write)
+		scopeResult.Expired += int64(n)
+		if err != nil {
+			result.Scopes = append(result.Scopes, scopeResult)
+			result.Expired += scopeResult.Expired
+			return fmt.Errorf("expire %s rows: %w", scope, err)
+		}
+		if n < s.policy.BatchSize {
+			break
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("close %s archive: %w", scope, err)
+	}
+	if scopeResult.Expired > 0 {
+		scopeResult.ArchiveFile = archive.path
+		metrics.RetentionRowsArchivedTotal.WithLabelValues(scope).Add(float64(scopeResult.Expired))
+		logger.Info("Archived expired cost data points",
+			"category", scope,
+			"cutoff", filter.Before,
+			"rows", scopeResult.Expired,
+			"file", archive.path)
+	}
+
+	result.Scopes = append(result.Scopes, scopeResult)
+	result.Expired += scopeResult.Expired
+	return nil
+}
+
+// archivePath returns ARCHIVE_DIR/<category>/<timestamp>.ndjson
+func archivePath(dir, scope string, now time.Time) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '-'
+		}
+	}, scope)
+	return filepath.Join(dir, slug, now.UTC().Format("20060102T150405Z")+".ndjson")
+}
+
+// archiveFile appends cost data points as NDJSON, opening the file on the
+// first write and syncing after every batch so rows are on disk before
+// their deletion commits
+type archiveFile struct {
+	path string
+	file *os.File
+}
+
+// Write appends one batch and syncs it
+func (a *archiveFile) Write(batch []*models.CostDataPoint) error {
+	if a.file == nil {
+		if err := os.MkdirAll(filepath.Dir(a.path), 0o755); err != nil {
+			return fmt.Errorf("failed to create archive directory: %w", err)
+		}
+		file, err := os.OpenFile(a.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+		a.file = file
+	}
+
+	buf := bufio.NewWriter(a.file)
+	enc := json.NewEncoder(buf)
+	for _, cdp := range batch {
+		if err := enc.Encode(cdp); err != nil {
+			return fmt.Errorf("failed to write archive: %w", err)
+		}
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := a.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync archive: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file if it was opened; it is safe to call twice
+func (a *archiveFile) Close() error {
+	if a.file == nil {
+		return nil
+	}
+	err := a.file.Close()
+	a.file = nil
+	return err
+}
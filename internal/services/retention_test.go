@@ -0,0 +1,127 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	"github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+func seedAged(t *testing.T, repo *mock.CostDataPointRepository, category string, age time.Duration, active bool) *models.CostDataPoint {
+	t.Helper()
+
+	recorded := time.Now().Add(-age)
+	dp := &models.CostDataPoint{
+		Category:   category,
+		ItemName:   category + " item",
+		Price:      100,
+		Source:     "test",
+		Location:   models.Location{Emirate: "Dubai"},
+		RecordedAt: recorded,
+		LastSeenAt: recorded,
+	}
+	if active {
+		dp.LastSeenAt = time.Now()
+	}
+	require.NoError(t, repo.Create(context.Background(), dp))
+	return dp
+}
+
+func TestRetentionServiceArchivesAndDeletes(t *testing.T) {
+	logger.Init()
+	points := mock.NewCostDataPointRepository()
+	maintenance := mock.NewMaintenanceRepository(points)
+
+	day := 24 * time.Hour
+	oldHousing := seedAged(t, points, "Housing", 400*day, false)
+	seedAged(t, points, "Housing", 400*day, true) // still listed
+	seedAged(t, points, "Housing", 100*day, false)
+	seedAged(t, points, "Food", 100*day, false)
+	seedAged(t, points, "Food", 10*day, false)
+	seedAged(t, points, "Utilities", 1000*day, false) // kept forever
+
+	dir := t.TempDir()
+	service := NewRetentionService(maintenance, RetentionPolicy{
+		CompressAfter:    30 * day,
+		DefaultRetention: 60 * day,
+		CategoryRetention: map[string]time.Duration{
+			"Housing":   365 * day,
+			"Utilities": 0,
+		},
+		ArchiveDir: dir,
+		BatchSize:  1,
+	})
+
+	result, err := service.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 30*day, maintenance.CompressAfter())
+	assert.Equal(t, int64(2), result.Expired)
+	require.Len(t, result.Scopes, 2)
+	assert.Equal(t, "Housing", result.Scopes[0].Category)
+	assert.Equal(t, int64(1), result.Scopes[0].Expired)
+	assert.Equal(t, defaultRetentionScope, result.Scopes[1].Category)
+	assert.Equal(t, int64(1), result.Scopes[1].Expired)
+
+	remaining, err := points.List(context.Background(), repository.ListFilter{})
+	require.NoError(t, err)
+	assert.Len(t, remaining, 4)
+
+	file, err := os.Open(result.Scopes[0].ArchiveFile)
+	require.NoError(t, err)
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	require.True(t, scanner.Scan())
+	var archived models.CostDataPoint
+	require.NoError(t, json.Unmarshal(scanner.Bytes(), &archived))
+	assert.Equal(t, oldHousing.ID, archived.ID)
+	assert.False(t, scanner.Scan())
+}
+
+func TestRetentionServiceDryRun(t *testing.T) {
+	logger.Init()
+	points := mock.NewCostDataPointRepository()
+	maintenance := mock.NewMaintenanceRepository(points)
+
+	seedAged(t, points, "Food", 100*24*time.Hour, false)
+
+	service := NewRetentionService(maintenance, RetentionPolicy{
+		CompressAfter:    24 * time.Hour,
+		DefaultRetention: 30 * 24 * time.Hour,
+		ArchiveDir:       t.TempDir(),
+		DryRun:           true,
+	})
+
+	result, err := service.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(1), result.Expired)
+	assert.Empty(t, result.Scopes[0].ArchiveFile)
+	assert.Equal(t, 0, maintenance.GetCallCount("SetCompressionPolicy"))
+	assert.Equal(t, 0, maintenance.GetCallCount("DeleteExpired"))
+	assert.Equal(t, 1, points.GetCallCount("Create"))
+}
+
+func TestParseCategoryRetention(t *testing.T) {
+	retention, err := ParseCategoryRetention("Housing=365, Food=730,Personal Care=0")
+	require.NoError(t, err)
+	assert.Equal(t, 365*24*time.Hour, retention["Housing"])
+	assert.Equal(t, 730*24*time.Hour, retention["Food"])
+	assert.Contains(t, retention, "Personal Care")
+
+	_, err = ParseCategoryRetention("Housing")
+	assert.Error(t, err)
+	_, err = ParseCategoryRetention("Housing=-1")
+	assert.Error(t, err)
+}
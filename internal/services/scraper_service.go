@@ -14,10 +14,16 @@ import (
 	"github.com/adonese/cost-of-living/pkg/metrics"
 )
 
+// ErrSaveFailed is returned by a run that could not save some of its data
+// points. The run is recorded as failed so compensation rolls back the rest.
+var ErrSaveFailed = errors.New("data points not saved")
+
 // ScraperService manages and runs scrapers
 type ScraperService struct {
 	scrapers  []scrapers.Scraper
 	repo      repository.CostDataPointRepository
+	runs      repository.ScrapeRunRepository
+	lifecycle *ListingLifecycle
 	validator validation.Validator
 	config    *ScraperServiceConfig
 }
@@ -28,6 +34,8 @@ type ScraperServiceConfig struct {
 	MinQualityScore    float64 // Minimum quality score to save data (default: 0.7)
 	FailOnValidation   bool    // Fail scrape if validation fails
 	ValidateBeforeSave bool    // Validate data before saving to DB
+	TrackListings      bool    // Close listings that disappear from a clean, complete scrape of their scope
+	MinListingsToClose int     // Minimum listings per scope before unseen ones are closed (default: 5)
 }
 
 // ValidationSummary captures how many data points passed validation and why
@@ -43,14 +51,24 @@ type ValidationSummary struct {
 // ScrapeResult represents the outcome of running a scraper end-to-end.
 type ScrapeResult struct {
 	ScraperName  string
+	RunID        string
 	Fetched      int
 	Validation   ValidationSummary
 	Saved        int
+	Refreshed    int
 	SaveFailures int
+	Delisted     int64
 	Duration     time.Duration
 	Errors       []error
 }
 
+// RunMetadata carries orchestration identifiers that are recorded alongside a
+// scrape run, such as the Temporal workflow that triggered it.
+type RunMetadata struct {
+	WorkflowID    string
+	WorkflowRunID string
+}
+
 // DefaultScraperServiceConfig returns default configuration
 func DefaultScraperServiceConfig() *ScraperServiceConfig {
 	return &ScraperServiceConfig{
@@ -58,6 +76,8 @@ func DefaultScraperServiceConfig() *ScraperServiceConfig {
 		MinQualityScore:    0.7,
 		FailOnValidation:   false,
 		ValidateBeforeSave: true,
+		TrackListings:      true,
+		MinListingsToClose: DefaultMinListingsPerScope,
 	}
 }
 
@@ -68,12 +88,22 @@ func NewScraperService(repo repository.CostDataPointRepository) *ScraperService
 
 // NewScraperServiceWithConfig creates a new scraper service with custom configuration
 func NewScraperServiceWithConfig(repo repository.CostDataPointRepository, config *ScraperServiceConfig) *ScraperService {
-	return &ScraperService{
+	service := &ScraperService{
 		scrapers:  []scrapers.Scraper{},
 		repo:      repo,
 		validator: validation.NewValidator(),
 		config:    config,
 	}
+	if config.TrackListings {
+		service.lifecycle = NewListingLifecycle(repo, config.MinListingsToClose)
+	}
+	return service
+}
+
+// SetRunRepository enables persisting every scraper execution as a scrape run.
+// When unset, runs are only logged.
+func (s *ScraperService) SetRunRepository(runs repository.ScrapeRunRepository) {
+	s.runs = runs
 }
 
 // RegisterScraper adds a scraper to the service
@@ -84,8 +114,14 @@ func (s *ScraperService) RegisterScraper(scraper scrapers.Scraper) {
 
 // RunScraper runs a specific scraper by name and returns a detailed summary.
 func (s *ScraperService) RunScraper(ctx context.Context, scraperName string) (*ScrapeResult, error) {
+	return s.RunScraperWithMetadata(ctx, scraperName, RunMetadata{})
+}
+
+// RunScraperWithMetadata runs a specific scraper by name, recording the supplied
+// metadata on the scrape run, and returns a detailed summary.
+func (s *ScraperService) RunScraperWithMetadata(ctx context.Context, scraperName string, meta RunMetadata) (result *ScrapeResult, err error) {
 	start := time.Now()
-	result := &ScrapeResult{ScraperName: scraperName}
+	result = &ScrapeResult{ScraperName: scraperName}
 
 	// Find scraper
 	var targetScraper scrapers.Scraper
@@ -103,6 +139,15 @@ func (s *ScraperService) RunScraper(ctx context.Context, scraperName string) (*S
 		return result, err
 	}
 
+	// Record the run so saved data points can be traced back to it
+	run := s.startRun(ctx, scraperName, meta)
+	if run != nil {
+		result.RunID = run.ID
+		defer func() {
+			s.finishRun(ctx, run, result, err)
+		}()
+	}
+
 	// Check if the scraper can run within rate limits
 	if !targetScraper.CanScrape() {
 		err := fmt.Errorf("rate limit exceeded")
@@ -151,17 +196,33 @@ func (s *ScraperService) RunScraper(ctx context.Context, scraperName string) (*S
 	}
 	result.Validation = summary
 
-	// Persist validated data points
+	// Persist validated data points in a single batch. Upserting on the
+	// natural key keeps re-runs from duplicating listings already stored.
+	seenAt := time.Now()
+	for _, dp := range validatedPoints {
+		dp.RunID = result.RunID
+		dp.LastSeenAt = seenAt
+	}
+
 	saved := 0
 	failed := 0
-	for _, dp := range validatedPoints {
-		if err := s.repo.Create(ctx, dp); err != nil {
-			logger.Error("Failed to save data point", "error", err, "item", dp.ItemName)
-			failed++
+	var batchErr error
+	if len(validatedPoints) > 0 {
+		batch, err := s.repo.Upsert(ctx, validatedPoints)
+		if err != nil {
+			logger.Error("Failed to save data points", "error", err, "scraper", scraperName, "count", len(validatedPoints))
+			failed = len(validatedPoints)
+			batchErr = err
 			result.Errors = append(result.Errors, err)
-			continue
+		} else {
+			saved = batch.Inserted + batch.Updated
+			result.Refreshed = batch.Updated
+			failed = len(batch.Failed)
+			for _, rowErr := range batch.Failed {
+				logger.Error("Failed to save data point", "error", rowErr.Err, "item", validatedPoints[rowErr.Index].ItemName)
+				result.Errors = append(result.Errors, rowErr)
+			}
 		}
-		saved++
 	}
 
 	result.Saved = saved
@@ -171,9 +232,42 @@ func (s *ScraperService) RunScraper(ctx context.Context, scraperName string) (*S
 		metrics.ScraperErrorsTotal.WithLabelValues(scraperName, "save_failed").Add(float64(failed))
 	}
 
+	// Listings missing from a clean, complete scrape have been taken off the
+	// market. A scrape with save failures may be missing listings it did see,
+	// and one that stopped at a page limit never saw the rest, so neither
+	// closes anything.
+	if s.lifecycle != nil && failed == 0 {
+		if crawledCompletely(targetScraper) {
+			delisted, err := s.lifecycle.CloseUnseen(ctx, validatedPoints, seenAt)
+			result.Delisted = delisted
+			if err != nil {
+				logger.Error("Failed to close unseen listings", "scraper", scraperName, "error", err)
+				result.Errors = append(result.Errors, err)
+			}
+		} else {
+			logger.Info("Skipping delisting for incomplete crawl", "scraper", scraperName)
+		}
+	}
+
 	result.Duration = time.Since(start)
 	metrics.ScraperDuration.WithLabelValues(scraperName).Observe(result.Duration.Seconds())
 
+	// A partial save fails the run, so the caller compensates the part that
+	// was written instead of keeping an incomplete snapshot.
+	if failed > 0 {
+		metrics.ScraperRunsTotal.WithLabelValues(scraperName, "error").Inc()
+		saveErr := fmt.Errorf("%w: %d of %d failed", ErrSaveFailed, failed, len(validatedPoints))
+		if batchErr != nil {
+			saveErr = fmt.Errorf("%w: %w", saveErr, batchErr)
+		}
+		logger.Error("Scraper saved only part of its data points",
+			"name", scraperName,
+			"saved", result.Saved,
+			"failed", result.SaveFailures,
+			"duration", result.Duration)
+		return result, saveErr
+	}
+
 	metrics.ScraperRunsTotal.WithLabelValues(scraperName, "success").Inc()
 	logger.Info("Scraper completed",
 		"name", scraperName,
@@ -182,7 +276,9 @@ func (s *ScraperService) RunScraper(ctx context.Context, scraperName string) (*S
 		"dropped_invalid", result.Validation.Invalid,
 		"dropped_low_quality", result.Validation.LowQuality,
 		"saved", result.Saved,
+		"refreshed", result.Refreshed,
 		"failed", result.SaveFailures,
+		"delisted", result.Delisted,
 		"duration", result.Duration)
 
 	return result, nil
@@ -220,6 +316,74 @@ func (s *ScraperService) ListScrapers() []string {
 	return names
 }
 
+// crawledCompletely reports whether scraper's last scrape returned every
+// listing of its scopes. Scrapers that cannot tell are assumed truncated.
+func crawledCompletely(scraper scrapers.Scraper) bool {
+	reporter, ok := scraper.(scrapers.CrawlReporter)
+	return ok && reporter.LastCrawlComplete()
+}
+
+// startRun records the beginning of a scrape run. Failures are logged and do
+// not prevent the scrape from running.
+func (s *ScraperService) startRun(ctx context.Context, scraperName string, meta RunMetadata) *models.ScrapeRun {
+	if s.runs == nil {
+		return nil
+	}
+
+	run := &models.ScrapeRun{
+		ScraperName:   scraperName,
+		Status:        models.ScrapeRunRunning,
+		StartedAt:     time.Now(),
+		WorkflowID:    meta.WorkflowID,
+		WorkflowRunID: meta.WorkflowRunID,
+	}
+	if err := s.runs.Create(ctx, run); err != nil {
+		logger.Error("Failed to record scrape run", "scraper", scraperName, "error", err)
+		return nil
+	}
+
+	return run
+}
+
+// finishRun persists the outcome of a scrape run. A run that saved only
+// part of its data points is recorded as failed so that compensation rolls
+// back the part it did save.
+func (s *ScraperService) finishRun(ctx context.Context, run *models.ScrapeRun, result *ScrapeResult, runErr error) {
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = models.ScrapeRunSucceeded
+	if runErr != nil || result.SaveFailures > 0 {
+		run.Status = models.ScrapeRunFailed
+	}
+
+	run.Fetched = result.Fetched
+	run.Validated = result.Validation.Valid
+	run.Saved = result.Saved
+	run.SaveFailures = result.SaveFailures
+	run.Validation = models.ScrapeRunValidation{
+		Total:      result.Validation.Total,
+		Valid:      result.Validation.Valid,
+		Invalid:    result.Validation.Invalid,
+		LowQuality: result.Validation.LowQuality,
+		Skipped:    result.Validation.Skipped,
+	}
+	run.Errors = make([]string, 0, len(result.Errors))
+	for _, e := range result.Errors {
+		if e != nil {
+			run.Errors = append(run.Errors, e.Error())
+		}
+	}
+
+	// The scrape context may already be cancelled (e.g. activity timeout), but
+	// the outcome should still be recorded.
+	if err := s.runs.Update(context.WithoutCancel(ctx), run); err != nil {
+		logger.Error("Failed to finalize scrape run",
+			"scraper", run.ScraperName,
+			"run_id", run.ID,
+			"error", err)
+	}
+}
+
 // validateAndFilter validates data points and filters out invalid ones
 func (s *ScraperService) validateAndFilter(ctx context.Context, dataPoints []*models.CostDataPoint, scraperName string) ([]*models.CostDataPoint, ValidationSummary, error) {
 	summary := ValidationSummary{Total: len(dataPoints)}
@@ -16,10 +16,11 @@ import (
 )
 
 type stubScraper struct {
-	name      string
-	points    []*models.CostDataPoint
-	scrapeErr error
-	canScrape bool
+	name       string
+	points     []*models.CostDataPoint
+	scrapeErr  error
+	canScrape  bool
+	incomplete bool
 }
 
 func (s *stubScraper) Name() string {
@@ -33,6 +34,10 @@ func (s *stubScraper) Scrape(ctx context.Context) ([]*models.CostDataPoint, erro
 	return s.points, nil
 }
 
+func (s *stubScraper) LastCrawlComplete() bool {
+	return !s.incomplete
+}
+
 func (s *stubScraper) CanScrape() bool {
 	if !s.canScrape {
 		return false
@@ -76,6 +81,10 @@ func TestScraperServiceRunScraperSuccess(t *testing.T) {
 	savedPoints, err := repo.List(context.Background(), mockListFilter())
 	require.NoError(t, err)
 	assert.Len(t, savedPoints, len(points))
+
+	// Points are written in a single batch rather than one insert each
+	assert.Equal(t, 1, repo.GetCallCount("Upsert"))
+	assert.Equal(t, 0, repo.GetCallCount("Create"))
 }
 
 func TestScraperServiceRunScraperHandlesSaveFailures(t *testing.T) {
@@ -99,7 +108,7 @@ func TestScraperServiceRunScraperHandlesSaveFailures(t *testing.T) {
 	service.RegisterScraper(scraper)
 
 	result, err := service.RunScraper(context.Background(), "test")
-	require.NoError(t, err)
+	require.ErrorIs(t, err, ErrSaveFailed)
 	require.NotNil(t, result)
 
 	assert.Equal(t, 1, result.SaveFailures)
@@ -107,6 +116,66 @@ func TestScraperServiceRunScraperHandlesSaveFailures(t *testing.T) {
 	assert.Len(t, result.Errors, 1)
 }
 
+func TestScraperServiceRerunDoesNotDuplicate(t *testing.T) {
+	logger.Init()
+
+	repo := mock.NewCostDataPointRepository()
+	config := &ScraperServiceConfig{EnableValidation: false, ValidateBeforeSave: false}
+	service := NewScraperServiceWithConfig(repo, config)
+
+	listing := func() *models.CostDataPoint {
+		dp := newTestPoint("2BR in Marina")
+		dp.Category = "Housing"
+		dp.SourceURL = "https://www.bayut.com/property/details-123.html?utm=feed"
+		return dp
+	}
+
+	scraper := &stubScraper{name: "test", points: []*models.CostDataPoint{listing()}, canScrape: true}
+	service.RegisterScraper(scraper)
+
+	first, err := service.RunScraper(context.Background(), "test")
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Saved)
+	assert.Equal(t, 0, first.Refreshed)
+
+	scraper.points = []*models.CostDataPoint{listing()}
+	second, err := service.RunScraper(context.Background(), "test")
+	require.NoError(t, err)
+	assert.Equal(t, 1, second.Saved)
+	assert.Equal(t, 1, second.Refreshed)
+
+	stored, err := repo.List(context.Background(), mockListFilter())
+	require.NoError(t, err)
+	assert.Len(t, stored, 1)
+}
+
+func TestScraperServiceBatchFailure(t *testing.T) {
+	logger.Init()
+
+	repo := &unavailableRepository{
+		CostDataPointRepository: mock.NewCostDataPointRepository(),
+		err:                     errors.New("connection refused"),
+	}
+
+	config := &ScraperServiceConfig{EnableValidation: false, ValidateBeforeSave: false}
+	service := NewScraperServiceWithConfig(repo, config)
+
+	points := []*models.CostDataPoint{
+		newTestPoint("item-1"),
+		newTestPoint("item-2"),
+	}
+	service.RegisterScraper(&stubScraper{name: "test", points: points, canScrape: true})
+
+	result, err := service.RunScraper(context.Background(), "test")
+	require.ErrorIs(t, err, ErrSaveFailed)
+	require.ErrorContains(t, err, "connection refused")
+	require.NotNil(t, result)
+
+	assert.Equal(t, 0, result.Saved)
+	assert.Equal(t, len(points), result.SaveFailures)
+	assert.Len(t, result.Errors, 1)
+}
+
 func TestScraperServiceRunAllScrapersAggregatesErrors(t *testing.T) {
 	logger.Init()
 
@@ -133,6 +202,97 @@ func TestScraperServiceRunAllScrapersAggregatesErrors(t *testing.T) {
 	assert.NotEmpty(t, bad.Errors)
 }
 
+func TestScraperServiceRecordsScrapeRun(t *testing.T) {
+	logger.Init()
+
+	repo := mock.NewCostDataPointRepository()
+	runs := mock.NewScrapeRunRepository()
+	config := &ScraperServiceConfig{EnableValidation: false, ValidateBeforeSave: false}
+	service := NewScraperServiceWithConfig(repo, config)
+	service.SetRunRepository(runs)
+
+	points := []*models.CostDataPoint{
+		newTestPoint("item-1"),
+		newTestPoint("item-2"),
+	}
+	service.RegisterScraper(&stubScraper{name: "test", points: points, canScrape: true})
+
+	result, err := service.RunScraperWithMetadata(context.Background(), "test", RunMetadata{
+		WorkflowID:    "wf-1",
+		WorkflowRunID: "run-1",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, result.RunID)
+
+	run, err := runs.GetByID(context.Background(), result.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ScrapeRunSucceeded, run.Status)
+	assert.Equal(t, "test", run.ScraperName)
+	assert.Equal(t, 2, run.Fetched)
+	assert.Equal(t, 2, run.Saved)
+	assert.Equal(t, "wf-1", run.WorkflowID)
+	assert.Equal(t, "run-1", run.WorkflowRunID)
+	require.NotNil(t, run.FinishedAt)
+
+	saved, err := repo.List(context.Background(), repository.ListFilter{RunID: result.RunID})
+	require.NoError(t, err)
+	assert.Len(t, saved, 2)
+}
+
+func TestScraperServiceRecordsFailedScrapeRun(t *testing.T) {
+	logger.Init()
+
+	runs := mock.NewScrapeRunRepository()
+	config := &ScraperServiceConfig{EnableValidation: false, ValidateBeforeSave: false}
+	service := NewScraperServiceWithConfig(mock.NewCostDataPointRepository(), config)
+	service.SetRunRepository(runs)
+	service.RegisterScraper(&stubScraper{name: "bad", scrapeErr: errors.New("boom"), canScrape: true})
+
+	result, err := service.RunScraper(context.Background(), "bad")
+	require.Error(t, err)
+
+	run, getErr := runs.GetByID(context.Background(), result.RunID)
+	require.NoError(t, getErr)
+	assert.Equal(t, models.ScrapeRunFailed, run.Status)
+	assert.NotEmpty(t, run.Errors)
+}
+
+func TestScraperServiceRecordsPartialSaveAsFailedRun(t *testing.T) {
+	logger.Init()
+
+	repo := mock.NewCostDataPointRepository()
+	runs := mock.NewScrapeRunRepository()
+	failingRepo := &failingRepository{
+		CostDataPointRepository: repo,
+		failAfter:               1,
+		err:                     errors.New("forced failure"),
+	}
+	config := &ScraperServiceConfig{EnableValidation: false, ValidateBeforeSave: false}
+	service := NewScraperServiceWithConfig(failingRepo, config)
+	service.SetRunRepository(runs)
+	service.RegisterScraper(&stubScraper{
+		name:      "test",
+		points:    []*models.CostDataPoint{newTestPoint("item-1"), newTestPoint("item-2")},
+		canScrape: true,
+	})
+
+	result, err := service.RunScraper(context.Background(), "test")
+	require.ErrorIs(t, err, ErrSaveFailed, "the caller must see the failure to compensate")
+	require.Equal(t, 1, result.Saved)
+
+	run, err := runs.GetByID(context.Background(), result.RunID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ScrapeRunFailed, run.Status, "a run that saved only part of its data is failed")
+	assert.Equal(t, 1, run.SaveFailures)
+
+	// Compensation picks the run up and rolls back the half it saved
+	compensation := NewCompensationService(repo, runs, nil, models.CompensationDelete)
+	compensated, err := compensation.CompensateRuns(context.Background(), repository.ScrapeRunFilter{ScraperName: "test"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{result.RunID}, compensated.Runs)
+	assert.Equal(t, int64(1), compensated.RowsAffected)
+}
+
 func newTestPoint(name string) *models.CostDataPoint {
 	now := time.Now()
 	return &models.CostDataPoint{
@@ -159,12 +319,29 @@ type failingRepository struct {
 	err       error
 }
 
-func (r *failingRepository) Create(ctx context.Context, cdp *models.CostDataPoint) error {
-	r.calls++
-	if r.failAfter > 0 && r.calls > r.failAfter {
-		return r.err
+func (r *failingRepository) Upsert(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	result := &repository.BatchResult{}
+	for i, cdp := range cdps {
+		r.calls++
+		if r.failAfter > 0 && r.calls > r.failAfter {
+			result.Failed = append(result.Failed, repository.BatchError{Index: i, Err: r.err})
+			continue
+		}
+		if err := r.CostDataPointRepository.Create(ctx, cdp); err != nil {
+			return nil, err
+		}
+		result.Inserted++
 	}
-	return r.CostDataPointRepository.Create(ctx, cdp)
+	return result, nil
+}
+
+type unavailableRepository struct {
+	repository.CostDataPointRepository
+	err error
+}
+
+func (r *unavailableRepository) Upsert(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	return nil, r.err
 }
 
 func findResult(results []*ScrapeResult, name string) *ScrapeResult {
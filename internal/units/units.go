@@ -0,0 +1,155 @@
+// Package units describes what a price is quoted per and converts between
+// the units and periods used by UAE tariffs and listings.
+//
+// A data point's price is an amount of its Currency for one Unit of
+// consumption, such as a kWh of electricity or a km of taxi ride, or for the
+// whole item when Unit is empty. Recurring charges such as rent also carry
+// the Period they cover.
+package units
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Units a price can be quoted per
+const (
+	KWh            = "kWh"
+	CubicMetre     = "m3"
+	ImperialGallon = "IG"
+	Kilometre      = "km"
+	Minute         = "minute"
+	Trip           = "trip"
+
+	// Fraction marks a rate that is a share of another charge rather than
+	// an amount of money, such as a sewerage charge of 0.5 of the water bill
+	Fraction = "fraction"
+)
+
+// Periods a recurring charge can cover
+const (
+	Month = "month"
+	Year  = "year"
+)
+
+// FilsPerDirham is the number of fils in one AED
+const FilsPerDirham = 100
+
+// CubicMetresPerImperialGallon is the volume of one imperial gallon
+const CubicMetresPerImperialGallon = 0.00454609
+
+var validUnits = map[string]bool{
+	"":             true,
+	KWh:            true,
+	CubicMetre:     true,
+	ImperialGallon: true,
+	Kilometre:      true,
+	Minute:         true,
+	Trip:           true,
+	Fraction:       true,
+}
+
+// ValidUnit reports whether unit is one of the units above, or empty
+func ValidUnit(unit string) bool {
+	return validUnits[unit]
+}
+
+// ValidPeriod reports whether period is Month, Year or empty
+func ValidPeriod(period string) bool {
+	return period == "" || period == Month || period == Year
+}
+
+// FilsToAED converts an amount in fils to dirhams
+func FilsToAED(fils float64) float64 {
+	return fils / FilsPerDirham
+}
+
+// AEDToFils converts an amount in dirhams to fils
+func AEDToFils(aed float64) float64 {
+	return aed * FilsPerDirham
+}
+
+// IGToM3 converts a volume in imperial gallons to cubic metres
+func IGToM3(ig float64) float64 {
+	return ig * CubicMetresPerImperialGallon
+}
+
+// M3ToIG converts a volume in cubic metres to imperial gallons
+func M3ToIG(m3 float64) float64 {
+	return m3 / CubicMetresPerImperialGallon
+}
+
+// Monthly converts an amount charged every period to a monthly amount. An
+// empty period is taken as a one-off amount and returned unchanged.
+func Monthly(amount float64, period string) (float64, error) {
+	switch period {
+	case "", Month:
+		return amount, nil
+	case Year:
+		return amount / 12, nil
+	}
+	return 0, fmt.Errorf("unknown period %q", period)
+}
+
+// Annual converts an amount charged every period to a yearly amount. An
+// empty period is taken as a one-off amount and returned unchanged.
+func Annual(amount float64, period string) (float64, error) {
+	switch period {
+	case "", Year:
+		return amount, nil
+	case Month:
+		return amount * 12, nil
+	}
+	return 0, fmt.Errorf("unknown period %q", period)
+}
+
+// PricePer converts a price per unit from into a price per unit to. Only
+// volumes convert between units; any other pair must match.
+func PricePer(price float64, from, to string) (float64, error) {
+	switch {
+	case from == to:
+		return price, nil
+	case from == ImperialGallon && to == CubicMetre:
+		return price / CubicMetresPerImperialGallon, nil
+	case from == CubicMetre && to == ImperialGallon:
+		return price * CubicMetresPerImperialGallon, nil
+	}
+	return 0, fmt.Errorf("cannot convert a price per %q to a price per %q", from, to)
+}
+
+// Measure is the currency, unit and period a price is quoted in. Scale
+// converts a price quoted in the legacy unit to one in this measure, for
+// example 1/1000 for a price per 1000 gallons.
+type Measure struct {
+	Currency string
+	Unit     string
+	Period   string
+	Scale    float64
+}
+
+// legacyUnits maps the free-text units stored before currency, unit and
+// period were separated. Migration 012 applies the same mapping to stored
+// rows.
+var legacyUnits = map[string]Measure{
+	"aed":                        {Currency: "AED", Scale: 1},
+	"aed/year":                   {Currency: "AED", Period: Year, Scale: 1},
+	"aed/month":                  {Currency: "AED", Period: Month, Scale: 1},
+	"aed/km":                     {Currency: "AED", Unit: Kilometre, Scale: 1},
+	"aed/minute":                 {Currency: "AED", Unit: Minute, Scale: 1},
+	"aed/kwh":                    {Currency: "AED", Unit: KWh, Scale: 1},
+	"aed per kwh":                {Currency: "AED", Unit: KWh, Scale: 1},
+	"aed per 1000 ig":            {Currency: "AED", Unit: ImperialGallon, Scale: 0.001},
+	"aed per 1000 gallons":       {Currency: "AED", Unit: ImperialGallon, Scale: 0.001},
+	"1000_ig":                    {Currency: "AED", Unit: ImperialGallon, Scale: 0.001},
+	"fils_per_kwh":               {Currency: "AED", Unit: KWh, Scale: 1.0 / FilsPerDirham},
+	"fils_per_ig":                {Currency: "AED", Unit: ImperialGallon, Scale: 1.0 / FilsPerDirham},
+	"percentage of water charge": {Unit: Fraction, Scale: 1},
+}
+
+// ParseLegacy maps a legacy unit string such as "AED/year" or "fils_per_kwh"
+// to a Measure. It reports false for strings it does not recognise,
+// including units already in the current form.
+func ParseLegacy(unit string) (Measure, bool) {
+	m, ok := legacyUnits[strings.ToLower(strings.TrimSpace(unit))]
+	return m, ok
+}
@@ -0,0 +1,92 @@
+package units
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConversions(t *testing.T) {
+	assert.Equal(t, 0.23, FilsToAED(23))
+	assert.Equal(t, 23.0, AEDToFils(0.23))
+	assert.InDelta(t, 4.54609, IGToM3(1000), 1e-12)
+	assert.InDelta(t, 1000, M3ToIG(4.54609), 1e-9)
+}
+
+func TestMonthlyAndAnnual(t *testing.T) {
+	monthly, err := Monthly(120000, Year)
+	require.NoError(t, err)
+	assert.Equal(t, 10000.0, monthly)
+
+	monthly, err = Monthly(900, Month)
+	require.NoError(t, err)
+	assert.Equal(t, 900.0, monthly)
+
+	annual, err := Annual(900, Month)
+	require.NoError(t, err)
+	assert.Equal(t, 10800.0, annual)
+
+	// One-off amounts are unchanged
+	annual, err = Annual(50, "")
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, annual)
+
+	_, err = Monthly(100, "fortnight")
+	assert.Error(t, err)
+	_, err = Annual(100, "fortnight")
+	assert.Error(t, err)
+}
+
+func TestPricePer(t *testing.T) {
+	perM3, err := PricePer(0.0357, ImperialGallon, CubicMetre)
+	require.NoError(t, err)
+	assert.InDelta(t, 7.853, perM3, 0.001)
+
+	perIG, err := PricePer(perM3, CubicMetre, ImperialGallon)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.0357, perIG, 1e-12)
+
+	price, err := PricePer(2.5, Kilometre, Kilometre)
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, price)
+
+	_, err = PricePer(12, Trip, Kilometre)
+	assert.Error(t, err)
+}
+
+func TestParseLegacy(t *testing.T) {
+	tests := []struct {
+		unit string
+		want Measure
+	}{
+		{"AED", Measure{Currency: "AED", Scale: 1}},
+		{"AED/year", Measure{Currency: "AED", Period: Year, Scale: 1}},
+		{" aed/km ", Measure{Currency: "AED", Unit: Kilometre, Scale: 1}},
+		{"AED per 1000 IG", Measure{Currency: "AED", Unit: ImperialGallon, Scale: 0.001}},
+		{"fils_per_kwh", Measure{Currency: "AED", Unit: KWh, Scale: 0.01}},
+		{"percentage of water charge", Measure{Unit: Fraction, Scale: 1}},
+	}
+	for _, tt := range tests {
+		got, ok := ParseLegacy(tt.unit)
+		assert.True(t, ok, tt.unit)
+		assert.Equal(t, tt.want, got, tt.unit)
+	}
+
+	for _, unit := range []string{"", "kWh", "km", "trip", "AED/semester"} {
+		_, ok := ParseLegacy(unit)
+		assert.False(t, ok, unit)
+	}
+}
+
+func TestValidUnitAndPeriod(t *testing.T) {
+	for _, unit := range []string{"", KWh, CubicMetre, ImperialGallon, Kilometre, Minute, Trip, Fraction} {
+		assert.True(t, ValidUnit(unit), unit)
+	}
+	assert.False(t, ValidUnit("AED"))
+	assert.False(t, ValidUnit("kwh"))
+
+	assert.True(t, ValidPeriod(""))
+	assert.True(t, ValidPeriod(Year))
+	assert.False(t, ValidPeriod("semester"))
+}
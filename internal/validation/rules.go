@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/units"
 )
 
 // Rule represents a validation rule
@@ -120,6 +121,24 @@ var CommonRules = []Rule{
 			return nil
 		},
 	},
+	{
+		Name:     "valid_measure",
+		Category: "all",
+		Field:    "Unit",
+		Severity: SeverityError,
+		Validator: func(dp *models.CostDataPoint) error {
+			if !units.ValidUnit(dp.Unit) {
+				return fmt.Errorf("invalid unit: %q", dp.Unit)
+			}
+			if !units.ValidPeriod(dp.Period) {
+				return fmt.Errorf("invalid period: %q", dp.Period)
+			}
+			if dp.Currency == "" && dp.Unit != units.Fraction {
+				return fmt.Errorf("currency is required")
+			}
+			return nil
+		},
+	},
 	{
 		Name:     "positive_price",
 		Category: "all",
@@ -232,17 +251,13 @@ var HousingRules = []Rule{
 		},
 	},
 	{
-		Name:     "housing_unit",
+		Name:     "housing_period",
 		Category: "Housing",
-		Field:    "Unit",
+		Field:    "Period",
 		Severity: SeverityWarning,
 		Validator: func(dp *models.CostDataPoint) error {
-			validUnits := map[string]bool{
-				"AED/year":  true,
-				"AED/month": true,
-			}
-			if !validUnits[dp.Unit] {
-				return fmt.Errorf("unexpected unit for housing: %s (expected AED/year or AED/month)", dp.Unit)
+			if dp.Period != units.Year && dp.Period != units.Month {
+				return fmt.Errorf("unexpected period for housing: %q (expected year or month)", dp.Period)
 			}
 			return nil
 		},
@@ -291,12 +306,17 @@ var UtilitiesRules = []Rule{
 		Severity: SeverityWarning,
 		Validator: func(dp *models.CostDataPoint) error {
 			validUnits := map[string]bool{
-				"AED/month": true,
-				"AED/kWh":   true,
-				"AED/unit":  true,
+				units.KWh:            true,
+				units.CubicMetre:     true,
+				units.ImperialGallon: true,
+				units.Fraction:       true,
+			}
+			// Fixed charges such as meter rent are billed per period
+			if dp.Unit == "" && dp.Period != "" {
+				return nil
 			}
 			if !validUnits[dp.Unit] {
-				return fmt.Errorf("unexpected unit for utilities: %s", dp.Unit)
+				return fmt.Errorf("unexpected unit for utilities: %q", dp.Unit)
 			}
 			return nil
 		},
@@ -373,18 +393,13 @@ var EducationRules = []Rule{
 		},
 	},
 	{
-		Name:     "education_unit",
+		Name:     "education_period",
 		Category: "Education",
-		Field:    "Unit",
+		Field:    "Period",
 		Severity: SeverityWarning,
 		Validator: func(dp *models.CostDataPoint) error {
-			validUnits := map[string]bool{
-				"AED/year":     true,
-				"AED/semester": true,
-				"AED/term":     true,
-			}
-			if !validUnits[dp.Unit] {
-				return fmt.Errorf("unexpected unit for education: %s", dp.Unit)
+			if dp.Period != units.Year && dp.Period != units.Month {
+				return fmt.Errorf("unexpected period for education: %q (expected year or month)", dp.Period)
 			}
 			return nil
 		},
@@ -18,7 +18,8 @@ func createTestDataPoint(category, itemName, emirate string, price float64) *mod
 		RecordedAt: time.Now(),
 		Source:     "TestSource",
 		Confidence: 0.8,
-		Unit:       "AED/year",
+		Currency:   "AED",
+		Period:     "year",
 		SampleSize: 1,
 	}
 }
@@ -91,6 +92,24 @@ func TestValidateDataPoint_Invalid(t *testing.T) {
 			dp:            createTestDataPoint("InvalidCategory", "Item", "Dubai", 100),
 			expectedError: true,
 		},
+		{
+			name: "legacy unit",
+			dp: func() *models.CostDataPoint {
+				dp := createTestDataPoint("Housing", "Studio", "Dubai", 50000)
+				dp.Unit = "AED/year"
+				return dp
+			}(),
+			expectedError: true,
+		},
+		{
+			name: "missing currency",
+			dp: func() *models.CostDataPoint {
+				dp := createTestDataPoint("Housing", "Studio", "Dubai", 50000)
+				dp.Currency = ""
+				return dp
+			}(),
+			expectedError: true,
+		},
 	}
 
 	for _, tt := range tests {
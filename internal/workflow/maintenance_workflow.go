@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/workflow"
+
+	"github.com/adonese/cost-of-living/internal/services"
+	"github.com/adonese/cost-of-living/pkg/logger"
+)
+
+// RetentionActivity applies the compression policy and archives and deletes
+// expired cost data points
+func RetentionActivity(ctx context.Context) (*services.RetentionResult, error) {
+	deps := GetActivityDependencies()
+	if deps == nil || deps.Retention == nil {
+		return nil, fmt.Errorf("retention service not configured")
+	}
+
+	result, err := deps.Retention.Run(ctx)
+	if err != nil {
+		return result, fmt.Errorf("retention failed: %w", err)
+	}
+
+	logger.Info("Retention activity completed",
+		"expired", result.Expired,
+		"scopes", len(result.Scopes))
+
+	return result, nil
+}
+
+// MaintenanceWorkflow runs the retention job. Schedule it with a cron
+// schedule, e.g. daily during off-peak hours.
+func MaintenanceWorkflow(ctx workflow.Context) (*services.RetentionResult, error) {
+	ao := workflow.ActivityOptions{
+		StartToCloseTimeout: 2 * time.Hour,
+		RetryPolicy: &temporal.RetryPolicy{
+			InitialInterval:    time.Minute,
+			BackoffCoefficient: 2.0,
+			MaximumInterval:    30 * time.Minute,
+			MaximumAttempts:    3,
+		},
+	}
+	ctx = workflow.WithActivityOptions(ctx, ao)
+
+	var result services.RetentionResult
+	if err := workflow.ExecuteActivity(ctx, RetentionActivity).Get(ctx, &result); err != nil {
+		workflow.GetLogger(ctx).Error("Maintenance workflow failed", "error", err)
+		return nil, err
+	}
+
+	return &result, nil
+}
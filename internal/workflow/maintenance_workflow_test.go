@@ -0,0 +1,31 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.temporal.io/sdk/testsuite"
+
+	"github.com/adonese/cost-of-living/internal/services"
+)
+
+func TestMaintenanceWorkflow(t *testing.T) {
+	testSuite := &testsuite.WorkflowTestSuite{}
+	env := testSuite.NewTestWorkflowEnvironment()
+
+	env.OnActivity(RetentionActivity, mock.Anything).Return(&services.RetentionResult{
+		Expired: 42,
+		Scopes:  []services.RetentionScopeResult{{Category: "Housing", Expired: 42}},
+	}, nil)
+
+	env.ExecuteWorkflow(MaintenanceWorkflow)
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.NoError(t, env.GetWorkflowError())
+
+	var result services.RetentionResult
+	require.NoError(t, env.GetWorkflowResult(&result))
+	require.Equal(t, int64(42), result.Expired)
+	require.Equal(t, "Housing", result.Scopes[0].Category)
+}
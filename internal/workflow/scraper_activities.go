@@ -14,6 +14,7 @@ import (
 
 type ScraperActivityResult struct {
 	ScraperName    string
+	RunID          string
 	ItemsFetched   int
 	ItemsScraped   int
 	ItemsValidated int
@@ -27,6 +28,9 @@ type ScraperActivityResult struct {
 type ScraperActivityDependencies struct {
 	ScraperService *services.ScraperService
 	Repository     repository.CostDataPointRepository
+	RunRepository  repository.ScrapeRunRepository
+	Compensation   *services.CompensationService
+	Retention      *services.RetentionService
 }
 
 var dependencies *ScraperActivityDependencies
@@ -47,7 +51,7 @@ func RunScraperActivity(ctx context.Context, scraperName string) (*ScraperActivi
 
 	start := time.Now()
 
-	serviceResult, err := dependencies.ScraperService.RunScraper(ctx, scraperName)
+	serviceResult, err := dependencies.ScraperService.RunScraperWithMetadata(ctx, scraperName, runMetadataFromContext(ctx))
 
 	result := &ScraperActivityResult{
 		ScraperName: scraperName,
@@ -55,6 +59,7 @@ func RunScraperActivity(ctx context.Context, scraperName string) (*ScraperActivi
 	}
 
 	if serviceResult != nil {
+		result.RunID = serviceResult.RunID
 		result.ItemsFetched = serviceResult.Fetched
 		result.ItemsScraped = serviceResult.Fetched
 		result.ItemsValidated = serviceResult.Validation.Valid
@@ -82,6 +87,7 @@ func RunScraperActivity(ctx context.Context, scraperName string) (*ScraperActivi
 
 	logger.Info("Scraper activity completed",
 		"scraper", scraperName,
+		"run_id", result.RunID,
 		"duration", result.Duration,
 		"fetched", result.ItemsFetched,
 		"scraped", result.ItemsScraped,
@@ -92,25 +98,57 @@ func RunScraperActivity(ctx context.Context, scraperName string) (*ScraperActivi
 	return result, nil
 }
 
-// CompensateFailedScrapeActivity performs compensation actions when a scrape fails
+// runMetadataFromContext extracts the Temporal workflow identifiers of the
+// calling activity so they can be recorded on the scrape run.
+func runMetadataFromContext(ctx context.Context) services.RunMetadata {
+	if !activity.IsActivity(ctx) {
+		return services.RunMetadata{}
+	}
+
+	info := activity.GetInfo(ctx)
+	return services.RunMetadata{
+		WorkflowID:    info.WorkflowExecution.ID,
+		WorkflowRunID: info.WorkflowExecution.RunID,
+	}
+}
+
+// CompensateFailedScrapeActivity performs compensation actions when a scrape fails.
+// Every failed or unfinished run of the scraper within the calling workflow
+// execution, including one that saved only part of its data points, has its
+// data invalidated or deleted, the outcome is recorded on the run and
+// operators are notified.
 func CompensateFailedScrapeActivity(ctx context.Context, scraperName string) (bool, error) {
 	logger.Info("Compensating failed scrape", "scraper", scraperName)
 
-	// Compensation logic:
-	// 1. Mark failed scrape in database (could add a scrape_runs table)
-	// 2. Clean up partial data if needed
-	// 3. Send alert notification
+	deps := GetActivityDependencies()
+	if deps == nil || deps.Compensation == nil {
+		logger.Warn("Compensation skipped, no compensation service configured",
+			"scraper", scraperName,
+			"timestamp", time.Now())
+		return true, nil
+	}
+
+	filter := repository.ScrapeRunFilter{ScraperName: scraperName}
+	meta := runMetadataFromContext(ctx)
+	if meta.WorkflowID != "" {
+		filter.WorkflowID = meta.WorkflowID
+		filter.WorkflowRunID = meta.WorkflowRunID
+	} else {
+		// Outside of a workflow only look at recent runs of this scraper
+		since := time.Now().Add(-24 * time.Hour)
+		filter.StartedAfter = &since
+	}
+
+	result, err := deps.Compensation.CompensateRuns(ctx, filter)
+	if err != nil {
+		return false, fmt.Errorf("compensation failed: %w", err)
+	}
 
-	// For now, just log the failure
 	logger.Warn("Compensation executed for failed scrape",
 		"scraper", scraperName,
-		"timestamp", time.Now())
-
-	// In a production system, you might want to:
-	// - Record the failure in a failures table
-	// - Delete any partial data from this scrape run
-	// - Send notifications to operators
-	// - Update monitoring dashboards
+		"runs", len(result.Runs),
+		"rows", result.RowsAffected,
+		"action", result.Action)
 
 	return true, nil
 }
@@ -1,6 +1,8 @@
 package workflow
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -8,6 +10,12 @@ import (
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"go.temporal.io/sdk/testsuite"
+
+	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/repository"
+	repomock "github.com/adonese/cost-of-living/internal/repository/mock"
+	"github.com/adonese/cost-of-living/internal/services"
+	"github.com/adonese/cost-of-living/pkg/logger"
 )
 
 func TestScraperWorkflow(t *testing.T) {
@@ -65,6 +73,51 @@ func TestScraperWorkflowWithError(t *testing.T) {
 	// The error itself is sufficient to show failure handling works
 }
 
+func TestScraperWorkflowCompensatesPartialSave(t *testing.T) {
+	logger.Init()
+
+	repo := repomock.NewCostDataPointRepository()
+	runs := repomock.NewScrapeRunRepository()
+	scraperService := services.NewScraperServiceWithConfig(
+		&partialSaveRepository{CostDataPointRepository: repo, keep: 1},
+		&services.ScraperServiceConfig{EnableValidation: false, ValidateBeforeSave: false},
+	)
+	scraperService.SetRunRepository(runs)
+	scraperService.RegisterScraper(&stubScraper{name: "bayut", points: []*models.CostDataPoint{
+		workflowTestPoint("2BR in Marina"),
+		workflowTestPoint("1BR in JLT"),
+	}})
+
+	previous := GetActivityDependencies()
+	SetActivityDependencies(&ScraperActivityDependencies{
+		ScraperService: scraperService,
+		Repository:     repo,
+		RunRepository:  runs,
+		Compensation:   services.NewCompensationService(repo, runs, nil, models.CompensationDelete),
+	})
+	t.Cleanup(func() { SetActivityDependencies(previous) })
+
+	testSuite := &testsuite.WorkflowTestSuite{}
+	env := testSuite.NewTestWorkflowEnvironment()
+	env.RegisterActivity(RunScraperActivity)
+	env.RegisterActivity(CompensateFailedScrapeActivity)
+
+	env.ExecuteWorkflow(ScraperWorkflow, ScraperWorkflowInput{ScraperName: "bayut", MaxRetries: 1})
+
+	require.True(t, env.IsWorkflowCompleted())
+	require.ErrorContains(t, env.GetWorkflowError(), services.ErrSaveFailed.Error())
+
+	// The half that was saved is rolled back with its run
+	stored, err := repo.List(context.Background(), repository.ListFilter{Limit: 10})
+	require.NoError(t, err)
+	require.Empty(t, stored)
+
+	recorded, err := runs.List(context.Background(), repository.ScrapeRunFilter{ScraperName: "bayut"})
+	require.NoError(t, err)
+	require.Len(t, recorded, 1)
+	require.Equal(t, models.ScrapeRunCompensated, recorded[0].Status)
+}
+
 func TestScheduledScraperWorkflow(t *testing.T) {
 	testSuite := &testsuite.WorkflowTestSuite{}
 	env := testSuite.NewTestWorkflowEnvironment()
@@ -84,3 +137,53 @@ func TestScheduledScraperWorkflow(t *testing.T) {
 	require.True(t, env.IsWorkflowCompleted())
 	require.NoError(t, env.GetWorkflowError())
 }
+
+type stubScraper struct {
+	name   string
+	points []*models.CostDataPoint
+}
+
+func (s *stubScraper) Name() string { return s.name }
+
+func (s *stubScraper) Scrape(ctx context.Context) ([]*models.CostDataPoint, error) {
+	return s.points, nil
+}
+
+func (s *stubScraper) CanScrape() bool { return true }
+
+// partialSaveRepository saves the first keep data points of a batch and
+// fails the rest
+type partialSaveRepository struct {
+	repository.CostDataPointRepository
+	keep int
+}
+
+func (r *partialSaveRepository) Upsert(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	kept := cdps
+	if len(kept) > r.keep {
+		kept = kept[:r.keep]
+	}
+	result, err := r.CostDataPointRepository.Upsert(ctx, kept)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(kept); i < len(cdps); i++ {
+		result.Failed = append(result.Failed, repository.BatchError{Index: i, Err: errors.New("forced failure")})
+	}
+	return result, nil
+}
+
+func workflowTestPoint(name string) *models.CostDataPoint {
+	now := time.Now()
+	return &models.CostDataPoint{
+		Category:   "Housing",
+		ItemName:   name,
+		Price:      100,
+		SampleSize: 1,
+		RecordedAt: now,
+		ValidFrom:  now,
+		Source:     "test",
+		Confidence: 1,
+		Unit:       "AED",
+	}
+}
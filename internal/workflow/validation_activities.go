@@ -97,27 +97,18 @@ func ValidateScraperDataActivity(ctx context.Context, scraperName string, since
 
 	validator := validation.NewValidator()
 
-	// Get data points from this scraper (use List with filters)
-	// Note: The current repository interface doesn't support filtering by source directly
-	// We'll get all recent data and filter in memory
+	// Get data points from this scraper
 	filter := repository.ListFilter{
+		Source:    scraperName,
 		StartDate: &since,
 		Limit:     10000,
 		Offset:    0,
 	}
-	allPoints, err := deps.Repository.List(ctx, filter)
+	dataPoints, err := deps.Repository.List(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get data: %w", err)
 	}
 
-	// Filter by scraper name (source)
-	dataPoints := make([]*models.CostDataPoint, 0)
-	for _, dp := range allPoints {
-		if dp.Source == scraperName {
-			dataPoints = append(dataPoints, dp)
-		}
-	}
-
 	if len(dataPoints) == 0 {
 		return &ValidationStats{
 			TotalValidated: 0,
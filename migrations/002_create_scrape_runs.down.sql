@@ -0,0 +1,7 @@
+-- Remove run linkage from cost data points
+DROP INDEX IF EXISTS idx_cost_data_points_run_id;
+ALTER TABLE cost_data_points DROP COLUMN IF EXISTS run_id;
+
+-- Drop scrape_runs table and its trigger
+DROP TRIGGER IF EXISTS update_scrape_runs_updated_at ON scrape_runs;
+DROP TABLE IF EXISTS scrape_runs;
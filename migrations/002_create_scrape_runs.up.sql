@@ -0,0 +1,31 @@
+-- Create scrape_runs table to audit every scraper execution
+CREATE TABLE IF NOT EXISTS scrape_runs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    scraper_name VARCHAR(255) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'running',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ,
+    fetched INTEGER NOT NULL DEFAULT 0,
+    validated INTEGER NOT NULL DEFAULT 0,
+    saved INTEGER NOT NULL DEFAULT 0,
+    save_failures INTEGER NOT NULL DEFAULT 0,
+    validation JSONB NOT NULL DEFAULT '{}'::jsonb,
+    errors TEXT[],
+    workflow_id VARCHAR(255),
+    workflow_run_id VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_scrape_runs_scraper_started ON scrape_runs(scraper_name, started_at DESC);
+CREATE INDEX idx_scrape_runs_status ON scrape_runs(status);
+CREATE INDEX idx_scrape_runs_workflow ON scrape_runs(workflow_id, workflow_run_id);
+
+CREATE TRIGGER update_scrape_runs_updated_at
+    BEFORE UPDATE ON scrape_runs
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Link every cost data point to the run that produced it
+ALTER TABLE cost_data_points ADD COLUMN IF NOT EXISTS run_id UUID;
+CREATE INDEX idx_cost_data_points_run_id ON cost_data_points(run_id);
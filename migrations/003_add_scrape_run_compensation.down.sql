@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_cost_data_points_valid_to;
+
+ALTER TABLE scrape_runs DROP COLUMN IF EXISTS compensation_error;
+ALTER TABLE scrape_runs DROP COLUMN IF EXISTS compensated_rows;
+ALTER TABLE scrape_runs DROP COLUMN IF EXISTS compensation_action;
+ALTER TABLE scrape_runs DROP COLUMN IF EXISTS compensated_at;
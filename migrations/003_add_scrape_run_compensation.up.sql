@@ -0,0 +1,8 @@
+-- Record the outcome of compensating a failed scrape run
+ALTER TABLE scrape_runs ADD COLUMN IF NOT EXISTS compensated_at TIMESTAMPTZ;
+ALTER TABLE scrape_runs ADD COLUMN IF NOT EXISTS compensation_action VARCHAR(32);
+ALTER TABLE scrape_runs ADD COLUMN IF NOT EXISTS compensated_rows BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE scrape_runs ADD COLUMN IF NOT EXISTS compensation_error TEXT;
+
+-- Speed up "active rows only" queries used after invalidating a run
+CREATE INDEX idx_cost_data_points_valid_to ON cost_data_points(valid_to);
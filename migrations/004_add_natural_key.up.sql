@@ -0,0 +1,26 @@
+-- Stable identity of a listing or tariff, used to make scraper re-runs idempotent.
+-- Mirrors models.CostDataPoint.NaturalKey.
+ALTER TABLE cost_data_points ADD COLUMN IF NOT EXISTS natural_key TEXT;
+
+UPDATE cost_data_points SET natural_key = CASE
+    WHEN category = 'Housing'
+        AND COALESCE(NULLIF(attributes->>'listing_id', ''), NULLIF(source_url, '')) IS NOT NULL
+    THEN source || '|listing|' || COALESCE(
+        NULLIF(attributes->>'listing_id', ''),
+        rtrim(split_part(split_part(source_url, '#', 1), '?', 1), '/')
+    )
+    ELSE lower(
+        source || '|item|' || btrim(item_name)
+        || '|' || COALESCE(location->>'emirate', '')
+        || '|' || COALESCE(location->>'city', '')
+        || '|' || COALESCE(location->>'area', '')
+    )
+END
+WHERE natural_key IS NULL;
+
+-- Unique indexes on a hypertable must include the partitioning column, so the
+-- key cannot be unique on its own. Uniqueness across runs is enforced by the
+-- repository's Upsert, which serialises writers per key with an advisory lock;
+-- this index guards against exact duplicates and serves the key lookup.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cost_data_points_natural_key
+    ON cost_data_points(natural_key, recorded_at DESC);
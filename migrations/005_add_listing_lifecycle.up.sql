@@ -0,0 +1,18 @@
+-- Track when each listing was first and most recently seen by a scraper
+ALTER TABLE cost_data_points ADD COLUMN IF NOT EXISTS first_seen_at TIMESTAMPTZ;
+ALTER TABLE cost_data_points ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMPTZ;
+
+UPDATE cost_data_points
+SET first_seen_at = COALESCE(first_seen_at, valid_from, recorded_at),
+    last_seen_at = COALESCE(last_seen_at, updated_at, recorded_at)
+WHERE first_seen_at IS NULL OR last_seen_at IS NULL;
+
+ALTER TABLE cost_data_points ALTER COLUMN first_seen_at SET DEFAULT NOW();
+ALTER TABLE cost_data_points ALTER COLUMN first_seen_at SET NOT NULL;
+ALTER TABLE cost_data_points ALTER COLUMN last_seen_at SET DEFAULT NOW();
+ALTER TABLE cost_data_points ALTER COLUMN last_seen_at SET NOT NULL;
+
+-- Supports closing listings that were not seen in the latest scrape of a scope
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_active_last_seen
+    ON cost_data_points(source, category, last_seen_at)
+    WHERE valid_to IS NULL;
@@ -0,0 +1,3 @@
+-- Dropping a continuous aggregate also removes its refresh policy
+DROP MATERIALIZED VIEW IF EXISTS cost_data_points_weekly;
+DROP MATERIALIZED VIEW IF EXISTS cost_data_points_daily;
@@ -0,0 +1,72 @@
+-- Daily and weekly price statistics per category, sub category, emirate and
+-- area, maintained by TimescaleDB continuous aggregates.
+--
+-- percentile_cont is an ordered-set aggregate, which continuous aggregates
+-- support from TimescaleDB 2.7. The views are created WITH NO DATA because a
+-- populating refresh cannot run inside the migration transaction; the
+-- policies below backfill them on their first run, or run
+--   CALL refresh_continuous_aggregate('cost_data_points_daily', NULL, NULL);
+-- to materialise existing history immediately.
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS cost_data_points_daily
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket(INTERVAL '1 day', recorded_at) AS bucket,
+    category,
+    sub_category,
+    location->>'emirate' AS emirate,
+    location->>'area' AS area,
+    COUNT(*) AS sample_count,
+    AVG(price)::DOUBLE PRECISION AS avg_price,
+    MIN(price)::DOUBLE PRECISION AS min_price,
+    MAX(price)::DOUBLE PRECISION AS max_price,
+    percentile_cont(0.25) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS p25_price,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS median_price,
+    percentile_cont(0.75) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS p75_price,
+    stddev_samp(price::DOUBLE PRECISION) AS stddev_price
+FROM cost_data_points
+GROUP BY bucket, category, sub_category, location->>'emirate', location->>'area'
+WITH NO DATA;
+
+-- Percentiles cannot be combined across buckets, so the weekly view reads the
+-- raw hypertable rather than the daily view
+CREATE MATERIALIZED VIEW IF NOT EXISTS cost_data_points_weekly
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket(INTERVAL '1 week', recorded_at) AS bucket,
+    category,
+    sub_category,
+    location->>'emirate' AS emirate,
+    location->>'area' AS area,
+    COUNT(*) AS sample_count,
+    AVG(price)::DOUBLE PRECISION AS avg_price,
+    MIN(price)::DOUBLE PRECISION AS min_price,
+    MAX(price)::DOUBLE PRECISION AS max_price,
+    percentile_cont(0.25) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS p25_price,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS median_price,
+    percentile_cont(0.75) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS p75_price,
+    stddev_samp(price::DOUBLE PRECISION) AS stddev_price
+FROM cost_data_points
+GROUP BY bucket, category, sub_category, location->>'emirate', location->>'area'
+WITH NO DATA;
+
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_daily_lookup
+    ON cost_data_points_daily(category, emirate, bucket DESC);
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_weekly_lookup
+    ON cost_data_points_weekly(category, emirate, bucket DESC);
+
+-- Refresh recent buckets; late corrections older than the start offset need
+-- a manual refresh_continuous_aggregate call
+SELECT add_continuous_aggregate_policy('cost_data_points_daily',
+    start_offset => INTERVAL '30 days',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '1 hour',
+    if_not_exists => TRUE
+);
+
+SELECT add_continuous_aggregate_policy('cost_data_points_weekly',
+    start_offset => INTERVAL '12 weeks',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '6 hours',
+    if_not_exists => TRUE
+);
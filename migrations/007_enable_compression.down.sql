@@ -0,0 +1,6 @@
+SELECT remove_compression_policy('cost_data_points', if_exists => TRUE);
+
+SELECT decompress_chunk(c, if_compressed => TRUE)
+FROM show_chunks('cost_data_points') c;
+
+ALTER TABLE cost_data_points SET (timescaledb.compress = false);
@@ -0,0 +1,12 @@
+-- Enable native compression on the hypertable. Chunks are compressed by a
+-- policy installed by the maintenance job (COMPRESS_AFTER_DAYS), so the age
+-- threshold can change without a migration.
+--
+-- Segmenting by source and category keeps per-scraper and per-category scans
+-- cheap on compressed chunks. Updates and deletes on compressed chunks, which
+-- listing refreshes and retention rely on, need TimescaleDB 2.11 or later.
+ALTER TABLE cost_data_points SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'source, category',
+    timescaledb.compress_orderby = 'recorded_at DESC, id'
+);
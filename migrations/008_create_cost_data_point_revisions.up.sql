@@ -0,0 +1,19 @@
+-- Record every manual update and delete of a cost data point so corrections
+-- to scraped prices can be traced back to who made them and why
+CREATE TABLE IF NOT EXISTS cost_data_point_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    data_point_id UUID NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL,
+    action VARCHAR(16) NOT NULL CHECK (action IN ('update', 'delete')),
+    actor VARCHAR(255) NOT NULL,
+    reason TEXT,
+    changed_fields TEXT[],
+    old_values JSONB,
+    new_values JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cost_data_point_revisions_data_point
+    ON cost_data_point_revisions(data_point_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_cost_data_point_revisions_actor
+    ON cost_data_point_revisions(actor, created_at DESC);
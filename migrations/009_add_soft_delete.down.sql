@@ -0,0 +1,76 @@
+-- Rebuild the rollups over every row, as created by 006
+DROP MATERIALIZED VIEW IF EXISTS cost_data_points_weekly;
+DROP MATERIALIZED VIEW IF EXISTS cost_data_points_daily;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS cost_data_points_daily
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket(INTERVAL '1 day', recorded_at) AS bucket,
+    category,
+    sub_category,
+    location->>'emirate' AS emirate,
+    location->>'area' AS area,
+    COUNT(*) AS sample_count,
+    AVG(price)::DOUBLE PRECISION AS avg_price,
+    MIN(price)::DOUBLE PRECISION AS min_price,
+    MAX(price)::DOUBLE PRECISION AS max_price,
+    percentile_cont(0.25) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS p25_price,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS median_price,
+    percentile_cont(0.75) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS p75_price,
+    stddev_samp(price::DOUBLE PRECISION) AS stddev_price
+FROM cost_data_points
+GROUP BY bucket, category, sub_category, location->>'emirate', location->>'area'
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS cost_data_points_weekly
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket(INTERVAL '1 week', recorded_at) AS bucket,
+    category,
+    sub_category,
+    location->>'emirate' AS emirate,
+    location->>'area' AS area,
+    COUNT(*) AS sample_count,
+    AVG(price)::DOUBLE PRECISION AS avg_price,
+    MIN(price)::DOUBLE PRECISION AS min_price,
+    MAX(price)::DOUBLE PRECISION AS max_price,
+    percentile_cont(0.25) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS p25_price,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS median_price,
+    percentile_cont(0.75) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS p75_price,
+    stddev_samp(price::DOUBLE PRECISION) AS stddev_price
+FROM cost_data_points
+GROUP BY bucket, category, sub_category, location->>'emirate', location->>'area'
+WITH NO DATA;
+
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_daily_lookup
+    ON cost_data_points_daily(category, emirate, bucket DESC);
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_weekly_lookup
+    ON cost_data_points_weekly(category, emirate, bucket DESC);
+
+-- Refresh recent buckets; late corrections older than the start offset need
+-- a manual refresh_continuous_aggregate call
+SELECT add_continuous_aggregate_policy('cost_data_points_daily',
+    start_offset => INTERVAL '30 days',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '1 hour',
+    if_not_exists => TRUE
+);
+
+SELECT add_continuous_aggregate_policy('cost_data_points_weekly',
+    start_offset => INTERVAL '12 weeks',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '6 hours',
+    if_not_exists => TRUE
+);
+
+-- Restore revisions cannot be represented once the constraint is narrowed
+DELETE FROM cost_data_point_revisions WHERE action = 'restore';
+ALTER TABLE cost_data_point_revisions
+    DROP CONSTRAINT IF EXISTS cost_data_point_revisions_action_check;
+ALTER TABLE cost_data_point_revisions
+    ADD CONSTRAINT cost_data_point_revisions_action_check
+    CHECK (action IN ('update', 'delete'));
+
+-- Soft-deleted rows become visible again
+DROP INDEX IF EXISTS idx_cost_data_points_deleted_at;
+ALTER TABLE cost_data_points DROP COLUMN IF EXISTS deleted_at;
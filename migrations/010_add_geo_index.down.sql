@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_cost_data_points_coordinates;
+DROP INDEX IF EXISTS idx_cost_data_points_coordinates_earth;
+
+DROP EXTENSION IF EXISTS earthdistance;
+DROP EXTENSION IF EXISTS cube;
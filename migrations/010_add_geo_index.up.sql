@@ -0,0 +1,17 @@
+-- Radius and bounding-box queries over location->'coordinates'. The
+-- earthdistance extension maps a lat/lon pair onto a cube so radius searches
+-- can use a GiST index; rows without coordinates index as NULL.
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_coordinates_earth
+    ON cost_data_points USING GIST (ll_to_earth(
+        (location->'coordinates'->>'lat')::DOUBLE PRECISION,
+        (location->'coordinates'->>'lon')::DOUBLE PRECISION
+    ));
+
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_coordinates
+    ON cost_data_points(
+        ((location->'coordinates'->>'lat')::DOUBLE PRECISION),
+        ((location->'coordinates'->>'lon')::DOUBLE PRECISION)
+    );
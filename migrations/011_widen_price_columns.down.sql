@@ -0,0 +1,89 @@
+-- Restore the DECIMAL(10, 2) price columns. Run after 012 down has put
+-- water prices back on their per-1000-gallon scale, rounding only the
+-- rows priced in fractions of a fils.
+DROP MATERIALIZED VIEW IF EXISTS cost_data_points_weekly;
+DROP MATERIALIZED VIEW IF EXISTS cost_data_points_daily;
+
+SELECT remove_compression_policy('cost_data_points', if_exists => TRUE);
+
+SELECT decompress_chunk(c, if_compressed => TRUE)
+FROM show_chunks('cost_data_points') c;
+
+ALTER TABLE cost_data_points SET (timescaledb.compress = false);
+
+ALTER TABLE cost_data_points
+    ALTER COLUMN price TYPE DECIMAL(10, 2),
+    ALTER COLUMN min_price TYPE DECIMAL(10, 2),
+    ALTER COLUMN max_price TYPE DECIMAL(10, 2),
+    ALTER COLUMN median_price TYPE DECIMAL(10, 2);
+
+-- Compression settings as in 007; the maintenance job reinstalls the policy
+ALTER TABLE cost_data_points SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'source, category',
+    timescaledb.compress_orderby = 'recorded_at DESC, id'
+);
+
+-- Rollups as created by 009
+CREATE MATERIALIZED VIEW IF NOT EXISTS cost_data_points_daily
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket(INTERVAL '1 day', recorded_at) AS bucket,
+    category,
+    sub_category,
+    location->>'emirate' AS emirate,
+    location->>'area' AS area,
+    COUNT(*) AS sample_count,
+    AVG(price)::DOUBLE PRECISION AS avg_price,
+    MIN(price)::DOUBLE PRECISION AS min_price,
+    MAX(price)::DOUBLE PRECISION AS max_price,
+    percentile_cont(0.25) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS p25_price,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS median_price,
+    percentile_cont(0.75) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS p75_price,
+    stddev_samp(price::DOUBLE PRECISION) AS stddev_price
+FROM cost_data_points
+WHERE deleted_at IS NULL
+GROUP BY bucket, category, sub_category, location->>'emirate', location->>'area'
+WITH NO DATA;
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS cost_data_points_weekly
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket(INTERVAL '1 week', recorded_at) AS bucket,
+    category,
+    sub_category,
+    location->>'emirate' AS emirate,
+    location->>'area' AS area,
+    COUNT(*) AS sample_count,
+    AVG(price)::DOUBLE PRECISION AS avg_price,
+    MIN(price)::DOUBLE PRECISION AS min_price,
+    MAX(price)::DOUBLE PRECISION AS max_price,
+    percentile_cont(0.25) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS p25_price,
+    percentile_cont(0.5) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS median_price,
+    percentile_cont(0.75) WITHIN GROUP (ORDER BY price::DOUBLE PRECISION) AS p75_price,
+    stddev_samp(price::DOUBLE PRECISION) AS stddev_price
+FROM cost_data_points
+WHERE deleted_at IS NULL
+GROUP BY bucket, category, sub_category, location->>'emirate', location->>'area'
+WITH NO DATA;
+
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_daily_lookup
+    ON cost_data_points_daily(category, emirate, bucket DESC);
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_weekly_lookup
+    ON cost_data_points_weekly(category, emirate, bucket DESC);
+
+-- Refresh recent buckets; late corrections older than the start offset need
+-- a manual refresh_continuous_aggregate call
+SELECT add_continuous_aggregate_policy('cost_data_points_daily',
+    start_offset => INTERVAL '30 days',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '1 hour',
+    if_not_exists => TRUE
+);
+
+SELECT add_continuous_aggregate_policy('cost_data_points_weekly',
+    start_offset => INTERVAL '12 weeks',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '6 hours',
+    if_not_exists => TRUE
+);
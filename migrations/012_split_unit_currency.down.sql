@@ -0,0 +1,22 @@
+-- Fold currency and period back into unit. Prices are restored to the
+-- scale each scraper used before 012; rows in other currencies are kept as
+-- their converted amounts and lose the currency.
+UPDATE cost_data_points SET
+    price = price * 1000,
+    min_price = min_price * 1000,
+    max_price = max_price * 1000,
+    median_price = median_price * 1000,
+    unit = CASE source WHEN 'sewa_official' THEN 'AED per 1000 gallons' ELSE 'AED per 1000 IG' END
+WHERE unit = 'IG' AND source IN ('aadc_official', 'sewa_official');
+
+UPDATE cost_data_points SET unit = 'AED per kWh'
+WHERE unit = 'kWh' AND source IN ('aadc_official', 'sewa_official');
+
+UPDATE cost_data_points SET unit = 'AED/km' WHERE unit = 'km' AND source = 'rta_official';
+UPDATE cost_data_points SET unit = 'AED/minute' WHERE unit = 'minute' AND source = 'rta_official';
+UPDATE cost_data_points SET unit = 'percentage of water charge' WHERE unit = 'fraction';
+UPDATE cost_data_points SET unit = 'AED' WHERE unit IN ('', 'kWh', 'm3', 'IG', 'km', 'minute', 'trip');
+
+ALTER TABLE cost_data_points ALTER COLUMN unit SET DEFAULT 'AED';
+ALTER TABLE cost_data_points DROP COLUMN IF EXISTS period;
+ALTER TABLE cost_data_points DROP COLUMN IF EXISTS currency;
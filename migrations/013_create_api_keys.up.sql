@@ -0,0 +1,16 @@
+-- API keys for authenticating writes. Only the SHA-256 hash of a key is
+-- stored; the prefix is the public part of the key used to look it up and
+-- to identify it as the actor of a change.
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(32) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    role VARCHAR(32) NOT NULL CHECK (role IN ('reader', 'contributor', 'admin')),
+    created_by VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_prefix ON api_keys(prefix);
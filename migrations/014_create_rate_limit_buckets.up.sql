@@ -0,0 +1,11 @@
+-- Token buckets shared by API instances when RATE_LIMIT_STORE=postgres.
+-- Losing them in a crash only resets the limits, so the table is unlogged.
+-- There is no SQLite equivalent: SQLite deployments run a single instance
+-- and use the in-memory store.
+CREATE UNLOGGED TABLE IF NOT EXISTS rate_limit_buckets (
+    key TEXT PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_cost_data_points_closed_by_run_id;
+
+ALTER TABLE cost_data_points DROP COLUMN IF EXISTS closed_by_run_id;
@@ -0,0 +1,7 @@
+-- Record which scrape run closed a row by upserting a new price for its
+-- listing, so that compensating the run can reopen the row it superseded
+ALTER TABLE cost_data_points ADD COLUMN IF NOT EXISTS closed_by_run_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_closed_by_run_id
+    ON cost_data_points(closed_by_run_id)
+    WHERE closed_by_run_id IS NOT NULL;
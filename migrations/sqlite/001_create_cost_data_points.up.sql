@@ -0,0 +1,37 @@
+-- SQLite schema for cost_data_points, mirroring migrations/001 without the
+-- TimescaleDB hypertable. Timestamps are stored as UTC text in a fixed-width
+-- format so they sort and compare correctly; location and attributes are JSON
+-- text queried with json_extract, and tags is a JSON array.
+CREATE TABLE IF NOT EXISTS cost_data_points (
+    id TEXT NOT NULL,
+    category TEXT NOT NULL,
+    sub_category TEXT,
+    item_name TEXT NOT NULL,
+    price REAL NOT NULL,
+    min_price REAL,
+    max_price REAL,
+    median_price REAL,
+    sample_size INTEGER DEFAULT 1,
+    location TEXT NOT NULL CHECK (json_valid(location)),
+    recorded_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    valid_from TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    valid_to TIMESTAMP,
+    source TEXT NOT NULL,
+    source_url TEXT,
+    confidence REAL DEFAULT 1.0 CHECK (confidence >= 0 AND confidence <= 1),
+    unit TEXT NOT NULL DEFAULT 'AED',
+    tags TEXT CHECK (tags IS NULL OR json_valid(tags)),
+    attributes TEXT DEFAULT '{}' CHECK (attributes IS NULL OR json_valid(attributes)),
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    PRIMARY KEY (id, recorded_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_category ON cost_data_points(category);
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_sub_category ON cost_data_points(sub_category);
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_item_name ON cost_data_points(item_name);
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_recorded_at ON cost_data_points(recorded_at DESC);
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_location_emirate
+    ON cost_data_points(json_extract(location, '$.emirate'));
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_location_city
+    ON cost_data_points(json_extract(location, '$.city'));
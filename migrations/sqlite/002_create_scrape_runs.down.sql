@@ -0,0 +1,5 @@
+-- Remove run linkage from cost data points
+DROP INDEX IF EXISTS idx_cost_data_points_run_id;
+ALTER TABLE cost_data_points DROP COLUMN run_id;
+
+DROP TABLE IF EXISTS scrape_runs;
@@ -0,0 +1,27 @@
+-- Create scrape_runs table to audit every scraper execution. IDs are
+-- generated by the repository; errors is a JSON array.
+CREATE TABLE IF NOT EXISTS scrape_runs (
+    id TEXT PRIMARY KEY,
+    scraper_name TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'running',
+    started_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    finished_at TIMESTAMP,
+    fetched INTEGER NOT NULL DEFAULT 0,
+    validated INTEGER NOT NULL DEFAULT 0,
+    saved INTEGER NOT NULL DEFAULT 0,
+    save_failures INTEGER NOT NULL DEFAULT 0,
+    validation TEXT NOT NULL DEFAULT '{}' CHECK (json_valid(validation)),
+    errors TEXT CHECK (errors IS NULL OR json_valid(errors)),
+    workflow_id TEXT,
+    workflow_run_id TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_scrape_runs_scraper_started ON scrape_runs(scraper_name, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_scrape_runs_status ON scrape_runs(status);
+CREATE INDEX IF NOT EXISTS idx_scrape_runs_workflow ON scrape_runs(workflow_id, workflow_run_id);
+
+-- Link every cost data point to the run that produced it
+ALTER TABLE cost_data_points ADD COLUMN run_id TEXT;
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_run_id ON cost_data_points(run_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_cost_data_points_valid_to;
+
+ALTER TABLE scrape_runs DROP COLUMN compensation_error;
+ALTER TABLE scrape_runs DROP COLUMN compensated_rows;
+ALTER TABLE scrape_runs DROP COLUMN compensation_action;
+ALTER TABLE scrape_runs DROP COLUMN compensated_at;
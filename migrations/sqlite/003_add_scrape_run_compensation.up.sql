@@ -0,0 +1,8 @@
+-- Record the outcome of compensating a failed scrape run
+ALTER TABLE scrape_runs ADD COLUMN compensated_at TIMESTAMP;
+ALTER TABLE scrape_runs ADD COLUMN compensation_action TEXT;
+ALTER TABLE scrape_runs ADD COLUMN compensated_rows INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE scrape_runs ADD COLUMN compensation_error TEXT;
+
+-- Speed up "active rows only" queries used after invalidating a run
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_valid_to ON cost_data_points(valid_to);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_cost_data_points_natural_key;
+
+ALTER TABLE cost_data_points DROP COLUMN natural_key;
@@ -0,0 +1,7 @@
+-- Stable identity of a listing or tariff, used to make scraper re-runs idempotent.
+-- Mirrors models.CostDataPoint.NaturalKey. SQLite databases are created empty,
+-- so unlike the PostgreSQL migration there are no existing rows to backfill.
+ALTER TABLE cost_data_points ADD COLUMN natural_key TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cost_data_points_natural_key
+    ON cost_data_points(natural_key, recorded_at DESC);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_cost_data_points_active_last_seen;
+
+ALTER TABLE cost_data_points DROP COLUMN last_seen_at;
+ALTER TABLE cost_data_points DROP COLUMN first_seen_at;
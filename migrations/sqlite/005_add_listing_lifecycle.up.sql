@@ -0,0 +1,10 @@
+-- Track when each listing was first and most recently seen by a scraper.
+-- SQLite cannot add a NOT NULL column with a non-constant default, so the
+-- repository always writes both columns.
+ALTER TABLE cost_data_points ADD COLUMN first_seen_at TIMESTAMP;
+ALTER TABLE cost_data_points ADD COLUMN last_seen_at TIMESTAMP;
+
+-- Supports closing listings that were not seen in the latest scrape of a scope
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_active_last_seen
+    ON cost_data_points(source, category, last_seen_at)
+    WHERE valid_to IS NULL;
@@ -0,0 +1,4 @@
+-- Drop the revision history of cost data points
+DROP INDEX IF EXISTS idx_cost_data_point_revisions_actor;
+DROP INDEX IF EXISTS idx_cost_data_point_revisions_data_point;
+DROP TABLE IF EXISTS cost_data_point_revisions;
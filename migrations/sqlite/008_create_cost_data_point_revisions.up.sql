@@ -0,0 +1,21 @@
+-- Record every manual update and delete of a cost data point so corrections
+-- to scraped prices can be traced back to who made them and why.
+-- Versions 006 (rollups) and 007 (compression) are TimescaleDB features with
+-- no SQLite equivalent.
+CREATE TABLE IF NOT EXISTS cost_data_point_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    data_point_id TEXT NOT NULL,
+    recorded_at TIMESTAMP NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('update', 'delete')),
+    actor TEXT NOT NULL,
+    reason TEXT,
+    changed_fields TEXT CHECK (changed_fields IS NULL OR json_valid(changed_fields)),
+    old_values TEXT,
+    new_values TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_cost_data_point_revisions_data_point
+    ON cost_data_point_revisions(data_point_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_cost_data_point_revisions_actor
+    ON cost_data_point_revisions(actor, created_at DESC);
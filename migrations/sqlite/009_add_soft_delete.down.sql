@@ -0,0 +1,28 @@
+-- Restore revisions cannot be represented once the constraint is narrowed
+DELETE FROM cost_data_point_revisions WHERE action = 'restore';
+
+CREATE TABLE cost_data_point_revisions_old (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    data_point_id TEXT NOT NULL,
+    recorded_at TIMESTAMP NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('update', 'delete')),
+    actor TEXT NOT NULL,
+    reason TEXT,
+    changed_fields TEXT CHECK (changed_fields IS NULL OR json_valid(changed_fields)),
+    old_values TEXT,
+    new_values TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+INSERT INTO cost_data_point_revisions_old SELECT * FROM cost_data_point_revisions;
+DROP TABLE cost_data_point_revisions;
+ALTER TABLE cost_data_point_revisions_old RENAME TO cost_data_point_revisions;
+
+CREATE INDEX IF NOT EXISTS idx_cost_data_point_revisions_data_point
+    ON cost_data_point_revisions(data_point_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_cost_data_point_revisions_actor
+    ON cost_data_point_revisions(actor, created_at DESC);
+
+-- Soft-deleted rows become visible again
+DROP INDEX IF EXISTS idx_cost_data_points_deleted_at;
+ALTER TABLE cost_data_points DROP COLUMN deleted_at;
@@ -0,0 +1,31 @@
+-- Soft delete: rows deleted through the API keep their data and are hidden
+-- from reads until restored or purged
+ALTER TABLE cost_data_points ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_deleted_at
+    ON cost_data_points(deleted_at)
+    WHERE deleted_at IS NOT NULL;
+
+-- Restores are recorded in the revision history alongside updates and
+-- deletes. SQLite cannot alter a CHECK constraint, so the table is rebuilt.
+CREATE TABLE cost_data_point_revisions_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    data_point_id TEXT NOT NULL,
+    recorded_at TIMESTAMP NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('update', 'delete', 'restore')),
+    actor TEXT NOT NULL,
+    reason TEXT,
+    changed_fields TEXT CHECK (changed_fields IS NULL OR json_valid(changed_fields)),
+    old_values TEXT,
+    new_values TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+INSERT INTO cost_data_point_revisions_new SELECT * FROM cost_data_point_revisions;
+DROP TABLE cost_data_point_revisions;
+ALTER TABLE cost_data_point_revisions_new RENAME TO cost_data_point_revisions;
+
+CREATE INDEX IF NOT EXISTS idx_cost_data_point_revisions_data_point
+    ON cost_data_point_revisions(data_point_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_cost_data_point_revisions_actor
+    ON cost_data_point_revisions(actor, created_at DESC);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_cost_data_points_coordinates;
@@ -0,0 +1,8 @@
+-- Bounding-box lookups over location coordinates. SQLite has no
+-- earthdistance, so radius queries filter on this index first and then on
+-- an approximate distance computed in SQL.
+CREATE INDEX IF NOT EXISTS idx_cost_data_points_coordinates
+    ON cost_data_points(
+        json_extract(location, '$.coordinates.lat'),
+        json_extract(location, '$.coordinates.lon')
+    );
@@ -0,0 +1,64 @@
+-- Currency, unit and period used to share one free-text unit column
+-- ("AED/year", "fils_per_kwh", "AED per 1000 IG"). Split them so prices can
+-- be converted and compared: price is an amount of currency per unit, or for
+-- the whole item when unit is empty, and recurring charges carry the period
+-- they cover. Mirrors units.ParseLegacy.
+-- SQLite cannot change a column default without rebuilding the table; the
+-- repository always writes unit, so the old 'AED' default is left in place.
+ALTER TABLE cost_data_points ADD COLUMN currency TEXT NOT NULL DEFAULT 'AED';
+ALTER TABLE cost_data_points ADD COLUMN period TEXT NOT NULL DEFAULT '';
+
+-- Water tariffs quoted per 1000 imperial gallons become a price per gallon
+UPDATE cost_data_points SET
+    price = price / 1000,
+    min_price = min_price / 1000,
+    max_price = max_price / 1000,
+    median_price = median_price / 1000,
+    unit = 'IG'
+WHERE lower(unit) IN ('aed per 1000 ig', 'aed per 1000 gallons', '1000_ig');
+
+-- Prices quoted in fils become dirhams
+UPDATE cost_data_points SET
+    price = price / 100,
+    min_price = min_price / 100,
+    max_price = max_price / 100,
+    median_price = median_price / 100,
+    unit = CASE lower(unit) WHEN 'fils_per_kwh' THEN 'kWh' ELSE 'IG' END
+WHERE lower(unit) IN ('fils_per_kwh', 'fils_per_ig');
+
+UPDATE cost_data_points SET unit = 'kWh' WHERE lower(unit) IN ('aed/kwh', 'aed per kwh');
+UPDATE cost_data_points SET unit = 'km' WHERE lower(unit) = 'aed/km';
+UPDATE cost_data_points SET unit = 'minute' WHERE lower(unit) = 'aed/minute';
+UPDATE cost_data_points SET unit = '', period = 'year' WHERE lower(unit) = 'aed/year';
+UPDATE cost_data_points SET unit = '', period = 'month' WHERE lower(unit) = 'aed/month';
+UPDATE cost_data_points SET unit = 'fraction', currency = ''
+WHERE lower(unit) = 'percentage of water charge';
+
+-- Rows stored as plain "AED" take their unit from what the scraper recorded
+-- alongside the price
+UPDATE cost_data_points SET unit = CASE json_extract(attributes, '$.unit')
+    WHEN 'fils_per_ig' THEN 'IG'
+    ELSE 'kWh'
+END
+WHERE unit = 'AED' AND source = 'dewa_official' AND json_extract(attributes, '$.rate_type') = 'slab';
+
+UPDATE cost_data_points SET unit = CASE json_extract(attributes, '$.rate_type')
+    WHEN 'per_km' THEN 'km'
+    WHEN 'per_minute_wait' THEN 'minute'
+    ELSE 'trip'
+END
+WHERE unit = 'AED' AND source = 'careem_rates';
+
+UPDATE cost_data_points SET unit = CASE json_extract(attributes, '$.fare_type')
+    WHEN 'day_pass' THEN ''
+    ELSE 'trip'
+END
+WHERE unit = 'AED' AND source = 'rta_official';
+
+-- Listings: apartments are let by the year, shared rooms by the month
+UPDATE cost_data_points SET
+    unit = '',
+    period = CASE WHEN sub_category = 'Rent' THEN 'year' ELSE 'month' END
+WHERE unit = 'AED' AND category = 'Housing';
+
+UPDATE cost_data_points SET unit = '' WHERE upper(unit) = 'AED';
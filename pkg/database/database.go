@@ -9,15 +9,30 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Supported database drivers
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
 )
 
 // DB wraps the database connection
 type DB struct {
-	conn *sql.DB
+	conn   *sql.DB
+	driver string
 }
 
 // Config holds database configuration
 type Config struct {
+	// Driver selects the backend, DriverPostgres or DriverSQLite
+	Driver string
+
+	// Path is the SQLite database file; the remaining fields configure
+	// PostgreSQL
+	Path string
+
 	Host     string
 	Port     string
 	User     string
@@ -29,6 +44,8 @@ type Config struct {
 // NewConfigFromEnv creates a Config from environment variables
 func NewConfigFromEnv() *Config {
 	return &Config{
+		Driver:   getEnv("DB_DRIVER", DriverPostgres),
+		Path:     getEnv("SQLITE_PATH", "cost_of_living.db"),
 		Host:     getEnv("DB_HOST", "localhost"),
 		Port:     getEnv("DB_PORT", "5432"),
 		User:     getEnv("DB_USER", "postgres"),
@@ -40,6 +57,35 @@ func NewConfigFromEnv() *Config {
 
 // Connect establishes a connection to the database
 func Connect(cfg *Config) (*DB, error) {
+	var conn *sql.DB
+	var err error
+
+	switch cfg.Driver {
+	case "", DriverPostgres:
+		conn, err = openPostgres(cfg)
+	case DriverSQLite:
+		conn, err = openSQLite(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Test the connection
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := conn.PingContext(ctx); err != nil {
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	log.Println("Database connection established successfully")
+	return &DB{conn: conn, driver: cfg.driver()}, nil
+}
+
+// openPostgres opens a pooled PostgreSQL connection
+func openPostgres(cfg *Config) (*sql.DB, error) {
 	dsn := fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
 		cfg.Host, cfg.Port, cfg.User, cfg.Password, cfg.DBName, cfg.SSLMode,
@@ -56,16 +102,38 @@ func Connect(cfg *Config) (*DB, error) {
 	conn.SetConnMaxLifetime(5 * time.Minute)
 	conn.SetConnMaxIdleTime(10 * time.Minute)
 
-	// Test the connection
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	return conn, nil
+}
 
-	if err := conn.PingContext(ctx); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err)
+// openSQLite opens the SQLite database file, creating it if needed
+func openSQLite(cfg *Config) (*sql.DB, error) {
+	conn, err := sql.Open("sqlite3", SQLiteDSN(cfg.Path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
 
-	log.Println("Database connection established successfully")
-	return &DB{conn: conn}, nil
+	// SQLite allows a single writer; one connection avoids "database is
+	// locked" errors between our own goroutines. Repositories must not hold
+	// it while calling back into other code, which is why the SQLite Stream
+	// reads in pages.
+	conn.SetMaxOpenConns(1)
+
+	return conn, nil
+}
+
+// SQLiteDSN returns the data source name for a SQLite database file. Write
+// transactions take the lock up front so concurrent writers wait on the busy
+// timeout instead of failing on upgrade.
+func SQLiteDSN(path string) string {
+	return path + "?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=5000&_txlock=immediate"
+}
+
+// driver returns the configured driver, defaulting to PostgreSQL
+func (cfg *Config) driver() string {
+	if cfg.Driver == "" {
+		return DriverPostgres
+	}
+	return cfg.Driver
 }
 
 // Close closes the database connection
@@ -108,6 +176,11 @@ func (db *DB) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// Driver returns the backend the connection was opened with
+func (db *DB) Driver() string {
+	return db.driver
+}
+
 // GetConn returns the underlying *sql.DB connection
 func (db *DB) GetConn() *sql.DB {
 	return db.conn
@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -22,6 +23,49 @@ func TestNewConfigFromEnv(t *testing.T) {
 	if cfg.DBName != "cost_of_living" {
 		t.Errorf("expected dbname 'cost_of_living', got '%s'", cfg.DBName)
 	}
+
+	if cfg.Driver != DriverPostgres {
+		t.Errorf("expected driver '%s', got '%s'", DriverPostgres, cfg.Driver)
+	}
+}
+
+func TestNewConfigFromEnvSQLite(t *testing.T) {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("SQLITE_PATH", "/tmp/col.db")
+
+	cfg := NewConfigFromEnv()
+
+	if cfg.Driver != DriverSQLite {
+		t.Errorf("expected driver '%s', got '%s'", DriverSQLite, cfg.Driver)
+	}
+
+	if cfg.Path != "/tmp/col.db" {
+		t.Errorf("expected path '/tmp/col.db', got '%s'", cfg.Path)
+	}
+}
+
+func TestConnectSQLite(t *testing.T) {
+	cfg := &Config{Driver: DriverSQLite, Path: filepath.Join(t.TempDir(), "test.db")}
+
+	db, err := Connect(cfg)
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	defer db.Close()
+
+	if db.Driver() != DriverSQLite {
+		t.Errorf("expected driver '%s', got '%s'", DriverSQLite, db.Driver())
+	}
+
+	if err := db.HealthCheck(context.Background()); err != nil {
+		t.Errorf("HealthCheck failed: %v", err)
+	}
+}
+
+func TestConnectUnsupportedDriver(t *testing.T) {
+	if _, err := Connect(&Config{Driver: "mysql"}); err == nil {
+		t.Error("expected error for unsupported driver")
+	}
 }
 
 func TestNewConfigFromEnvWithCustomValues(t *testing.T) {
@@ -15,6 +15,15 @@ var (
 		[]string{"method", "endpoint", "status"},
 	)
 
+	// RateLimitRejectedTotal counts requests rejected by the rate limiter
+	RateLimitRejectedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_rate_limited_total",
+			Help: "Total number of HTTP requests rejected with 429 by the rate limiter",
+		},
+		[]string{"endpoint", "client"},
+	)
+
 	// ScraperRunsTotal counts the total number of scraper runs
 	ScraperRunsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -51,4 +60,40 @@ var (
 		},
 		[]string{"scraper"},
 	)
+
+	// ListingsDelistedTotal counts listings closed because they disappeared from a scrape
+	ListingsDelistedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "listings_delisted_total",
+			Help: "Total number of listings closed after no longer appearing in scrapes",
+		},
+		[]string{"source"},
+	)
+
+	// RepositoryCacheHitsTotal counts repository reads served from the cache
+	RepositoryCacheHitsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "repository_cache_hits_total",
+			Help: "Total number of repository reads served from the in-process cache",
+		},
+		[]string{"operation"},
+	)
+
+	// RepositoryCacheMissesTotal counts repository reads that had to query the database
+	RepositoryCacheMissesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "repository_cache_misses_total",
+			Help: "Total number of repository reads not found in the in-process cache",
+		},
+		[]string{"operation"},
+	)
+
+	// RetentionRowsArchivedTotal counts rows archived and deleted by the retention job
+	RetentionRowsArchivedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "retention_rows_archived_total",
+			Help: "Total number of expired cost data points archived and deleted",
+		},
+		[]string{"category"},
+	)
 )
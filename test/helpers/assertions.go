@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/adonese/cost-of-living/internal/models"
+	"github.com/adonese/cost-of-living/internal/units"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -21,7 +22,11 @@ func AssertCostDataPoint(t *testing.T, cdp *models.CostDataPoint, expectations C
 	assert.NotEmpty(t, cdp.Category, "Category should not be empty")
 	assert.NotEmpty(t, cdp.ItemName, "ItemName should not be empty")
 	assert.NotEmpty(t, cdp.Source, "Source should not be empty")
-	assert.NotEmpty(t, cdp.Unit, "Unit should not be empty")
+	assert.True(t, units.ValidUnit(cdp.Unit), "Unit %q is not a known unit", cdp.Unit)
+	assert.True(t, units.ValidPeriod(cdp.Period), "Period %q is not a known period", cdp.Period)
+	if cdp.Unit != units.Fraction {
+		assert.NotEmpty(t, cdp.Currency, "Currency should not be empty")
+	}
 
 	// Price validation
 	if expectations.MinPrice > 0 {
@@ -107,7 +107,8 @@ func TestAADCIntegration_ElectricityRates(t *testing.T) {
 		assert.Contains(t, dp.ItemName, "AADC Electricity")
 		assert.Greater(t, dp.Price, 0.0)
 		assert.Less(t, dp.Price, 1.0) // All electricity rates should be < 1 AED per kWh
-		assert.Equal(t, "AED per kWh", dp.Unit)
+		assert.Equal(t, "AED", dp.Currency)
+		assert.Equal(t, "kWh", dp.Unit)
 		assert.Contains(t, dp.Tags, "electricity")
 
 		// Check attributes
@@ -163,7 +164,8 @@ func TestAADCIntegration_WaterRates(t *testing.T) {
 		assert.Equal(t, "Water", dp.SubCategory)
 		assert.Contains(t, dp.ItemName, "AADC Water")
 		assert.Greater(t, dp.Price, 0.0)
-		assert.Equal(t, "AED per 1000 IG", dp.Unit)
+		assert.Equal(t, "AED", dp.Currency)
+		assert.Equal(t, "IG", dp.Unit)
 		assert.Contains(t, dp.Tags, "water")
 
 		// Check attributes
@@ -225,7 +225,8 @@ func TestBayutScraperDataValidation(t *testing.T) {
 			assert.Equal(t, "Housing", dp.Category)
 			assert.Equal(t, "Rent", dp.SubCategory)
 			assert.Equal(t, "bayut", dp.Source)
-			assert.Equal(t, "AED", dp.Unit)
+			assert.Equal(t, "AED", dp.Currency)
+			assert.Equal(t, "year", dp.Period)
 			assert.Contains(t, dp.Tags, "bayut")
 		}
 	}
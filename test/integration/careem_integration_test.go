@@ -53,8 +53,8 @@ func TestCareemIntegration_WithStaticSource(t *testing.T) {
 		if dp.SubCategory != "Ride Sharing" {
 			t.Errorf("Expected subcategory Ride Sharing, got %s", dp.SubCategory)
 		}
-		if dp.Unit != "AED" {
-			t.Errorf("Expected unit AED, got %s", dp.Unit)
+		if dp.Currency != "AED" {
+			t.Errorf("Expected currency AED, got %s", dp.Currency)
 		}
 		if dp.Price <= 0 {
 			t.Errorf("Expected positive price, got %f", dp.Price)
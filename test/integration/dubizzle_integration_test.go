@@ -328,7 +328,8 @@ func TestDubizzleScraperDataValidation(t *testing.T) {
 			assert.Equal(t, "Housing", dp.Category)
 			assert.Contains(t, []string{"Rent", "Shared Accommodation"}, dp.SubCategory)
 			assert.Equal(t, "dubizzle", dp.Source)
-			assert.Equal(t, "AED", dp.Unit)
+			assert.Equal(t, "AED", dp.Currency)
+			assert.Contains(t, []string{"month", "year"}, dp.Period)
 			assert.Contains(t, dp.Tags, "dubizzle")
 		}
 	}
@@ -10,6 +10,7 @@ import (
 	"github.com/adonese/cost-of-living/internal/models"
 	"github.com/adonese/cost-of-living/internal/repository"
 	"github.com/adonese/cost-of-living/internal/repository/postgres"
+	"github.com/adonese/cost-of-living/internal/units"
 	"github.com/adonese/cost-of-living/pkg/database"
 	"github.com/stretchr/testify/require"
 )
@@ -108,7 +109,10 @@ func ValidateCostDataPoint(t *testing.T, cdp *models.CostDataPoint) {
 	require.NotEmpty(t, cdp.Location.Emirate, "Emirate should not be empty")
 	require.NotEmpty(t, cdp.Source, "Source should not be empty")
 	require.Greater(t, cdp.Confidence, float32(0), "Confidence should be greater than 0")
-	require.NotEmpty(t, cdp.Unit, "Unit should not be empty")
+	require.True(t, units.ValidUnit(cdp.Unit), "Unit %q is not a known unit", cdp.Unit)
+	if cdp.Unit != units.Fraction {
+		require.NotEmpty(t, cdp.Currency, "Currency should not be empty")
+	}
 	require.NotZero(t, cdp.RecordedAt, "RecordedAt should not be zero")
 	require.NotZero(t, cdp.ValidFrom, "ValidFrom should not be zero")
 }
@@ -161,7 +165,9 @@ func AssertDataPointsEqual(t *testing.T, expected, actual *models.CostDataPoint)
 	require.Equal(t, expected.Location.City, actual.Location.City, "City mismatch")
 	require.Equal(t, expected.Location.Area, actual.Location.Area, "Area mismatch")
 	require.Equal(t, expected.Source, actual.Source, "Source mismatch")
+	require.Equal(t, expected.Currency, actual.Currency, "Currency mismatch")
 	require.Equal(t, expected.Unit, actual.Unit, "Unit mismatch")
+	require.Equal(t, expected.Period, actual.Period, "Period mismatch")
 }
 
 // CreateTestDataPoint creates a test CostDataPoint
@@ -180,7 +186,7 @@ func CreateTestDataPoint(category, itemName, source string, price float64) *mode
 		Source:      source,
 		SourceURL:   "https://example.com/test",
 		Confidence:  0.8,
-		Unit:        "AED",
+		Currency:    "AED",
 		RecordedAt:  now,
 		ValidFrom:   now,
 		SampleSize:  1,
@@ -310,8 +310,8 @@ func TestRTAScraperTaxiFares(t *testing.T) {
 		if rtaContains(itemName, "Kilometer") || rtaContains(itemName, "kilometer") || rtaContains(itemName, "km") {
 			hasPerKm = true
 			// Validate unit
-			if dp.Unit != "AED/km" {
-				t.Errorf("Expected unit AED/km for per km fare, got %s", dp.Unit)
+			if dp.Unit != "km" {
+				t.Errorf("Expected unit km for per km fare, got %s", dp.Unit)
 			}
 		}
 
@@ -387,8 +387,8 @@ func TestRTAScraperDataQuality(t *testing.T) {
 			t.Errorf("Data point %d: invalid price %f", i, dp.Price)
 		}
 
-		if dp.Unit == "" {
-			t.Errorf("Data point %d: missing unit", i)
+		if dp.Currency == "" {
+			t.Errorf("Data point %d: missing currency", i)
 		}
 
 		if dp.Source == "" {
@@ -118,13 +118,13 @@ func TestSEWAScraper_Integration(t *testing.T) {
 		require.NotNil(t, expatWater, "should have Expatriate water rate")
 
 		// Verify Emirati water rate
-		assert.Equal(t, 8.0, emiratiWater.Price)
-		assert.Equal(t, "AED per 1000 gallons", emiratiWater.Unit)
+		assert.InDelta(t, 0.008, emiratiWater.Price, 1e-9) // AED 8 per 1000 gallons
+		assert.Equal(t, "IG", emiratiWater.Unit)
 		assert.Contains(t, emiratiWater.ItemName, "Emirati")
 
 		// Verify Expatriate water rate
-		assert.Equal(t, 15.0, expatWater.Price)
-		assert.Equal(t, "AED per 1000 gallons", expatWater.Unit)
+		assert.InDelta(t, 0.015, expatWater.Price, 1e-9)
+		assert.Equal(t, "IG", expatWater.Unit)
 		assert.Contains(t, expatWater.ItemName, "Expatriate")
 	})
 
@@ -142,7 +142,7 @@ func TestSEWAScraper_Integration(t *testing.T) {
 		assert.Equal(t, "Utilities", sewerage.Category)
 		assert.Equal(t, "SEWA Sewerage Charge", sewerage.ItemName)
 		assert.Equal(t, 0.50, sewerage.Price, "50% stored as decimal")
-		assert.Equal(t, "percentage of water charge", sewerage.Unit)
+		assert.Equal(t, "fraction", sewerage.Unit)
 		assert.Contains(t, sewerage.Attributes, "calculation_method")
 	})
 
@@ -402,6 +402,51 @@ func (m *MockRepository) List(ctx context.Context, filter repository.ListFilter)
 	return result, nil
 }
 
-func (m *MockRepository) Count(ctx context.Context, filter repository.ListFilter) (int, error) {
-	return len(m.items), nil
+func (m *MockRepository) Count(ctx context.Context, filter repository.ListFilter) (int64, error) {
+	return int64(len(m.items)), nil
+}
+
+func (m *MockRepository) InvalidateByRunID(ctx context.Context, runID string, validTo time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockRepository) DeleteByRunID(ctx context.Context, runID string) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockRepository) CreateBatch(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	for _, cdp := range cdps {
+		_ = m.Create(ctx, cdp)
+	}
+	return &repository.BatchResult{Inserted: len(cdps)}, nil
+}
+
+func (m *MockRepository) Upsert(ctx context.Context, cdps []*models.CostDataPoint) (*repository.BatchResult, error) {
+	return m.CreateBatch(ctx, cdps)
+}
+
+func (m *MockRepository) CloseUnseenListings(ctx context.Context, scope repository.ListingScope, seenBefore, validTo time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (m *MockRepository) Stream(ctx context.Context, filter repository.ListFilter, fn func(*models.CostDataPoint) error) error {
+	items, _ := m.List(ctx, filter)
+	for _, item := range items {
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) History(ctx context.Context, id string) ([]*models.Revision, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) SoftDelete(ctx context.Context, id string, recordedAt time.Time) error {
+	return m.Delete(ctx, id, recordedAt)
+}
+
+func (m *MockRepository) Restore(ctx context.Context, id string, recordedAt time.Time) (*models.CostDataPoint, error) {
+	return nil, assert.AnError
 }
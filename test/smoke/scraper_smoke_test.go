@@ -149,7 +149,7 @@ func TestScraperSmokeSuite(t *testing.T) {
 			assert.Greater(t, result.Fetched, 0, "should fetch items")
 			assert.Greater(t, result.Saved, 0, "should persist items")
 			assert.Empty(t, result.Errors, "should not record errors")
-			assert.Greater(t, repo.GetCallCount("Create"), 0, "repository should receive creations")
+			assert.Greater(t, repo.GetCallCount("Upsert"), 0, "repository should receive upserts")
 		})
 	}
 }
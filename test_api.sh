@@ -12,6 +12,8 @@ echo -e "${BLUE}========================================${NC}"
 echo ""
 
 BASE_URL="http://localhost:8080"
+# Writes need a contributor key: go run ./cmd/apikey issue -name test -role contributor
+API_KEY="${API_KEY:?set API_KEY to a contributor API key}"
 
 # Function to print test header
 print_test() {
@@ -41,6 +43,7 @@ fi
 # 2. Test Create Cost Data Point
 print_test "2. Create Cost Data Point (Housing)"
 response=$(curl -s -X POST "${BASE_URL}/api/v1/cost-data-points" \
+  -H "Authorization: Bearer ${API_KEY}" \
   -H "Content-Type: application/json" \
   -d '{
     "category": "Housing",
@@ -63,6 +66,7 @@ fi
 # 3. Test Create with Validation Error
 print_test "3. Create with Validation Error (Missing Required Fields)"
 response=$(curl -s -X POST "${BASE_URL}/api/v1/cost-data-points" \
+  -H "Authorization: Bearer ${API_KEY}" \
   -H "Content-Type: application/json" \
   -d '{"category": "Housing", "price": 85000}')
 echo "Response: $response"
@@ -109,6 +113,7 @@ fi
 # 7. Create another record for more testing
 print_test "7. Create Another Cost Data Point (Food)"
 response=$(curl -s -X POST "${BASE_URL}/api/v1/cost-data-points" \
+  -H "Authorization: Bearer ${API_KEY}" \
   -H "Content-Type: application/json" \
   -d '{
     "category": "Food",
@@ -142,6 +147,7 @@ if [ ! -z "$ID" ] && [ ! -z "$RECORDED_AT" ]; then
     print_test "9. Update Cost Data Point"
     ENCODED_TIME=$(echo "$RECORDED_AT" | sed 's/+/%2B/g')
     response=$(curl -s -X PUT "${BASE_URL}/api/v1/cost-data-points/${ID}?recorded_at=${ENCODED_TIME}" \
+  -H "Authorization: Bearer ${API_KEY}" \
       -H "Content-Type: application/json" \
       -d '{"price": 90000}')
     echo "Response: $response"
@@ -157,6 +163,7 @@ if [ ! -z "$FOOD_ID" ] && [ ! -z "$FOOD_RECORDED_AT" ]; then
     print_test "10. Delete Cost Data Point"
     ENCODED_TIME=$(echo "$FOOD_RECORDED_AT" | sed 's/+/%2B/g')
     status_code=$(curl -s -o /dev/null -w "%{http_code}" \
+      -H "Authorization: Bearer ${API_KEY}" \
       -X DELETE "${BASE_URL}/api/v1/cost-data-points/${FOOD_ID}?recorded_at=${ENCODED_TIME}")
     echo "HTTP Status: $status_code"
     if [ "$status_code" = "204" ]; then